@@ -93,13 +93,22 @@ profiles:
 						{
 							Name: coscheduling.Name,
 							Args: &config.CoschedulingArgs{
-								PermitWaitingTimeSeconds: 60,
+								PermitWaitingTimeSeconds:          60,
+								PodGroupMaxBackoffSeconds:         300,
+								EarlyRejectionThresholdPercentage: 10,
+								TopologyKey:                       "topology.kubernetes.io/zone",
+								QueueSortMode:                     "Pod",
+								PodGroupAgingSeconds:              600,
+								FairShareWindowSeconds:            300,
+								MinCandidateNodesPercentage:       10,
+								MinCandidateNodesAbsolute:         100,
 							},
 						},
 						{
 							Name: topologicalsort.Name,
 							Args: &config.TopologicalSortArgs{
-								Namespaces: []string{"networkAware"},
+								Namespaces:        []string{"networkAware"},
+								OrderingAlgorithm: config.OrderingAlgorithmAppGroup,
 							},
 						},
 						{
@@ -108,6 +117,7 @@ profiles:
 								Namespaces:          []string{"networkAware"},
 								WeightsName:         "netCosts",
 								NetworkTopologyName: "net-topology-v1",
+								TopologyKeys:        []string{corev1.LabelTopologyRegion, corev1.LabelTopologyZone},
 							},
 						},
 						{
@@ -168,7 +178,8 @@ profiles:
 						{
 							Name: topologicalsort.Name,
 							Args: &config.TopologicalSortArgs{
-								Namespaces: []string{"default"},
+								Namespaces:        []string{"default"},
+								OrderingAlgorithm: config.OrderingAlgorithmAppGroup,
 							},
 						},
 						{
@@ -177,6 +188,7 @@ profiles:
 								Namespaces:          []string{"default"},
 								WeightsName:         "UserDefined",
 								NetworkTopologyName: "nt-default",
+								TopologyKeys:        []string{corev1.LabelTopologyRegion, corev1.LabelTopologyZone},
 							},
 						},
 						{
@@ -280,12 +292,19 @@ func TestCodecsEncodePluginConfig(t *testing.T) {
 											Type:    config.Prometheus,
 											Address: "http://prometheus-k8s.monitoring.svc.cluster.local:9090",
 										},
-										WatcherAddress: "http://deadbeef:2020"},
+										WatcherAddress:               "http://deadbeef:2020",
+										MetricsUpdateIntervalSeconds: 30,
+										StaleMetricsPolicy:           config.StaleMetricsPolicyNeutral},
 									TargetUtilization: 60,
 									DefaultRequests: corev1.ResourceList{
 										corev1.ResourceCPU: testCPUQuantity,
 									},
 									DefaultRequestsMultiplier: "1.8",
+									Forecast: config.ForecastSpec{
+										Algorithm:      config.ForecastAlgorithmNone,
+										HorizonSeconds: v1.DefaultForecastHorizonSeconds,
+										SeasonSeconds:  v1.DefaultForecastSeasonSeconds,
+									},
 								},
 							},
 							{
@@ -297,9 +316,16 @@ func TestCodecsEncodePluginConfig(t *testing.T) {
 											Address:            "http://prometheus-k8s.monitoring.svc.cluster.local:9090",
 											InsecureSkipVerify: false,
 										},
-										WatcherAddress: "http://deadbeef:2020"},
+										WatcherAddress:               "http://deadbeef:2020",
+										MetricsUpdateIntervalSeconds: 30,
+										StaleMetricsPolicy:           config.StaleMetricsPolicyNeutral},
 									SafeVarianceMargin:      v1.DefaultSafeVarianceMargin,
 									SafeVarianceSensitivity: v1.DefaultSafeVarianceSensitivity,
+									Forecast: config.ForecastSpec{
+										Algorithm:      config.ForecastAlgorithmNone,
+										HorizonSeconds: v1.DefaultForecastHorizonSeconds,
+										SeasonSeconds:  v1.DefaultForecastSeasonSeconds,
+									},
 								},
 							},
 							{
@@ -311,7 +337,9 @@ func TestCodecsEncodePluginConfig(t *testing.T) {
 											Address:            "http://prometheus-k8s.monitoring.svc.cluster.local:9090",
 											InsecureSkipVerify: false,
 										},
-										WatcherAddress: "http://deadbeef:2020"},
+										WatcherAddress:               "http://deadbeef:2020",
+										MetricsUpdateIntervalSeconds: 30,
+										StaleMetricsPolicy:           config.StaleMetricsPolicyNeutral},
 									SmoothingWindowSize: v1.DefaultSmoothingWindowSize,
 									RiskLimitWeights: map[corev1.ResourceName]float64{
 										corev1.ResourceCPU:    v1.DefaultRiskLimitWeight,
@@ -322,7 +350,8 @@ func TestCodecsEncodePluginConfig(t *testing.T) {
 							{
 								Name: topologicalsort.Name,
 								Args: &config.TopologicalSortArgs{
-									Namespaces: []string{"default"},
+									Namespaces:        []string{"default"},
+									OrderingAlgorithm: config.OrderingAlgorithmAppGroup,
 								},
 							},
 							{
@@ -362,9 +391,18 @@ profiles:
 - pluginConfig:
   - args:
       apiVersion: kubescheduler.config.k8s.io/v1
+      earlyRejectionThresholdPercentage: 0
+      enableGangPreemption: false
+      fairShareWindowSeconds: 0
       kind: CoschedulingArgs
+      minCandidateNodesAbsolute: 0
+      minCandidateNodesPercentage: 0
       permitWaitingTimeSeconds: 10
+      podGroupAgingSeconds: 0
       podGroupBackoffSeconds: 0
+      podGroupMaxBackoffSeconds: 0
+      queueSortMode: ""
+      topologyKey: ""
     name: Coscheduling
   - args:
       apiVersion: kubescheduler.config.k8s.io/v1
@@ -381,25 +419,39 @@ profiles:
       defaultRequests:
         cpu: "1"
       defaultRequestsMultiplier: "1.8"
+      forecast:
+        algorithm: None
+        horizonSeconds: 900
+        seasonSeconds: 86400
       kind: TargetLoadPackingArgs
       metricProvider:
         address: http://prometheus-k8s.monitoring.svc.cluster.local:9090
         insecureSkipVerify: false
         token: ""
         type: Prometheus
+      metricsMaxStalenessSeconds: 0
+      metricsUpdateIntervalSeconds: 30
+      staleMetricsPolicy: Neutral
       targetUtilization: 60
       watcherAddress: http://deadbeef:2020
     name: TargetLoadPacking
   - args:
       apiVersion: kubescheduler.config.k8s.io/v1
+      forecast:
+        algorithm: None
+        horizonSeconds: 900
+        seasonSeconds: 86400
       kind: LoadVariationRiskBalancingArgs
       metricProvider:
         address: http://prometheus-k8s.monitoring.svc.cluster.local:9090
         insecureSkipVerify: false
         token: ""
         type: Prometheus
+      metricsMaxStalenessSeconds: 0
+      metricsUpdateIntervalSeconds: 30
       safeVarianceMargin: 1
       safeVarianceSensitivity: 1
+      staleMetricsPolicy: Neutral
       watcherAddress: http://deadbeef:2020
     name: LoadVariationRiskBalancing
   - args:
@@ -410,10 +462,13 @@ profiles:
         insecureSkipVerify: false
         token: ""
         type: Prometheus
+      metricsMaxStalenessSeconds: 0
+      metricsUpdateIntervalSeconds: 30
       riskLimitWeights:
         cpu: 0.5
         memory: 0.5
       smoothingWindowSize: 5
+      staleMetricsPolicy: Neutral
       watcherAddress: http://deadbeef:2020
     name: LowRiskOverCommitment
   - args:
@@ -421,6 +476,7 @@ profiles:
       kind: TopologicalSortArgs
       namespaces:
       - default
+      orderingAlgorithm: AppGroup
     name: TopologicalSort
   - args:
       apiVersion: kubescheduler.config.k8s.io/v1
@@ -428,6 +484,7 @@ profiles:
       namespaces:
       - default
       networkTopologyName: net-topology-v1
+      placementPlanning: false
       weightsName: netCosts
     name: NetworkOverhead
   schedulerName: scheduler-plugins
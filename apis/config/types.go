@@ -30,10 +30,47 @@ type CoschedulingArgs struct {
 
 	// PermitWaitingTimeSeconds is the waiting timeout in seconds.
 	PermitWaitingTimeSeconds int64
-	// PodGroupBackoffSeconds is the backoff time in seconds before a pod group can be scheduled again.
+	// PodGroupBackoffSeconds is the initial backoff time in seconds before a pod group can be scheduled again.
+	// The backoff doubles on every consecutive failure of the pod group, and is reset once it gets scheduled.
 	PodGroupBackoffSeconds int64
+	// PodGroupMaxBackoffSeconds is the upper bound of the backoff time in seconds of a pod group.
+	PodGroupMaxBackoffSeconds int64
+	// EarlyRejectionThresholdPercentage is the percentage of minMember below which the number of
+	// pods missing to reach the quorum is considered a small gap, in which case the pod group
+	// is not rejected in PostFilter so that subsequent pods still get a chance to satisfy it.
+	EarlyRejectionThresholdPercentage int32
+	// TopologyKey is the node label key used to determine the topology domain
+	// in which members of a pod group prefer to be co-located.
+	TopologyKey string
+	// EnableGangPreemption allows a pod group to preempt lower-priority pods as a whole
+	// when its minimum cannot be satisfied otherwise.
+	EnableGangPreemption bool
+	// QueueSortMode is the granularity at which pods are ordered in the scheduling queue,
+	// either "Pod" or "PodGroup".
+	QueueSortMode string
+	// PodGroupAgingSeconds is the time in seconds after which a waiting pod group moves ahead
+	// of the pod groups of the same priority, regardless of fair share, in the PodGroup queue sort mode.
+	// Zero disables aging.
+	PodGroupAgingSeconds int64
+	// FairShareWindowSeconds is the time window in seconds during which the pod groups admitted
+	// in a namespace count against its fair share in the PodGroup queue sort mode.
+	FairShareWindowSeconds int64
+	// MinCandidateNodesPercentage is the minimum number of nodes the gang preemption dry runs are
+	// bounded to, as a percentage of the nodes where preemption may help.
+	MinCandidateNodesPercentage int32
+	// MinCandidateNodesAbsolute is the minimum absolute number of nodes the gang preemption dry runs
+	// are bounded to. The larger of the two minimums is used.
+	MinCandidateNodesAbsolute int32
 }
 
+const (
+	// QueueSortModePod orders pods by their priorities, then by the creation time of their pod groups.
+	QueueSortModePod = "Pod"
+	// QueueSortModePodGroup orders pods at pod group granularity, by group priority, aging and
+	// the fair share of their namespaces, keeping the siblings of a pod group contiguous.
+	QueueSortModePodGroup = "PodGroup"
+)
+
 // ModeType is a "string" type.
 type ModeType string
 
@@ -88,8 +125,43 @@ type TrimaranSpec struct {
 	MetricProvider MetricProviderSpec
 	// Address of load watcher service
 	WatcherAddress string
+	// Interval in seconds between updates of the metrics from load watcher
+	MetricsUpdateIntervalSeconds int64
+	// Maximum age in seconds of the metrics from load watcher before they are stale, 0 to never consider them stale
+	MetricsMaxStalenessSeconds int64
+	// Policy scoring nodes when the metrics are stale
+	StaleMetricsPolicy string
 }
 
+const (
+	// StaleMetricsPolicyNeutral scores nodes neutrally when the metrics are stale.
+	StaleMetricsPolicyNeutral = "Neutral"
+	// StaleMetricsPolicyRequests scores nodes from the requests of their pods when the metrics are stale.
+	StaleMetricsPolicyRequests = "Requests"
+	// StaleMetricsPolicyUnknown scores nodes as nodes without metrics when the metrics are stale.
+	StaleMetricsPolicyUnknown = "Unknown"
+)
+
+// ForecastSpec holds parameters to score nodes on their forecasted load, for trimaran plugins
+type ForecastSpec struct {
+	// Algorithm forecasting the load of nodes from their history
+	Algorithm string
+	// Horizon in seconds of the forecast, nodes being scored on their peak load forecasted within it
+	HorizonSeconds int64
+	// Period in seconds of the seasonality of the load, for the HoltWinters algorithm
+	SeasonSeconds int64
+}
+
+const (
+	// ForecastAlgorithmNone scores nodes on their current load.
+	ForecastAlgorithmNone = "None"
+	// ForecastAlgorithmEWMA forecasts the load with an exponentially weighted moving average.
+	ForecastAlgorithmEWMA = "EWMA"
+	// ForecastAlgorithmHoltWinters forecasts the load with additive Holt-Winters triple exponential
+	// smoothing, following its trend and seasonality.
+	ForecastAlgorithmHoltWinters = "HoltWinters"
+)
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // TargetLoadPackingArgs holds arguments used to configure TargetLoadPacking plugin.
@@ -104,6 +176,8 @@ type TargetLoadPackingArgs struct {
 	DefaultRequestsMultiplier string
 	// Node target CPU Utilization for bin packing
 	TargetUtilization int64
+	// Forecast of the load to score nodes on
+	Forecast ForecastSpec
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -118,6 +192,8 @@ type LoadVariationRiskBalancingArgs struct {
 	SafeVarianceMargin float64
 	// Root power of standard deviation in risk value
 	SafeVarianceSensitivity float64
+	// Forecast of the load to score nodes on
+	Forecast ForecastSpec
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -251,8 +327,23 @@ type TopologicalSortArgs struct {
 
 	// Namespaces to be considered by TopologySort plugin
 	Namespaces []string
+
+	// OrderingAlgorithm is the algorithm ordering the workloads of an AppGroup, either "AppGroup",
+	// "KahnSort" or "ReverseKahn".
+	OrderingAlgorithm string
 }
 
+const (
+	// OrderingAlgorithmAppGroup orders workloads as in the status of the AppGroup, workloads
+	// missing from it being ordered after the others as with OrderingAlgorithmKahnSort.
+	OrderingAlgorithmAppGroup = "AppGroup"
+	// OrderingAlgorithmKahnSort orders workloads before their dependencies, the workloads of a
+	// dependency cycle being ordered together.
+	OrderingAlgorithmKahnSort = "KahnSort"
+	// OrderingAlgorithmReverseKahn orders dependencies before the workloads depending on them.
+	OrderingAlgorithmReverseKahn = "ReverseKahn"
+)
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 type NetworkOverheadArgs struct {
@@ -266,6 +357,14 @@ type NetworkOverheadArgs struct {
 
 	// The NetworkTopology CRD name
 	NetworkTopologyName string
+
+	// Node label keys of the topology levels with network costs, from the broadest to the narrowest
+	// (Default: topology.kubernetes.io/region, topology.kubernetes.io/zone)
+	TopologyKeys []string
+
+	// PlacementPlanning plans the placement of all the workloads of an AppGroup upon the arrival of its first pod,
+	// and favors the planned topology domains when scoring its later pods (Default: false)
+	PlacementPlanning bool
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
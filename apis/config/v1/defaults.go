@@ -24,11 +24,24 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	schedulerconfigv1 "k8s.io/kube-scheduler/config/v1"
 	k8sschedulerconfigv1 "k8s.io/kubernetes/pkg/scheduler/apis/config/v1"
+
+	"sigs.k8s.io/scheduler-plugins/apis/config"
 )
 
 var (
-	defaultPermitWaitingTimeSeconds int64 = 60
-	defaultPodGroupBackoffSeconds   int64 = 0
+	defaultPermitWaitingTimeSeconds          int64 = 60
+	defaultPodGroupBackoffSeconds            int64 = 0
+	defaultPodGroupMaxBackoffSeconds         int64 = 300
+	defaultEarlyRejectionThresholdPercentage int32 = 10
+	defaultCoschedulingTopologyKey                 = v1.LabelTopologyZone
+	defaultEnableGangPreemption                    = false
+	defaultPlacementPlanning                       = false
+	defaultOrderingAlgorithm                       = config.OrderingAlgorithmAppGroup
+	defaultQueueSortMode                           = "Pod"
+	defaultPodGroupAgingSeconds              int64 = 600
+	defaultFairShareWindowSeconds            int64 = 300
+	defaultMinCandidateNodesPercentage       int32 = 10
+	defaultMinCandidateNodesAbsolute         int32 = 100
 
 	defaultNodeResourcesAllocatableMode = Least
 
@@ -79,6 +92,18 @@ var (
 	DefaultMetricProviderType = KubernetesMetricsServer
 	// DefaultInsecureSkipVerify is whether to skip the certificate verification
 	DefaultInsecureSkipVerify = true
+	// DefaultMetricsUpdateIntervalSeconds is the interval in seconds between updates of the metrics
+	DefaultMetricsUpdateIntervalSeconds int64 = 30
+	// DefaultMetricsMaxStalenessSeconds is the maximum age in seconds of the metrics, 0 never considering them stale
+	DefaultMetricsMaxStalenessSeconds int64 = 0
+	// DefaultStaleMetricsPolicy is the policy scoring nodes when the metrics are stale
+	DefaultStaleMetricsPolicy = "Neutral"
+	// DefaultForecastAlgorithm is the algorithm forecasting the load, None scoring nodes on their current load
+	DefaultForecastAlgorithm = "None"
+	// DefaultForecastHorizonSeconds is the horizon in seconds of the load forecast
+	DefaultForecastHorizonSeconds int64 = 900
+	// DefaultForecastSeasonSeconds is the period in seconds of the seasonality of the load
+	DefaultForecastSeasonSeconds int64 = 86400
 
 	defaultResourceSpec = []schedulerconfigv1.ResourceSpec{
 		{Name: string(v1.ResourceCPU), Weight: 1},
@@ -96,6 +121,8 @@ var (
 	DefaultWeightsName = "UserDefined"
 	// DefaultNetworkTopologyName contains the networkTopology CR name to be used by networkAware plugins
 	DefaultNetworkTopologyName = "nt-default"
+	// DefaultNetworkTopologyKeys contains the topology levels with network costs to be used by networkAware plugins
+	DefaultNetworkTopologyKeys = []string{v1.LabelTopologyRegion, v1.LabelTopologyZone}
 
 	// Defaults for SySched
 	// DefaultSySchedProfileNamespace is the namesapce of the default syscall profile CR for SySched plugin
@@ -112,6 +139,33 @@ func SetDefaults_CoschedulingArgs(obj *CoschedulingArgs) {
 	if obj.PodGroupBackoffSeconds == nil {
 		obj.PodGroupBackoffSeconds = &defaultPodGroupBackoffSeconds
 	}
+	if obj.PodGroupMaxBackoffSeconds == nil {
+		obj.PodGroupMaxBackoffSeconds = &defaultPodGroupMaxBackoffSeconds
+	}
+	if obj.EarlyRejectionThresholdPercentage == nil {
+		obj.EarlyRejectionThresholdPercentage = &defaultEarlyRejectionThresholdPercentage
+	}
+	if obj.TopologyKey == nil {
+		obj.TopologyKey = &defaultCoschedulingTopologyKey
+	}
+	if obj.EnableGangPreemption == nil {
+		obj.EnableGangPreemption = &defaultEnableGangPreemption
+	}
+	if obj.QueueSortMode == nil {
+		obj.QueueSortMode = &defaultQueueSortMode
+	}
+	if obj.PodGroupAgingSeconds == nil {
+		obj.PodGroupAgingSeconds = &defaultPodGroupAgingSeconds
+	}
+	if obj.FairShareWindowSeconds == nil {
+		obj.FairShareWindowSeconds = &defaultFairShareWindowSeconds
+	}
+	if obj.MinCandidateNodesPercentage == nil {
+		obj.MinCandidateNodesPercentage = &defaultMinCandidateNodesPercentage
+	}
+	if obj.MinCandidateNodesAbsolute == nil {
+		obj.MinCandidateNodesAbsolute = &defaultMinCandidateNodesAbsolute
+	}
 }
 
 // SetDefaults_NodeResourcesAllocatableArgs sets the defaults parameters for NodeResourceAllocatable.
@@ -133,6 +187,28 @@ func SetDefaultTrimaranSpec(args *TrimaranSpec) {
 	if args.MetricProvider.Type == Prometheus && args.MetricProvider.InsecureSkipVerify == nil {
 		args.MetricProvider.InsecureSkipVerify = &DefaultInsecureSkipVerify
 	}
+	if args.MetricsUpdateIntervalSeconds == nil || *args.MetricsUpdateIntervalSeconds <= 0 {
+		args.MetricsUpdateIntervalSeconds = &DefaultMetricsUpdateIntervalSeconds
+	}
+	if args.MetricsMaxStalenessSeconds == nil || *args.MetricsMaxStalenessSeconds < 0 {
+		args.MetricsMaxStalenessSeconds = &DefaultMetricsMaxStalenessSeconds
+	}
+	if args.StaleMetricsPolicy == nil {
+		args.StaleMetricsPolicy = &DefaultStaleMetricsPolicy
+	}
+}
+
+// SetDefaultForecastSpec sets the default parameters for the load forecast of Trimaran plugins
+func SetDefaultForecastSpec(args *ForecastSpec) {
+	if args.Algorithm == nil {
+		args.Algorithm = &DefaultForecastAlgorithm
+	}
+	if args.HorizonSeconds == nil || *args.HorizonSeconds <= 0 {
+		args.HorizonSeconds = &DefaultForecastHorizonSeconds
+	}
+	if args.SeasonSeconds == nil || *args.SeasonSeconds <= 0 {
+		args.SeasonSeconds = &DefaultForecastSeasonSeconds
+	}
 }
 
 // SetDefaults_TargetLoadPackingArgs sets the default parameters for TargetLoadPacking plugin
@@ -148,6 +224,7 @@ func SetDefaults_TargetLoadPackingArgs(args *TargetLoadPackingArgs) {
 	if args.TargetUtilization == nil || *args.TargetUtilization <= 0 {
 		args.TargetUtilization = &DefaultTargetUtilizationPercent
 	}
+	SetDefaultForecastSpec(&args.Forecast)
 }
 
 // SetDefaults_LoadVariationRiskBalancingArgs sets the default parameters for LoadVariationRiskBalancing plugin
@@ -159,6 +236,7 @@ func SetDefaults_LoadVariationRiskBalancingArgs(args *LoadVariationRiskBalancing
 	if args.SafeVarianceSensitivity == nil || *args.SafeVarianceSensitivity < 0 {
 		args.SafeVarianceSensitivity = &DefaultSafeVarianceSensitivity
 	}
+	SetDefaultForecastSpec(&args.Forecast)
 }
 
 // SetDefaults_LowRiskOverCommitmentArgs sets the default parameters for LowRiskOverCommitment plugin
@@ -223,6 +301,10 @@ func SetDefaults_TopologicalSortArgs(obj *TopologicalSortArgs) {
 	if len(obj.Namespaces) == 0 {
 		obj.Namespaces = []string{metav1.NamespaceDefault}
 	}
+
+	if obj.OrderingAlgorithm == nil {
+		obj.OrderingAlgorithm = &defaultOrderingAlgorithm
+	}
 }
 
 // SetDefaults_NetworkOverheadArgs sets the default parameters for NetworkMinCostArgs plugin.
@@ -238,6 +320,14 @@ func SetDefaults_NetworkOverheadArgs(obj *NetworkOverheadArgs) {
 	if obj.NetworkTopologyName == nil {
 		obj.NetworkTopologyName = &DefaultNetworkTopologyName
 	}
+
+	if len(obj.TopologyKeys) == 0 {
+		obj.TopologyKeys = append([]string{}, DefaultNetworkTopologyKeys...)
+	}
+
+	if obj.PlacementPlanning == nil {
+		obj.PlacementPlanning = &defaultPlacementPlanning
+	}
 }
 
 // SetDefaults_SySchedArgs sets the default parameters for SySchedArgs plugin.
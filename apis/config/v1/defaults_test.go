@@ -39,19 +39,46 @@ func TestSchedulingDefaults(t *testing.T) {
 			name:   "empty config CoschedulingArgs",
 			config: &CoschedulingArgs{},
 			expect: &CoschedulingArgs{
-				PermitWaitingTimeSeconds: pointer.Int64Ptr(60),
-				PodGroupBackoffSeconds:   pointer.Int64Ptr(0),
+				PermitWaitingTimeSeconds:          pointer.Int64Ptr(60),
+				PodGroupBackoffSeconds:            pointer.Int64Ptr(0),
+				PodGroupMaxBackoffSeconds:         pointer.Int64Ptr(300),
+				EarlyRejectionThresholdPercentage: pointer.Int32Ptr(10),
+				TopologyKey:                       pointer.String(v1.LabelTopologyZone),
+				EnableGangPreemption:              pointer.Bool(false),
+				QueueSortMode:                     pointer.String("Pod"),
+				PodGroupAgingSeconds:              pointer.Int64Ptr(600),
+				FairShareWindowSeconds:            pointer.Int64Ptr(300),
+				MinCandidateNodesPercentage:       pointer.Int32Ptr(10),
+				MinCandidateNodesAbsolute:         pointer.Int32Ptr(100),
 			},
 		},
 		{
 			name: "set non default CoschedulingArgs",
 			config: &CoschedulingArgs{
-				PermitWaitingTimeSeconds: pointer.Int64Ptr(60),
-				PodGroupBackoffSeconds:   pointer.Int64Ptr(20),
+				PermitWaitingTimeSeconds:          pointer.Int64Ptr(60),
+				PodGroupBackoffSeconds:            pointer.Int64Ptr(20),
+				PodGroupMaxBackoffSeconds:         pointer.Int64Ptr(160),
+				EarlyRejectionThresholdPercentage: pointer.Int32Ptr(25),
+				TopologyKey:                       pointer.String(v1.LabelTopologyRegion),
+				EnableGangPreemption:              pointer.Bool(true),
+				QueueSortMode:                     pointer.String("PodGroup"),
+				PodGroupAgingSeconds:              pointer.Int64Ptr(60),
+				FairShareWindowSeconds:            pointer.Int64Ptr(30),
+				MinCandidateNodesPercentage:       pointer.Int32Ptr(20),
+				MinCandidateNodesAbsolute:         pointer.Int32Ptr(50),
 			},
 			expect: &CoschedulingArgs{
-				PermitWaitingTimeSeconds: pointer.Int64Ptr(60),
-				PodGroupBackoffSeconds:   pointer.Int64Ptr(20),
+				PermitWaitingTimeSeconds:          pointer.Int64Ptr(60),
+				PodGroupBackoffSeconds:            pointer.Int64Ptr(20),
+				PodGroupMaxBackoffSeconds:         pointer.Int64Ptr(160),
+				EarlyRejectionThresholdPercentage: pointer.Int32Ptr(25),
+				TopologyKey:                       pointer.String(v1.LabelTopologyRegion),
+				EnableGangPreemption:              pointer.Bool(true),
+				QueueSortMode:                     pointer.String("PodGroup"),
+				PodGroupAgingSeconds:              pointer.Int64Ptr(60),
+				FairShareWindowSeconds:            pointer.Int64Ptr(30),
+				MinCandidateNodesPercentage:       pointer.Int32Ptr(20),
+				MinCandidateNodesAbsolute:         pointer.Int32Ptr(50),
 			},
 		},
 		{
@@ -86,28 +113,52 @@ func TestSchedulingDefaults(t *testing.T) {
 				TrimaranSpec: TrimaranSpec{
 					MetricProvider: MetricProviderSpec{
 						Type: "KubernetesMetricsServer",
-					}},
+					},
+					MetricsUpdateIntervalSeconds: pointer.Int64Ptr(30),
+					MetricsMaxStalenessSeconds:   pointer.Int64Ptr(0),
+					StaleMetricsPolicy:           pointer.StringPtr("Neutral")},
 				DefaultRequests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(
 					strconv.FormatInt(DefaultRequestsMilliCores, 10) + "m")},
 				DefaultRequestsMultiplier: pointer.StringPtr("1.5"),
 				TargetUtilization:         pointer.Int64Ptr(40),
+				Forecast: ForecastSpec{
+					Algorithm:      pointer.StringPtr("None"),
+					HorizonSeconds: pointer.Int64Ptr(900),
+					SeasonSeconds:  pointer.Int64Ptr(86400),
+				},
 			},
 		},
 		{
 			name: "set non default TargetLoadPackingArgs",
 			config: &TargetLoadPackingArgs{
 				TrimaranSpec: TrimaranSpec{
-					WatcherAddress: pointer.StringPtr("http://localhost:2020")},
+					WatcherAddress:               pointer.StringPtr("http://localhost:2020"),
+					MetricsUpdateIntervalSeconds: pointer.Int64Ptr(10),
+					MetricsMaxStalenessSeconds:   pointer.Int64Ptr(120),
+					StaleMetricsPolicy:           pointer.StringPtr("Requests")},
 				DefaultRequests:           v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")},
 				DefaultRequestsMultiplier: pointer.StringPtr("2.5"),
 				TargetUtilization:         pointer.Int64Ptr(50),
+				Forecast: ForecastSpec{
+					Algorithm:      pointer.StringPtr("HoltWinters"),
+					HorizonSeconds: pointer.Int64Ptr(3600),
+					SeasonSeconds:  pointer.Int64Ptr(604800),
+				},
 			},
 			expect: &TargetLoadPackingArgs{
 				TrimaranSpec: TrimaranSpec{
-					WatcherAddress: pointer.StringPtr("http://localhost:2020")},
+					WatcherAddress:               pointer.StringPtr("http://localhost:2020"),
+					MetricsUpdateIntervalSeconds: pointer.Int64Ptr(10),
+					MetricsMaxStalenessSeconds:   pointer.Int64Ptr(120),
+					StaleMetricsPolicy:           pointer.StringPtr("Requests")},
 				DefaultRequests:           v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")},
 				DefaultRequestsMultiplier: pointer.StringPtr("2.5"),
 				TargetUtilization:         pointer.Int64Ptr(50),
+				Forecast: ForecastSpec{
+					Algorithm:      pointer.StringPtr("HoltWinters"),
+					HorizonSeconds: pointer.Int64Ptr(3600),
+					SeasonSeconds:  pointer.Int64Ptr(604800),
+				},
 			},
 		},
 		{
@@ -117,9 +168,17 @@ func TestSchedulingDefaults(t *testing.T) {
 				TrimaranSpec: TrimaranSpec{
 					MetricProvider: MetricProviderSpec{
 						Type: "KubernetesMetricsServer",
-					}},
+					},
+					MetricsUpdateIntervalSeconds: pointer.Int64Ptr(30),
+					MetricsMaxStalenessSeconds:   pointer.Int64Ptr(0),
+					StaleMetricsPolicy:           pointer.StringPtr("Neutral")},
 				SafeVarianceMargin:      pointer.Float64Ptr(1.0),
 				SafeVarianceSensitivity: pointer.Float64Ptr(1.0),
+				Forecast: ForecastSpec{
+					Algorithm:      pointer.StringPtr("None"),
+					HorizonSeconds: pointer.Int64Ptr(900),
+					SeasonSeconds:  pointer.Int64Ptr(86400),
+				},
 			},
 		},
 		{
@@ -127,14 +186,25 @@ func TestSchedulingDefaults(t *testing.T) {
 			config: &LoadVariationRiskBalancingArgs{
 				SafeVarianceMargin:      pointer.Float64Ptr(2.0),
 				SafeVarianceSensitivity: pointer.Float64Ptr(2.0),
+				Forecast: ForecastSpec{
+					Algorithm: pointer.StringPtr("EWMA"),
+				},
 			},
 			expect: &LoadVariationRiskBalancingArgs{
 				TrimaranSpec: TrimaranSpec{
 					MetricProvider: MetricProviderSpec{
 						Type: "KubernetesMetricsServer",
-					}},
+					},
+					MetricsUpdateIntervalSeconds: pointer.Int64Ptr(30),
+					MetricsMaxStalenessSeconds:   pointer.Int64Ptr(0),
+					StaleMetricsPolicy:           pointer.StringPtr("Neutral")},
 				SafeVarianceMargin:      pointer.Float64Ptr(2.0),
 				SafeVarianceSensitivity: pointer.Float64Ptr(2.0),
+				Forecast: ForecastSpec{
+					Algorithm:      pointer.StringPtr("EWMA"),
+					HorizonSeconds: pointer.Int64Ptr(900),
+					SeasonSeconds:  pointer.Int64Ptr(86400),
+				},
 			},
 		},
 		{
@@ -144,7 +214,10 @@ func TestSchedulingDefaults(t *testing.T) {
 				TrimaranSpec: TrimaranSpec{
 					MetricProvider: MetricProviderSpec{
 						Type: "KubernetesMetricsServer",
-					}},
+					},
+					MetricsUpdateIntervalSeconds: pointer.Int64Ptr(30),
+					MetricsMaxStalenessSeconds:   pointer.Int64Ptr(0),
+					StaleMetricsPolicy:           pointer.StringPtr("Neutral")},
 				SmoothingWindowSize: pointer.Int64Ptr(5),
 				RiskLimitWeights: map[v1.ResourceName]float64{
 					v1.ResourceCPU:    0.5,
@@ -165,7 +238,10 @@ func TestSchedulingDefaults(t *testing.T) {
 				TrimaranSpec: TrimaranSpec{
 					MetricProvider: MetricProviderSpec{
 						Type: "KubernetesMetricsServer",
-					}},
+					},
+					MetricsUpdateIntervalSeconds: pointer.Int64Ptr(30),
+					MetricsMaxStalenessSeconds:   pointer.Int64Ptr(0),
+					StaleMetricsPolicy:           pointer.StringPtr("Neutral")},
 				SmoothingWindowSize: pointer.Int64Ptr(10),
 				RiskLimitWeights: map[v1.ResourceName]float64{
 					v1.ResourceCPU:    0.2,
@@ -186,7 +262,10 @@ func TestSchedulingDefaults(t *testing.T) {
 				TrimaranSpec: TrimaranSpec{
 					MetricProvider: MetricProviderSpec{
 						Type: "KubernetesMetricsServer",
-					}},
+					},
+					MetricsUpdateIntervalSeconds: pointer.Int64Ptr(30),
+					MetricsMaxStalenessSeconds:   pointer.Int64Ptr(0),
+					StaleMetricsPolicy:           pointer.StringPtr("Neutral")},
 				SmoothingWindowSize: pointer.Int64Ptr(10),
 				RiskLimitWeights: map[v1.ResourceName]float64{
 					v1.ResourceCPU:    0.5,
@@ -221,16 +300,19 @@ func TestSchedulingDefaults(t *testing.T) {
 			name:   "empty config TopologySortArgs",
 			config: &TopologicalSortArgs{},
 			expect: &TopologicalSortArgs{
-				Namespaces: []string{"default"},
+				Namespaces:        []string{"default"},
+				OrderingAlgorithm: pointer.String("AppGroup"),
 			},
 		},
 		{
 			name: "set non default TopologySortArgs",
 			config: &TopologicalSortArgs{
-				Namespaces: []string{"n1"},
+				Namespaces:        []string{"n1"},
+				OrderingAlgorithm: pointer.String("KahnSort"),
 			},
 			expect: &TopologicalSortArgs{
-				Namespaces: []string{"n1"},
+				Namespaces:        []string{"n1"},
+				OrderingAlgorithm: pointer.String("KahnSort"),
 			},
 		},
 		{
@@ -240,6 +322,8 @@ func TestSchedulingDefaults(t *testing.T) {
 				Namespaces:          []string{"default"},
 				WeightsName:         pointer.StringPtr("UserDefined"),
 				NetworkTopologyName: pointer.StringPtr("nt-default"),
+				TopologyKeys:        []string{v1.LabelTopologyRegion, v1.LabelTopologyZone},
+				PlacementPlanning:   pointer.Bool(false),
 			},
 		},
 		{
@@ -248,11 +332,15 @@ func TestSchedulingDefaults(t *testing.T) {
 				Namespaces:          []string{"n2"},
 				WeightsName:         pointer.StringPtr("latency"),
 				NetworkTopologyName: pointer.StringPtr("nt-latency-costs"),
+				TopologyKeys:        []string{v1.LabelTopologyZone, "example.com/rack", v1.LabelHostname},
+				PlacementPlanning:   pointer.Bool(true),
 			},
 			expect: &NetworkOverheadArgs{
 				Namespaces:          []string{"n2"},
 				WeightsName:         pointer.StringPtr("latency"),
 				NetworkTopologyName: pointer.StringPtr("nt-latency-costs"),
+				TopologyKeys:        []string{v1.LabelTopologyZone, "example.com/rack", v1.LabelHostname},
+				PlacementPlanning:   pointer.Bool(true),
 			},
 		},
 		{
@@ -30,8 +30,37 @@ type CoschedulingArgs struct {
 
 	// PermitWaitingTimeSeconds is the waiting timeout in seconds.
 	PermitWaitingTimeSeconds *int64 `json:"permitWaitingTimeSeconds,omitempty"`
-	// PodGroupBackoffSeconds is the backoff time in seconds before a pod group can be scheduled again.
+	// PodGroupBackoffSeconds is the initial backoff time in seconds before a pod group can be scheduled again.
+	// The backoff doubles on every consecutive failure of the pod group, and is reset once it gets scheduled.
 	PodGroupBackoffSeconds *int64 `json:"podGroupBackoffSeconds,omitempty"`
+	// PodGroupMaxBackoffSeconds is the upper bound of the backoff time in seconds of a pod group.
+	PodGroupMaxBackoffSeconds *int64 `json:"podGroupMaxBackoffSeconds,omitempty"`
+	// EarlyRejectionThresholdPercentage is the percentage of minMember below which the number of
+	// pods missing to reach the quorum is considered a small gap, in which case the pod group
+	// is not rejected in PostFilter so that subsequent pods still get a chance to satisfy it.
+	EarlyRejectionThresholdPercentage *int32 `json:"earlyRejectionThresholdPercentage,omitempty"`
+	// TopologyKey is the node label key used to determine the topology domain
+	// in which members of a pod group prefer to be co-located.
+	TopologyKey *string `json:"topologyKey,omitempty"`
+	// EnableGangPreemption allows a pod group to preempt lower-priority pods as a whole
+	// when its minimum cannot be satisfied otherwise.
+	EnableGangPreemption *bool `json:"enableGangPreemption,omitempty"`
+	// QueueSortMode is the granularity at which pods are ordered in the scheduling queue,
+	// either "Pod" or "PodGroup".
+	QueueSortMode *string `json:"queueSortMode,omitempty"`
+	// PodGroupAgingSeconds is the time in seconds after which a waiting pod group moves ahead
+	// of the pod groups of the same priority, regardless of fair share, in the PodGroup queue sort mode.
+	// Zero disables aging.
+	PodGroupAgingSeconds *int64 `json:"podGroupAgingSeconds,omitempty"`
+	// FairShareWindowSeconds is the time window in seconds during which the pod groups admitted
+	// in a namespace count against its fair share in the PodGroup queue sort mode.
+	FairShareWindowSeconds *int64 `json:"fairShareWindowSeconds,omitempty"`
+	// MinCandidateNodesPercentage is the minimum number of nodes the gang preemption dry runs are
+	// bounded to, as a percentage of the nodes where preemption may help.
+	MinCandidateNodesPercentage *int32 `json:"minCandidateNodesPercentage,omitempty"`
+	// MinCandidateNodesAbsolute is the minimum absolute number of nodes the gang preemption dry runs
+	// are bounded to. The larger of the two minimums is used.
+	MinCandidateNodesAbsolute *int32 `json:"minCandidateNodesAbsolute,omitempty"`
 }
 
 // ModeType is a type "string".
@@ -88,6 +117,22 @@ type TrimaranSpec struct {
 	MetricProvider MetricProviderSpec `json:"metricProvider,omitempty"`
 	// Address of load watcher service
 	WatcherAddress *string `json:"watcherAddress,omitempty"`
+	// Interval in seconds between updates of the metrics from load watcher
+	MetricsUpdateIntervalSeconds *int64 `json:"metricsUpdateIntervalSeconds,omitempty"`
+	// Maximum age in seconds of the metrics from load watcher before they are stale, 0 to never consider them stale
+	MetricsMaxStalenessSeconds *int64 `json:"metricsMaxStalenessSeconds,omitempty"`
+	// Policy scoring nodes when the metrics are stale: Neutral, Requests or Unknown
+	StaleMetricsPolicy *string `json:"staleMetricsPolicy,omitempty"`
+}
+
+// ForecastSpec holds parameters to score nodes on their forecasted load, for trimaran plugins
+type ForecastSpec struct {
+	// Algorithm forecasting the load of nodes from their history: None, EWMA or HoltWinters
+	Algorithm *string `json:"algorithm,omitempty"`
+	// Horizon in seconds of the forecast, nodes being scored on their peak load forecasted within it
+	HorizonSeconds *int64 `json:"horizonSeconds,omitempty"`
+	// Period in seconds of the seasonality of the load, for the HoltWinters algorithm
+	SeasonSeconds *int64 `json:"seasonSeconds,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -105,6 +150,8 @@ type TargetLoadPackingArgs struct {
 	DefaultRequestsMultiplier *string `json:"defaultRequestsMultiplier,omitempty"`
 	// Node target CPU Utilization for bin packing
 	TargetUtilization *int64 `json:"targetUtilization,omitempty"`
+	// Forecast of the load to score nodes on
+	Forecast ForecastSpec `json:"forecast,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -120,6 +167,8 @@ type LoadVariationRiskBalancingArgs struct {
 	SafeVarianceMargin *float64 `json:"safeVarianceMargin,omitempty"`
 	// Root power of standard deviation in risk value
 	SafeVarianceSensitivity *float64 `json:"safeVarianceSensitivity,omitempty"`
+	// Forecast of the load to score nodes on
+	Forecast ForecastSpec `json:"forecast,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -249,6 +298,10 @@ type TopologicalSortArgs struct {
 
 	// Namespaces to be considered by TopologySort plugin
 	Namespaces []string `json:"namespaces,omitempty"`
+
+	// OrderingAlgorithm is the algorithm ordering the workloads of an AppGroup, either "AppGroup",
+	// "KahnSort" or "ReverseKahn".
+	OrderingAlgorithm *string `json:"orderingAlgorithm,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -264,6 +317,14 @@ type NetworkOverheadArgs struct {
 
 	// The NetworkTopology CRD name
 	NetworkTopologyName *string `json:"networkTopologyName,omitempty"`
+
+	// Node label keys of the topology levels with network costs, from the broadest to the narrowest
+	// (Default: topology.kubernetes.io/region, topology.kubernetes.io/zone)
+	TopologyKeys []string `json:"topologyKeys,omitempty"`
+
+	// PlacementPlanning plans the placement of all the workloads of an AppGroup upon the arrival of its first pod,
+	// and favors the planned topology domains when scoring its later pods (Default: false)
+	PlacementPlanning *bool `json:"placementPlanning,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -50,6 +50,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*ForecastSpec)(nil), (*config.ForecastSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1_ForecastSpec_To_config_ForecastSpec(a.(*ForecastSpec), b.(*config.ForecastSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.ForecastSpec)(nil), (*ForecastSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_ForecastSpec_To_v1_ForecastSpec(a.(*config.ForecastSpec), b.(*ForecastSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*LoadVariationRiskBalancingArgs)(nil), (*config.LoadVariationRiskBalancingArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1_LoadVariationRiskBalancingArgs_To_config_LoadVariationRiskBalancingArgs(a.(*LoadVariationRiskBalancingArgs), b.(*config.LoadVariationRiskBalancingArgs), scope)
 	}); err != nil {
@@ -210,6 +220,33 @@ func autoConvert_v1_CoschedulingArgs_To_config_CoschedulingArgs(in *Coscheduling
 	if err := metav1.Convert_Pointer_int64_To_int64(&in.PodGroupBackoffSeconds, &out.PodGroupBackoffSeconds, s); err != nil {
 		return err
 	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.PodGroupMaxBackoffSeconds, &out.PodGroupMaxBackoffSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_int32_To_int32(&in.EarlyRejectionThresholdPercentage, &out.EarlyRejectionThresholdPercentage, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_string_To_string(&in.TopologyKey, &out.TopologyKey, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_bool_To_bool(&in.EnableGangPreemption, &out.EnableGangPreemption, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_string_To_string(&in.QueueSortMode, &out.QueueSortMode, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.PodGroupAgingSeconds, &out.PodGroupAgingSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.FairShareWindowSeconds, &out.FairShareWindowSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_int32_To_int32(&in.MinCandidateNodesPercentage, &out.MinCandidateNodesPercentage, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_int32_To_int32(&in.MinCandidateNodesAbsolute, &out.MinCandidateNodesAbsolute, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -225,6 +262,33 @@ func autoConvert_config_CoschedulingArgs_To_v1_CoschedulingArgs(in *config.Cosch
 	if err := metav1.Convert_int64_To_Pointer_int64(&in.PodGroupBackoffSeconds, &out.PodGroupBackoffSeconds, s); err != nil {
 		return err
 	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.PodGroupMaxBackoffSeconds, &out.PodGroupMaxBackoffSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_int32_To_Pointer_int32(&in.EarlyRejectionThresholdPercentage, &out.EarlyRejectionThresholdPercentage, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_string_To_Pointer_string(&in.TopologyKey, &out.TopologyKey, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_bool_To_Pointer_bool(&in.EnableGangPreemption, &out.EnableGangPreemption, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_string_To_Pointer_string(&in.QueueSortMode, &out.QueueSortMode, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.PodGroupAgingSeconds, &out.PodGroupAgingSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.FairShareWindowSeconds, &out.FairShareWindowSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_int32_To_Pointer_int32(&in.MinCandidateNodesPercentage, &out.MinCandidateNodesPercentage, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_int32_To_Pointer_int32(&in.MinCandidateNodesAbsolute, &out.MinCandidateNodesAbsolute, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -233,6 +297,42 @@ func Convert_config_CoschedulingArgs_To_v1_CoschedulingArgs(in *config.Coschedul
 	return autoConvert_config_CoschedulingArgs_To_v1_CoschedulingArgs(in, out, s)
 }
 
+func autoConvert_v1_ForecastSpec_To_config_ForecastSpec(in *ForecastSpec, out *config.ForecastSpec, s conversion.Scope) error {
+	if err := metav1.Convert_Pointer_string_To_string(&in.Algorithm, &out.Algorithm, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.HorizonSeconds, &out.HorizonSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.SeasonSeconds, &out.SeasonSeconds, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1_ForecastSpec_To_config_ForecastSpec is an autogenerated conversion function.
+func Convert_v1_ForecastSpec_To_config_ForecastSpec(in *ForecastSpec, out *config.ForecastSpec, s conversion.Scope) error {
+	return autoConvert_v1_ForecastSpec_To_config_ForecastSpec(in, out, s)
+}
+
+func autoConvert_config_ForecastSpec_To_v1_ForecastSpec(in *config.ForecastSpec, out *ForecastSpec, s conversion.Scope) error {
+	if err := metav1.Convert_string_To_Pointer_string(&in.Algorithm, &out.Algorithm, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.HorizonSeconds, &out.HorizonSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.SeasonSeconds, &out.SeasonSeconds, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_config_ForecastSpec_To_v1_ForecastSpec is an autogenerated conversion function.
+func Convert_config_ForecastSpec_To_v1_ForecastSpec(in *config.ForecastSpec, out *ForecastSpec, s conversion.Scope) error {
+	return autoConvert_config_ForecastSpec_To_v1_ForecastSpec(in, out, s)
+}
+
 func autoConvert_v1_LoadVariationRiskBalancingArgs_To_config_LoadVariationRiskBalancingArgs(in *LoadVariationRiskBalancingArgs, out *config.LoadVariationRiskBalancingArgs, s conversion.Scope) error {
 	if err := Convert_v1_TrimaranSpec_To_config_TrimaranSpec(&in.TrimaranSpec, &out.TrimaranSpec, s); err != nil {
 		return err
@@ -243,6 +343,9 @@ func autoConvert_v1_LoadVariationRiskBalancingArgs_To_config_LoadVariationRiskBa
 	if err := metav1.Convert_Pointer_float64_To_float64(&in.SafeVarianceSensitivity, &out.SafeVarianceSensitivity, s); err != nil {
 		return err
 	}
+	if err := Convert_v1_ForecastSpec_To_config_ForecastSpec(&in.Forecast, &out.Forecast, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -261,6 +364,9 @@ func autoConvert_config_LoadVariationRiskBalancingArgs_To_v1_LoadVariationRiskBa
 	if err := metav1.Convert_float64_To_Pointer_float64(&in.SafeVarianceSensitivity, &out.SafeVarianceSensitivity, s); err != nil {
 		return err
 	}
+	if err := Convert_config_ForecastSpec_To_v1_ForecastSpec(&in.Forecast, &out.Forecast, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -347,6 +453,10 @@ func autoConvert_v1_NetworkOverheadArgs_To_config_NetworkOverheadArgs(in *Networ
 	if err := metav1.Convert_Pointer_string_To_string(&in.NetworkTopologyName, &out.NetworkTopologyName, s); err != nil {
 		return err
 	}
+	out.TopologyKeys = *(*[]string)(unsafe.Pointer(&in.TopologyKeys))
+	if err := metav1.Convert_Pointer_bool_To_bool(&in.PlacementPlanning, &out.PlacementPlanning, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -363,6 +473,10 @@ func autoConvert_config_NetworkOverheadArgs_To_v1_NetworkOverheadArgs(in *config
 	if err := metav1.Convert_string_To_Pointer_string(&in.NetworkTopologyName, &out.NetworkTopologyName, s); err != nil {
 		return err
 	}
+	out.TopologyKeys = *(*[]string)(unsafe.Pointer(&in.TopologyKeys))
+	if err := metav1.Convert_bool_To_Pointer_bool(&in.PlacementPlanning, &out.PlacementPlanning, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -578,6 +692,9 @@ func autoConvert_v1_TargetLoadPackingArgs_To_config_TargetLoadPackingArgs(in *Ta
 	if err := metav1.Convert_Pointer_int64_To_int64(&in.TargetUtilization, &out.TargetUtilization, s); err != nil {
 		return err
 	}
+	if err := Convert_v1_ForecastSpec_To_config_ForecastSpec(&in.Forecast, &out.Forecast, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -597,6 +714,9 @@ func autoConvert_config_TargetLoadPackingArgs_To_v1_TargetLoadPackingArgs(in *co
 	if err := metav1.Convert_int64_To_Pointer_int64(&in.TargetUtilization, &out.TargetUtilization, s); err != nil {
 		return err
 	}
+	if err := Convert_config_ForecastSpec_To_v1_ForecastSpec(&in.Forecast, &out.Forecast, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -607,6 +727,9 @@ func Convert_config_TargetLoadPackingArgs_To_v1_TargetLoadPackingArgs(in *config
 
 func autoConvert_v1_TopologicalSortArgs_To_config_TopologicalSortArgs(in *TopologicalSortArgs, out *config.TopologicalSortArgs, s conversion.Scope) error {
 	out.Namespaces = *(*[]string)(unsafe.Pointer(&in.Namespaces))
+	if err := metav1.Convert_Pointer_string_To_string(&in.OrderingAlgorithm, &out.OrderingAlgorithm, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -617,6 +740,9 @@ func Convert_v1_TopologicalSortArgs_To_config_TopologicalSortArgs(in *Topologica
 
 func autoConvert_config_TopologicalSortArgs_To_v1_TopologicalSortArgs(in *config.TopologicalSortArgs, out *TopologicalSortArgs, s conversion.Scope) error {
 	out.Namespaces = *(*[]string)(unsafe.Pointer(&in.Namespaces))
+	if err := metav1.Convert_string_To_Pointer_string(&in.OrderingAlgorithm, &out.OrderingAlgorithm, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -632,6 +758,15 @@ func autoConvert_v1_TrimaranSpec_To_config_TrimaranSpec(in *TrimaranSpec, out *c
 	if err := metav1.Convert_Pointer_string_To_string(&in.WatcherAddress, &out.WatcherAddress, s); err != nil {
 		return err
 	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.MetricsUpdateIntervalSeconds, &out.MetricsUpdateIntervalSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.MetricsMaxStalenessSeconds, &out.MetricsMaxStalenessSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_string_To_string(&in.StaleMetricsPolicy, &out.StaleMetricsPolicy, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -647,6 +782,15 @@ func autoConvert_config_TrimaranSpec_To_v1_TrimaranSpec(in *config.TrimaranSpec,
 	if err := metav1.Convert_string_To_Pointer_string(&in.WatcherAddress, &out.WatcherAddress, s); err != nil {
 		return err
 	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.MetricsUpdateIntervalSeconds, &out.MetricsUpdateIntervalSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.MetricsMaxStalenessSeconds, &out.MetricsMaxStalenessSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_string_To_Pointer_string(&in.StaleMetricsPolicy, &out.StaleMetricsPolicy, s); err != nil {
+		return err
+	}
 	return nil
 }
 
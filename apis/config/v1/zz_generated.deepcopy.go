@@ -41,6 +41,51 @@ func (in *CoschedulingArgs) DeepCopyInto(out *CoschedulingArgs) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.PodGroupMaxBackoffSeconds != nil {
+		in, out := &in.PodGroupMaxBackoffSeconds, &out.PodGroupMaxBackoffSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.EarlyRejectionThresholdPercentage != nil {
+		in, out := &in.EarlyRejectionThresholdPercentage, &out.EarlyRejectionThresholdPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TopologyKey != nil {
+		in, out := &in.TopologyKey, &out.TopologyKey
+		*out = new(string)
+		**out = **in
+	}
+	if in.EnableGangPreemption != nil {
+		in, out := &in.EnableGangPreemption, &out.EnableGangPreemption
+		*out = new(bool)
+		**out = **in
+	}
+	if in.QueueSortMode != nil {
+		in, out := &in.QueueSortMode, &out.QueueSortMode
+		*out = new(string)
+		**out = **in
+	}
+	if in.PodGroupAgingSeconds != nil {
+		in, out := &in.PodGroupAgingSeconds, &out.PodGroupAgingSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.FairShareWindowSeconds != nil {
+		in, out := &in.FairShareWindowSeconds, &out.FairShareWindowSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MinCandidateNodesPercentage != nil {
+		in, out := &in.MinCandidateNodesPercentage, &out.MinCandidateNodesPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinCandidateNodesAbsolute != nil {
+		in, out := &in.MinCandidateNodesAbsolute, &out.MinCandidateNodesAbsolute
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
@@ -62,6 +107,37 @@ func (in *CoschedulingArgs) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ForecastSpec) DeepCopyInto(out *ForecastSpec) {
+	*out = *in
+	if in.Algorithm != nil {
+		in, out := &in.Algorithm, &out.Algorithm
+		*out = new(string)
+		**out = **in
+	}
+	if in.HorizonSeconds != nil {
+		in, out := &in.HorizonSeconds, &out.HorizonSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.SeasonSeconds != nil {
+		in, out := &in.SeasonSeconds, &out.SeasonSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ForecastSpec.
+func (in *ForecastSpec) DeepCopy() *ForecastSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ForecastSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoadVariationRiskBalancingArgs) DeepCopyInto(out *LoadVariationRiskBalancingArgs) {
 	*out = *in
@@ -77,6 +153,7 @@ func (in *LoadVariationRiskBalancingArgs) DeepCopyInto(out *LoadVariationRiskBal
 		*out = new(float64)
 		**out = **in
 	}
+	in.Forecast.DeepCopyInto(&out.Forecast)
 	return
 }
 
@@ -186,6 +263,16 @@ func (in *NetworkOverheadArgs) DeepCopyInto(out *NetworkOverheadArgs) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.TopologyKeys != nil {
+		in, out := &in.TopologyKeys, &out.TopologyKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PlacementPlanning != nil {
+		in, out := &in.PlacementPlanning, &out.PlacementPlanning
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -474,6 +561,7 @@ func (in *TargetLoadPackingArgs) DeepCopyInto(out *TargetLoadPackingArgs) {
 		*out = new(int64)
 		**out = **in
 	}
+	in.Forecast.DeepCopyInto(&out.Forecast)
 	return
 }
 
@@ -504,6 +592,11 @@ func (in *TopologicalSortArgs) DeepCopyInto(out *TopologicalSortArgs) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.OrderingAlgorithm != nil {
+		in, out := &in.OrderingAlgorithm, &out.OrderingAlgorithm
+		*out = new(string)
+		**out = **in
+	}
 	return
 }
 
@@ -534,6 +627,21 @@ func (in *TrimaranSpec) DeepCopyInto(out *TrimaranSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.MetricsUpdateIntervalSeconds != nil {
+		in, out := &in.MetricsUpdateIntervalSeconds, &out.MetricsUpdateIntervalSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MetricsMaxStalenessSeconds != nil {
+		in, out := &in.MetricsMaxStalenessSeconds, &out.MetricsMaxStalenessSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.StaleMetricsPolicy != nil {
+		in, out := &in.StaleMetricsPolicy, &out.StaleMetricsPolicy
+		*out = new(string)
+		**out = **in
+	}
 	return
 }
 
@@ -31,6 +31,12 @@ import (
 // +kubebuilder:metadata:annotations="api-approved.kubernetes.io=https://github.com/kubernetes-sigs/scheduler-plugins/pull/52"
 // +kubebuilder:printcolumn:name="Used",JSONPath=".status.used",type=string,description="Used is the current observed total usage of the resource in the namespace."
 // +kubebuilder:printcolumn:name="Max",JSONPath=".spec.max",type=string,description="Max is the set of desired max limits for each named resource."
+// +kubebuilder:printcolumn:name="Borrowed",JSONPath=".status.borrowed",type=string,priority=1,description="Borrowed is the usage beyond min."
+// +kubebuilder:printcolumn:name="Lent",JSONPath=".status.lent",type=string,priority=1,description="Lent is the unused min borrowed by others."
+// +kubebuilder:printcolumn:name="Available",JSONPath=".status.available",type=string,priority=1,description="Available is the usage left."
+// +kubebuilder:printcolumn:name="Pending",JSONPath=".status.pendingPods",type=integer,description="Pending is the number of pods blocked by quota."
+// +kubebuilder:printcolumn:name="OverMin",JSONPath=".status.conditions[?(@.type==\"OverMin\")].status",type=string,description="OverMin tells whether the usage exceeds min."
+// +kubebuilder:printcolumn:name="AtMax",JSONPath=".status.conditions[?(@.type==\"AtMax\")].status",type=string,description="AtMax tells whether the usage reaches max."
 // +kubebuilder:printcolumn:name="Age",JSONPath=".metadata.creationTimestamp",type=date,description="Age is the time ElasticQuota was created."
 type ElasticQuota struct {
 	metav1.TypeMeta `json:",inline"`
@@ -58,6 +64,49 @@ type ElasticQuotaSpec struct {
 	// successfully scheduled pods.
 	// +optional
 	Max v1.ResourceList `json:"max,omitempty" protobuf:"bytes,2,rep,name=max, casttype=ResourceList,castkey=ResourceName"`
+
+	// Parent references the parent ElasticQuota in a hierarchy of quotas, e.g. department, team and namespace.
+	// The usage of an ElasticQuota also counts against the min and max of each of its ancestors, and its unused
+	// min is lent to its siblings before its cousins. The parents must not form a cycle.
+	// +optional
+	Parent *ElasticQuotaReference `json:"parent,omitempty" protobuf:"bytes,3,opt,name=parent"`
+
+	// Weight is the weight of the ElasticQuota when borrowing the unused min of other ElasticQuotas. Borrowers are
+	// given a share of the borrowable resources proportional to their weights, based on their dominant resource.
+	// Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Weight *int32 `json:"weight,omitempty" protobuf:"varint,4,opt,name=weight"`
+
+	// NamespaceSelector selects the namespaces whose pods are subject to the ElasticQuota. When it isn't set,
+	// the ElasticQuota only applies to the pods of its own namespace.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty" protobuf:"bytes,5,opt,name=namespaceSelector"`
+
+	// PodSelector selects the pods subject to the ElasticQuota among the pods of the selected namespaces. When it
+	// isn't set, the ElasticQuota applies to all of them.
+	// A pod selected by several ElasticQuotas is subject to a single one: an ElasticQuota with a pod selector takes
+	// precedence over one without, then an ElasticQuota selecting its own namespace only over one with a namespace
+	// selector, then the oldest ElasticQuota, then the first one by namespace and name.
+	// +optional
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty" protobuf:"bytes,6,opt,name=podSelector"`
+
+	// ReclaimGracePeriodSeconds is how long the pods of the ElasticQuota using resources borrowed from other
+	// ElasticQuotas are given to exit, once the resources are reclaimed, before being preempted. They are notified
+	// of the reclaim deadline by the ReclaimDeadlineAnnotation and an Event. Defaults to 0, i.e. the pods are
+	// preempted immediately.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ReclaimGracePeriodSeconds *int32 `json:"reclaimGracePeriodSeconds,omitempty" protobuf:"varint,7,opt,name=reclaimGracePeriodSeconds"`
+}
+
+// ElasticQuotaReference references an ElasticQuota.
+type ElasticQuotaReference struct {
+	// Namespace is the namespace of the referenced ElasticQuota.
+	Namespace string `json:"namespace" protobuf:"bytes,1,opt,name=namespace"`
+
+	// Name is the name of the referenced ElasticQuota.
+	Name string `json:"name" protobuf:"bytes,2,opt,name=name"`
 }
 
 // ElasticQuotaStatus defines the observed use.
@@ -65,8 +114,84 @@ type ElasticQuotaStatus struct {
 	// Used is the current observed total usage of the resource in the namespace.
 	// +optional
 	Used v1.ResourceList `json:"used,omitempty" protobuf:"bytes,1,rep,name=used,casttype=ResourceList,castkey=ResourceName"`
+
+	// FairShare is the computed amount of resources the ElasticQuota is entitled to when borrowing is contended:
+	// its min plus its weighted share of the min that other ElasticQuotas don't use.
+	// +optional
+	FairShare v1.ResourceList `json:"fairShare,omitempty" protobuf:"bytes,2,rep,name=fairShare,casttype=ResourceList,castkey=ResourceName"`
+
+	// Conditions represent the latest available observations of the ElasticQuota's state.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" protobuf:"bytes,3,rep,name=conditions"`
+
+	// Borrowed is the usage beyond the min, i.e. the resources borrowed from other ElasticQuotas.
+	// +optional
+	Borrowed v1.ResourceList `json:"borrowed,omitempty" protobuf:"bytes,4,rep,name=borrowed,casttype=ResourceList,castkey=ResourceName"`
+
+	// Lent is the part of the unused min that is borrowed by other ElasticQuotas. The unused min of the sibling
+	// ElasticQuotas, or of the root ElasticQuotas, is lent in proportion to the unused min of each of them.
+	// +optional
+	Lent v1.ResourceList `json:"lent,omitempty" protobuf:"bytes,5,rep,name=lent,casttype=ResourceList,castkey=ResourceName"`
+
+	// Available is the amount of resources the ElasticQuota can still use given the cluster state: its unused min,
+	// which can be reclaimed from the borrowers, or the min of the root ElasticQuotas that nobody uses if more,
+	// bounded by the max of the ElasticQuota and of its ancestors.
+	// +optional
+	Available v1.ResourceList `json:"available,omitempty" protobuf:"bytes,6,rep,name=available,casttype=ResourceList,castkey=ResourceName"`
+
+	// PendingPods is the number of pending pods subject to the ElasticQuota or its descendants that the scheduler
+	// rejected because of their quotas.
+	// +optional
+	PendingPods int32 `json:"pendingPods,omitempty" protobuf:"varint,7,opt,name=pendingPods"`
 }
 
+// These are the valid condition types of elasticQuotas.
+const (
+	// ElasticQuotaOverlapping means some pods selected by the ElasticQuota are also selected by other ElasticQuotas.
+	ElasticQuotaOverlapping = "Overlapping"
+
+	// ElasticQuotaOverMin means the ElasticQuota uses more than its min of some resource, borrowing from other
+	// ElasticQuotas.
+	ElasticQuotaOverMin = "OverMin"
+
+	// ElasticQuotaAtMax means the ElasticQuota uses its max of some resource.
+	ElasticQuotaAtMax = "AtMax"
+)
+
+// These are the reasons of the conditions of elasticQuotas.
+const (
+	// ElasticQuotaReasonPodsSelectedByOthers means some pods selected by the ElasticQuota are also selected by
+	// other ElasticQuotas.
+	ElasticQuotaReasonPodsSelectedByOthers = "PodsSelectedByOthers"
+
+	// ElasticQuotaReasonNoOverlap means no pod selected by the ElasticQuota is selected by another ElasticQuota.
+	ElasticQuotaReasonNoOverlap = "NoOverlap"
+
+	// ElasticQuotaReasonBorrowing means the ElasticQuota uses more than its min of some resource.
+	ElasticQuotaReasonBorrowing = "Borrowing"
+
+	// ElasticQuotaReasonWithinMin means the ElasticQuota uses at most its min of every resource.
+	ElasticQuotaReasonWithinMin = "WithinMin"
+
+	// ElasticQuotaReasonMaxReached means the ElasticQuota uses its max of some resource.
+	ElasticQuotaReasonMaxReached = "MaxReached"
+
+	// ElasticQuotaReasonBelowMax means the ElasticQuota uses less than its max of every resource.
+	ElasticQuotaReasonBelowMax = "BelowMax"
+)
+
+const (
+	// ReclaimDeadlineAnnotation is set on the pods using borrowed resources that are reclaimed by another
+	// ElasticQuota, with the RFC 3339 time after which they are preempted.
+	ReclaimDeadlineAnnotation = scheduling.GroupName + "/reclaim-deadline"
+
+	// BlockedByQuotaAnnotation is set by CapacityScheduling on the pods it rejects because of their ElasticQuota,
+	// with the reason of the rejection, and removed once the pods are no longer rejected because of it.
+	BlockedByQuotaAnnotation = scheduling.GroupName + "/blocked-by-quota"
+)
+
 // +kubebuilder:object:root=true
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -110,8 +235,108 @@ const (
 
 	// PodGroupLabel is the default label of coscheduling
 	PodGroupLabel = scheduling.GroupName + "/pod-group"
+
+	// PodGroupRoleLabel is the label of the role that a pod plays in its pod group
+	PodGroupRoleLabel = scheduling.GroupName + "/pod-group-role"
+
+	// PodGroupGangAnnotation is the annotation of a workload, e.g. a batch Job, whose pods are scheduled as a gang.
+	// When set to "true", a pod group named after the workload is created for its pods.
+	PodGroupGangAnnotation = scheduling.GroupName + "/gang"
+
+	// PodGroupMinMemberAnnotation is the annotation of a gang workload overriding the minMember of its pod group.
+	PodGroupMinMemberAnnotation = scheduling.GroupName + "/min-member"
+)
+
+// These are the valid condition types of podGroups.
+const (
+	// PodGroupScheduled means at least `spec.minMember` pods of the pod group have been bound to nodes.
+	PodGroupScheduled = "Scheduled"
+
+	// PodGroupQuorumReached means enough pods of the pod group have been assumed or bound to nodes
+	// to satisfy its quorum in the scheduler.
+	PodGroupQuorumReached = "QuorumReached"
+
+	// PodGroupTimedOut means the pod group failed to reach its quorum in time.
+	PodGroupTimedOut = "TimedOut"
+
+	// PodGroupBackoff means the pod group is backed off from being scheduled after a failure.
+	PodGroupBackoff = "Backoff"
+)
+
+// These are the reasons of the conditions of podGroups.
+const (
+	// PodGroupReasonUnschedulable means a pod of the pod group could not be scheduled,
+	// so the whole pod group got rejected.
+	PodGroupReasonUnschedulable = "Unschedulable"
+
+	// PodGroupReasonPermitTimeout means pods of the pod group waited in Permit longer than
+	// the schedule timeout for their siblings.
+	PodGroupReasonPermitTimeout = "PermitTimeout"
+
+	// PodGroupReasonScheduleTimeout means the pod group was not scheduled within `spec.scheduleTimeoutSeconds`.
+	PodGroupReasonScheduleTimeout = "ScheduleTimeout"
+
+	// PodGroupReasonNotEnoughPods means the pod group has fewer pods than its quorum.
+	PodGroupReasonNotEnoughPods = "NotEnoughPods"
+
+	// PodGroupReasonQuorumReached means enough pods of the pod group have been assigned to satisfy its quorum.
+	PodGroupReasonQuorumReached = "QuorumReached"
+
+	// PodGroupReasonWaitingForPods means fewer than `spec.minMember` pods of the pod group have been bound to nodes.
+	PodGroupReasonWaitingForPods = "WaitingForPods"
+
+	// PodGroupReasonPodsBound means at least `spec.minMember` pods of the pod group have been bound to nodes.
+	PodGroupReasonPodsBound = "PodsBound"
+
+	// PodGroupReasonBackedOff means the pod group is backed off after consecutive scheduling failures.
+	PodGroupReasonBackedOff = "BackedOff"
+
+	// PodGroupReasonRestarted means all members of the pod group were deleted to restart
+	// because of the RestartGroup failure policy.
+	PodGroupReasonRestarted = "Restarted"
+)
+
+// TopologyPreference is the level of preference for placing the members of a pod group
+// in the same topology domain.
+type TopologyPreference string
+
+// These are the valid topology preferences of podGroups.
+const (
+	// TopologyPreferenceNone means members of the pod group are placed regardless of
+	// the topology domains of their siblings.
+	TopologyPreferenceNone TopologyPreference = "None"
+
+	// TopologyPreferenceLow means members of the pod group are mildly preferred to be placed
+	// in the topology domains where their siblings have been assigned.
+	TopologyPreferenceLow TopologyPreference = "Low"
+
+	// TopologyPreferenceHigh means members of the pod group are strongly preferred to be placed
+	// in the topology domains where their siblings have been assigned.
+	TopologyPreferenceHigh TopologyPreference = "High"
+)
+
+// PodGroupFailurePolicy is the action taken on a pod group when one of its members fails.
+type PodGroupFailurePolicy string
+
+// These are the valid failure policies of podGroups.
+const (
+	// PodGroupFailurePolicyIgnore means the other members of the pod group keep running
+	// when one of its members fails.
+	PodGroupFailurePolicyIgnore PodGroupFailurePolicy = "Ignore"
+
+	// PodGroupFailurePolicyRestartGroup means all members of the pod group are deleted when
+	// one of its members fails, so that their controllers recreate the whole group.
+	PodGroupFailurePolicyRestartGroup PodGroupFailurePolicy = "RestartGroup"
+
+	// PodGroupFailurePolicyFailGroup means the pod group fails and its remaining members are deleted
+	// when one of its members fails.
+	PodGroupFailurePolicyFailGroup PodGroupFailurePolicy = "FailGroup"
 )
 
+// DefaultPodGroupMaxRestarts is the maximum number of restarts of a pod group
+// with the RestartGroup failure policy, if its maxRestarts is unset.
+const DefaultPodGroupMaxRestarts int32 = 6
+
 // PodGroup is a collection of Pod; used for batch workload.
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -150,6 +375,19 @@ type PodGroupSpec struct {
 	// +kubebuilder:validation:Minimum=1
 	MinMember int32 `json:"minMember,omitempty"`
 
+	// MaxMember defines the desired number of members/tasks of an elastic pod group.
+	// The first minMember pods are scheduled as a gang, and then the pod group grows
+	// opportunistically up to maxMember pods, scheduled one by one.
+	// If unset, the pod group is not elastic and its size is not limited.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxMember *int32 `json:"maxMember,omitempty"`
+
+	// PreemptibleSurplus allows the members of an elastic pod group beyond its minMember to be
+	// preempted by the minimum members of other pod groups, regardless of their priorities.
+	// +optional
+	PreemptibleSurplus bool `json:"preemptibleSurplus,omitempty"`
+
 	// MinResources defines the minimal resource of members/tasks to run the pod group;
 	// if there's not enough resources to start all tasks, the scheduler
 	// will not start any.
@@ -157,6 +395,61 @@ type PodGroupSpec struct {
 
 	// ScheduleTimeoutSeconds defines the maximal time of members/tasks to wait before run the pod group;
 	ScheduleTimeoutSeconds *int32 `json:"scheduleTimeoutSeconds,omitempty"`
+
+	// TopologyPreference defines how strongly members/tasks of the pod group prefer nodes
+	// in the same topology domain as their already assigned siblings.
+	// The topology domain is determined by the topology key configured in the scheduler.
+	// Defaults to None.
+	// +kubebuilder:validation:Enum=None;Low;High
+	// +optional
+	TopologyPreference TopologyPreference `json:"topologyPreference,omitempty"`
+
+	// Roles defines the named sub-groups of the pod group, each with its own minimal number of members/tasks.
+	// A pod plays the role named by its `scheduling.x-k8s.io/pod-group-role` label.
+	// If roles are defined, the pod group is only schedulable when the minMember of every role is met,
+	// in addition to the minMember of the whole pod group.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Roles []PodGroupRole `json:"roles,omitempty"`
+
+	// TTLSecondsAfterFinished limits the lifetime of a pod group that has finished or failed.
+	// Once the pod group has been Finished or Failed for this long, it is deleted.
+	// If unset, the pod group is never deleted automatically.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+
+	// FailurePolicy defines what happens to the pod group when one of its members fails.
+	// Defaults to Ignore.
+	// +kubebuilder:validation:Enum=Ignore;RestartGroup;FailGroup
+	// +optional
+	FailurePolicy PodGroupFailurePolicy `json:"failurePolicy,omitempty"`
+
+	// MaxRestarts limits the number of times the members of the pod group are restarted
+	// because of the RestartGroup failure policy. Once a member fails after that many restarts,
+	// the pod group fails and its remaining members are deleted.
+	// Defaults to 6.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxRestarts *int32 `json:"maxRestarts,omitempty"`
+
+	// PriorityClassName is the name of the PriorityClass giving the priority of the pod group
+	// in the scheduling queue, when pods are ordered at pod group granularity.
+	// If unset or not found, the priority of the pods is used.
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+}
+
+// PodGroupRole represents a named sub-group of a pod group.
+type PodGroupRole struct {
+	// Name of the role, matched against the `scheduling.x-k8s.io/pod-group-role` label of the pods.
+	Name string `json:"name"`
+
+	// MinMember defines the minimal number of members/tasks playing this role to run the pod group.
+	// The minimum is 1
+	// +kubebuilder:validation:Minimum=1
+	MinMember int32 `json:"minMember"`
 }
 
 // PodGroupStatus represents the current state of a pod group.
@@ -182,6 +475,63 @@ type PodGroupStatus struct {
 
 	// ScheduleStartTime of the group
 	ScheduleStartTime metav1.Time `json:"scheduleStartTime,omitempty"`
+
+	// SchedulingFailures is the number of consecutive times the group failed to be scheduled.
+	// It is reset once the group gets scheduled.
+	// +optional
+	SchedulingFailures int32 `json:"schedulingFailures,omitempty"`
+
+	// NextRetryTime is the time before which the group is backed off from being scheduled again.
+	// +optional
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+
+	// Conditions represent the latest available observations of the group's state.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Roles is the observed state of each role of the pod group.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Roles []PodGroupRoleStatus `json:"roles,omitempty"`
+
+	// CompletionTime is the time the pod group became Finished or Failed.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Restarts is the number of times the members of the pod group were restarted
+	// because of the RestartGroup failure policy.
+	// +optional
+	Restarts int32 `json:"restarts,omitempty"`
+
+	// Elastic is the number of actively running pods beyond `spec.minMember`,
+	// if the pod group is elastic.
+	// +optional
+	Elastic int32 `json:"elastic,omitempty"`
+}
+
+// PodGroupRoleStatus represents the current state of a role of a pod group.
+type PodGroupRoleStatus struct {
+	// Name of the role.
+	Name string `json:"name"`
+
+	// The number of pods playing the role.
+	// +optional
+	Total int32 `json:"total,omitempty"`
+
+	// The number of actively running pods playing the role.
+	// +optional
+	Running int32 `json:"running,omitempty"`
+
+	// The number of pods playing the role which reached phase Succeeded.
+	// +optional
+	Succeeded int32 `json:"succeeded,omitempty"`
+
+	// The number of pods playing the role which reached phase Failed.
+	// +optional
+	Failed int32 `json:"failed,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -17,7 +17,12 @@ limitations under the License.
 package app
 
 import (
+	"time"
+
 	"github.com/spf13/pflag"
+	v1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/controllers"
 )
 
 type ServerRunOptions struct {
@@ -27,6 +32,25 @@ type ServerRunOptions struct {
 	ApiServerBurst       int
 	Workers              int
 	EnableLeaderElection bool
+	// EnablePodGroupOwner enables the automatic creation of PodGroups for gang workloads,
+	// and the webhook labeling their pods.
+	EnablePodGroupOwner bool
+	// EnableValidatingWebhooks enables the webhooks validating ElasticQuotas and PodGroups.
+	EnableValidatingWebhooks bool
+	// EnforceElasticQuotaMinSum denies the ElasticQuotas growing the sum of the min of the root
+	// ElasticQuotas beyond the allocatable resources of the cluster.
+	EnforceElasticQuotaMinSum bool
+	WebhookHost               string
+	WebhookPort               int
+	CertDir                   string
+	// EnableNetworkTopology enables the aggregation of probed latencies into NetworkTopology costs.
+	EnableNetworkTopology bool
+	// NetworkTopologyKeys are the node label keys of the topology levels network costs are measured between.
+	NetworkTopologyKeys []string
+	// NetworkCostSmoothingFactor is the weight of the latest measurements in the network costs.
+	NetworkCostSmoothingFactor float64
+	// NetworkCostSyncPeriod is the period the network costs are updated at.
+	NetworkCostSyncPeriod time.Duration
 }
 
 func NewServerRunOptions() *ServerRunOptions {
@@ -42,4 +66,14 @@ func (s *ServerRunOptions) addAllFlags() {
 	pflag.IntVar(&s.ApiServerBurst, "burst", 10, "burst of query apiserver.")
 	pflag.IntVar(&s.Workers, "workers", 1, "workers of scheduler-plugin-controllers.")
 	pflag.BoolVar(&s.EnableLeaderElection, "enableLeaderElection", s.EnableLeaderElection, "If EnableLeaderElection for controller.")
+	pflag.BoolVar(&s.EnablePodGroupOwner, "enablePodGroupOwner", s.EnablePodGroupOwner, "If create PodGroups automatically for the Jobs annotated with scheduling.x-k8s.io/gang, and label their pods through a mutating webhook.")
+	pflag.BoolVar(&s.EnableValidatingWebhooks, "enableValidatingWebhooks", s.EnableValidatingWebhooks, "If validate ElasticQuotas and PodGroups through validating webhooks.")
+	pflag.BoolVar(&s.EnforceElasticQuotaMinSum, "enforceElasticQuotaMinSum", s.EnforceElasticQuotaMinSum, "If deny the ElasticQuotas whose min can't be guaranteed by the allocatable resources of the cluster, when validating webhooks are enabled.")
+	pflag.StringVar(&s.WebhookHost, "webhookHost", "", "Address the webhook server binds to, all interfaces by default.")
+	pflag.IntVar(&s.WebhookPort, "webhookPort", 9443, "Port of the webhook server.")
+	pflag.StringVar(&s.CertDir, "certDir", "", "Directory of the serving certificate of the webhook server.")
+	pflag.BoolVar(&s.EnableNetworkTopology, "enableNetworkTopology", s.EnableNetworkTopology, "If aggregate the latencies published by node probers into the NetperfCosts weights of NetworkTopologies.")
+	pflag.StringSliceVar(&s.NetworkTopologyKeys, "networkTopologyKeys", []string{v1.LabelTopologyRegion, v1.LabelTopologyZone}, "Node label keys of the topology levels network costs are measured between, from the broadest.")
+	pflag.Float64Var(&s.NetworkCostSmoothingFactor, "networkCostSmoothingFactor", controllers.DefaultNetworkCostSmoothingFactor, "Weight in (0, 1] of the latest measurements in the network costs, 1 disabling smoothing.")
+	pflag.DurationVar(&s.NetworkCostSyncPeriod, "networkCostSyncPeriod", controllers.DefaultNetworkCostSyncPeriod, "Period the network costs are updated at.")
 }
@@ -24,6 +24,9 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	ntv1alpha1 "github.com/diktyo-io/networktopology-api/pkg/apis/networktopology/v1alpha1"
 
 	schedulingv1a1 "sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
 	"sigs.k8s.io/scheduler-plugins/pkg/controllers"
@@ -38,6 +41,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(schedulingv1a1.AddToScheme(scheme))
+	utilruntime.Must(ntv1alpha1.AddToScheme(scheme))
 }
 
 func Run(s *ServerRunOptions) error {
@@ -56,6 +60,11 @@ func Run(s *ServerRunOptions) error {
 		LeaderElection:          s.EnableLeaderElection,
 		LeaderElectionID:        "sched-plugins-controllers",
 		LeaderElectionNamespace: "kube-system",
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Host:    s.WebhookHost,
+			Port:    s.WebhookPort,
+			CertDir: s.CertDir,
+		}),
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -80,6 +89,41 @@ func Run(s *ServerRunOptions) error {
 		return err
 	}
 
+	if s.EnablePodGroupOwner {
+		adapters := []controllers.PodGroupOwnerAdapter{&controllers.JobAdapter{}}
+		for _, adapter := range adapters {
+			if err = (&controllers.PodGroupOwnerReconciler{
+				Client:  mgr.GetClient(),
+				Scheme:  mgr.GetScheme(),
+				Adapter: adapter,
+				Workers: s.Workers,
+			}).SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create controller", "controller", "PodGroupOwner", "kind", adapter.GroupVersionKind().Kind)
+				return err
+			}
+		}
+		(&controllers.PodGroupLabeler{Adapters: adapters}).SetupWithManager(mgr)
+	}
+
+	if s.EnableValidatingWebhooks {
+		(&controllers.ElasticQuotaValidator{EnforceMinSum: s.EnforceElasticQuotaMinSum}).SetupWithManager(mgr)
+		(&controllers.PodGroupValidator{}).SetupWithManager(mgr)
+	}
+
+	if s.EnableNetworkTopology {
+		if err = (&controllers.NetworkTopologyReconciler{
+			Client:          mgr.GetClient(),
+			Scheme:          mgr.GetScheme(),
+			Workers:         s.Workers,
+			TopologyKeys:    s.NetworkTopologyKeys,
+			SmoothingFactor: s.NetworkCostSmoothingFactor,
+			SyncPeriod:      s.NetworkCostSyncPeriod,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "NetworkTopology")
+			return err
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		return err
@@ -18,21 +18,28 @@ package capacityscheduling
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"sync"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	policy "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/informers"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	policylisters "k8s.io/client-go/listers/policy/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
 	"k8s.io/klog/v2"
 	extenderv1 "k8s.io/kube-scheduler/extender/v1"
@@ -40,6 +47,7 @@ import (
 	"k8s.io/kubernetes/pkg/scheduler/framework/preemption"
 	"k8s.io/kubernetes/pkg/scheduler/metrics"
 	schedutil "k8s.io/kubernetes/pkg/scheduler/util"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"sigs.k8s.io/scheduler-plugins/apis/scheduling"
@@ -60,9 +68,28 @@ type CapacityScheduling struct {
 	logger            klog.Logger
 	fh                framework.Handle
 	podLister         corelisters.PodLister
+	namespaceLister   corelisters.NamespaceLister
 	pdbLister         policylisters.PodDisruptionBudgetLister
 	client            client.Client
-	elasticQuotaInfos ElasticQuotaInfos
+	elasticQuotaInfos *ElasticQuotaInfos
+	// reclaimingPods are the preemptors waiting for the reclaim deadline of their victims.
+	reclaimingPods sets.Set[types.UID]
+	// elasticQuotasVersion is incremented when the ElasticQuotaInfos are added, updated or deleted, for the pods
+	// reassigned without the lock to detect the changes made meanwhile.
+	elasticQuotasVersion uint64
+	// blockedByQuotaQueue queues the UIDs of the pods whose BlockedByQuotaAnnotation has to be patched.
+	blockedByQuotaQueue workqueue.TypedRateLimitingInterface[types.UID]
+	// blockedByQuotaMarks are the last BlockedByQuotaAnnotation reasons decided for the pods, by UID.
+	blockedByQuotaMarks map[types.UID]blockedByQuotaMark
+	// blockedByQuotaLock protects blockedByQuotaMarks.
+	blockedByQuotaLock sync.Mutex
+}
+
+// blockedByQuotaMark is the BlockedByQuotaAnnotation reason decided for a pod.
+type blockedByQuotaMark struct {
+	namespace string
+	name      string
+	reason    string
 }
 
 // PreFilterState computed at PreFilter and used at PostFilter or Reserve.
@@ -87,7 +114,7 @@ func (s *PreFilterState) Clone() framework.StateData {
 
 // ElasticQuotaSnapshotState stores the snapshot of elasticQuotas.
 type ElasticQuotaSnapshotState struct {
-	elasticQuotaInfos ElasticQuotaInfos
+	elasticQuotaInfos *ElasticQuotaInfos
 }
 
 // Clone the ElasticQuotaSnapshot state.
@@ -110,6 +137,11 @@ const (
 	// preFilterStateKey is the key in CycleState to NodeResourcesFit pre-computed data.
 	preFilterStateKey       = "PreFilter" + Name
 	ElasticQuotaSnapshotKey = "ElasticQuotaSnapshot"
+
+	// Reasons of the BlockedByQuotaAnnotation of the pods rejected in PreFilter.
+	blockedByQuotaReasonOverMax           = "OverMax"
+	blockedByQuotaReasonOverFairShare     = "OverFairShare"
+	blockedByQuotaReasonAggregatedOverMin = "AggregatedOverMin"
 )
 
 // Name returns name of the plugin. It is used in logs, etc.
@@ -125,6 +157,7 @@ func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (fram
 		fh:                handle,
 		elasticQuotaInfos: NewElasticQuotaInfos(),
 		podLister:         handle.SharedInformerFactory().Core().V1().Pods().Lister(),
+		namespaceLister:   handle.SharedInformerFactory().Core().V1().Namespaces().Lister(),
 		pdbLister:         getPDBLister(handle.SharedInformerFactory()),
 	}
 	logger := klog.FromContext(ctx)
@@ -186,6 +219,33 @@ func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (fram
 			},
 		},
 	)
+	// The pending pods deleted while blocked by quota no longer contend for the lendable resources.
+	podInformer.AddEventHandler(
+		cache.FilteringResourceEventHandler{
+			FilterFunc: func(obj interface{}) bool {
+				switch t := obj.(type) {
+				case *v1.Pod:
+					return !assignedPod(t)
+				case cache.DeletedFinalStateUnknown:
+					if pod, ok := t.Obj.(*v1.Pod); ok {
+						return !assignedPod(pod)
+					}
+					return false
+				default:
+					return false
+				}
+			},
+			Handler: cache.ResourceEventHandlerFuncs{
+				DeleteFunc: c.deletePendingPod,
+			},
+		},
+	)
+	// The pods subject to an ElasticQuota with a namespace selector change with the labels of their namespace.
+	namespaceInformer := handle.SharedInformerFactory().Core().V1().Namespaces().Informer()
+	namespaceInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: c.updateNamespace,
+	})
+	c.startBlockedByQuotaWorker(ctx)
 	logger.Info("CapacityScheduling start")
 	return c, nil
 }
@@ -202,8 +262,9 @@ func (c *CapacityScheduling) EventsToRegister(_ context.Context) ([]framework.Cl
 }
 
 // PreFilter performs the following validations.
-// 1. Check if the (pod.request + eq.allocated) is less than eq.max.
-// 2. Check if the sum(eq's usage) > sum(eq's min).
+// 1. Check if the (pod.request + eq.allocated) is less than eq.max, for the eq and each of its ancestors.
+// 2. Check if the eq borrows more than its weighted fair share of the lendable resources.
+// 3. Check if the sum(eq's usage) > sum(root eq's min).
 func (c *CapacityScheduling) PreFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod) (*framework.PreFilterResult, *framework.Status) {
 	// TODO improve the efficiency of taking snapshot
 	// e.g. use a two-pointer data structure to only copy the updated EQs when necessary.
@@ -213,12 +274,14 @@ func (c *CapacityScheduling) PreFilter(ctx context.Context, state *framework.Cyc
 	state.Write(ElasticQuotaSnapshotKey, snapshotElasticQuota)
 
 	elasticQuotaInfos := snapshotElasticQuota.elasticQuotaInfos
-	eq := snapshotElasticQuota.elasticQuotaInfos[pod.Namespace]
+	key := elasticQuotaInfos.keyOf(pod, getNamespaceLabels(c.namespaceLister, pod.Namespace))
+	eq := elasticQuotaInfos.infos[key]
 	if eq == nil {
 		preFilterState := &PreFilterState{
 			podReq: *podReq,
 		}
 		state.Write(preFilterStateKey, preFilterState)
+		c.markBlockedByQuota(ctx, pod, key, "")
 		return nil, framework.NewStatus(framework.Success)
 	}
 
@@ -242,18 +305,18 @@ func (c *CapacityScheduling) PreFilter(ctx context.Context, state *framework.Cyc
 			if p.Pod.UID == pod.UID {
 				continue
 			}
-			ns := p.Pod.Namespace
-			info := c.elasticQuotaInfos[ns]
+			pKey := elasticQuotaInfos.keyOf(p.Pod, getNamespaceLabels(c.namespaceLister, p.Pod.Namespace))
+			info := elasticQuotaInfos.infos[pKey]
 			if info != nil {
 				pResourceRequest := util.ResourceList(computePodResourceRequest(p.Pod))
-				// If they are subject to the same quota(namespace) and p is more important than pod,
+				// If they are subject to the same quota and p is more important than pod,
 				// p will be added to the nominatedResource and totalNominatedResource.
-				// If they aren't subject to the same quota(namespace) and the usage of quota(p's namespace) does not exceed min,
+				// If they aren't subject to the same quota and the usage of p's quota does not exceed min,
 				// p will be added to the totalNominatedResource.
-				if ns == pod.Namespace && corev1helpers.PodPriority(p.Pod) >= corev1helpers.PodPriority(pod) {
+				if pKey == key && corev1helpers.PodPriority(p.Pod) >= corev1helpers.PodPriority(pod) {
 					nominatedPodsReqInEQWithPodReq.Add(pResourceRequest)
 					nominatedPodsReqWithPodReq.Add(pResourceRequest)
-				} else if ns != pod.Namespace && !info.usedOverMin() {
+				} else if pKey != key && !info.usedOverMin() {
 					nominatedPodsReqWithPodReq.Add(pResourceRequest)
 				}
 			}
@@ -269,17 +332,151 @@ func (c *CapacityScheduling) PreFilter(ctx context.Context, state *framework.Cyc
 	}
 	state.Write(preFilterStateKey, preFilterState)
 
-	if eq.usedOverMaxWith(nominatedPodsReqInEQWithPodReq) {
-		return nil, framework.NewStatus(framework.Unschedulable, fmt.Sprintf("Pod %v/%v is rejected in PreFilter because ElasticQuota %v is more than Max", pod.Namespace, pod.Name, eq.Namespace))
+	if overMax := elasticQuotaInfos.ancestorOverMaxWith(key, nominatedPodsReqInEQWithPodReq); overMax != nil {
+		c.markBlockedByQuota(ctx, pod, key, blockedByQuotaReasonOverMax)
+		return nil, framework.NewStatus(framework.Unschedulable, fmt.Sprintf("Pod %v/%v is rejected in PreFilter because ElasticQuota %v is more than Max", pod.Namespace, pod.Name, overMax.Namespace))
+	}
+
+	if elasticQuotaInfos.overFairShareWith(key, nominatedPodsReqInEQWithPodReq) {
+		c.markBlockedByQuota(ctx, pod, key, blockedByQuotaReasonOverFairShare)
+		return nil, framework.NewStatus(framework.Unschedulable, fmt.Sprintf("Pod %v/%v is rejected in PreFilter because ElasticQuota %v would borrow more than its fair share", pod.Namespace, pod.Name, eq.Namespace))
 	}
 
 	if elasticQuotaInfos.aggregatedUsedOverMinWith(*nominatedPodsReqWithPodReq) {
+		c.markBlockedByQuota(ctx, pod, key, blockedByQuotaReasonAggregatedOverMin)
 		return nil, framework.NewStatus(framework.Unschedulable, fmt.Sprintf("Pod %v/%v is rejected in PreFilter because total ElasticQuota used is more than min", pod.Namespace, pod.Name))
 	}
 
+	c.markBlockedByQuota(ctx, pod, key, "")
 	return nil, framework.NewStatus(framework.Success, "")
 }
 
+// markBlockedByQuota sets the BlockedByQuotaAnnotation of the pod to the given reason, or removes it if the reason
+// is empty, for the ElasticQuota controller to count the pods pending because of their quota. The pod is only
+// patched when the reason changes, by a single worker not to delay the scheduling cycle, which only applies the last
+// reason decided for the pod. The pods blocked because the lendable resources are contended are also recorded in the
+// ElasticQuotaInfo of the given key, for the fair share to cap the borrowing of the other ElasticQuotas.
+func (c *CapacityScheduling) markBlockedByQuota(ctx context.Context, pod *v1.Pod, key string, reason string) {
+	c.setBlockedPod(pod, key, reason == blockedByQuotaReasonOverFairShare || reason == blockedByQuotaReasonAggregatedOverMin)
+	if c.blockedByQuotaQueue == nil {
+		return
+	}
+	c.blockedByQuotaLock.Lock()
+	defer c.blockedByQuotaLock.Unlock()
+	// The queued pod may be stale until the informer catches up with the last patch.
+	current := pod.Annotations[v1alpha1.BlockedByQuotaAnnotation]
+	if mark, ok := c.blockedByQuotaMarks[pod.UID]; ok {
+		current = mark.reason
+	}
+	if current == reason {
+		return
+	}
+	c.blockedByQuotaMarks[pod.UID] = blockedByQuotaMark{namespace: pod.Namespace, name: pod.Name, reason: reason}
+	c.blockedByQuotaQueue.Add(pod.UID)
+}
+
+// forgetBlockedByQuota drops the BlockedByQuotaAnnotation reason decided for the given deleted pod.
+func (c *CapacityScheduling) forgetBlockedByQuota(pod *v1.Pod) {
+	if c.blockedByQuotaQueue == nil {
+		return
+	}
+	c.blockedByQuotaLock.Lock()
+	defer c.blockedByQuotaLock.Unlock()
+	delete(c.blockedByQuotaMarks, pod.UID)
+}
+
+// startBlockedByQuotaWorker starts the worker patching the BlockedByQuotaAnnotation of the pods, until the given
+// context is done.
+func (c *CapacityScheduling) startBlockedByQuotaWorker(ctx context.Context) {
+	c.blockedByQuotaMarks = make(map[types.UID]blockedByQuotaMark)
+	c.blockedByQuotaQueue = workqueue.NewTypedRateLimitingQueueWithConfig(
+		workqueue.DefaultTypedControllerRateLimiter[types.UID](),
+		workqueue.TypedRateLimitingQueueConfig[types.UID]{Name: "CapacitySchedulingBlockedByQuota"},
+	)
+	go func() {
+		<-ctx.Done()
+		c.blockedByQuotaQueue.ShutDown()
+	}()
+	go wait.UntilWithContext(ctx, func(ctx context.Context) {
+		for c.processNextBlockedByQuota(ctx) {
+		}
+	}, time.Second)
+}
+
+// processNextBlockedByQuota patches the BlockedByQuotaAnnotation of the next queued pod to the last reason decided
+// for it. It returns false when the queue is shut down.
+func (c *CapacityScheduling) processNextBlockedByQuota(ctx context.Context) bool {
+	uid, quit := c.blockedByQuotaQueue.Get()
+	if quit {
+		return false
+	}
+	defer c.blockedByQuotaQueue.Done(uid)
+
+	c.blockedByQuotaLock.Lock()
+	mark, ok := c.blockedByQuotaMarks[uid]
+	c.blockedByQuotaLock.Unlock()
+	if !ok {
+		c.blockedByQuotaQueue.Forget(uid)
+		return true
+	}
+	if err := c.patchBlockedByQuota(ctx, uid, mark); err != nil {
+		if apierrors.IsNotFound(err) {
+			c.blockedByQuotaQueue.Forget(uid)
+			return true
+		}
+		klog.FromContext(ctx).Error(err, "Failed to mark the pod blocked by quota", "pod", klog.KRef(mark.namespace, mark.name), "reason", mark.reason)
+		c.blockedByQuotaQueue.AddRateLimited(uid)
+		return true
+	}
+	c.blockedByQuotaQueue.Forget(uid)
+	return true
+}
+
+// patchBlockedByQuota patches the BlockedByQuotaAnnotation of the given pod, unless it's already up to date.
+func (c *CapacityScheduling) patchBlockedByQuota(ctx context.Context, uid types.UID, mark blockedByQuotaMark) error {
+	if c.podLister != nil {
+		pod, err := c.podLister.Pods(mark.namespace).Get(mark.name)
+		if err == nil && pod.UID == uid && pod.Annotations[v1alpha1.BlockedByQuotaAnnotation] == mark.reason {
+			return nil
+		}
+	}
+	var value interface{}
+	if mark.reason != "" {
+		value = mark.reason
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{v1alpha1.BlockedByQuotaAnnotation: value},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = c.fh.ClientSet().CoreV1().Pods(mark.namespace).Patch(ctx, mark.name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// setBlockedPod records whether the given pod is blocked by the ElasticQuotaInfo of the given key, and forgets it
+// in the other ElasticQuotaInfos.
+func (c *CapacityScheduling) setBlockedPod(pod *v1.Pod, key string, blocked bool) {
+	podKey, err := framework.GetPodKey(pod)
+	if err != nil {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	for k, elasticQuotaInfo := range c.elasticQuotaInfos.infos {
+		if blocked && k == key {
+			if elasticQuotaInfo.blockedPods == nil {
+				elasticQuotaInfo.blockedPods = sets.New[string]()
+			}
+			elasticQuotaInfo.blockedPods.Insert(podKey)
+		} else {
+			elasticQuotaInfo.blockedPods.Delete(podKey)
+		}
+	}
+}
+
 // PreFilterExtensions returns prefilter extensions, pod add and remove.
 func (c *CapacityScheduling) PreFilterExtensions() framework.PreFilterExtensions {
 	return c
@@ -295,7 +492,8 @@ func (c *CapacityScheduling) AddPod(ctx context.Context, cycleState *framework.C
 		return framework.NewStatus(framework.Error, err.Error())
 	}
 
-	elasticQuotaInfo := elasticQuotaSnapshotState.elasticQuotaInfos[podToAdd.Pod.Namespace]
+	elasticQuotaInfos := elasticQuotaSnapshotState.elasticQuotaInfos
+	elasticQuotaInfo := elasticQuotaInfos.infos[elasticQuotaInfos.keyOf(podToAdd.Pod, getNamespaceLabels(c.namespaceLister, podToAdd.Pod.Namespace))]
 	if elasticQuotaInfo != nil {
 		err := elasticQuotaInfo.addPodIfNotPresent(podToAdd.Pod)
 		if err != nil {
@@ -316,7 +514,8 @@ func (c *CapacityScheduling) RemovePod(ctx context.Context, cycleState *framewor
 		return framework.NewStatus(framework.Error, err.Error())
 	}
 
-	elasticQuotaInfo := elasticQuotaSnapshotState.elasticQuotaInfos[podToRemove.Pod.Namespace]
+	elasticQuotaInfos := elasticQuotaSnapshotState.elasticQuotaInfos
+	elasticQuotaInfo := elasticQuotaInfos.infos[elasticQuotaInfos.keyOf(podToRemove.Pod, getNamespaceLabels(c.namespaceLister, podToRemove.Pod.Namespace))]
 	if elasticQuotaInfo != nil {
 		err = elasticQuotaInfo.deletePodIfPresent(podToRemove.Pod)
 		if err != nil {
@@ -336,22 +535,107 @@ func (c *CapacityScheduling) PostFilter(ctx context.Context, state *framework.Cy
 		c.Name(),
 		c.fh,
 		&preemptor{
-			logger: c.logger,
-			fh:     c.fh,
-			state:  state,
+			logger:          c.logger,
+			fh:              c.fh,
+			namespaceLister: c.namespaceLister,
+			state:           state,
 		},
 		false, // enableAsyncPreemption
 	)
+	pe.PreemptPod = c.preemptPodAfterGracePeriod(state, pe.PreemptPod)
 
 	return pe.Preempt(ctx, state, pod, m)
 }
 
+// preemptPodAfterGracePeriod wraps preemptPod so that the victims borrowing the resources reclaimed by the
+// preemptor are notified of the reclaim deadline and only preempted once it has passed, if their ElasticQuota has a
+// reclaim grace period. Until then, the preemptor keeps being nominated to the node of its victims.
+func (c *CapacityScheduling) preemptPodAfterGracePeriod(state *framework.CycleState, preemptPod func(context.Context, preemption.Candidate, *v1.Pod, *v1.Pod, string) error) func(context.Context, preemption.Candidate, *v1.Pod, *v1.Pod, string) error {
+	return func(ctx context.Context, candidate preemption.Candidate, preemptor, victim *v1.Pod, pluginName string) error {
+		gracePeriod := c.reclaimGracePeriod(state, preemptor, victim)
+		if gracePeriod <= 0 || victim.DeletionTimestamp != nil || c.fh.GetWaitingPod(victim.UID) != nil {
+			return preemptPod(ctx, candidate, preemptor, victim, pluginName)
+		}
+
+		now := time.Now()
+		deadline, err := time.Parse(time.RFC3339, victim.Annotations[v1alpha1.ReclaimDeadlineAnnotation])
+		// A deadline that passed more than a grace period ago is left over from an earlier reclaim.
+		if err != nil || now.After(deadline.Add(gracePeriod)) {
+			deadline = now.Add(gracePeriod).Truncate(time.Second)
+			if err := c.notifyReclaim(ctx, preemptor, victim, deadline); err != nil {
+				return err
+			}
+		}
+		if now.Before(deadline) {
+			c.activateAt(preemptor, deadline)
+			return nil
+		}
+		return preemptPod(ctx, candidate, preemptor, victim, pluginName)
+	}
+}
+
+// reclaimGracePeriod returns the reclaim grace period of the victim when the preemptor reclaims resources from it,
+// zero when both are subject to the same ElasticQuota.
+func (c *CapacityScheduling) reclaimGracePeriod(state *framework.CycleState, preemptor, victim *v1.Pod) time.Duration {
+	elasticQuotaSnapshotState, err := getElasticQuotaSnapshotState(state)
+	if err != nil {
+		return 0
+	}
+	elasticQuotaInfos := elasticQuotaSnapshotState.elasticQuotaInfos
+	key := elasticQuotaInfos.keyOf(victim, getNamespaceLabels(c.namespaceLister, victim.Namespace))
+	if key == elasticQuotaInfos.keyOf(preemptor, getNamespaceLabels(c.namespaceLister, preemptor.Namespace)) {
+		return 0
+	}
+	if elasticQuotaInfo := elasticQuotaInfos.infos[key]; elasticQuotaInfo != nil {
+		return elasticQuotaInfo.ReclaimGracePeriod
+	}
+	return 0
+}
+
+// notifyReclaim annotates the victim with the reclaim deadline and records an Event announcing it.
+func (c *CapacityScheduling) notifyReclaim(ctx context.Context, preemptor, victim *v1.Pod, deadline time.Time) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{v1alpha1.ReclaimDeadlineAnnotation: deadline.Format(time.RFC3339)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := c.fh.ClientSet().CoreV1().Pods(victim.Namespace).Patch(ctx, victim.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		klog.FromContext(ctx).Error(err, "Failed to annotate the pod with its reclaim deadline", "pod", klog.KObj(victim))
+		return err
+	}
+	c.fh.EventRecorder().Eventf(victim, preemptor, v1.EventTypeNormal, "ReclaimScheduled", "Preempting",
+		"Borrowed resources are reclaimed by pod %v, the pod will be preempted after %v", klog.KObj(preemptor), deadline.Format(time.RFC3339))
+	return nil
+}
+
+// activateAt moves the preemptor back to the active queue at the given deadline, to preempt its victims then.
+func (c *CapacityScheduling) activateAt(preemptor *v1.Pod, deadline time.Time) {
+	c.Lock()
+	defer c.Unlock()
+	if c.reclaimingPods == nil {
+		c.reclaimingPods = sets.New[types.UID]()
+	}
+	if c.reclaimingPods.Has(preemptor.UID) {
+		return
+	}
+	c.reclaimingPods.Insert(preemptor.UID)
+	time.AfterFunc(time.Until(deadline), func() {
+		c.Lock()
+		c.reclaimingPods.Delete(preemptor.UID)
+		c.Unlock()
+		c.fh.Activate(c.logger, map[string]*v1.Pod{preemptor.Namespace + "/" + preemptor.Name: preemptor})
+	})
+}
+
 func (c *CapacityScheduling) Reserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) *framework.Status {
 	c.Lock()
 	defer c.Unlock()
 	logger := klog.FromContext(klog.NewContext(ctx, c.logger)).WithValues("ExtensionPoint", "Reserve")
 
-	elasticQuotaInfo := c.elasticQuotaInfos[pod.Namespace]
+	elasticQuotaInfo := c.elasticQuotaInfos.infos[c.elasticQuotaKeyOf(pod)]
 	if elasticQuotaInfo != nil {
 		err := elasticQuotaInfo.addPodIfNotPresent(pod)
 		if err != nil {
@@ -368,7 +652,7 @@ func (c *CapacityScheduling) Unreserve(ctx context.Context, state *framework.Cyc
 
 	logger := klog.FromContext(ctx)
 
-	elasticQuotaInfo := c.elasticQuotaInfos[pod.Namespace]
+	elasticQuotaInfo := c.elasticQuotaInfos.infos[c.elasticQuotaKeyOf(pod)]
 	if elasticQuotaInfo != nil {
 		err := elasticQuotaInfo.deletePodIfPresent(pod)
 		if err != nil {
@@ -378,9 +662,10 @@ func (c *CapacityScheduling) Unreserve(ctx context.Context, state *framework.Cyc
 }
 
 type preemptor struct {
-	logger klog.Logger
-	fh     framework.Handle
-	state  *framework.CycleState
+	logger          klog.Logger
+	fh              framework.Handle
+	namespaceLister corelisters.NamespaceLister
+	state           *framework.CycleState
 }
 
 func (p *preemptor) OrderedScoreFuncs(ctx context.Context, nodesToVictims map[string]*extenderv1.Victims) []func(node string) int64 {
@@ -440,27 +725,34 @@ func (p *preemptor) PodEligibleToPreemptOthers(ctx context.Context, pod *v1.Pod,
 		}
 
 		podPriority := corev1helpers.PodPriority(pod)
-		preemptorEQInfo, preemptorWithEQ := elasticQuotaSnapshotState.elasticQuotaInfos[pod.Namespace]
-		if preemptorWithEQ {
-			moreThanMinWithPreemptor := preemptorEQInfo.usedOverMinWith(&preFilterState.nominatedPodsReqInEQWithPodReq)
+		elasticQuotaInfos := elasticQuotaSnapshotState.elasticQuotaInfos
+		keyOf := func(pod *v1.Pod) string {
+			return elasticQuotaInfos.keyOf(pod, getNamespaceLabels(p.namespaceLister, pod.Namespace))
+		}
+		key := keyOf(pod)
+		if _, preemptorWithEQ := elasticQuotaInfos.infos[key]; preemptorWithEQ {
+			// guaranteed is the closest quota, among the preemptor's one and its ancestors, whose min isn't exceeded
+			// with the preemptor. Its min can be reclaimed from the quotas borrowing from it.
+			guaranteed := elasticQuotaInfos.guaranteedAncestorWith(key, &preFilterState.nominatedPodsReqInEQWithPodReq)
+			moreThanMinWithPreemptor := guaranteed == nil
 			for _, p := range nodeInfo.Pods {
 				// Checking terminating pods
 				if p.Pod.DeletionTimestamp != nil {
-					eqInfo, withEQ := elasticQuotaSnapshotState.elasticQuotaInfos[p.Pod.Namespace]
-					if !withEQ {
+					pKey := keyOf(p.Pod)
+					if _, withEQ := elasticQuotaInfos.infos[pKey]; !withEQ {
 						continue
 					}
-					if p.Pod.Namespace == pod.Namespace && corev1helpers.PodPriority(p.Pod) < podPriority {
+					if pKey == key && corev1helpers.PodPriority(p.Pod) < podPriority {
 						// There is a terminating pod on the nominated node.
-						// If the terminating pod is in the same namespace with preemptor
+						// If the terminating pod is subject to the same quota as the preemptor
 						// and it is less important than preemptor,
 						// return false to avoid preempting more pods.
 						return false, "not eligible due to a terminating pod on the nominated node."
-					} else if p.Pod.Namespace != pod.Namespace && !moreThanMinWithPreemptor && eqInfo.usedOverMin() {
+					} else if pKey != key && !moreThanMinWithPreemptor && elasticQuotaInfos.borrowsFrom(guaranteed, pKey) {
 						// There is a terminating pod on the nominated node.
-						// The terminating pod isn't in the same namespace with preemptor.
-						// If moreThanMinWithPreemptor is false, it indicates that preemptor can preempt the pods in other EQs whose used is over min.
-						// And if the used of terminating pod's quota is over min, so the room released by terminating pod on the nominated node can be used by the preemptor.
+						// The terminating pod isn't subject to the same quota as the preemptor.
+						// If moreThanMinWithPreemptor is false, it indicates that preemptor can preempt the pods in other EQs borrowing from its guaranteed quota.
+						// And if the terminating pod's quota borrows from it, so the room released by terminating pod on the nominated node can be used by the preemptor.
 						// return false to avoid preempting more pods.
 						return false, "not eligible due to a terminating pod on the nominated node."
 					}
@@ -468,7 +760,7 @@ func (p *preemptor) PodEligibleToPreemptOthers(ctx context.Context, pod *v1.Pod,
 			}
 		} else {
 			for _, p := range nodeInfo.Pods {
-				_, withEQ := elasticQuotaSnapshotState.elasticQuotaInfos[p.Pod.Namespace]
+				_, withEQ := elasticQuotaInfos.infos[keyOf(p.Pod)]
 				if withEQ {
 					continue
 				}
@@ -529,8 +821,19 @@ func (p *preemptor) SelectVictimsOnNode(
 	}
 
 	elasticQuotaInfos := elasticQuotaSnapshotState.elasticQuotaInfos
+	keys := make(map[*v1.Pod]string)
+	keyOf := func(pod *v1.Pod) string {
+		if key, ok := keys[pod]; ok {
+			return key
+		}
+		key := elasticQuotaInfos.keyOf(pod, getNamespaceLabels(p.namespaceLister, pod.Namespace))
+		keys[pod] = key
+		return key
+	}
 	podPriority := corev1helpers.PodPriority(pod)
-	preemptorElasticQuotaInfo, preemptorWithElasticQuota := elasticQuotaInfos[pod.Namespace]
+	key := keyOf(pod)
+	_, preemptorWithElasticQuota := elasticQuotaInfos.infos[key]
+	var guaranteed *ElasticQuotaInfo
 
 	// sort the pods in node by the priority class
 	sort.Slice(nodeInfo.Pods, func(i, j int) bool { return !schedutil.MoreImportantPod(nodeInfo.Pods[i].Pod, nodeInfo.Pods[j].Pod) })
@@ -539,10 +842,13 @@ func (p *preemptor) SelectVictimsOnNode(
 	if preemptorWithElasticQuota {
 		nominatedPodsReqInEQWithPodReq = preFilterState.nominatedPodsReqInEQWithPodReq
 		nominatedPodsReqWithPodReq = preFilterState.nominatedPodsReqWithPodReq
-		moreThanMinWithPreemptor := preemptorElasticQuotaInfo.usedOverMinWith(&nominatedPodsReqInEQWithPodReq)
+		// guaranteed is the closest quota, among the preemptor's one and its ancestors, whose min isn't exceeded
+		// with the preemptor. Its min can be reclaimed from the quotas borrowing from it.
+		guaranteed = elasticQuotaInfos.guaranteedAncestorWith(key, &nominatedPodsReqInEQWithPodReq)
+		moreThanMinWithPreemptor := guaranteed == nil
 		for _, p := range nodeInfo.Pods {
-			eqInfo, withEQ := elasticQuotaInfos[p.Pod.Namespace]
-			if !withEQ {
+			pKey := keyOf(p.Pod)
+			if _, withEQ := elasticQuotaInfos.infos[pKey]; !withEQ {
 				continue
 			}
 
@@ -552,7 +858,7 @@ func (p *preemptor) SelectVictimsOnNode(
 				// quotas. So that we will select the pods which subject to the
 				// same quota(namespace) with the lower priority than the
 				// preemptor's priority as potential victims in a node.
-				if p.Pod.Namespace == pod.Namespace && corev1helpers.PodPriority(p.Pod) < podPriority {
+				if pKey == key && corev1helpers.PodPriority(p.Pod) < podPriority {
 					potentialVictims = append(potentialVictims, p)
 					if err := removePod(p); err != nil {
 						return nil, 0, framework.AsStatus(err)
@@ -560,13 +866,13 @@ func (p *preemptor) SelectVictimsOnNode(
 				}
 
 			} else {
-				// If Preemptor.Request + Quota.allocated <= Quota.min: It
-				// means that its min(guaranteed) resource is used or
-				// `borrowed` by other Quota. Potential victims in a node
-				// will be chosen from Quotas that allocates more resources
-				// than its min, i.e., borrowing resources from other
-				// Quotas.
-				if p.Pod.Namespace != pod.Namespace && eqInfo.usedOverMin() {
+				// If Preemptor.Request + Quota.allocated <= Quota.min, for its
+				// quota or one of its ancestors: It means that its min(guaranteed)
+				// resource is used or `borrowed` by other Quota. Potential victims
+				// in a node will be chosen from Quotas that allocates more resources
+				// than their min below the closest common ancestor, i.e., borrowing
+				// resources from the guaranteed quota.
+				if pKey != key && elasticQuotaInfos.borrowsFrom(guaranteed, pKey) {
 					potentialVictims = append(potentialVictims, p)
 					if err := removePod(p); err != nil {
 						return nil, 0, framework.AsStatus(err)
@@ -576,7 +882,7 @@ func (p *preemptor) SelectVictimsOnNode(
 		}
 	} else {
 		for _, p := range nodeInfo.Pods {
-			_, withEQ := elasticQuotaInfos[p.Pod.Namespace]
+			_, withEQ := elasticQuotaInfos.infos[keyOf(p.Pod)]
 			if withEQ {
 				continue
 			}
@@ -605,11 +911,11 @@ func (p *preemptor) SelectVictimsOnNode(
 		return nil, 0, s
 	}
 
-	// If the quota.used + pod.request > quota.max or sum(quotas.used) + pod.request > sum(quotas.min)
-	// after removing all the lower priority pods,
+	// If the quota.used + pod.request > quota.max, for the quota or one of its ancestors,
+	// or sum(quotas.used) + pod.request > sum(root quotas.min) after removing all the lower priority pods,
 	// we are almost done and this node is not suitable for preemption.
 	if preemptorWithElasticQuota {
-		if preemptorElasticQuotaInfo.usedOverMaxWith(&podReq) ||
+		if elasticQuotaInfos.ancestorOverMaxWith(key, &podReq) != nil ||
 			elasticQuotaInfos.aggregatedUsedOverMinWith(podReq) {
 			return nil, 0, framework.NewStatus(framework.Unschedulable, "global quota max exceeded")
 		}
@@ -618,8 +924,34 @@ func (p *preemptor) SelectVictimsOnNode(
 	var victims []*v1.Pod
 	numViolatingVictim := 0
 	// Sort potentialVictims by pod priority from high to low, which ensures to
-	// reprieve higher priority pods first.
+	// reprieve higher priority pods first. When reclaiming the min of a quota, the
+	// pods of its farther relatives are reprieved first, so that its min is reclaimed
+	// from its siblings before its cousins. Then the pods of the borrowers with the
+	// lowest weighted dominant share are reprieved first, so that the min is reclaimed
+	// from the borrowers exceeding their fair share the most.
+	shares := make(map[string]float64)
+	lendable := elasticQuotaInfos.lendable()
+	weightedDominantShare := func(key string) float64 {
+		if share, ok := shares[key]; ok {
+			return share
+		}
+		eqInfo := elasticQuotaInfos.infos[key]
+		share := eqInfo.dominantShareWith(lendable, nil) / float64(eqInfo.weight())
+		shares[key] = share
+		return share
+	}
 	sort.Slice(potentialVictims, func(i, j int) bool {
+		if guaranteed != nil {
+			ki, kj := keyOf(potentialVictims[i].Pod), keyOf(potentialVictims[j].Pod)
+			di := elasticQuotaInfos.reclaimDistance(guaranteed, ki)
+			dj := elasticQuotaInfos.reclaimDistance(guaranteed, kj)
+			if di != dj {
+				return di > dj
+			}
+			if si, sj := weightedDominantShare(ki), weightedDominantShare(kj); si != sj {
+				return si < sj
+			}
+		}
 		return schedutil.MoreImportantPod(potentialVictims[i].Pod, potentialVictims[j].Pod)
 	})
 	// Try to reprieve as many pods as possible. We first try to reprieve the PDB
@@ -640,7 +972,7 @@ func (p *preemptor) SelectVictimsOnNode(
 			logger.V(5).Info("Found a potential preemption victim on node", "pod", klog.KObj(pi.Pod), "node", klog.KObj(nodeInfo.Node()))
 		}
 
-		if preemptorWithElasticQuota && (preemptorElasticQuotaInfo.usedOverMaxWith(&nominatedPodsReqInEQWithPodReq) || elasticQuotaInfos.aggregatedUsedOverMinWith(nominatedPodsReqWithPodReq)) {
+		if preemptorWithElasticQuota && (elasticQuotaInfos.ancestorOverMaxWith(key, &nominatedPodsReqInEQWithPodReq) != nil || elasticQuotaInfos.aggregatedUsedOverMinWith(nominatedPodsReqWithPodReq)) {
 			if err := removePod(pi); err != nil {
 				return false, err
 			}
@@ -675,39 +1007,82 @@ func (p *preemptor) SelectVictimsOnNode(
 
 func (c *CapacityScheduling) addElasticQuota(obj interface{}) {
 	eq := obj.(*v1alpha1.ElasticQuota)
-	oldElasticQuotaInfo := c.elasticQuotaInfos[eq.Namespace]
+	key := elasticQuotaKey(eq)
+	oldElasticQuotaInfo := c.elasticQuotaInfos.infos[key]
 	if oldElasticQuotaInfo != nil {
 		return
 	}
 
-	elasticQuotaInfo := newElasticQuotaInfo(eq.Namespace, eq.Spec.Min, eq.Spec.Max, nil)
+	elasticQuotaInfo := c.elasticQuotaInfoOf(eq)
 
 	c.Lock()
-	defer c.Unlock()
-	c.elasticQuotaInfos[eq.Namespace] = elasticQuotaInfo
+	c.elasticQuotaInfos.set(key, elasticQuotaInfo)
+	c.elasticQuotasVersion++
+	c.Unlock()
+	if util.IsElasticQuotaScoped(eq) {
+		c.reassignPods(c.namespacesIn(elasticQuotaInfo.scope()))
+	}
 }
 
 func (c *CapacityScheduling) updateElasticQuota(oldObj, newObj interface{}) {
 	oldEQ := oldObj.(*v1alpha1.ElasticQuota)
 	newEQ := newObj.(*v1alpha1.ElasticQuota)
-	newEQInfo := newElasticQuotaInfo(newEQ.Namespace, newEQ.Spec.Min, newEQ.Spec.Max, nil)
+	newEQInfo := c.elasticQuotaInfoOf(newEQ)
 
 	c.Lock()
-	defer c.Unlock()
-
-	oldEQInfo := c.elasticQuotaInfos[oldEQ.Namespace]
+	oldKey, newKey := elasticQuotaKey(oldEQ), elasticQuotaKey(newEQ)
+	oldEQInfo := c.elasticQuotaInfos.infos[oldKey]
 	if oldEQInfo != nil {
 		newEQInfo.pods = oldEQInfo.pods
 		newEQInfo.Used = oldEQInfo.Used
+		newEQInfo.blockedPods = oldEQInfo.blockedPods
+	}
+	c.elasticQuotaInfos.remove(oldKey)
+	c.elasticQuotaInfos.set(newKey, newEQInfo)
+	c.elasticQuotasVersion++
+	c.Unlock()
+	if util.IsElasticQuotaScoped(oldEQ) || util.IsElasticQuotaScoped(newEQ) {
+		// The pods selected before the update are tracked by the updated ElasticQuotaInfo.
+		namespaces := c.namespacesIn(newEQInfo.scope())
+		if oldEQInfo != nil {
+			namespaces = namespaces.Union(c.namespacesIn(oldEQInfo.scope()))
+		}
+		c.reassignPods(namespaces)
 	}
-	c.elasticQuotaInfos[newEQ.Namespace] = newEQInfo
 }
 
 func (c *CapacityScheduling) deleteElasticQuota(obj interface{}) {
 	elasticQuota := obj.(*v1alpha1.ElasticQuota)
 	c.Lock()
-	defer c.Unlock()
-	delete(c.elasticQuotaInfos, elasticQuota.Namespace)
+	key := elasticQuotaKey(elasticQuota)
+	elasticQuotaInfo := c.elasticQuotaInfos.infos[key]
+	c.elasticQuotaInfos.remove(key)
+	c.elasticQuotasVersion++
+	c.Unlock()
+	if util.IsElasticQuotaScoped(elasticQuota) && elasticQuotaInfo != nil {
+		c.reassignPods(c.namespacesIn(elasticQuotaInfo.scope()))
+	}
+}
+
+func (c *CapacityScheduling) updateNamespace(oldObj, newObj interface{}) {
+	oldNamespace := oldObj.(*v1.Namespace)
+	newNamespace := newObj.(*v1.Namespace)
+	if labels.Equals(oldNamespace.Labels, newNamespace.Labels) {
+		return
+	}
+
+	c.RLock()
+	selected := false
+	for _, elasticQuotaInfo := range c.elasticQuotaInfos.infos {
+		if elasticQuotaInfo.Scope != nil && elasticQuotaInfo.Scope.NamespaceSelector != nil {
+			selected = true
+			break
+		}
+	}
+	c.RUnlock()
+	if selected {
+		c.reassignPods(sets.New(newNamespace.Name))
+	}
 }
 
 func (c *CapacityScheduling) addPod(obj interface{}) {
@@ -715,16 +1090,18 @@ func (c *CapacityScheduling) addPod(obj interface{}) {
 	logger := klog.FromContext(ctx)
 
 	pod := obj.(*v1.Pod)
+	// An assigned pod is no longer blocked by quota.
+	c.setBlockedPod(pod, "", false)
 
 	c.Lock()
 	defer c.Unlock()
 
-	elasticQuotaInfo := c.elasticQuotaInfos[pod.Namespace]
+	elasticQuotaInfo := c.elasticQuotaInfos.infos[c.elasticQuotaKeyOf(pod)]
 	// If elasticQuotaInfo is nil, try to list ElasticQuotas through elasticQuotaLister
 	if elasticQuotaInfo == nil {
 		var eqList v1alpha1.ElasticQuotaList
-		if err := c.client.List(ctx, &eqList, client.InNamespace(pod.Namespace)); err != nil {
-			logger.Error(err, "Failed to get elasticQuota", "elasticQuota", pod.Namespace)
+		if err := c.client.List(ctx, &eqList); err != nil {
+			logger.Error(err, "Failed to list elasticQuotas", "pod", klog.KObj(pod))
 			return
 		}
 
@@ -734,11 +1111,15 @@ func (c *CapacityScheduling) addPod(obj interface{}) {
 			return
 		}
 
-		if len(eqs) > 0 {
-			// only one elasticquota is supported in each namespace
-			eq := eqs[0]
-			elasticQuotaInfo = newElasticQuotaInfo(eq.Namespace, eq.Spec.Min, eq.Spec.Max, nil)
-			c.elasticQuotaInfos[eq.Namespace] = elasticQuotaInfo
+		for i := range eqs {
+			if key := elasticQuotaKey(&eqs[i]); c.elasticQuotaInfos.infos[key] == nil {
+				c.elasticQuotaInfos.set(key, c.elasticQuotaInfoOf(&eqs[i]))
+				c.elasticQuotasVersion++
+			}
+		}
+		elasticQuotaInfo = c.elasticQuotaInfos.infos[c.elasticQuotaKeyOf(pod)]
+		if elasticQuotaInfo == nil {
+			return
 		}
 	}
 
@@ -762,13 +1143,24 @@ func (c *CapacityScheduling) updatePod(oldObj, newObj interface{}) {
 		c.Lock()
 		defer c.Unlock()
 
-		elasticQuotaInfo := c.elasticQuotaInfos[newPod.Namespace]
+		elasticQuotaInfo := c.elasticQuotaInfos.infos[c.elasticQuotaKeyOf(newPod)]
 		if elasticQuotaInfo != nil {
 			err := elasticQuotaInfo.deletePodIfPresent(newPod)
 			if err != nil {
 				logger.Error(err, "Failed to delete Pod from its associated elasticQuota", "pod", klog.KObj(newPod))
 			}
 		}
+		return
+	}
+
+	// The ElasticQuota of the pod may change with its labels.
+	if !labels.Equals(oldPod.Labels, newPod.Labels) {
+		c.Lock()
+		defer c.Unlock()
+
+		if err := c.reassignPod(newPod); err != nil {
+			logger.Error(err, "Failed to move Pod to its associated elasticQuota", "pod", klog.KObj(newPod))
+		}
 	}
 }
 
@@ -776,10 +1168,11 @@ func (c *CapacityScheduling) deletePod(obj interface{}) {
 	logger := klog.FromContext(context.TODO())
 
 	pod := obj.(*v1.Pod)
+	c.forgetBlockedByQuota(pod)
 	c.Lock()
 	defer c.Unlock()
 
-	elasticQuotaInfo := c.elasticQuotaInfos[pod.Namespace]
+	elasticQuotaInfo := c.elasticQuotaInfos.infos[c.elasticQuotaKeyOf(pod)]
 	if elasticQuotaInfo != nil {
 		err := elasticQuotaInfo.deletePodIfPresent(pod)
 		if err != nil {
@@ -788,6 +1181,184 @@ func (c *CapacityScheduling) deletePod(obj interface{}) {
 	}
 }
 
+func (c *CapacityScheduling) deletePendingPod(obj interface{}) {
+	var pod *v1.Pod
+	switch t := obj.(type) {
+	case *v1.Pod:
+		pod = t
+	case cache.DeletedFinalStateUnknown:
+		pod = t.Obj.(*v1.Pod)
+	default:
+		return
+	}
+	c.setBlockedPod(pod, "", false)
+	c.forgetBlockedByQuota(pod)
+}
+
+// reassignPods moves the pods of the given namespaces to the ElasticQuotaInfos they are subject to, after the
+// ElasticQuotas selecting them changed. The assigned pods which aren't subject to any ElasticQuotaInfo yet are added.
+// The new assignment is computed from a snapshot of the scopes without the lock, then applied with the lock held.
+func (c *CapacityScheduling) reassignPods(namespaces sets.Set[string]) {
+	if c.podLister == nil || namespaces.Len() == 0 {
+		return
+	}
+	var pods []*v1.Pod
+	for namespace := range namespaces {
+		namespacePods, err := c.podLister.Pods(namespace).List(labels.Everything())
+		if err != nil {
+			c.logger.Error(err, "Failed to list pods to move them to their associated elasticQuotas", "namespace", namespace)
+			return
+		}
+		for _, pod := range namespacePods {
+			if pod.Status.Phase != v1.PodSucceeded && pod.Status.Phase != v1.PodFailed {
+				pods = append(pods, pod)
+			}
+		}
+	}
+
+	c.RLock()
+	version := c.elasticQuotasVersion
+	scopes := &ElasticQuotaInfos{
+		infos:      make(map[string]*ElasticQuotaInfo, len(c.elasticQuotaInfos.infos)),
+		scopedKeys: c.elasticQuotaInfos.scopedKeys,
+		childKeys:  c.elasticQuotaInfos.childKeys,
+	}
+	for key, elasticQuotaInfo := range c.elasticQuotaInfos.infos {
+		scopes.infos[key] = &ElasticQuotaInfo{Namespace: elasticQuotaInfo.Namespace, Scope: elasticQuotaInfo.Scope}
+	}
+	c.RUnlock()
+	keys := make([]string, len(pods))
+	for i, pod := range pods {
+		keys[i] = scopes.keyOf(pod, getNamespaceLabels(c.namespaceLister, pod.Namespace))
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	for i, pod := range pods {
+		// The pods deleted meanwhile are left to deletePod, and the ones updated meanwhile are reassigned as they are
+		// now, as well as all of them if the ElasticQuotas changed meanwhile.
+		current, err := c.podLister.Pods(pod.Namespace).Get(pod.Name)
+		if err != nil || current.UID != pod.UID {
+			continue
+		}
+		key := keys[i]
+		if current != pod || c.elasticQuotasVersion != version {
+			key = c.elasticQuotaKeyOf(current)
+		}
+		if err := c.reassignPodTo(current, key); err != nil {
+			c.logger.Error(err, "Failed to move Pod to its associated elasticQuota", "pod", klog.KObj(current))
+		}
+	}
+}
+
+// reassignPod moves the given pod to the ElasticQuotaInfo it is subject to, if it is assigned or already
+// subject to another ElasticQuotaInfo. It must be called with the lock held.
+func (c *CapacityScheduling) reassignPod(pod *v1.Pod) error {
+	return c.reassignPodTo(pod, c.elasticQuotaKeyOf(pod))
+}
+
+// reassignPodTo moves the given pod to the ElasticQuotaInfo of the given key, if it is assigned or already
+// subject to another ElasticQuotaInfo. It must be called with the lock held.
+func (c *CapacityScheduling) reassignPodTo(pod *v1.Pod, key string) error {
+	podKey, err := framework.GetPodKey(pod)
+	if err != nil {
+		return err
+	}
+	if elasticQuotaInfo := c.elasticQuotaInfos.infos[key]; elasticQuotaInfo != nil && elasticQuotaInfo.pods.Has(podKey) {
+		return nil
+	}
+	tracked := false
+	for k, elasticQuotaInfo := range c.elasticQuotaInfos.infos {
+		if !elasticQuotaInfo.pods.Has(podKey) {
+			continue
+		}
+		tracked = true
+		if k != key {
+			if err := elasticQuotaInfo.deletePodIfPresent(pod); err != nil {
+				return err
+			}
+		}
+	}
+	if elasticQuotaInfo := c.elasticQuotaInfos.infos[key]; elasticQuotaInfo != nil && (tracked || assignedPod(pod)) {
+		return elasticQuotaInfo.addPodIfNotPresent(pod)
+	}
+	return nil
+}
+
+// namespacesIn returns the namespaces of the pods in the given scope.
+func (c *CapacityScheduling) namespacesIn(scope *util.ElasticQuotaScope) sets.Set[string] {
+	if scope.NamespaceSelector == nil {
+		return sets.New(scope.Namespace)
+	}
+	namespaces := sets.New[string]()
+	if c.namespaceLister == nil {
+		return namespaces
+	}
+	namespaceList, err := c.namespaceLister.List(scope.NamespaceSelector)
+	if err != nil {
+		c.logger.Error(err, "Failed to list the namespaces selected by elasticQuota", "elasticQuota", scope.NamespacedName)
+		return namespaces
+	}
+	for _, namespace := range namespaceList {
+		namespaces.Insert(namespace.Name)
+	}
+	return namespaces
+}
+
+// elasticQuotaInfoOf returns the ElasticQuotaInfo of the given ElasticQuota. An ElasticQuota with an invalid
+// selector selects no pods.
+func (c *CapacityScheduling) elasticQuotaInfoOf(eq *v1alpha1.ElasticQuota) *ElasticQuotaInfo {
+	elasticQuotaInfo := newElasticQuotaInfo(eq.Namespace, parentKey(eq), int64(ptr.Deref(eq.Spec.Weight, 0)), eq.Spec.Min, eq.Spec.Max, nil)
+	elasticQuotaInfo.ReclaimGracePeriod = time.Duration(ptr.Deref(eq.Spec.ReclaimGracePeriodSeconds, 0)) * time.Second
+	if util.IsElasticQuotaScoped(eq) {
+		scope, err := util.NewElasticQuotaScope(eq)
+		if err != nil {
+			c.logger.Error(err, "Invalid elasticQuota selector", "elasticQuota", klog.KObj(eq))
+			scope = &util.ElasticQuotaScope{
+				NamespacedName:    types.NamespacedName{Namespace: eq.Namespace, Name: eq.Name},
+				NamespaceSelector: labels.Nothing(),
+				PodSelector:       labels.Nothing(),
+			}
+		}
+		elasticQuotaInfo.Scope = scope
+	}
+	return elasticQuotaInfo
+}
+
+// elasticQuotaKeyOf returns the key of the ElasticQuotaInfo the given pod is subject to.
+func (c *CapacityScheduling) elasticQuotaKeyOf(pod *v1.Pod) string {
+	return c.elasticQuotaInfos.keyOf(pod, getNamespaceLabels(c.namespaceLister, pod.Namespace))
+}
+
+// elasticQuotaKey returns the key of the given ElasticQuota in ElasticQuotaInfos: its namespace if it applies to all
+// the pods of its namespace, its namespace and name if it has selectors.
+func elasticQuotaKey(eq *v1alpha1.ElasticQuota) string {
+	if util.IsElasticQuotaScoped(eq) {
+		return types.NamespacedName{Namespace: eq.Namespace, Name: eq.Name}.String()
+	}
+	return eq.Namespace
+}
+
+// parentKey returns the namespace and name of the parent of the given ElasticQuota, empty for a root ElasticQuota.
+func parentKey(eq *v1alpha1.ElasticQuota) string {
+	if parent, ok := util.GetElasticQuotaParent(eq); ok {
+		return parent.String()
+	}
+	return ""
+}
+
+// getNamespaceLabels returns the labels of the given namespace, nil if the namespace is unknown.
+func getNamespaceLabels(namespaceLister corelisters.NamespaceLister, namespace string) labels.Set {
+	if namespaceLister == nil {
+		return nil
+	}
+	ns, err := namespaceLister.Get(namespace)
+	if err != nil {
+		return nil
+	}
+	return ns.Labels
+}
+
 // getElasticQuotasSnapshot will return the snapshot of elasticQuotas.
 func (c *CapacityScheduling) snapshotElasticQuota() *ElasticQuotaSnapshotState {
 	c.RLock()
@@ -21,8 +21,10 @@ import (
 	"reflect"
 	"sort"
 	"testing"
+	"time"
 
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 
 	gocmp "github.com/google/go-cmp/cmp"
 	v1 "k8s.io/api/core/v1"
@@ -30,6 +32,8 @@ import (
 	apiruntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/informers"
 	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/events"
 	extenderv1 "k8s.io/kube-scheduler/extender/v1"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
@@ -129,6 +133,165 @@ func TestPreFilter(t *testing.T) {
 				framework.Unschedulable,
 			},
 		},
+		{
+			name: "the parent ElasticQuota is more than max",
+			podInfos: []podInfo{
+				{podName: "ns1-p1", podNamespace: "ns1", memReq: 100},
+				{podName: "ns1-p2", podNamespace: "ns1", memReq: 500},
+			},
+			elasticQuotas: map[string]*ElasticQuotaInfo{
+				"dept": {
+					Namespace: "dept",
+					Min: &framework.Resource{
+						Memory: 2000,
+					},
+					Max: &framework.Resource{
+						Memory: 1000,
+					},
+					Used: &framework.Resource{},
+				},
+				"ns1": {
+					Namespace: "ns1",
+					Parent:    "dept",
+					Min: &framework.Resource{
+						Memory: 100,
+					},
+					Max: &framework.Resource{
+						Memory: 2000,
+					},
+					Used: &framework.Resource{
+						Memory: 800,
+					},
+				},
+			},
+			expected: []framework.Code{
+				framework.Success,
+				framework.Unschedulable,
+			},
+		},
+		{
+			name: "the sum of used is bigger than the sum of root min",
+			podInfos: []podInfo{
+				{podName: "ns2-p1", podNamespace: "ns2", memReq: 500},
+			},
+			elasticQuotas: map[string]*ElasticQuotaInfo{
+				"dept": {
+					Namespace: "dept",
+					Min: &framework.Resource{
+						Memory: 1000,
+					},
+					Max: &framework.Resource{
+						Memory: 2000,
+					},
+					Used: &framework.Resource{},
+				},
+				"ns1": {
+					Namespace: "ns1",
+					Parent:    "dept",
+					Min: &framework.Resource{
+						Memory: 1000,
+					},
+					Max: &framework.Resource{
+						Memory: 2000,
+					},
+					Used: &framework.Resource{
+						Memory: 800,
+					},
+				},
+				"ns2": {
+					Namespace: "ns2",
+					Parent:    "dept",
+					Min: &framework.Resource{
+						Memory: 1000,
+					},
+					Max: &framework.Resource{
+						Memory: 2000,
+					},
+					Used: &framework.Resource{},
+				},
+			},
+			expected: []framework.Code{
+				framework.Unschedulable,
+			},
+		},
+		{
+			name: "ElasticQuota borrowing more than its fair share",
+			podInfos: []podInfo{
+				{podName: "ns1-p1", podNamespace: "ns1", memReq: 400},
+				{podName: "ns1-p2", podNamespace: "ns1", memReq: 600},
+			},
+			elasticQuotas: map[string]*ElasticQuotaInfo{
+				"ns1": {
+					Namespace: "ns1",
+					Min: &framework.Resource{
+						Memory: 1000,
+					},
+					Used: &framework.Resource{
+						Memory: 1000,
+					},
+				},
+				"ns2": {
+					Namespace: "ns2",
+					Min: &framework.Resource{
+						Memory: 1000,
+					},
+					Used: &framework.Resource{
+						Memory: 1500,
+					},
+				},
+				"ns3": {
+					Namespace: "ns3",
+					Min: &framework.Resource{
+						Memory: 1000,
+					},
+					Used:        &framework.Resource{},
+					blockedPods: sets.New("ns3/p1"),
+				},
+			},
+			expected: []framework.Code{
+				framework.Success,
+				framework.Unschedulable,
+			},
+		},
+		{
+			name: "ElasticQuota borrowing more than its fair share without contention",
+			// ns1 borrows 1200 of the 2000 lendable memory, more than its fair share, while no pod is blocked by quota.
+			podInfos: []podInfo{
+				{podName: "ns1-p1", podNamespace: "ns1", memReq: 400},
+				{podName: "ns1-p2", podNamespace: "ns1", memReq: 1200},
+			},
+			elasticQuotas: map[string]*ElasticQuotaInfo{
+				"ns1": {
+					Namespace: "ns1",
+					Min: &framework.Resource{
+						Memory: 1000,
+					},
+					Used: &framework.Resource{
+						Memory: 1000,
+					},
+				},
+				"ns2": {
+					Namespace: "ns2",
+					Min: &framework.Resource{
+						Memory: 1000,
+					},
+					Used: &framework.Resource{
+						Memory: 1500,
+					},
+				},
+				"ns3": {
+					Namespace: "ns3",
+					Min: &framework.Resource{
+						Memory: 2000,
+					},
+					Used: &framework.Resource{},
+				},
+			},
+			expected: []framework.Code{
+				framework.Success,
+				framework.Success,
+			},
+		},
 		{
 			name: "without elasticQuotaInfo",
 			podInfos: []podInfo{
@@ -154,6 +317,7 @@ func TestPreFilter(t *testing.T) {
 
 			fwk, err := tf.NewFramework(
 				ctx, registeredPlugins, "",
+				frameworkruntime.WithClientSet(clientsetfake.NewSimpleClientset()),
 				frameworkruntime.WithPodNominator(testutil.NewPodNominator(nil)),
 				frameworkruntime.WithSnapshotSharedLister(testutil.NewFakeSharedLister(make([]*v1.Pod, 0), make([]*v1.Node, 0))),
 			)
@@ -163,7 +327,7 @@ func TestPreFilter(t *testing.T) {
 			}
 
 			cs := &CapacityScheduling{
-				elasticQuotaInfos: tt.elasticQuotas,
+				elasticQuotaInfos: newElasticQuotaInfosFrom(tt.elasticQuotas),
 				fh:                fwk,
 			}
 
@@ -183,6 +347,75 @@ func TestPreFilter(t *testing.T) {
 	}
 }
 
+func TestPreFilterMarksBlockedByQuota(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	admitted := makePod("ns1-p1", "ns1", 500, 0, 0, 0, "ns1-p1", "")
+	rejected := makePod("ns1-p2", "ns1", 1800, 0, 0, 0, "ns1-p2", "")
+	unblocked := makePod("ns1-p3", "ns1", 100, 0, 0, 0, "ns1-p3", "")
+	unblocked.Annotations = map[string]string{v1alpha1.BlockedByQuotaAnnotation: blockedByQuotaReasonOverMax}
+	cs := clientsetfake.NewSimpleClientset(admitted, rejected, unblocked)
+
+	fwk, err := tf.NewFramework(
+		ctx, []tf.RegisterPluginFunc{
+			tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+			tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		}, "",
+		frameworkruntime.WithClientSet(cs),
+		frameworkruntime.WithPodNominator(testutil.NewPodNominator(nil)),
+		frameworkruntime.WithSnapshotSharedLister(testutil.NewFakeSharedLister(make([]*v1.Pod, 0), make([]*v1.Node, 0))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &CapacityScheduling{
+		elasticQuotaInfos: newElasticQuotaInfosFrom(map[string]*ElasticQuotaInfo{
+			"ns1": {
+				Namespace: "ns1",
+				Min:       &framework.Resource{Memory: 1000},
+				Max:       &framework.Resource{Memory: 2000},
+				Used:      &framework.Resource{Memory: 300},
+			},
+		}),
+		fh: fwk,
+	}
+	c.startBlockedByQuotaWorker(ctx)
+
+	want := map[*v1.Pod]string{
+		admitted:  "",
+		rejected:  blockedByQuotaReasonOverMax,
+		unblocked: "",
+	}
+	// The queued pods stay stale across the cycles, which must not patch them again.
+	for i := 0; i < 3; i++ {
+		for pod := range want {
+			c.PreFilter(ctx, framework.NewCycleState(), pod)
+		}
+	}
+	for pod, reason := range want {
+		if err := wait.PollUntilContextTimeout(ctx, 10*time.Millisecond, time.Second, true, func(ctx context.Context) (bool, error) {
+			got, err := cs.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			return got.Annotations[v1alpha1.BlockedByQuotaAnnotation] == reason, nil
+		}); err != nil {
+			t.Errorf("pod %v: want blocked by quota reason %q: %v", pod.Name, reason, err)
+		}
+	}
+	var patched []string
+	for _, action := range cs.Actions() {
+		if patch, ok := action.(k8stesting.PatchAction); ok {
+			patched = append(patched, patch.GetName())
+		}
+	}
+	sort.Strings(patched)
+	if diff := gocmp.Diff([]string{rejected.Name, unblocked.Name}, patched); diff != "" {
+		t.Errorf("Unexpected patched pods (-want, +got): %s", diff)
+	}
+}
+
 func TestPostFilter(t *testing.T) {
 	res := map[v1.ResourceName]string{v1.ResourceMemory: "150"}
 	tests := []struct {
@@ -339,7 +572,7 @@ func TestPostFilter(t *testing.T) {
 
 			podReq := computePodResourceRequest(tt.pod)
 			elasticQuotaSnapshotState := &ElasticQuotaSnapshotState{
-				elasticQuotaInfos: tt.elasticQuotas,
+				elasticQuotaInfos: newElasticQuotaInfosFrom(tt.elasticQuotas),
 			}
 			prefilterState := &PreFilterState{
 				podReq:                         *podReq,
@@ -350,7 +583,7 @@ func TestPostFilter(t *testing.T) {
 			state.Write(ElasticQuotaSnapshotKey, elasticQuotaSnapshotState)
 
 			c := &CapacityScheduling{
-				elasticQuotaInfos: tt.elasticQuotas,
+				elasticQuotaInfos: newElasticQuotaInfosFrom(tt.elasticQuotas),
 				fh:                fwk,
 				podLister:         informerFactory.Core().V1().Pods().Lister(),
 				pdbLister:         getPDBLister(informerFactory),
@@ -366,6 +599,117 @@ func TestPostFilter(t *testing.T) {
 	}
 }
 
+func TestPreemptPodAfterGracePeriod(t *testing.T) {
+	now := time.Now()
+	elasticQuotas := map[string]*ElasticQuotaInfo{
+		"ns1": {Namespace: "ns1"},
+		"ns2": {Namespace: "ns2", ReclaimGracePeriod: time.Minute},
+		"ns3": {Namespace: "ns3"},
+	}
+	withDeadline := func(pod *v1.Pod, deadline time.Time) *v1.Pod {
+		pod.Annotations = map[string]string{v1alpha1.ReclaimDeadlineAnnotation: deadline.Format(time.RFC3339)}
+		return pod
+	}
+	tests := []struct {
+		name          string
+		victim        *v1.Pod
+		wantPreempted bool
+		wantNotified  bool
+	}{
+		{
+			name:          "victim subject to the same quota",
+			victim:        makePod("p2", "ns1", 50, 0, 0, midPriority, "p2", "node-a"),
+			wantPreempted: true,
+		},
+		{
+			name:          "victim quota without reclaim grace period",
+			victim:        makePod("p2", "ns3", 50, 0, 0, midPriority, "p2", "node-a"),
+			wantPreempted: true,
+		},
+		{
+			name:         "victim not notified yet",
+			victim:       makePod("p2", "ns2", 50, 0, 0, midPriority, "p2", "node-a"),
+			wantNotified: true,
+		},
+		{
+			name:   "reclaim deadline not passed",
+			victim: withDeadline(makePod("p2", "ns2", 50, 0, 0, midPriority, "p2", "node-a"), now.Add(30*time.Second)),
+		},
+		{
+			name:          "reclaim deadline passed",
+			victim:        withDeadline(makePod("p2", "ns2", 50, 0, 0, midPriority, "p2", "node-a"), now.Add(-time.Second)),
+			wantPreempted: true,
+		},
+		{
+			name:         "reclaim deadline left over from an earlier reclaim",
+			victim:       withDeadline(makePod("p2", "ns2", 50, 0, 0, midPriority, "p2", "node-a"), now.Add(-2*time.Minute)),
+			wantNotified: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			cs := clientsetfake.NewSimpleClientset(tt.victim)
+			recorder := &events.FakeRecorder{Events: make(chan string, 1)}
+			fwk, err := tf.NewFramework(
+				ctx,
+				makeRegisteredPlugin(),
+				"default-scheduler",
+				frameworkruntime.WithClientSet(cs),
+				frameworkruntime.WithEventRecorder(recorder),
+				frameworkruntime.WithWaitingPods(frameworkruntime.NewWaitingPodsMap()),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			state := framework.NewCycleState()
+			state.Write(ElasticQuotaSnapshotKey, &ElasticQuotaSnapshotState{elasticQuotaInfos: newElasticQuotaInfosFrom(elasticQuotas)})
+			c := &CapacityScheduling{
+				elasticQuotaInfos: newElasticQuotaInfosFrom(elasticQuotas),
+				fh:                fwk,
+			}
+
+			preempted := false
+			preemptPod := c.preemptPodAfterGracePeriod(state, func(context.Context, preemption.Candidate, *v1.Pod, *v1.Pod, string) error {
+				preempted = true
+				return nil
+			})
+			preemptor := makePod("p1", "ns1", 50, 0, 0, highPriority, "p1", "")
+			if err := preemptPod(ctx, nil, preemptor, tt.victim, Name); err != nil {
+				t.Fatal(err)
+			}
+			if preempted != tt.wantPreempted {
+				t.Errorf("Unexpected preemption, want %v, got %v", tt.wantPreempted, preempted)
+			}
+
+			victim, err := cs.CoreV1().Pods(tt.victim.Namespace).Get(ctx, tt.victim.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			deadline := victim.Annotations[v1alpha1.ReclaimDeadlineAnnotation]
+			if notified := deadline != tt.victim.Annotations[v1alpha1.ReclaimDeadlineAnnotation]; notified != tt.wantNotified {
+				t.Errorf("Unexpected notification, want %v, got %v", tt.wantNotified, notified)
+			}
+			if tt.wantNotified {
+				got, err := time.Parse(time.RFC3339, deadline)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want := now.Add(time.Minute); got.Before(want.Add(-time.Second)) || got.After(want.Add(time.Second)) {
+					t.Errorf("Unexpected reclaim deadline, want about %v, got %v", want, got)
+				}
+				if len(recorder.Events) != 1 {
+					t.Errorf("Expected an Event announcing the reclaim deadline")
+				}
+			}
+		})
+	}
+}
+
 func TestReserve(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -462,7 +806,7 @@ func TestReserve(t *testing.T) {
 			}
 
 			cs := &CapacityScheduling{
-				elasticQuotaInfos: tt.elasticQuotas,
+				elasticQuotaInfos: newElasticQuotaInfosFrom(tt.elasticQuotas),
 				fh:                fwk,
 			}
 
@@ -472,8 +816,8 @@ func TestReserve(t *testing.T) {
 				if got.Code() != tt.expectedCodes[i] {
 					t.Errorf("expected %v, got %v : %v", tt.expected[i], got.Code(), got.Message())
 				}
-				if !reflect.DeepEqual(cs.elasticQuotaInfos["ns1"], tt.expected[i]["ns1"]) {
-					t.Errorf("expected %v, got %v", tt.expected[i]["ns1"], cs.elasticQuotaInfos["ns1"])
+				if !reflect.DeepEqual(cs.elasticQuotaInfos.infos["ns1"], tt.expected[i]["ns1"]) {
+					t.Errorf("expected %v, got %v", tt.expected[i]["ns1"], cs.elasticQuotaInfos.infos["ns1"])
 				}
 			}
 		})
@@ -584,15 +928,15 @@ func TestUnreserve(t *testing.T) {
 			}
 
 			cs := &CapacityScheduling{
-				elasticQuotaInfos: tt.elasticQuotas,
+				elasticQuotaInfos: newElasticQuotaInfosFrom(tt.elasticQuotas),
 				fh:                fwk,
 			}
 
 			state := framework.NewCycleState()
 			for i, pod := range tt.pods {
 				cs.Unreserve(context.TODO(), state, pod, "node-a")
-				if !reflect.DeepEqual(cs.elasticQuotaInfos["ns1"], tt.expected[i]["ns1"]) {
-					t.Errorf("expected %#v, got %#v", tt.expected[i]["ns1"].Used, cs.elasticQuotaInfos["ns1"].Used)
+				if !reflect.DeepEqual(cs.elasticQuotaInfos.infos["ns1"], tt.expected[i]["ns1"]) {
+					t.Errorf("expected %#v, got %#v", tt.expected[i]["ns1"].Used, cs.elasticQuotaInfos.infos["ns1"].Used)
 				}
 			}
 		})
@@ -746,7 +1090,7 @@ func TestDryRunPreemption(t *testing.T) {
 
 			podReq := computePodResourceRequest(tt.pod)
 			elasticQuotaSnapshotState := &ElasticQuotaSnapshotState{
-				elasticQuotaInfos: tt.elasticQuotas,
+				elasticQuotaInfos: newElasticQuotaInfosFrom(tt.elasticQuotas),
 			}
 			prefilterState := &PreFilterState{
 				podReq:                         *podReq,
@@ -993,7 +1337,7 @@ func TestPodEligibleToPreemptOthers(t *testing.T) {
 
 			podReq := computePodResourceRequest(tt.pod)
 			elasticQuotaSnapshotState := &ElasticQuotaSnapshotState{
-				elasticQuotaInfos: tt.elasticQuotas,
+				elasticQuotaInfos: newElasticQuotaInfosFrom(tt.elasticQuotas),
 			}
 			prefilterState := &PreFilterState{
 				podReq:                         *podReq,
@@ -1146,7 +1490,7 @@ func TestAddElasticQuota(t *testing.T) {
 			}
 
 			cs := &CapacityScheduling{
-				elasticQuotaInfos: map[string]*ElasticQuotaInfo{},
+				elasticQuotaInfos: NewElasticQuotaInfos(),
 				fh:                fwk,
 			}
 
@@ -1155,7 +1499,7 @@ func TestAddElasticQuota(t *testing.T) {
 			}
 
 			for _, ns := range tt.ns {
-				if got := cs.elasticQuotaInfos[ns]; !reflect.DeepEqual(got, tt.expected[ns]) {
+				if got := cs.elasticQuotaInfos.infos[ns]; !reflect.DeepEqual(got, tt.expected[ns]) {
 					t.Errorf("expected %v, got %v", tt.expected[ns], got)
 				}
 			}
@@ -1219,14 +1563,14 @@ func TestUpdateElasticQuota(t *testing.T) {
 			}
 
 			cs := &CapacityScheduling{
-				elasticQuotaInfos: map[string]*ElasticQuotaInfo{},
+				elasticQuotaInfos: NewElasticQuotaInfos(),
 				fh:                fwk,
 			}
 			cs.addElasticQuota(tt.oldElasticQuota)
 			cs.updateElasticQuota(tt.oldElasticQuota, tt.newElasticQuota)
 
 			for _, ns := range tt.ns {
-				if got := cs.elasticQuotaInfos[ns]; !reflect.DeepEqual(got, tt.expected[ns]) {
+				if got := cs.elasticQuotaInfos.infos[ns]; !reflect.DeepEqual(got, tt.expected[ns]) {
 					t.Errorf("expected %v, got %v", tt.expected[ns], got)
 				}
 			}
@@ -1271,14 +1615,14 @@ func TestDeleteElasticQuota(t *testing.T) {
 			}
 
 			cs := &CapacityScheduling{
-				elasticQuotaInfos: map[string]*ElasticQuotaInfo{},
+				elasticQuotaInfos: NewElasticQuotaInfos(),
 				fh:                fwk,
 			}
 			cs.addElasticQuota(tt.elasticQuota)
 			cs.deleteElasticQuota(tt.elasticQuota)
 
 			for _, ns := range tt.ns {
-				if got := cs.elasticQuotaInfos[ns]; !reflect.DeepEqual(got, tt.expected[ns]) {
+				if got := cs.elasticQuotaInfos.infos[ns]; !reflect.DeepEqual(got, tt.expected[ns]) {
 					t.Errorf("expected %v, got %v", tt.expected[ns], got)
 				}
 			}
@@ -1349,7 +1693,7 @@ func TestAddPod(t *testing.T) {
 			}
 
 			cs := &CapacityScheduling{
-				elasticQuotaInfos: map[string]*ElasticQuotaInfo{},
+				elasticQuotaInfos: NewElasticQuotaInfos(),
 				fh:                fwk,
 			}
 			cs.addElasticQuota(tt.elasticQuota)
@@ -1357,7 +1701,7 @@ func TestAddPod(t *testing.T) {
 				cs.addPod(pod)
 			}
 			for _, ns := range tt.ns {
-				if got := cs.elasticQuotaInfos[ns]; !reflect.DeepEqual(got, tt.expected[ns]) {
+				if got := cs.elasticQuotaInfos.infos[ns]; !reflect.DeepEqual(got, tt.expected[ns]) {
 					t.Errorf("expected %v, got %v", tt.expected[ns], got)
 				}
 			}
@@ -1461,7 +1805,7 @@ func TestUpdatePod(t *testing.T) {
 			}
 
 			cs := &CapacityScheduling{
-				elasticQuotaInfos: map[string]*ElasticQuotaInfo{},
+				elasticQuotaInfos: NewElasticQuotaInfos(),
 				fh:                fwk,
 			}
 			cs.addElasticQuota(tt.elasticQuota)
@@ -1470,7 +1814,7 @@ func TestUpdatePod(t *testing.T) {
 				cs.updatePod(pods[0], pods[1])
 			}
 			for _, ns := range tt.ns {
-				if got := cs.elasticQuotaInfos[ns]; !reflect.DeepEqual(got, tt.expected[ns]) {
+				if got := cs.elasticQuotaInfos.infos[ns]; !reflect.DeepEqual(got, tt.expected[ns]) {
 					t.Errorf("expected %v, got %v", tt.expected[ns], got)
 				}
 			}
@@ -1578,7 +1922,7 @@ func TestDeletePod(t *testing.T) {
 			}
 
 			cs := &CapacityScheduling{
-				elasticQuotaInfos: map[string]*ElasticQuotaInfo{},
+				elasticQuotaInfos: NewElasticQuotaInfos(),
 				fh:                fwk,
 			}
 			cs.addElasticQuota(tt.elasticQuota)
@@ -1589,7 +1933,7 @@ func TestDeletePod(t *testing.T) {
 				cs.deletePod(deletepod)
 			}
 			for _, ns := range tt.ns {
-				if got := cs.elasticQuotaInfos[ns]; !reflect.DeepEqual(got, tt.expected[ns]) {
+				if got := cs.elasticQuotaInfos.infos[ns]; !reflect.DeepEqual(got, tt.expected[ns]) {
 					t.Errorf("expected %v, got %v", tt.expected[ns], got)
 				}
 			}
@@ -1603,6 +1947,145 @@ func makeUnschedulableNodeStatusReader() *framework.NodeToStatus {
 	return nodeStatusReader
 }
 
+func TestReassignPods(t *testing.T) {
+	informerFactory := informers.NewSharedInformerFactory(clientsetfake.NewSimpleClientset(), 0)
+	namespaceStore := informerFactory.Core().V1().Namespaces().Informer().GetStore()
+	podStore := informerFactory.Core().V1().Pods().Informer().GetStore()
+	ns2 := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns2", Labels: map[string]string{"org": "research"}}}
+	for _, ns := range []*v1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: "ns1"}}, ns2} {
+		if err := namespaceStore.Add(ns); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p1 := makePod("p1", "ns1", 10, 0, 0, midPriority, "p1", "node-a")
+	p1.Labels = map[string]string{"team": "a"}
+	p2 := makePod("p2", "ns2", 20, 0, 0, midPriority, "p2", "node-a")
+	for _, pod := range []*v1.Pod{p1, p2} {
+		if err := podStore.Add(pod); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cs := &CapacityScheduling{
+		elasticQuotaInfos: NewElasticQuotaInfos(),
+		podLister:         informerFactory.Core().V1().Pods().Lister(),
+		namespaceLister:   informerFactory.Core().V1().Namespaces().Lister(),
+	}
+	cs.addElasticQuota(makeEQ("ns1", "default", nil, nil))
+	// p2 isn't subject to any ElasticQuota yet.
+	cs.addPod(p1)
+	checkUsed := func(expected map[string]int64) {
+		t.Helper()
+		for key, memory := range expected {
+			if got := cs.elasticQuotaInfos.infos[key].Used.Memory; got != memory {
+				t.Errorf("expected ElasticQuota %v to use %v memory, got %v", key, memory, got)
+			}
+		}
+	}
+	checkUsed(map[string]int64{"ns1": 10})
+
+	// The pods selected by a new ElasticQuota move to it, or are added to it if untracked.
+	teamA := makeEQ("ns1", "team-a", nil, nil)
+	teamA.Spec.PodSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+	research := makeEQ("ns3", "research", nil, nil)
+	research.Spec.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"org": "research"}}
+	cs.addElasticQuota(teamA)
+	cs.addElasticQuota(research)
+	checkUsed(map[string]int64{"ns1": 0, "ns1/team-a": 10, "ns3/research": 20})
+
+	// The pods of a namespace no longer selected leave the ElasticQuota.
+	newNS2 := ns2.DeepCopy()
+	newNS2.Labels = nil
+	if err := namespaceStore.Update(newNS2); err != nil {
+		t.Fatal(err)
+	}
+	cs.updateNamespace(ns2, newNS2)
+	checkUsed(map[string]int64{"ns1": 0, "ns1/team-a": 10, "ns3/research": 0})
+
+	// The pods of a deleted ElasticQuota move back to the ElasticQuota of their namespace.
+	cs.deleteElasticQuota(teamA)
+	checkUsed(map[string]int64{"ns1": 10, "ns3/research": 0})
+}
+
+func TestBlockedPods(t *testing.T) {
+	c := &CapacityScheduling{
+		elasticQuotaInfos: newElasticQuotaInfosFrom(map[string]*ElasticQuotaInfo{
+			"ns1": {Namespace: "ns1"},
+			"ns2": {Namespace: "ns2"},
+		}),
+	}
+	pod := makePod("p1", "ns1", 100, 0, 0, 0, "p1", "")
+
+	// A pod blocked in ns1 contends for the lendable resources with the other ElasticQuotas.
+	c.setBlockedPod(pod, "ns1", true)
+	if !c.elasticQuotaInfos.contendedBy(c.elasticQuotaInfos.infos["ns2"]) {
+		t.Errorf("expected ns2 to be contended")
+	}
+	if c.elasticQuotaInfos.contendedBy(c.elasticQuotaInfos.infos["ns1"]) {
+		t.Errorf("expected ns1 not to be contended by its own pods")
+	}
+
+	// A blocked pod deleted while pending no longer contends.
+	c.deletePendingPod(cache.DeletedFinalStateUnknown{Key: "ns1/p1", Obj: pod})
+	if c.elasticQuotaInfos.contendedBy(c.elasticQuotaInfos.infos["ns2"]) {
+		t.Errorf("expected ns2 not to be contended")
+	}
+}
+
+func TestScopedElasticQuotas(t *testing.T) {
+	informerFactory := informers.NewSharedInformerFactory(clientsetfake.NewSimpleClientset(), 0)
+	namespaceStore := informerFactory.Core().V1().Namespaces().Informer().GetStore()
+	for _, ns := range []*v1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "ns1", Labels: map[string]string{"org": "research"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "ns2", Labels: map[string]string{"org": "research"}}},
+	} {
+		if err := namespaceStore.Add(ns); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cs := &CapacityScheduling{
+		elasticQuotaInfos: NewElasticQuotaInfos(),
+		namespaceLister:   informerFactory.Core().V1().Namespaces().Lister(),
+	}
+	teamA := makeEQ("ns1", "team-a", nil, nil)
+	teamA.Spec.PodSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+	research := makeEQ("ns3", "research", nil, nil)
+	research.Spec.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"org": "research"}}
+	for _, eq := range []*v1alpha1.ElasticQuota{makeEQ("ns1", "default", nil, nil), teamA, research} {
+		cs.addElasticQuota(eq)
+	}
+
+	p1 := makePod("p1", "ns1", 10, 0, 0, midPriority, "p1", "node-a")
+	p1.Labels = map[string]string{"team": "a"}
+	p2 := makePodWithStatus(makePod("p2", "ns1", 20, 0, 0, midPriority, "p2", "node-a"), v1.PodRunning)
+	p3 := makePod("p3", "ns2", 40, 0, 0, midPriority, "p3", "node-a")
+	for _, pod := range []*v1.Pod{p1, p2, p3} {
+		cs.addPod(pod)
+	}
+
+	checkUsed := func(expected map[string]int64) {
+		t.Helper()
+		for key, memory := range expected {
+			if got := cs.elasticQuotaInfos.infos[key].Used.Memory; got != memory {
+				t.Errorf("expected ElasticQuota %v to use %v memory, got %v", key, memory, got)
+			}
+		}
+	}
+	// The pod selector of team-a takes precedence over the ElasticQuota of ns1, which takes precedence over the
+	// namespace selector of research.
+	checkUsed(map[string]int64{"ns1/team-a": 10, "ns1": 20, "ns3/research": 40})
+
+	// The pods move to their new ElasticQuota when their labels change.
+	newP2 := p2.DeepCopy()
+	newP2.Labels = map[string]string{"team": "a"}
+	cs.updatePod(p2, newP2)
+	checkUsed(map[string]int64{"ns1/team-a": 30, "ns1": 0, "ns3/research": 40})
+
+	cs.deletePod(newP2)
+	checkUsed(map[string]int64{"ns1/team-a": 10, "ns1": 0, "ns3/research": 40})
+}
+
 func makePod(podName string, namespace string, memReq int64, cpuReq int64, gpuReq int64, priority int32, uid string, nodeName string) *v1.Pod {
 	pause := imageutils.GetPauseImageName()
 	pod := st.MakePod().Namespace(namespace).Name(podName).Container(pause).
@@ -18,9 +18,14 @@ package capacityscheduling
 
 import (
 	"math"
+	"slices"
+	"strings"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 	"sigs.k8s.io/scheduler-plugins/pkg/util"
@@ -31,44 +36,319 @@ const (
 	LowerBoundOfMin = 0
 )
 
-type ElasticQuotaInfos map[string]*ElasticQuotaInfo
+// ElasticQuotaInfos maps keys to ElasticQuotaInfos. An ElasticQuota applying to all the pods of its namespace is
+// keyed by its namespace, and an ElasticQuota with selectors by its namespace and name. The ElasticQuotaInfos are
+// added and removed with set and remove, which rebuild the indexes of the ElasticQuotas with selectors and of the
+// hierarchy, so that looking up the ElasticQuota of a pod or the usage of a subtree doesn't go through all of them.
+type ElasticQuotaInfos struct {
+	infos map[string]*ElasticQuotaInfo
+	// scopedKeys are the keys of the ElasticQuotaInfos with selectors, which may select the pods of any namespace.
+	scopedKeys []string
+	// childKeys are the keys of the children of the ElasticQuotaInfos, by key.
+	// The indexes are never modified once built, so that clones share them.
+	childKeys map[string][]string
+}
+
+func NewElasticQuotaInfos() *ElasticQuotaInfos {
+	return newElasticQuotaInfosFrom(make(map[string]*ElasticQuotaInfo))
+}
+
+// newElasticQuotaInfosFrom returns the ElasticQuotaInfos indexing the given ones by key.
+func newElasticQuotaInfosFrom(infos map[string]*ElasticQuotaInfo) *ElasticQuotaInfos {
+	e := &ElasticQuotaInfos{infos: infos}
+	e.reindex()
+	return e
+}
+
+// set adds or replaces the ElasticQuotaInfo of the given key.
+func (e *ElasticQuotaInfos) set(key string, elasticQuotaInfo *ElasticQuotaInfo) {
+	e.infos[key] = elasticQuotaInfo
+	e.reindex()
+}
 
-func NewElasticQuotaInfos() ElasticQuotaInfos {
-	return make(ElasticQuotaInfos)
+// remove removes the ElasticQuotaInfo of the given key.
+func (e *ElasticQuotaInfos) remove(key string) {
+	delete(e.infos, key)
+	e.reindex()
 }
 
-func (e ElasticQuotaInfos) clone() ElasticQuotaInfos {
-	elasticQuotas := make(ElasticQuotaInfos)
-	for key, elasticQuotaInfo := range e {
-		elasticQuotas[key] = elasticQuotaInfo.clone()
+// reindex rebuilds the indexes of the ElasticQuotas with selectors and of the hierarchy.
+func (e *ElasticQuotaInfos) reindex() {
+	var scopedKeys []string
+	childKeys := make(map[string][]string)
+	for key, elasticQuotaInfo := range e.infos {
+		if elasticQuotaInfo.Scope != nil {
+			scopedKeys = append(scopedKeys, key)
+		}
+		if parentKey := e.parentKeyOf(elasticQuotaInfo); parentKey != "" {
+			childKeys[parentKey] = append(childKeys[parentKey], key)
+		}
+	}
+	e.scopedKeys, e.childKeys = scopedKeys, childKeys
+}
+
+func (e *ElasticQuotaInfos) clone() *ElasticQuotaInfos {
+	elasticQuotas := &ElasticQuotaInfos{
+		infos:      make(map[string]*ElasticQuotaInfo, len(e.infos)),
+		scopedKeys: e.scopedKeys,
+		childKeys:  e.childKeys,
+	}
+	for key, elasticQuotaInfo := range e.infos {
+		elasticQuotas.infos[key] = elasticQuotaInfo.clone()
 	}
 	return elasticQuotas
 }
 
-func (e ElasticQuotaInfos) aggregatedUsedOverMinWith(podRequest framework.Resource) bool {
+// aggregatedUsedOverMinWith checks whether the total usage of all ElasticQuotas with podRequest exceeds the total min
+// of the root ElasticQuotas. The min of an ElasticQuota with a parent is part of the min of its parent.
+func (e *ElasticQuotaInfos) aggregatedUsedOverMinWith(podRequest framework.Resource) bool {
 	used := framework.NewResource(nil)
 	min := framework.NewResource(nil)
 
-	for _, elasticQuotaInfo := range e {
+	for _, elasticQuotaInfo := range e.infos {
 		used.Add(util.ResourceList(elasticQuotaInfo.Used))
-		min.Add(util.ResourceList(elasticQuotaInfo.Min))
+		if e.parentOf(elasticQuotaInfo) == nil {
+			min.Add(util.ResourceList(elasticQuotaInfo.Min))
+		}
 	}
 
 	used.Add(util.ResourceList(&podRequest))
 	return cmp(used, min, LowerBoundOfMin)
 }
 
+// keyOf returns the key of the ElasticQuotaInfo the given pod, in a namespace with the given labels, is subject to,
+// or an empty string if there is none. Among the ElasticQuotas selecting the pod, the one with precedence wins.
+// Only the ElasticQuota of the namespace of the pod and the ones with selectors may select it.
+func (e *ElasticQuotaInfos) keyOf(pod *v1.Pod, namespaceLabels labels.Set) string {
+	var key string
+	var scope *util.ElasticQuotaScope
+	selects := func(k string) {
+		s := e.infos[k].scope()
+		if s.Selects(pod, namespaceLabels) && (scope == nil || s.Precedes(scope)) {
+			key, scope = k, s
+		}
+	}
+	if elasticQuotaInfo := e.infos[pod.Namespace]; elasticQuotaInfo != nil && elasticQuotaInfo.Scope == nil {
+		selects(pod.Namespace)
+	}
+	for _, k := range e.scopedKeys {
+		selects(k)
+	}
+	return key
+}
+
+// parentOf returns the parent of the given ElasticQuotaInfo, or nil if it is a root.
+func (e *ElasticQuotaInfos) parentOf(elasticQuotaInfo *ElasticQuotaInfo) *ElasticQuotaInfo {
+	if parentKey := e.parentKeyOf(elasticQuotaInfo); parentKey != "" {
+		return e.infos[parentKey]
+	}
+	return nil
+}
+
+// parentKeyOf returns the key of the parent of the given ElasticQuotaInfo, or an empty string if it is a root.
+func (e *ElasticQuotaInfos) parentKeyOf(elasticQuotaInfo *ElasticQuotaInfo) string {
+	if elasticQuotaInfo.Parent == "" {
+		return ""
+	}
+	parentKey := elasticQuotaInfo.Parent
+	if e.infos[parentKey] == nil {
+		// The parent applies to all the pods of its namespace, so it is keyed by its namespace.
+		namespace, _, ok := strings.Cut(elasticQuotaInfo.Parent, "/")
+		if !ok || e.infos[namespace] == nil || e.infos[namespace].Scope != nil {
+			return ""
+		}
+		parentKey = namespace
+	}
+	if e.infos[parentKey] == elasticQuotaInfo {
+		return ""
+	}
+	return parentKey
+}
+
+// lineageOf returns the given ElasticQuotaInfo followed by its ancestors, up to its root.
+// The hierarchy is cut where the parents form a cycle.
+func (e *ElasticQuotaInfos) lineageOf(elasticQuotaInfo *ElasticQuotaInfo) []*ElasticQuotaInfo {
+	var lineage []*ElasticQuotaInfo
+	for current := elasticQuotaInfo; current != nil && !slices.Contains(lineage, current); current = e.parentOf(current) {
+		lineage = append(lineage, current)
+	}
+	return lineage
+}
+
+// ancestorsOf returns the ElasticQuotaInfo of the given key followed by its ancestors, up to its root.
+func (e *ElasticQuotaInfos) ancestorsOf(key string) []*ElasticQuotaInfo {
+	return e.lineageOf(e.infos[key])
+}
+
+// isAncestorOrSelf checks whether the given ancestor is the given ElasticQuotaInfo or one of its ancestors.
+func (e *ElasticQuotaInfos) isAncestorOrSelf(ancestor, elasticQuotaInfo *ElasticQuotaInfo) bool {
+	return slices.Contains(e.lineageOf(elasticQuotaInfo), ancestor)
+}
+
+// subtreeUsed returns the resources used by the given ElasticQuotaInfo and all its descendants.
+func (e *ElasticQuotaInfos) subtreeUsed(elasticQuotaInfo *ElasticQuotaInfo) *framework.Resource {
+	used := framework.NewResource(nil)
+	// The descendants are the ElasticQuotaInfos the given one is an ancestor of, where the parents forming a cycle
+	// are all ancestors of one another.
+	visited := sets.New[string]()
+	keys := []string{elasticQuotaInfo.key()}
+	for len(keys) > 0 {
+		key := keys[len(keys)-1]
+		keys = keys[:len(keys)-1]
+		if visited.Has(key) {
+			continue
+		}
+		visited.Insert(key)
+		if info := e.infos[key]; info != nil && info.Used != nil {
+			used.Add(util.ResourceList(info.Used))
+		}
+		keys = append(keys, e.childKeys[key]...)
+	}
+	return used
+}
+
+// ancestorOverMaxWith returns the first ElasticQuotaInfo, among the one of the given key and its ancestors,
+// whose usage with podRequest exceeds its max, or nil if there is none.
+func (e *ElasticQuotaInfos) ancestorOverMaxWith(key string, podRequest *framework.Resource) *ElasticQuotaInfo {
+	for _, elasticQuotaInfo := range e.ancestorsOf(key) {
+		// "ElasticQuotaInfo doesn't have Max" means there are no limitations(infinite)
+		if elasticQuotaInfo.Max == nil {
+			continue
+		}
+		if cmp2(podRequest, e.subtreeUsed(elasticQuotaInfo), elasticQuotaInfo.Max, UpperBoundOfMax) {
+			return elasticQuotaInfo
+		}
+	}
+	return nil
+}
+
+// guaranteedAncestorWith returns the closest ElasticQuotaInfo, among the one of the given key and its ancestors,
+// whose usage with podRequest is within its min, or nil if there is none. The min of the returned
+// ElasticQuotaInfo is used or borrowed by the ElasticQuotas outside of it.
+func (e *ElasticQuotaInfos) guaranteedAncestorWith(key string, podRequest *framework.Resource) *ElasticQuotaInfo {
+	for _, elasticQuotaInfo := range e.ancestorsOf(key) {
+		// "ElasticQuotaInfo doesn't have Min" means used values exceeded min(0)
+		if elasticQuotaInfo.Min == nil {
+			continue
+		}
+		if !cmp2(podRequest, e.subtreeUsed(elasticQuotaInfo), elasticQuotaInfo.Min, LowerBoundOfMin) {
+			return elasticQuotaInfo
+		}
+	}
+	return nil
+}
+
+// borrowsFrom checks whether the ElasticQuota of the given key borrows resources from the given lender.
+// That is the case when it is outside of the lender, and the branch it belongs to below their closest common
+// ancestor uses more than its min. Hence, the min of a quota is reclaimed from its siblings before its cousins.
+func (e *ElasticQuotaInfos) borrowsFrom(lender *ElasticQuotaInfo, key string) bool {
+	borrower := e.infos[key]
+	if borrower == nil || e.isAncestorOrSelf(lender, borrower) {
+		return false
+	}
+	var branch *ElasticQuotaInfo
+	for _, elasticQuotaInfo := range e.lineageOf(borrower) {
+		if e.isAncestorOrSelf(elasticQuotaInfo, lender) {
+			break
+		}
+		branch = elasticQuotaInfo
+	}
+	if branch == nil {
+		return false
+	}
+	// "ElasticQuotaInfo doesn't have Min" means used values exceeded min(0)
+	if branch.Min == nil {
+		return true
+	}
+	return cmp(e.subtreeUsed(branch), branch.Min, LowerBoundOfMin)
+}
+
+// reclaimDistance returns the number of levels between the ElasticQuota of the given key and its closest
+// common ancestor with the given lender. Siblings are at distance 1, cousins at distance 2, and so on.
+func (e *ElasticQuotaInfos) reclaimDistance(lender *ElasticQuotaInfo, key string) int {
+	lineage := e.ancestorsOf(key)
+	for distance, elasticQuotaInfo := range lineage {
+		if e.isAncestorOrSelf(elasticQuotaInfo, lender) {
+			return distance
+		}
+	}
+	return len(lineage)
+}
+
+// lendable returns the resources that can be lent to the borrowers, by name: the min of the root ElasticQuotas
+// that isn't used by the ElasticQuotas within their own min. CPU is in millicores.
+func (e *ElasticQuotaInfos) lendable() map[v1.ResourceName]int64 {
+	lendable := make(map[v1.ResourceName]int64)
+	for _, elasticQuotaInfo := range e.infos {
+		minValues := resourceValues(elasticQuotaInfo.Min)
+		if e.parentOf(elasticQuotaInfo) == nil {
+			for name, value := range minValues {
+				lendable[name] += value
+			}
+		}
+		for name, value := range resourceValues(elasticQuotaInfo.Used) {
+			lendable[name] -= min(value, minValues[name])
+		}
+	}
+	return lendable
+}
+
+// fairShareOf returns the fraction of the lendable resources the given ElasticQuotaInfo is entitled to borrow
+// when borrowing is contended: its weight over the total weight of the borrowers, including itself.
+func (e *ElasticQuotaInfos) fairShareOf(elasticQuotaInfo *ElasticQuotaInfo) float64 {
+	totalWeight := elasticQuotaInfo.weight()
+	for _, info := range e.infos {
+		if info != elasticQuotaInfo && len(info.borrowedWith(nil)) > 0 {
+			totalWeight += info.weight()
+		}
+	}
+	return float64(elasticQuotaInfo.weight()) / float64(totalWeight)
+}
+
+// contendedBy checks whether ElasticQuotas other than the given one have pending pods blocked because the
+// lendable resources are contended.
+func (e *ElasticQuotaInfos) contendedBy(elasticQuotaInfo *ElasticQuotaInfo) bool {
+	for _, info := range e.infos {
+		if info != elasticQuotaInfo && info.blockedPods.Len() > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// overFairShareWith checks whether the ElasticQuota of the given key borrows with podRequest more than its
+// fair share of the lendable resources, in terms of its dominant resource. The fair share only caps borrowing
+// when other ElasticQuotas have pending pods blocked by quota; otherwise the lendable resources are left to use.
+func (e *ElasticQuotaInfos) overFairShareWith(key string, podRequest *framework.Resource) bool {
+	elasticQuotaInfo := e.infos[key]
+	if elasticQuotaInfo == nil || len(elasticQuotaInfo.borrowedWith(podRequest)) == 0 || !e.contendedBy(elasticQuotaInfo) {
+		return false
+	}
+	return elasticQuotaInfo.dominantShareWith(e.lendable(), podRequest) > e.fairShareOf(elasticQuotaInfo)
+}
+
 // ElasticQuotaInfo is a wrapper to a ElasticQuota with information.
-// Each namespace can only have one ElasticQuota.
+// Each namespace can only have one ElasticQuota applying to all its pods.
 type ElasticQuotaInfo struct {
 	Namespace string
-	pods      sets.Set[string]
-	Min       *framework.Resource
-	Max       *framework.Resource
-	Used      *framework.Resource
+	// Scope selects the pods subject to an ElasticQuota with selectors, nil for all the pods of the namespace.
+	Scope *util.ElasticQuotaScope
+	// Parent is the key, or the namespace and name, of the parent ElasticQuota, empty for a root ElasticQuota.
+	Parent string
+	// Weight is the weight of the ElasticQuota when borrowing. Zero means the default weight of 1.
+	Weight int64
+	// ReclaimGracePeriod is how long the pods borrowing resources are given to exit when the resources are reclaimed.
+	ReclaimGracePeriod time.Duration
+	pods               sets.Set[string]
+	// blockedPods are the pending pods rejected because they would borrow more lendable resources than available or
+	// than their fair share, which is then only enforced for the other ElasticQuotas.
+	blockedPods sets.Set[string]
+	Min         *framework.Resource
+	Max         *framework.Resource
+	Used        *framework.Resource
 }
 
-func newElasticQuotaInfo(namespace string, min, max, used v1.ResourceList) *ElasticQuotaInfo {
+func newElasticQuotaInfo(namespace, parent string, weight int64, min, max, used v1.ResourceList) *ElasticQuotaInfo {
 	if min == nil {
 		min = makeResourceListForBound(LowerBoundOfMin)
 	}
@@ -78,6 +358,8 @@ func newElasticQuotaInfo(namespace string, min, max, used v1.ResourceList) *Elas
 
 	elasticQuotaInfo := &ElasticQuotaInfo{
 		Namespace: namespace,
+		Parent:    parent,
+		Weight:    weight,
 		pods:      sets.New[string](),
 		Min:       framework.NewResource(min),
 		Max:       framework.NewResource(max),
@@ -130,10 +412,66 @@ func (e *ElasticQuotaInfo) usedOverMin() bool {
 	return cmp(e.Used, e.Min, LowerBoundOfMin)
 }
 
+// key returns the key of the ElasticQuotaInfo in ElasticQuotaInfos.
+func (e *ElasticQuotaInfo) key() string {
+	if e.Scope != nil {
+		return e.Scope.NamespacedName.String()
+	}
+	return e.Namespace
+}
+
+// scope returns the pods subject to the ElasticQuota.
+func (e *ElasticQuotaInfo) scope() *util.ElasticQuotaScope {
+	if e.Scope != nil {
+		return e.Scope
+	}
+	return &util.ElasticQuotaScope{NamespacedName: types.NamespacedName{Namespace: e.Namespace}}
+}
+
+func (e *ElasticQuotaInfo) weight() int64 {
+	if e.Weight < 1 {
+		return 1
+	}
+	return e.Weight
+}
+
+// borrowedWith returns the resources used over the min with podRequest, by name.
+func (e *ElasticQuotaInfo) borrowedWith(podRequest *framework.Resource) map[v1.ResourceName]int64 {
+	used := resourceValues(e.Used)
+	for name, value := range resourceValues(podRequest) {
+		used[name] += value
+	}
+	minValues := resourceValues(e.Min)
+	borrowed := make(map[v1.ResourceName]int64)
+	for name, value := range used {
+		if value > minValues[name] {
+			borrowed[name] = value - minValues[name]
+		}
+	}
+	return borrowed
+}
+
+// dominantShareWith returns the largest share of the given lendable resources that is borrowed with podRequest,
+// among all resources. It is the share of the dominant resource of the borrower.
+func (e *ElasticQuotaInfo) dominantShareWith(lendable map[v1.ResourceName]int64, podRequest *framework.Resource) float64 {
+	var share float64
+	for name, value := range e.borrowedWith(podRequest) {
+		if lendable[name] <= 0 {
+			continue
+		}
+		share = max(share, float64(value)/float64(lendable[name]))
+	}
+	return share
+}
+
 func (e *ElasticQuotaInfo) clone() *ElasticQuotaInfo {
 	newEQInfo := &ElasticQuotaInfo{
-		Namespace: e.Namespace,
-		pods:      sets.New[string](),
+		Namespace:          e.Namespace,
+		Scope:              e.Scope,
+		Parent:             e.Parent,
+		Weight:             e.Weight,
+		ReclaimGracePeriod: e.ReclaimGracePeriod,
+		pods:               sets.New[string](),
 	}
 
 	if e.Min != nil {
@@ -148,6 +486,9 @@ func (e *ElasticQuotaInfo) clone() *ElasticQuotaInfo {
 	for pod := range e.pods {
 		newEQInfo.pods.Insert(pod)
 	}
+	if e.blockedPods != nil {
+		newEQInfo.blockedPods = e.blockedPods.Clone()
+	}
 
 	return newEQInfo
 }
@@ -220,6 +561,22 @@ func cmp2(x1, x2, y *framework.Resource, bound int64) bool {
 	return false
 }
 
+// resourceValues returns the values of the given resource by name. CPU is in millicores.
+func resourceValues(r *framework.Resource) map[v1.ResourceName]int64 {
+	values := make(map[v1.ResourceName]int64)
+	if r == nil {
+		return values
+	}
+	values[v1.ResourceCPU] = r.MilliCPU
+	values[v1.ResourceMemory] = r.Memory
+	values[v1.ResourceEphemeralStorage] = r.EphemeralStorage
+	values[v1.ResourcePods] = int64(r.AllowedPodNumber)
+	for name, value := range r.ScalarResources {
+		values[name] = value
+	}
+	return values
+}
+
 func makeResourceListForBound(bound int64) v1.ResourceList {
 	return v1.ResourceList{
 		v1.ResourceCPU:              *resource.NewMilliQuantity(bound, resource.DecimalSI),
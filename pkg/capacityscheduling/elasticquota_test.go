@@ -23,7 +23,11 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
 )
 
 func TestReserveResource(t *testing.T) {
@@ -624,9 +628,305 @@ func TestNewElasticQuotaInfo(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			eqp := tt.elasticQuotaParam
-			if got := newElasticQuotaInfo(eqp.namespace, eqp.min, eqp.max, eqp.used); !reflect.DeepEqual(got, tt.expected) {
+			if got := newElasticQuotaInfo(eqp.namespace, "", 0, eqp.min, eqp.max, eqp.used); !reflect.DeepEqual(got, tt.expected) {
 				t.Errorf("expected %v, got %v", tt.expected, got)
 			}
 		})
 	}
 }
+
+func TestElasticQuotaHierarchy(t *testing.T) {
+	// org
+	// ├── dept-a
+	// │   ├── team-a1
+	// │   └── team-a2 (borrowing from team-a1)
+	// └── dept-b (borrowing from dept-a)
+	//     └── team-b1
+	elasticQuotaInfos := newElasticQuotaInfosFrom(map[string]*ElasticQuotaInfo{
+		"org": {
+			Namespace: "org",
+			Min:       &framework.Resource{Memory: 100},
+			Used:      &framework.Resource{},
+		},
+		"dept-a": {
+			Namespace: "dept-a",
+			Parent:    "org",
+			Min:       &framework.Resource{Memory: 60},
+			Max:       &framework.Resource{Memory: 70},
+			Used:      &framework.Resource{},
+		},
+		"team-a1": {
+			Namespace: "team-a1",
+			Parent:    "dept-a",
+			Min:       &framework.Resource{Memory: 30},
+			Used:      &framework.Resource{Memory: 10},
+		},
+		"team-a2": {
+			Namespace: "team-a2",
+			Parent:    "dept-a",
+			Min:       &framework.Resource{Memory: 30},
+			Used:      &framework.Resource{Memory: 50},
+		},
+		"dept-b": {
+			Namespace: "dept-b",
+			Parent:    "org",
+			Min:       &framework.Resource{Memory: 40},
+			Used:      &framework.Resource{},
+		},
+		"team-b1": {
+			Namespace: "team-b1",
+			Parent:    "dept-b",
+			Min:       &framework.Resource{Memory: 40},
+			Used:      &framework.Resource{Memory: 45},
+		},
+	})
+
+	var ancestors []string
+	for _, elasticQuotaInfo := range elasticQuotaInfos.ancestorsOf("team-a1") {
+		ancestors = append(ancestors, elasticQuotaInfo.Namespace)
+	}
+	if want := []string{"team-a1", "dept-a", "org"}; !reflect.DeepEqual(ancestors, want) {
+		t.Errorf("expected ancestors %v, got %v", want, ancestors)
+	}
+
+	if got := elasticQuotaInfos.subtreeUsed(elasticQuotaInfos.infos["dept-a"]).Memory; got != 60 {
+		t.Errorf("expected dept-a to use 60, got %v", got)
+	}
+	if got := elasticQuotaInfos.subtreeUsed(elasticQuotaInfos.infos["org"]).Memory; got != 105 {
+		t.Errorf("expected org to use 105, got %v", got)
+	}
+
+	if got := elasticQuotaInfos.ancestorOverMaxWith("team-a1", &framework.Resource{Memory: 5}); got != nil {
+		t.Errorf("expected no quota over max, got %v", got.Namespace)
+	}
+	if got := elasticQuotaInfos.ancestorOverMaxWith("team-a1", &framework.Resource{Memory: 15}); got == nil || got.Namespace != "dept-a" {
+		t.Errorf("expected dept-a to be over max, got %v", got)
+	}
+
+	// The min of the root quotas includes the min of their descendants.
+	if !elasticQuotaInfos.aggregatedUsedOverMinWith(framework.Resource{}) {
+		t.Error("expected the aggregated used to be over the min of the root quotas")
+	}
+
+	guaranteed := elasticQuotaInfos.guaranteedAncestorWith("team-a1", &framework.Resource{Memory: 10})
+	if guaranteed == nil || guaranteed.Namespace != "team-a1" {
+		t.Fatalf("expected team-a1 to be guaranteed, got %v", guaranteed)
+	}
+	if got := elasticQuotaInfos.guaranteedAncestorWith("team-a2", &framework.Resource{Memory: 10}); got != nil {
+		t.Errorf("expected no guaranteed quota for team-a2, got %v", got.Namespace)
+	}
+
+	for _, tt := range []struct {
+		namespace    string
+		wantBorrows  bool
+		wantDistance int
+	}{
+		{namespace: "team-a2", wantBorrows: true, wantDistance: 1},
+		{namespace: "team-b1", wantBorrows: true, wantDistance: 2},
+		{namespace: "dept-a", wantBorrows: false, wantDistance: 0},
+		{namespace: "org", wantBorrows: false, wantDistance: 0},
+	} {
+		if got := elasticQuotaInfos.borrowsFrom(guaranteed, tt.namespace); got != tt.wantBorrows {
+			t.Errorf("expected %v to borrow from team-a1: %v, got %v", tt.namespace, tt.wantBorrows, got)
+		}
+		if got := elasticQuotaInfos.reclaimDistance(guaranteed, tt.namespace); got != tt.wantDistance {
+			t.Errorf("expected the distance of %v from team-a1 to be %v, got %v", tt.namespace, tt.wantDistance, got)
+		}
+	}
+
+	// A quota below its min within its own branch doesn't lend to its cousins.
+	elasticQuotaInfos.infos["dept-b"].Min = &framework.Resource{Memory: 50}
+	if elasticQuotaInfos.borrowsFrom(guaranteed, "team-b1") {
+		t.Error("expected team-b1 not to borrow from team-a1 while dept-b is within its min")
+	}
+
+	// Parents forming a cycle cut the hierarchy.
+	org := elasticQuotaInfos.infos["org"]
+	org.Parent = "team-a1"
+	elasticQuotaInfos.set("org", org)
+	if got := len(elasticQuotaInfos.ancestorsOf("team-a1")); got != 3 {
+		t.Errorf("expected 3 ancestors with a cycle, got %v", got)
+	}
+	if got := elasticQuotaInfos.subtreeUsed(org).Memory; got != 105 {
+		t.Errorf("expected a used of 105 in the subtree of org with a cycle, got %v", got)
+	}
+}
+
+func TestFairShare(t *testing.T) {
+	elasticQuotaInfos := newElasticQuotaInfosFrom(map[string]*ElasticQuotaInfo{
+		"ns1": {
+			Namespace: "ns1",
+			Min:       &framework.Resource{MilliCPU: 1000, Memory: 1000},
+			Used:      &framework.Resource{MilliCPU: 1000, Memory: 1000},
+		},
+		"ns2": {
+			Namespace: "ns2",
+			Min:       &framework.Resource{MilliCPU: 1000, Memory: 1000},
+			Used:      &framework.Resource{MilliCPU: 1000, Memory: 1500},
+		},
+		"ns3": {
+			Namespace: "ns3",
+			Min:       &framework.Resource{MilliCPU: 2000, Memory: 1000},
+			Used:      &framework.Resource{},
+		},
+	})
+
+	lendable := elasticQuotaInfos.lendable()
+	if lendable[v1.ResourceCPU] != 2000 || lendable[v1.ResourceMemory] != 1000 {
+		t.Errorf("expected 2000m CPU and 1000 memory to be lendable, got %v", lendable)
+	}
+
+	// ns2 borrows half of the lendable memory.
+	if got := elasticQuotaInfos.infos["ns2"].dominantShareWith(lendable, nil); got != 0.5 {
+		t.Errorf("expected a dominant share of 0.5 for ns2, got %v", got)
+	}
+	if got := elasticQuotaInfos.fairShareOf(elasticQuotaInfos.infos["ns1"]); got != 0.5 {
+		t.Errorf("expected a fair share of 0.5 for ns1, got %v", got)
+	}
+
+	for _, tt := range []struct {
+		name        string
+		weight      int64
+		blockedPods sets.Set[string]
+		podRequest  *framework.Resource
+		want        bool
+	}{
+		{
+			name:        "not borrowing",
+			blockedPods: sets.New("ns3/p1"),
+			podRequest:  &framework.Resource{},
+			want:        false,
+		},
+		{
+			name:        "borrowing within the fair share",
+			blockedPods: sets.New("ns3/p1"),
+			podRequest:  &framework.Resource{MilliCPU: 500, Memory: 400},
+			want:        false,
+		},
+		{
+			name:        "borrowing more than the fair share of the dominant resource",
+			blockedPods: sets.New("ns3/p1"),
+			podRequest:  &framework.Resource{MilliCPU: 500, Memory: 600},
+			want:        true,
+		},
+		{
+			name:        "borrowing within the fair share of a higher weight",
+			weight:      2,
+			blockedPods: sets.New("ns3/p1"),
+			podRequest:  &framework.Resource{MilliCPU: 500, Memory: 600},
+			want:        false,
+		},
+		{
+			name:       "borrowing more than the fair share without pods blocked by quota",
+			podRequest: &framework.Resource{MilliCPU: 500, Memory: 600},
+			want:       false,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			elasticQuotaInfos.infos["ns1"].Weight = tt.weight
+			elasticQuotaInfos.infos["ns3"].blockedPods = tt.blockedPods
+			if got := elasticQuotaInfos.overFairShareWith("ns1", tt.podRequest); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParentOf(t *testing.T) {
+	elasticQuotaInfos := newElasticQuotaInfosFrom(map[string]*ElasticQuotaInfo{
+		"dept": {
+			Namespace: "dept",
+		},
+		"team/team-a": {
+			Namespace: "team",
+			Scope:     &util.ElasticQuotaScope{NamespacedName: types.NamespacedName{Namespace: "team", Name: "team-a"}},
+			Parent:    "dept/eq",
+		},
+		"ns": {
+			Namespace: "ns",
+			Parent:    "team/team-a",
+		},
+		"orphan": {
+			Namespace: "orphan",
+			Parent:    "team/missing",
+		},
+	})
+
+	for key, want := range map[string]string{
+		"dept":        "",
+		"team/team-a": "dept",
+		"ns":          "team",
+		"orphan":      "",
+	} {
+		var got string
+		if parent := elasticQuotaInfos.parentOf(elasticQuotaInfos.infos[key]); parent != nil {
+			got = parent.Namespace
+		}
+		if got != want {
+			t.Errorf("expected the parent of %v in namespace %q, got %q", key, want, got)
+		}
+	}
+}
+
+func TestKeyOf(t *testing.T) {
+	elasticQuotaInfos := newElasticQuotaInfosFrom(map[string]*ElasticQuotaInfo{
+		"ns1": {
+			Namespace: "ns1",
+		},
+		"ns2": {
+			Namespace: "ns2",
+		},
+		"team/gpu": {
+			Namespace: "team",
+			Scope: &util.ElasticQuotaScope{
+				NamespacedName:    types.NamespacedName{Namespace: "team", Name: "gpu"},
+				NamespaceSelector: labels.SelectorFromSet(labels.Set{"team": "a"}),
+				PodSelector:       labels.SelectorFromSet(labels.Set{"gpu": "true"}),
+			},
+		},
+	})
+
+	gpuPod := makePod("p1", "ns1", 0, 0, 0, 0, "p1", "")
+	gpuPod.Labels = map[string]string{"gpu": "true"}
+	for _, tt := range []struct {
+		name            string
+		pod             *v1.Pod
+		namespaceLabels labels.Set
+		want            string
+	}{
+		{
+			name: "pod subject to the ElasticQuota of its namespace",
+			pod:  makePod("p1", "ns1", 0, 0, 0, 0, "p1", ""),
+			want: "ns1",
+		},
+		{
+			name:            "pod selected by an ElasticQuota of another namespace",
+			pod:             gpuPod,
+			namespaceLabels: labels.Set{"team": "a"},
+			want:            "team/gpu",
+		},
+		{
+			name:            "pod in a namespace not selected",
+			pod:             gpuPod,
+			namespaceLabels: labels.Set{"team": "b"},
+			want:            "ns1",
+		},
+		{
+			name: "pod without ElasticQuota",
+			pod:  makePod("p1", "ns3", 0, 0, 0, 0, "p1", ""),
+			want: "",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := elasticQuotaInfos.keyOf(tt.pod, tt.namespaceLabels); got != tt.want {
+				t.Errorf("expected key %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	// Removing the ElasticQuota with selectors drops it from the index.
+	elasticQuotaInfos.remove("team/gpu")
+	if got := elasticQuotaInfos.keyOf(gpuPod, labels.Set{"team": "a"}); got != "ns1" {
+		t.Errorf("expected key %q once team/gpu is removed, got %q", "ns1", got)
+	}
+}
@@ -19,23 +19,48 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	quota "k8s.io/apiserver/pkg/quota/v1"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
 	schedv1alpha1 "sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
 )
 
+// allElasticQuotas is the request to reconcile all the elastic quotas at once, as the status of each of them depends
+// on the usage of the others.
+var allElasticQuotas = reconcile.Request{}
+
+// allElasticQuotasPeriod is the minimum period between two reconciliations of all the elastic quotas following changes
+// of pods, which otherwise only reconcile the elastic quotas selecting them.
+const allElasticQuotasPeriod = 5 * time.Second
+
 type ElasticQuotaReconciler struct {
 	recorder record.EventRecorder
 
@@ -47,11 +72,15 @@ type ElasticQuotaReconciler struct {
 // +kubebuilder:rbac:groups=scheduling.x-k8s.io,resources=elasticquota,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=scheduling.x-k8s.io,resources=elasticquota/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=scheduling.x-k8s.io,resources=elasticquota/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
 func (r *ElasticQuotaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := log.FromContext(ctx)
 	log.Info("reconciling")
-	eqList := &schedv1alpha1.ElasticQuotaList{}
-	if err := r.List(ctx, eqList, client.InNamespace(req.Namespace)); err != nil {
+	if req == allElasticQuotas {
+		return r.reconcileAll(ctx)
+	}
+	eq := &schedv1alpha1.ElasticQuota{}
+	if err := r.Get(ctx, req.NamespacedName, eq); err != nil {
 		if apierrs.IsNotFound(err) {
 			log.V(5).Info("no elasticquota found")
 			return ctrl.Result{}, nil
@@ -60,52 +89,449 @@ func (r *ElasticQuotaReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, err
 	}
 
-	// TODO: When elastic quota supports multiple instances in a namespace, modify this
-	if len(eqList.Items) == 0 {
-		log.V(5).Info("no elasticquota found")
-		return ctrl.Result{}, nil
+	allEQList := &schedv1alpha1.ElasticQuotaList{}
+	if err := r.List(ctx, allEQList); err != nil {
+		log.V(3).Error(err, "Unable to list elasticquotas")
+		return ctrl.Result{}, err
+	}
+	if _, err := util.GetElasticQuotaAncestors(eq, allEQList.Items); err != nil {
+		r.recorder.Event(eq, v1.EventTypeWarning, "InvalidParent", err.Error())
+	}
+	if _, err := util.NewElasticQuotaScope(eq); err != nil {
+		r.recorder.Event(eq, v1.EventTypeWarning, "InvalidSelector", err.Error())
 	}
 
-	eq := &eqList.Items[0]
-	used, err := r.computeElasticQuotaUsed(ctx, req.Namespace, eq)
+	// Only the pods selected by the elastic quota and its descendants are counted.
+	subtree := getSubtree(eq, allEQList.Items)
+	usage, err := r.computeQuotaUsage(ctx, allEQList.Items, sets.New(subtree...))
 	if err != nil {
 		return ctrl.Result{}, err
 	}
+	return ctrl.Result{}, r.updateStatus(ctx, eq, computeStatus(eq, allEQList.Items, usage))
+}
 
-	// Ignore this loop if the usage value has not changed
-	if apiequality.Semantic.DeepEqual(used, eq.Status.Used) {
-		return ctrl.Result{}, nil
+// reconcileAll updates the status of all the elastic quotas from a single computation of their usage, including the
+// fair share, lent and available resources that depend on the usage of all of them.
+func (r *ElasticQuotaReconciler) reconcileAll(ctx context.Context) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	allEQList := &schedv1alpha1.ElasticQuotaList{}
+	if err := r.List(ctx, allEQList); err != nil {
+		log.V(3).Error(err, "Unable to list elasticquotas")
+		return ctrl.Result{}, err
+	}
+	usage, err := r.computeQuotaUsage(ctx, allEQList.Items, nil)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	var errs []error
+	for i := range allEQList.Items {
+		eq := &allEQList.Items[i]
+		status := computeStatus(eq, allEQList.Items, usage)
+		status.FairShare = computeFairShare(eq, allEQList.Items, usage.used)
+		status.Lent = computeLent(eq, allEQList.Items, usage.used)
+		status.Available = computeAvailable(eq, allEQList.Items, usage.used)
+		if err := r.updateStatus(ctx, eq, status); err != nil {
+			errs = append(errs, err)
+		}
 	}
+	return ctrl.Result{}, utilerrors.NewAggregate(errs)
+}
 
-	// create a usage object that is based on the elastic quota version that will handle updates
-	// by default, we set used to the current status
+// computeStatus returns the status of the given elastic quota, given the usage of the elastic quotas of its subtree.
+// The fair share, lent and available resources, which depend on the usage of all the elastic quotas, are unchanged.
+func computeStatus(eq *schedv1alpha1.ElasticQuota, eqs []schedv1alpha1.ElasticQuota, usage *quotaUsage) schedv1alpha1.ElasticQuotaStatus {
+	// The usage of an elastic quota aggregates the usage of its descendants.
+	subtree := getSubtree(eq, eqs)
+	used := computeElasticQuotaUsed(subtree, eq, usage.used)
+
+	status := *eq.Status.DeepCopy()
+	status.Used = used
+	status.Borrowed = quota.SubtractWithNonNegativeResult(used, eq.Spec.Min)
+	status.PendingPods = 0
+	for _, name := range subtree {
+		status.PendingPods += usage.blocked[name]
+	}
+	meta.SetStatusCondition(&status.Conditions, overlapCondition(usage.overlaps[client.ObjectKeyFromObject(eq)]))
+	meta.SetStatusCondition(&status.Conditions, overMinCondition(status.Borrowed))
+	meta.SetStatusCondition(&status.Conditions, atMaxCondition(eq, used))
+	return status
+}
+
+// updateStatus patches the status of the given elastic quota, if it changed.
+func (r *ElasticQuotaReconciler) updateStatus(ctx context.Context, eq *schedv1alpha1.ElasticQuota, status schedv1alpha1.ElasticQuotaStatus) error {
+	if apiequality.Semantic.DeepEqual(status, eq.Status) {
+		return nil
+	}
 	newEQ := eq.DeepCopy()
-	newEQ.Status.Used = used
-	if err = r.patchElasticQuota(ctx, eq, newEQ); err != nil {
-		return ctrl.Result{}, err
+	newEQ.Status = status
+	if err := r.patchElasticQuota(ctx, eq, newEQ); err != nil {
+		return err
 	}
-	r.recorder.Event(eq, v1.EventTypeNormal, "Synced", fmt.Sprintf("Elastic Quota %s synced successfully", req.NamespacedName))
-	return ctrl.Result{}, nil
+	r.recorder.Event(eq, v1.EventTypeNormal, "Synced", fmt.Sprintf("Elastic Quota %s synced successfully", client.ObjectKeyFromObject(eq)))
+	return nil
 }
 
 func (r *ElasticQuotaReconciler) patchElasticQuota(ctx context.Context, old, new *schedv1alpha1.ElasticQuota) error {
-	patch := client.MergeFrom(old)
+	// Both the reconciliation of an elastic quota and of all of them update its status, the stale one retries.
+	patch := client.MergeFromWithOptions(old, client.MergeFromWithOptimisticLock{})
 	return r.Status().Patch(ctx, new, patch)
 }
 
-func (r *ElasticQuotaReconciler) computeElasticQuotaUsed(ctx context.Context, namespace string, eq *schedv1alpha1.ElasticQuota) (v1.ResourceList, error) {
-	used := newZeroUsed(eq)
-	podList := &v1.PodList{}
-	if err := r.List(ctx, podList, client.InNamespace(namespace)); err != nil {
+// quotaUsage is the usage of the elastic quotas, not including their descendants.
+type quotaUsage struct {
+	// used is the resources used by the running pods subject to each elastic quota.
+	used map[types.NamespacedName]v1.ResourceList
+	// blocked is the number of pending pods subject to each elastic quota that the scheduler rejected because of
+	// quotas.
+	blocked map[types.NamespacedName]int32
+	// overlaps is the other elastic quotas selecting some of the pods each elastic quota selects.
+	overlaps map[types.NamespacedName]sets.Set[types.NamespacedName]
+}
+
+// computeQuotaUsage returns the usage of the target elastic quotas among the given ones, or of all of them if targets
+// is nil. The overlaps are only complete for the targets.
+func (r *ElasticQuotaReconciler) computeQuotaUsage(ctx context.Context, eqs []schedv1alpha1.ElasticQuota, targets sets.Set[types.NamespacedName]) (*quotaUsage, error) {
+	namespaceLabels, err := r.getNamespaceLabels(ctx, eqs)
+	if err != nil {
 		return nil, err
 	}
 
-	for _, p := range podList.Items {
+	// Elastic quotas with invalid selectors select no pods.
+	var scopes, targetScopes []*util.ElasticQuotaScope
+	for i := range eqs {
+		if scope, err := util.NewElasticQuotaScope(&eqs[i]); err == nil {
+			scopes = append(scopes, scope)
+			if targets == nil || targets.Has(scope.NamespacedName) {
+				targetScopes = append(targetScopes, scope)
+			}
+		}
+	}
+
+	// Only the pods of the namespaces the targets select may be subject to them.
+	var pods []v1.Pod
+	for _, namespace := range selectedNamespaces(targetScopes, namespaceLabels) {
+		podList := &v1.PodList{}
+		if err := r.List(ctx, podList, client.InNamespace(namespace)); err != nil {
+			return nil, err
+		}
+		pods = append(pods, podList.Items...)
+	}
+
+	usage := &quotaUsage{
+		used:     make(map[types.NamespacedName]v1.ResourceList),
+		blocked:  make(map[types.NamespacedName]int32),
+		overlaps: make(map[types.NamespacedName]sets.Set[types.NamespacedName]),
+	}
+	for i := range pods {
+		p := &pods[i]
+		if p.Status.Phase == v1.PodSucceeded || p.Status.Phase == v1.PodFailed {
+			continue
+		}
+		// The other elastic quotas only matter for the pods selected by a target, to find the one taking precedence.
+		if !slices.ContainsFunc(targetScopes, func(scope *util.ElasticQuotaScope) bool {
+			return scope.Selects(p, namespaceLabels[p.Namespace])
+		}) {
+			continue
+		}
+		var selecting []*util.ElasticQuotaScope
+		for _, scope := range scopes {
+			if scope.Selects(p, namespaceLabels[p.Namespace]) {
+				selecting = append(selecting, scope)
+			}
+		}
+		if len(selecting) == 0 {
+			continue
+		}
+		// The pod is subject to the elastic quota taking precedence.
+		owner := selecting[0]
+		for _, scope := range selecting[1:] {
+			if scope.Precedes(owner) {
+				owner = scope
+			}
+		}
 		if p.Status.Phase == v1.PodRunning {
-			used = quota.Add(used, computePodResourceRequest(&p))
+			usage.used[owner.NamespacedName] = quota.Add(usage.used[owner.NamespacedName], computePodResourceRequest(p))
+		} else if blockedByQuota(p) {
+			usage.blocked[owner.NamespacedName]++
+		}
+		for _, scope := range selecting {
+			for _, other := range selecting {
+				if other != scope {
+					if usage.overlaps[scope.NamespacedName] == nil {
+						usage.overlaps[scope.NamespacedName] = sets.New[types.NamespacedName]()
+					}
+					usage.overlaps[scope.NamespacedName].Insert(other.NamespacedName)
+				}
+			}
+		}
+	}
+	return usage, nil
+}
+
+// selectedNamespaces returns the namespaces whose pods the given scopes may select, given the labels of each
+// namespace.
+func selectedNamespaces(scopes []*util.ElasticQuotaScope, namespaceLabels map[string]labels.Set) []string {
+	namespaces := sets.New[string]()
+	for _, scope := range scopes {
+		if scope.NamespaceSelector == nil {
+			namespaces.Insert(scope.Namespace)
+			continue
+		}
+		for namespace, nsLabels := range namespaceLabels {
+			if scope.NamespaceSelector.Matches(nsLabels) {
+				namespaces.Insert(namespace)
+			}
+		}
+	}
+	return sets.List(namespaces)
+}
+
+// blockedByQuota checks whether the given pod is pending because the scheduler rejected it in the PreFilter of
+// CapacityScheduling, which marks such pods with the BlockedByQuotaAnnotation.
+func blockedByQuota(pod *v1.Pod) bool {
+	if pod.Spec.NodeName != "" {
+		return false
+	}
+	_, ok := pod.Annotations[schedv1alpha1.BlockedByQuotaAnnotation]
+	return ok
+}
+
+// getNamespaceLabels returns the labels of each namespace, if one of the given elastic quotas has a namespace selector.
+func (r *ElasticQuotaReconciler) getNamespaceLabels(ctx context.Context, eqs []schedv1alpha1.ElasticQuota) (map[string]labels.Set, error) {
+	namespaceLabels := make(map[string]labels.Set)
+	for i := range eqs {
+		if eqs[i].Spec.NamespaceSelector == nil {
+			continue
+		}
+		namespaceList := &v1.NamespaceList{}
+		if err := r.List(ctx, namespaceList); err != nil {
+			return nil, err
+		}
+		for _, ns := range namespaceList.Items {
+			namespaceLabels[ns.Name] = ns.Labels
+		}
+		break
+	}
+	return namespaceLabels, nil
+}
+
+// overlapCondition returns the Overlapping condition of an elastic quota, given the other elastic quotas selecting
+// some of the pods it selects.
+func overlapCondition(overlapping sets.Set[types.NamespacedName]) metav1.Condition {
+	if overlapping.Len() == 0 {
+		return metav1.Condition{
+			Type:    schedv1alpha1.ElasticQuotaOverlapping,
+			Status:  metav1.ConditionFalse,
+			Reason:  schedv1alpha1.ElasticQuotaReasonNoOverlap,
+			Message: "No pod selected by the elastic quota is selected by another elastic quota",
+		}
+	}
+	names := make([]string, 0, overlapping.Len())
+	for _, name := range overlapping.UnsortedList() {
+		names = append(names, name.String())
+	}
+	sort.Strings(names)
+	return metav1.Condition{
+		Type:    schedv1alpha1.ElasticQuotaOverlapping,
+		Status:  metav1.ConditionTrue,
+		Reason:  schedv1alpha1.ElasticQuotaReasonPodsSelectedByOthers,
+		Message: fmt.Sprintf("Pods selected by the elastic quota are also selected by %s", strings.Join(names, ", ")),
+	}
+}
+
+// overMinCondition returns the OverMin condition of an elastic quota, given the resources it borrows.
+func overMinCondition(borrowed v1.ResourceList) metav1.Condition {
+	if quota.IsZero(borrowed) {
+		return metav1.Condition{
+			Type:    schedv1alpha1.ElasticQuotaOverMin,
+			Status:  metav1.ConditionFalse,
+			Reason:  schedv1alpha1.ElasticQuotaReasonWithinMin,
+			Message: "The elastic quota uses at most its min",
 		}
 	}
-	return used, nil
+	return metav1.Condition{
+		Type:    schedv1alpha1.ElasticQuotaOverMin,
+		Status:  metav1.ConditionTrue,
+		Reason:  schedv1alpha1.ElasticQuotaReasonBorrowing,
+		Message: fmt.Sprintf("The elastic quota borrows %s beyond its min", formatResourceNames(quota.RemoveZeros(borrowed))),
+	}
+}
+
+// atMaxCondition returns the AtMax condition of an elastic quota, given its usage.
+func atMaxCondition(eq *schedv1alpha1.ElasticQuota, used v1.ResourceList) metav1.Condition {
+	atMax := v1.ResourceList{}
+	for name, max := range eq.Spec.Max {
+		if quantity, ok := used[name]; ok && quantity.Cmp(max) >= 0 {
+			atMax[name] = quantity
+		}
+	}
+	if len(atMax) == 0 {
+		return metav1.Condition{
+			Type:    schedv1alpha1.ElasticQuotaAtMax,
+			Status:  metav1.ConditionFalse,
+			Reason:  schedv1alpha1.ElasticQuotaReasonBelowMax,
+			Message: "The elastic quota uses less than its max",
+		}
+	}
+	return metav1.Condition{
+		Type:    schedv1alpha1.ElasticQuotaAtMax,
+		Status:  metav1.ConditionTrue,
+		Reason:  schedv1alpha1.ElasticQuotaReasonMaxReached,
+		Message: fmt.Sprintf("The elastic quota uses its max of %s", formatResourceNames(atMax)),
+	}
+}
+
+// formatResourceNames returns the sorted names of the given resources, separated by commas.
+func formatResourceNames(resources v1.ResourceList) string {
+	names := make([]string, 0, len(resources))
+	for name := range resources {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func computeElasticQuotaUsed(subtree []types.NamespacedName, eq *schedv1alpha1.ElasticQuota, quotaUsed map[types.NamespacedName]v1.ResourceList) v1.ResourceList {
+	used := newZeroUsed(eq)
+	for _, name := range subtree {
+		used = quota.Add(used, quotaUsed[name])
+	}
+	return used
+}
+
+// computeFairShare returns the resources the given elastic quota is entitled to when borrowing is contended: its min
+// plus its weighted share of the lendable resources, that is the min of the root elastic quotas that isn't used by the
+// elastic quotas within their own min. The lendable resources are shared among the elastic quota and the other
+// elastic quotas borrowing, i.e. using more than their min, in proportion to their weights.
+func computeFairShare(eq *schedv1alpha1.ElasticQuota, eqs []schedv1alpha1.ElasticQuota, quotaUsed map[types.NamespacedName]v1.ResourceList) v1.ResourceList {
+	lendable := v1.ResourceList{}
+	for i := range eqs {
+		if ancestors, err := util.GetElasticQuotaAncestors(&eqs[i], eqs); err == nil && len(ancestors) == 0 {
+			lendable = quota.Add(lendable, eqs[i].Spec.Min)
+		}
+	}
+
+	weight := util.GetElasticQuotaWeight(eq)
+	totalWeight := weight
+	for i := range eqs {
+		used := quotaUsed[client.ObjectKeyFromObject(&eqs[i])]
+		borrowed := quota.SubtractWithNonNegativeResult(used, eqs[i].Spec.Min)
+		lendable = quota.Subtract(lendable, quota.Subtract(used, borrowed))
+		if !quota.IsZero(borrowed) && (eqs[i].Namespace != eq.Namespace || eqs[i].Name != eq.Name) {
+			totalWeight += util.GetElasticQuotaWeight(&eqs[i])
+		}
+	}
+
+	fairShare := quota.Add(newZeroUsed(eq), eq.Spec.Min)
+	for name, quantity := range lendable {
+		if quantity.Sign() <= 0 {
+			continue
+		}
+		var share *resource.Quantity
+		if name == v1.ResourceCPU {
+			share = resource.NewMilliQuantity(quantity.MilliValue()*weight/totalWeight, quantity.Format)
+		} else {
+			share = resource.NewQuantity(quantity.Value()*weight/totalWeight, quantity.Format)
+		}
+		fairShare = quota.Add(fairShare, v1.ResourceList{name: *share})
+	}
+	return fairShare
+}
+
+// computeLent returns the part of the unused min of the given elastic quota that is borrowed by other elastic quotas.
+// An elastic quota lends to its siblings, or to the other root elastic quotas for a root elastic quota: the
+// resources they borrow are taken from the unused min of each of them in proportion.
+func computeLent(eq *schedv1alpha1.ElasticQuota, eqs []schedv1alpha1.ElasticQuota, quotaUsed map[types.NamespacedName]v1.ResourceList) v1.ResourceList {
+	parent := getParent(eq, eqs)
+	borrowed, unused := v1.ResourceList{}, v1.ResourceList{}
+	for i := range eqs {
+		if getParent(&eqs[i], eqs) != parent {
+			continue
+		}
+		used := computeElasticQuotaUsed(getSubtree(&eqs[i], eqs), &eqs[i], quotaUsed)
+		borrowed = quota.Add(borrowed, quota.SubtractWithNonNegativeResult(used, eqs[i].Spec.Min))
+		unused = quota.Add(unused, quota.SubtractWithNonNegativeResult(eqs[i].Spec.Min, used))
+	}
+
+	used := computeElasticQuotaUsed(getSubtree(eq, eqs), eq, quotaUsed)
+	lent := newZeroUsed(eq)
+	for name, quantity := range quota.SubtractWithNonNegativeResult(eq.Spec.Min, used) {
+		total, lentTotal := unused[name], borrowed[name]
+		if quantity.Sign() <= 0 || lentTotal.Sign() <= 0 {
+			continue
+		}
+		if lentTotal.Cmp(total) >= 0 {
+			lent[name] = quantity
+			continue
+		}
+		ratio := lentTotal.AsApproximateFloat64() / total.AsApproximateFloat64()
+		if name == v1.ResourceCPU {
+			lent[name] = *resource.NewMilliQuantity(int64(float64(quantity.MilliValue())*ratio), quantity.Format)
+		} else {
+			lent[name] = *resource.NewQuantity(int64(float64(quantity.Value())*ratio), quantity.Format)
+		}
+	}
+	return lent
+}
+
+// computeAvailable returns the resources the given elastic quota can still use: its unused min, which can be
+// reclaimed from the borrowers, or the min of the root elastic quotas that nobody uses if more, bounded by the max
+// of the elastic quota and of its ancestors.
+func computeAvailable(eq *schedv1alpha1.ElasticQuota, eqs []schedv1alpha1.ElasticQuota, quotaUsed map[types.NamespacedName]v1.ResourceList) v1.ResourceList {
+	unusedRootMin := v1.ResourceList{}
+	for i := range eqs {
+		if ancestors, err := util.GetElasticQuotaAncestors(&eqs[i], eqs); err == nil && len(ancestors) == 0 {
+			unusedRootMin = quota.Add(unusedRootMin, eqs[i].Spec.Min)
+		}
+	}
+	for _, used := range quotaUsed {
+		unusedRootMin = quota.SubtractWithNonNegativeResult(unusedRootMin, used)
+	}
+
+	used := computeElasticQuotaUsed(getSubtree(eq, eqs), eq, quotaUsed)
+	available := quota.Max(quota.SubtractWithNonNegativeResult(eq.Spec.Min, used), unusedRootMin)
+	available = quota.Mask(quota.Add(newZeroUsed(eq), available), quota.ResourceNames(newZeroUsed(eq)))
+	// A cycle returns no ancestors, its elastic quotas are roots.
+	ancestors, _ := util.GetElasticQuotaAncestors(eq, eqs)
+	for _, e := range append([]*schedv1alpha1.ElasticQuota{eq}, ancestors...) {
+		// The resources not in the max are unbounded.
+		headroom := quota.Mask(quota.SubtractWithNonNegativeResult(e.Spec.Max, computeElasticQuotaUsed(getSubtree(e, eqs), e, quotaUsed)), quota.ResourceNames(e.Spec.Max))
+		for name, quantity := range headroom {
+			if current, ok := available[name]; ok && quantity.Cmp(current) < 0 {
+				available[name] = quantity
+			}
+		}
+	}
+	return available
+}
+
+// getParent returns the parent of the given elastic quota among eqs, or an empty name for a root elastic quota.
+// The elastic quotas whose parents form a cycle are roots.
+func getParent(eq *schedv1alpha1.ElasticQuota, eqs []schedv1alpha1.ElasticQuota) types.NamespacedName {
+	ancestors, err := util.GetElasticQuotaAncestors(eq, eqs)
+	if err != nil || len(ancestors) == 0 {
+		return types.NamespacedName{}
+	}
+	return client.ObjectKeyFromObject(ancestors[0])
+}
+
+// getSubtree returns the given elastic quota and its descendants among eqs.
+// The elastic quotas whose parents form a cycle aren't descendants of any elastic quota.
+func getSubtree(eq *schedv1alpha1.ElasticQuota, eqs []schedv1alpha1.ElasticQuota) []types.NamespacedName {
+	subtree := []types.NamespacedName{client.ObjectKeyFromObject(eq)}
+	for i := range eqs {
+		ancestors, err := util.GetElasticQuotaAncestors(&eqs[i], eqs)
+		if err != nil {
+			continue
+		}
+		for _, ancestor := range ancestors {
+			if ancestor.Namespace == eq.Namespace && ancestor.Name == eq.Name {
+				subtree = append(subtree, client.ObjectKeyFromObject(&eqs[i]))
+				break
+			}
+		}
+	}
+	return subtree
 }
 
 // computePodResourceRequest returns a v1.ResourceList that covers the largest
@@ -168,11 +594,77 @@ func newZeroUsed(eq *schedv1alpha1.ElasticQuota) v1.ResourceList {
 	return res
 }
 
+// getElasticQuotaRequests returns the requests to reconcile the elastic quotas selecting the given pod and their
+// ancestors, whose usage includes the usage of the pod.
+func (r *ElasticQuotaReconciler) getElasticQuotaRequests(ctx context.Context, pod *v1.Pod) []reconcile.Request {
+	eqList := &schedv1alpha1.ElasticQuotaList{}
+	if err := r.List(ctx, eqList); err != nil {
+		log.FromContext(ctx).V(3).Error(err, "Unable to list elasticquotas")
+		return nil
+	}
+	namespaceLabels, err := r.getNamespaceLabels(ctx, eqList.Items)
+	if err != nil {
+		log.FromContext(ctx).V(3).Error(err, "Unable to list namespaces")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	// All the elastic quotas selecting the pod are reconciled, as their overlap may change.
+	for _, eq := range util.GetElasticQuotasForPod(pod, namespaceLabels[pod.Namespace], eqList.Items) {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(eq)})
+		// A cycle still returns no ancestors, so that the quotas of the cycle aren't reconciled endlessly.
+		ancestors, _ := util.GetElasticQuotaAncestors(eq, eqList.Items)
+		for _, ancestor := range ancestors {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(ancestor)})
+		}
+	}
+	return requests
+}
+
+// podEventHandler enqueues the elastic quotas selecting a pod, before and after its update, and all the elastic quotas
+// at most once per allElasticQuotasPeriod, as their fair share, lent and available resources depend on every pod.
+func (r *ElasticQuotaReconciler) podEventHandler() handler.EventHandler {
+	enqueue := func(ctx context.Context, q workqueue.TypedRateLimitingInterface[reconcile.Request], objs ...client.Object) {
+		for _, obj := range objs {
+			if pod, ok := obj.(*v1.Pod); ok {
+				for _, req := range r.getElasticQuotaRequests(ctx, pod) {
+					q.Add(req)
+				}
+			}
+		}
+		// The delaying queue keeps the earliest of the pending additions.
+		q.AddAfter(allElasticQuotas, allElasticQuotasPeriod)
+	}
+	return handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.Object)
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.ObjectOld, e.ObjectNew)
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.Object)
+		},
+		GenericFunc: func(ctx context.Context, e event.GenericEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.Object)
+		},
+	}
+}
+
 func (r *ElasticQuotaReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.recorder = mgr.GetEventRecorderFor("ElasticQuotaController")
+	// The fair share, lent and available resources of every elastic quota depend on the usage of all the others.
+	enqueueAllElasticQuotas := handler.EnqueueRequestsFromMapFunc(func(context.Context, client.Object) []reconcile.Request {
+		return []reconcile.Request{allElasticQuotas}
+	})
+	// The status of the elastic quotas only depends on their spec, and their own status updates are ignored.
+	specChanged := builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{}))
 	return ctrl.NewControllerManagedBy(mgr).
-		Watches(&v1.Pod{}, &handler.EnqueueRequestForObject{}).
-		For(&schedv1alpha1.ElasticQuota{}).
+		Watches(&v1.Pod{}, r.podEventHandler()).
+		For(&schedv1alpha1.ElasticQuota{}, specChanged).
+		Watches(&schedv1alpha1.ElasticQuota{}, enqueueAllElasticQuotas, specChanged).
+		// The pods selected by namespace selectors change with the labels of the namespaces.
+		Watches(&v1.Namespace{}, enqueueAllElasticQuotas, builder.WithPredicates(predicate.LabelChangedPredicate{})).
 		WithOptions(controller.Options{MaxConcurrentReconciles: r.Workers}).
 		Complete(r)
 }
@@ -20,12 +20,15 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"reflect"
 	"testing"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -38,6 +41,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
 	testutil "sigs.k8s.io/scheduler-plugins/test/integration"
 )
 
@@ -45,6 +49,7 @@ func TestElasticQuotaController_Run(t *testing.T) {
 	ctx := context.TODO()
 	cases := []struct {
 		name          string
+		namespaces    []*v1.Namespace
 		elasticQuotas []*v1alpha1.ElasticQuota
 		pods          []*v1.Pod
 		want          []*v1alpha1.ElasticQuota
@@ -181,11 +186,162 @@ func TestElasticQuotaController_Run(t *testing.T) {
 					Used(testutil.MakeResourceList().CPU(0).Mem(0).GPU(0).Obj()).Obj(),
 			},
 		},
+		{
+			name: "usage aggregated up the hierarchy",
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t7-dept", "t7-eq-dept").
+					Min(testutil.MakeResourceList().CPU(10).Mem(20).Obj()).
+					Max(testutil.MakeResourceList().CPU(20).Mem(40).Obj()).Obj(),
+				testutil.MakeEQ("t7-team1", "t7-eq-team1").Parent("t7-dept", "t7-eq-dept").
+					Min(testutil.MakeResourceList().CPU(5).Mem(10).Obj()).
+					Max(testutil.MakeResourceList().CPU(10).Mem(20).Obj()).Obj(),
+				testutil.MakeEQ("t7-ns1", "t7-eq-ns1").Parent("t7-team1", "t7-eq-team1").
+					Min(testutil.MakeResourceList().CPU(2).Mem(4).Obj()).
+					Max(testutil.MakeResourceList().CPU(5).Mem(10).Obj()).Obj(),
+				testutil.MakeEQ("t7-team2", "t7-eq-team2").Parent("t7-dept", "t7-eq-dept").
+					Min(testutil.MakeResourceList().CPU(5).Mem(10).Obj()).
+					Max(testutil.MakeResourceList().CPU(10).Mem(20).Obj()).Obj(),
+			},
+			pods: []*v1.Pod{
+				testutil.MakePod("t7-ns1", "pod1").Phase(v1.PodRunning).
+					Container(testutil.MakeResourceList().CPU(1).Mem(2).Obj()).Obj(),
+				testutil.MakePod("t7-team1", "pod2").Phase(v1.PodRunning).
+					Container(testutil.MakeResourceList().CPU(2).Mem(1).Obj()).Obj(),
+				testutil.MakePod("t7-team2", "pod3").Phase(v1.PodRunning).
+					Container(testutil.MakeResourceList().CPU(3).Mem(3).Obj()).Obj(),
+			},
+			want: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t7-dept", "t7-eq-dept").
+					Used(testutil.MakeResourceList().CPU(6).Mem(6).Obj()).Obj(),
+				testutil.MakeEQ("t7-team1", "t7-eq-team1").
+					Used(testutil.MakeResourceList().CPU(3).Mem(3).Obj()).Obj(),
+				testutil.MakeEQ("t7-ns1", "t7-eq-ns1").
+					Used(testutil.MakeResourceList().CPU(1).Mem(2).Obj()).Obj(),
+				testutil.MakeEQ("t7-team2", "t7-eq-team2").
+					Used(testutil.MakeResourceList().CPU(3).Mem(3).Obj()).Obj(),
+			},
+		},
+		{
+			name: "parents forming a cycle",
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t8-ns1", "t8-eq1").Parent("t8-ns2", "t8-eq2").
+					Min(testutil.MakeResourceList().CPU(3).Mem(5).Obj()).Obj(),
+				testutil.MakeEQ("t8-ns2", "t8-eq2").Parent("t8-ns1", "t8-eq1").
+					Min(testutil.MakeResourceList().CPU(3).Mem(5).Obj()).Obj(),
+			},
+			pods: []*v1.Pod{
+				testutil.MakePod("t8-ns1", "pod1").Phase(v1.PodRunning).
+					Container(testutil.MakeResourceList().CPU(1).Mem(2).Obj()).Obj(),
+			},
+			want: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t8-ns1", "t8-eq1").
+					Used(testutil.MakeResourceList().CPU(1).Mem(2).Obj()).Obj(),
+				testutil.MakeEQ("t8-ns2", "t8-eq2").
+					Used(testutil.MakeResourceList().CPU(0).Mem(0).Obj()).Obj(),
+			},
+		},
+		{
+			name: "elastic quotas with selectors",
+			namespaces: []*v1.Namespace{
+				{ObjectMeta: metav1.ObjectMeta{Name: "t9-ns1", Labels: map[string]string{"org": "research"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "t9-ns2", Labels: map[string]string{"org": "research"}}},
+			},
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t9-ns1", "t9-eq-ns1").
+					Min(testutil.MakeResourceList().CPU(3).Mem(5).Obj()).Obj(),
+				testutil.MakeEQ("t9-ns1", "t9-eq-team-a").PodSelector(map[string]string{"team": "a"}).
+					Min(testutil.MakeResourceList().CPU(3).Mem(5).Obj()).Obj(),
+				testutil.MakeEQ("t9-ns3", "t9-eq-research").NamespaceSelector(map[string]string{"org": "research"}).
+					Min(testutil.MakeResourceList().CPU(3).Mem(5).Obj()).Obj(),
+			},
+			pods: []*v1.Pod{
+				testutil.MakePod("t9-ns1", "pod1").Phase(v1.PodRunning).Labels(map[string]string{"team": "a"}).
+					Container(testutil.MakeResourceList().CPU(1).Mem(2).Obj()).Obj(),
+				testutil.MakePod("t9-ns1", "pod2").Phase(v1.PodRunning).
+					Container(testutil.MakeResourceList().CPU(2).Mem(1).Obj()).Obj(),
+				testutil.MakePod("t9-ns2", "pod3").Phase(v1.PodRunning).
+					Container(testutil.MakeResourceList().CPU(3).Mem(3).Obj()).Obj(),
+			},
+			want: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t9-ns1", "t9-eq-team-a").
+					Used(testutil.MakeResourceList().CPU(1).Mem(2).Obj()).
+					Condition(v1alpha1.ElasticQuotaOverlapping, metav1.ConditionTrue).Obj(),
+				testutil.MakeEQ("t9-ns1", "t9-eq-ns1").
+					Used(testutil.MakeResourceList().CPU(2).Mem(1).Obj()).
+					Condition(v1alpha1.ElasticQuotaOverlapping, metav1.ConditionTrue).Obj(),
+				testutil.MakeEQ("t9-ns3", "t9-eq-research").
+					Used(testutil.MakeResourceList().CPU(3).Mem(3).Obj()).
+					Condition(v1alpha1.ElasticQuotaOverlapping, metav1.ConditionTrue).Obj(),
+			},
+		},
+		{
+			name: "elastic quotas without overlap",
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t10-ns1", "t10-eq-team-a").PodSelector(map[string]string{"team": "a"}).
+					Min(testutil.MakeResourceList().CPU(3).Mem(5).Obj()).Obj(),
+				testutil.MakeEQ("t10-ns1", "t10-eq-team-b").PodSelector(map[string]string{"team": "b"}).
+					Min(testutil.MakeResourceList().CPU(3).Mem(5).Obj()).Obj(),
+			},
+			pods: []*v1.Pod{
+				testutil.MakePod("t10-ns1", "pod1").Phase(v1.PodRunning).Labels(map[string]string{"team": "a"}).
+					Container(testutil.MakeResourceList().CPU(1).Mem(2).Obj()).Obj(),
+				testutil.MakePod("t10-ns1", "pod2").Phase(v1.PodRunning).Labels(map[string]string{"team": "b"}).
+					Container(testutil.MakeResourceList().CPU(2).Mem(1).Obj()).Obj(),
+			},
+			want: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t10-ns1", "t10-eq-team-a").
+					Used(testutil.MakeResourceList().CPU(1).Mem(2).Obj()).
+					Condition(v1alpha1.ElasticQuotaOverlapping, metav1.ConditionFalse).Obj(),
+				testutil.MakeEQ("t10-ns1", "t10-eq-team-b").
+					Used(testutil.MakeResourceList().CPU(2).Mem(1).Obj()).
+					Condition(v1alpha1.ElasticQuotaOverlapping, metav1.ConditionFalse).Obj(),
+			},
+		},
+		{
+			name: "borrowing elastic quota at max",
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t11-ns1", "t11-eq1").
+					Min(testutil.MakeResourceList().CPU(2).Obj()).
+					Max(testutil.MakeResourceList().CPU(4).Obj()).Obj(),
+				testutil.MakeEQ("t11-ns2", "t11-eq2").
+					Min(testutil.MakeResourceList().CPU(4).Obj()).Obj(),
+			},
+			pods: []*v1.Pod{
+				testutil.MakePod("t11-ns1", "pod1").Phase(v1.PodRunning).
+					Container(testutil.MakeResourceList().CPU(4).Obj()).Obj(),
+				testutil.MakePod("t11-ns1", "pod2").Phase(v1.PodPending).
+					Annotation(v1alpha1.BlockedByQuotaAnnotation, "OverMax").
+					Unschedulable("0/1 nodes are available: Pod t11-ns1/pod2 is rejected in PreFilter because ElasticQuota t11-ns1 is more than Max.").
+					Container(testutil.MakeResourceList().CPU(1).Obj()).Obj(),
+				testutil.MakePod("t11-ns2", "pod3").Phase(v1.PodPending).
+					Unschedulable("0/1 nodes are available: 1 Insufficient cpu.").
+					Container(testutil.MakeResourceList().CPU(8).Obj()).Obj(),
+				// Messages of other plugins mentioning ElasticQuotas are not rejections by quota
+				testutil.MakePod("t11-ns2", "pod4").Phase(v1.PodPending).
+					Unschedulable("0/1 nodes are available: 1 node(s) didn't match ElasticQuota-aware affinity.").
+					Container(testutil.MakeResourceList().CPU(1).Obj()).Obj(),
+			},
+			want: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t11-ns1", "t11-eq1").
+					Used(testutil.MakeResourceList().CPU(4).Obj()).PendingPods(1).
+					Condition(v1alpha1.ElasticQuotaOverMin, metav1.ConditionTrue).
+					Condition(v1alpha1.ElasticQuotaAtMax, metav1.ConditionTrue).Obj(),
+				testutil.MakeEQ("t11-ns2", "t11-eq2").
+					Used(testutil.MakeResourceList().CPU(0).Obj()).
+					Condition(v1alpha1.ElasticQuotaOverMin, metav1.ConditionFalse).
+					Condition(v1alpha1.ElasticQuotaAtMax, metav1.ConditionFalse).Obj(),
+			},
+		},
 	}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
 			controller, kClient := setUpEQ(ctx, t, c.elasticQuotas, c.pods)
+			for _, ns := range c.namespaces {
+				if err := kClient.Create(ctx, ns); err != nil {
+					t.Fatal("setup namespaces", err)
+				}
+			}
 			for _, pod := range c.pods {
 				if _, err := controller.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{
 					Namespace: pod.Namespace,
@@ -218,6 +374,14 @@ func TestElasticQuotaController_Run(t *testing.T) {
 					if !quota.Equals(eq.Status.Used, v.Status.Used) {
 						return false, fmt.Errorf("%v: want %v, got %v", c.name, v.Status.Used, eq.Status.Used)
 					}
+					if eq.Status.PendingPods != v.Status.PendingPods {
+						return false, fmt.Errorf("%v: want %v pending pods, got %v", c.name, v.Status.PendingPods, eq.Status.PendingPods)
+					}
+					for _, condition := range v.Status.Conditions {
+						if !meta.IsStatusConditionPresentAndEqual(eq.Status.Conditions, condition.Type, condition.Status) {
+							return false, fmt.Errorf("%v: want condition %v %v, got %v", c.name, condition.Type, condition.Status, eq.Status.Conditions)
+						}
+					}
 				}
 				return true, nil
 			})
@@ -229,6 +393,62 @@ func TestElasticQuotaController_Run(t *testing.T) {
 	}
 }
 
+func TestElasticQuotaController_ReconcileAll(t *testing.T) {
+	ctx := context.TODO()
+	eqs := []*v1alpha1.ElasticQuota{
+		testutil.MakeEQ("ns1", "eq1").
+			Min(testutil.MakeResourceList().CPU(4).Obj()).Obj(),
+		testutil.MakeEQ("ns2", "eq2").
+			Min(testutil.MakeResourceList().CPU(4).Obj()).Obj(),
+	}
+	pods := []*v1.Pod{
+		testutil.MakePod("ns1", "pod1").Phase(v1.PodRunning).
+			Container(testutil.MakeResourceList().CPU(6).Obj()).Obj(),
+		testutil.MakePod("ns2", "pod2").Phase(v1.PodRunning).
+			Container(testutil.MakeResourceList().CPU(2).Obj()).Obj(),
+	}
+	controller, kClient := setUpEQ(ctx, t, eqs, pods)
+
+	// The reconciliation of an elastic quota leaves the fields depending on the others unchanged.
+	if _, err := controller.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(eqs[0])}); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	eq := &v1alpha1.ElasticQuota{}
+	if err := kClient.Get(ctx, client.ObjectKeyFromObject(eqs[0]), eq); err != nil {
+		t.Fatal(err)
+	}
+	if !quota.Equals(eq.Status.Used, testutil.MakeResourceList().CPU(6).Obj()) || eq.Status.FairShare != nil {
+		t.Errorf("want used 6 CPUs and no fair share, got %v and %v", eq.Status.Used, eq.Status.FairShare)
+	}
+
+	if _, err := controller.Reconcile(ctx, allElasticQuotas); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+
+	// A single reconciliation updates the status of all the elastic quotas.
+	wantUsed := map[string]v1.ResourceList{
+		"eq1": testutil.MakeResourceList().CPU(6).Obj(),
+		"eq2": testutil.MakeResourceList().CPU(2).Obj(),
+	}
+	// The 2 CPUs of min unused by eq2 are lendable: eq1 is the only borrower, and eq2 shares them with eq1.
+	wantFairShare := map[string]v1.ResourceList{
+		"eq1": testutil.MakeResourceList().CPU(6).Obj(),
+		"eq2": testutil.MakeResourceList().CPU(5).Obj(),
+	}
+	for _, e := range eqs {
+		eq := &v1alpha1.ElasticQuota{}
+		if err := kClient.Get(ctx, client.ObjectKeyFromObject(e), eq); err != nil {
+			t.Fatal(err)
+		}
+		if !quota.Equals(eq.Status.Used, wantUsed[eq.Name]) {
+			t.Errorf("%v: want used %v, got %v", eq.Name, wantUsed[eq.Name], eq.Status.Used)
+		}
+		if !quota.Equals(eq.Status.FairShare, wantFairShare[eq.Name]) {
+			t.Errorf("%v: want fair share %v, got %v", eq.Name, wantFairShare[eq.Name], eq.Status.FairShare)
+		}
+	}
+}
+
 func setUpEQ(ctx context.Context,
 	t *testing.T,
 	eqs []*v1alpha1.ElasticQuota,
@@ -261,8 +481,105 @@ func setUpEQ(ctx context.Context,
 	controller := &ElasticQuotaReconciler{
 		Client:   client,
 		Scheme:   s,
-		recorder: record.NewFakeRecorder(3),
+		recorder: record.NewFakeRecorder(100),
 	}
 
 	return controller, client
 }
+
+func TestComputeFairShare(t *testing.T) {
+	eqs := []v1alpha1.ElasticQuota{
+		*testutil.MakeEQ("ns1", "eq1").
+			Min(testutil.MakeResourceList().CPU(4).Mem(8).Obj()).Obj(),
+		// eq2 borrows 2 CPUs.
+		*testutil.MakeEQ("ns2", "eq2").Weight(3).
+			Min(testutil.MakeResourceList().CPU(4).Obj()).Obj(),
+		*testutil.MakeEQ("ns3", "eq3").
+			Min(testutil.MakeResourceList().CPU(2).Obj()).Obj(),
+	}
+	quotaUsed := map[types.NamespacedName]v1.ResourceList{
+		{Namespace: "ns1", Name: "eq1"}: testutil.MakeResourceList().CPU(2).Obj(),
+		{Namespace: "ns2", Name: "eq2"}: testutil.MakeResourceList().CPU(6).Obj(),
+	}
+	// 4 CPUs and 8 memory are lendable: the 10 CPUs of min minus the 6 CPUs used within the min.
+	want := map[string]v1.ResourceList{
+		// eq1 shares the lendable resources with eq2, which borrows with a weight of 3.
+		"eq1": testutil.MakeResourceList().CPU(5).Mem(10).Obj(),
+		// eq2 is the only borrower.
+		"eq2": testutil.MakeResourceList().CPU(8).Mem(8).Obj(),
+		"eq3": testutil.MakeResourceList().CPU(3).Mem(2).Obj(),
+	}
+	for i := range eqs {
+		got := computeFairShare(&eqs[i], eqs, quotaUsed)
+		if !quota.Equals(got, want[eqs[i].Name]) {
+			t.Errorf("%v: want fair share %v, got %v", eqs[i].Name, want[eqs[i].Name], got)
+		}
+	}
+}
+
+func TestSelectedNamespaces(t *testing.T) {
+	eqs := []v1alpha1.ElasticQuota{
+		*testutil.MakeEQ("ns1", "eq1").Obj(),
+		*testutil.MakeEQ("ns2", "eq2").NamespaceSelector(map[string]string{"team": "a"}).Obj(),
+	}
+	var scopes []*util.ElasticQuotaScope
+	for i := range eqs {
+		scope, err := util.NewElasticQuotaScope(&eqs[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		scopes = append(scopes, scope)
+	}
+	namespaceLabels := map[string]labels.Set{
+		"ns1": nil,
+		"ns2": nil,
+		"ns3": {"team": "a"},
+		"ns4": {"team": "b"},
+	}
+	// eq1 selects the pods of its namespace and eq2 the pods of ns3 only.
+	if got, want := selectedNamespaces(scopes, namespaceLabels), []string{"ns1", "ns3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want namespaces %v, got %v", want, got)
+	}
+	if got, want := selectedNamespaces(scopes[1:], namespaceLabels), []string{"ns3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want namespaces %v, got %v", want, got)
+	}
+}
+
+func TestComputeLentAndAvailable(t *testing.T) {
+	eqs := []v1alpha1.ElasticQuota{
+		*testutil.MakeEQ("ns1", "eq1").
+			Min(testutil.MakeResourceList().CPU(4).Mem(8).Obj()).
+			Max(testutil.MakeResourceList().CPU(10).Mem(10).Obj()).Obj(),
+		// eq2 borrows 4 CPUs.
+		*testutil.MakeEQ("ns2", "eq2").
+			Min(testutil.MakeResourceList().CPU(4).Obj()).
+			Max(testutil.MakeResourceList().CPU(9).Obj()).Obj(),
+		*testutil.MakeEQ("ns3", "eq3").
+			Min(testutil.MakeResourceList().CPU(3).Obj()).Obj(),
+	}
+	quotaUsed := map[types.NamespacedName]v1.ResourceList{
+		{Namespace: "ns1", Name: "eq1"}: testutil.MakeResourceList().CPU(1).Obj(),
+		{Namespace: "ns2", Name: "eq2"}: testutil.MakeResourceList().CPU(8).Obj(),
+	}
+	// The 4 CPUs borrowed by eq2 are taken from the 6 CPUs of min unused by eq1 and eq3, in proportion.
+	wantLent := map[string]v1.ResourceList{
+		"eq1": testutil.MakeResourceList().CPU(2).Mem(0).Obj(),
+		"eq2": testutil.MakeResourceList().CPU(0).Obj(),
+		"eq3": testutil.MakeResourceList().CPU(2).Obj(),
+	}
+	// 2 CPUs and 8 memory of min are used by nobody, but eq1 and eq3 can reclaim their 3 CPUs of unused min,
+	// and eq2 is bounded by its max.
+	wantAvailable := map[string]v1.ResourceList{
+		"eq1": testutil.MakeResourceList().CPU(3).Mem(8).Obj(),
+		"eq2": testutil.MakeResourceList().CPU(1).Obj(),
+		"eq3": testutil.MakeResourceList().CPU(3).Obj(),
+	}
+	for i := range eqs {
+		if got := computeLent(&eqs[i], eqs, quotaUsed); !quota.Equals(got, wantLent[eqs[i].Name]) {
+			t.Errorf("%v: want lent %v, got %v", eqs[i].Name, wantLent[eqs[i].Name], got)
+		}
+		if got := computeAvailable(&eqs[i], eqs, quotaUsed); !quota.Equals(got, wantAvailable[eqs[i].Name]) {
+			t.Errorf("%v: want available %v, got %v", eqs[i].Name, wantAvailable[eqs[i].Name], got)
+		}
+	}
+}
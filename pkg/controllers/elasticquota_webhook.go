@@ -0,0 +1,179 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	quota "k8s.io/apiserver/pkg/quota/v1"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	schedv1alpha1 "sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// ElasticQuotaValidatorPath is the path serving the ElasticQuotaValidator webhook.
+const ElasticQuotaValidatorPath = "/validate-scheduling-x-k8s-io-v1alpha1-elasticquota"
+
+// +kubebuilder:webhook:path=/validate-scheduling-x-k8s-io-v1alpha1-elasticquota,mutating=false,failurePolicy=fail,sideEffects=None,groups=scheduling.x-k8s.io,resources=elasticquotas,verbs=create;update,versions=v1alpha1,name=elasticquota-validator.scheduling.x-k8s.io,admissionReviewVersions=v1
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+
+// ElasticQuotaValidator is a validating admission webhook which denies the elastic quotas
+// CapacityScheduling can't honor.
+type ElasticQuotaValidator struct {
+	Client  client.Client
+	Decoder admission.Decoder
+	// EnforceMinSum denies the elastic quotas growing the sum of the min of the root elastic quotas
+	// beyond the allocatable resources of the cluster.
+	EnforceMinSum bool
+}
+
+var _ admission.Handler = &ElasticQuotaValidator{}
+
+// Handle denies the elastic quota if its min exceeds its max, its selectors or parent are invalid, or,
+// if EnforceMinSum is set, the cluster can't guarantee its min.
+func (v *ElasticQuotaValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	eq := &schedv1alpha1.ElasticQuota{}
+	if err := v.Decoder.Decode(req, eq); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	// The namespace of the elastic quota may not be set yet upon creation.
+	eq.Namespace = req.Namespace
+
+	allErrs := validateElasticQuotaSpec(eq)
+	if len(allErrs) == 0 {
+		eqList := &schedv1alpha1.ElasticQuotaList{}
+		if err := v.Client.List(ctx, eqList); err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+		eqs := make([]schedv1alpha1.ElasticQuota, 0, len(eqList.Items)+1)
+		for i := range eqList.Items {
+			if eqList.Items[i].Namespace != eq.Namespace || eqList.Items[i].Name != eq.Name {
+				eqs = append(eqs, eqList.Items[i])
+			}
+		}
+		eqs = append(eqs, *eq)
+
+		ancestors, err := util.GetElasticQuotaAncestors(eq, eqs)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "parent"), eq.Spec.Parent, err.Error()))
+		} else if len(ancestors) == 0 && v.EnforceMinSum {
+			old := &schedv1alpha1.ElasticQuota{}
+			if req.Operation == admissionv1.Update {
+				if err := v.Decoder.DecodeRaw(req.OldObject, old); err != nil {
+					return admission.Errored(http.StatusBadRequest, err)
+				}
+			}
+			errs, err := v.validateMinSum(ctx, eq, old, eqs)
+			if err != nil {
+				return admission.Errored(http.StatusInternalServerError, err)
+			}
+			allErrs = append(allErrs, errs...)
+		}
+	}
+
+	if len(allErrs) > 0 {
+		return admission.Denied(allErrs.ToAggregate().Error())
+	}
+	return admission.Allowed("")
+}
+
+// validateElasticQuotaSpec validates the spec of the given elastic quota on its own.
+func validateElasticQuotaSpec(eq *schedv1alpha1.ElasticQuota) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+	minPath, maxPath := specPath.Child("min"), specPath.Child("max")
+	for _, name := range sortedResourceNames(eq.Spec.Min) {
+		min := eq.Spec.Min[name]
+		if min.Sign() < 0 {
+			allErrs = append(allErrs, field.Invalid(minPath.Key(string(name)), min.String(), "must be greater than or equal to 0"))
+		} else if max, ok := eq.Spec.Max[name]; ok && min.Cmp(max) > 0 {
+			allErrs = append(allErrs, field.Invalid(minPath.Key(string(name)), min.String(),
+				fmt.Sprintf("must be less than or equal to %v (%v)", maxPath.Key(string(name)), max.String())))
+		}
+	}
+	for _, name := range sortedResourceNames(eq.Spec.Max) {
+		if max := eq.Spec.Max[name]; max.Sign() < 0 {
+			allErrs = append(allErrs, field.Invalid(maxPath.Key(string(name)), max.String(), "must be greater than or equal to 0"))
+		}
+	}
+
+	opts := metav1validation.LabelSelectorValidationOptions{}
+	if eq.Spec.NamespaceSelector != nil {
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(eq.Spec.NamespaceSelector, opts, specPath.Child("namespaceSelector"))...)
+	}
+	if eq.Spec.PodSelector != nil {
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(eq.Spec.PodSelector, opts, specPath.Child("podSelector"))...)
+	}
+	return allErrs
+}
+
+// validateMinSum validates that the sum of the min of the root elastic quotas eqs, including the given one, doesn't
+// exceed the allocatable resources of the cluster. Only the resources whose min grows from the old elastic quota are
+// validated, so that the elastic quotas can still be updated when the cluster shrinks.
+func (v *ElasticQuotaValidator) validateMinSum(ctx context.Context, eq, old *schedv1alpha1.ElasticQuota, eqs []schedv1alpha1.ElasticQuota) (field.ErrorList, error) {
+	nodeList := &v1.NodeList{}
+	if err := v.Client.List(ctx, nodeList); err != nil {
+		return nil, err
+	}
+	allocatable := v1.ResourceList{}
+	for i := range nodeList.Items {
+		allocatable = quota.Add(allocatable, nodeList.Items[i].Status.Allocatable)
+	}
+	minSum := v1.ResourceList{}
+	for i := range eqs {
+		if ancestors, err := util.GetElasticQuotaAncestors(&eqs[i], eqs); err == nil && len(ancestors) == 0 {
+			minSum = quota.Add(minSum, eqs[i].Spec.Min)
+		}
+	}
+
+	var allErrs field.ErrorList
+	minPath := field.NewPath("spec", "min")
+	for _, name := range sortedResourceNames(eq.Spec.Min) {
+		min, oldMin := eq.Spec.Min[name], old.Spec.Min[name]
+		sum, available := minSum[name], allocatable[name]
+		if min.Cmp(oldMin) > 0 && sum.Cmp(available) > 0 {
+			allErrs = append(allErrs, field.Forbidden(minPath.Key(string(name)),
+				fmt.Sprintf("the min of the root elastic quotas would sum up to %v, more than the %v allocatable in the cluster", sum.String(), available.String())))
+		}
+	}
+	return allErrs, nil
+}
+
+// sortedResourceNames returns the names of the given resources in order, for stable denial messages.
+func sortedResourceNames(resources v1.ResourceList) []v1.ResourceName {
+	names := quota.ResourceNames(resources)
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// SetupWithManager registers the webhook in the webhook server of the Manager.
+func (v *ElasticQuotaValidator) SetupWithManager(mgr ctrl.Manager) {
+	v.Client = mgr.GetClient()
+	v.Decoder = admission.NewDecoder(mgr.GetScheme())
+	mgr.GetWebhookServer().Register(ElasticQuotaValidatorPath, &webhook.Admission{Handler: v})
+}
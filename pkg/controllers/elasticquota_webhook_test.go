@@ -0,0 +1,153 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	testutil "sigs.k8s.io/scheduler-plugins/test/integration"
+)
+
+func TestElasticQuotaValidator(t *testing.T) {
+	ctx := context.TODO()
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node"},
+		Status:     v1.NodeStatus{Allocatable: testutil.MakeResourceList().CPU(8).Mem(16).Obj()},
+	}
+	existing := testutil.MakeEQ("ns1", "eq1").Min(testutil.MakeResourceList().CPU(4).Obj()).Obj()
+	cases := []struct {
+		name          string
+		eq            *v1alpha1.ElasticQuota
+		old           *v1alpha1.ElasticQuota
+		enforceMinSum bool
+		wantDenied    []string
+	}{
+		{
+			name: "valid elastic quota",
+			eq: testutil.MakeEQ("ns2", "eq2").
+				Min(testutil.MakeResourceList().CPU(2).Obj()).
+				Max(testutil.MakeResourceList().CPU(4).Obj()).Obj(),
+		},
+		{
+			name: "min more than max",
+			eq: testutil.MakeEQ("ns2", "eq2").
+				Min(testutil.MakeResourceList().CPU(4).Mem(8).Obj()).
+				Max(testutil.MakeResourceList().CPU(2).Mem(8).Obj()).Obj(),
+			wantDenied: []string{"spec.min[cpu]: Invalid value: \"4\": must be less than or equal to spec.max[cpu] (2)"},
+		},
+		{
+			name: "invalid pod selector",
+			eq: testutil.MakeEQ("ns2", "eq2").
+				PodSelector(map[string]string{"team": "a b"}).Obj(),
+			wantDenied: []string{"spec.podSelector.matchLabels"},
+		},
+		{
+			name:       "parent forming a cycle",
+			eq:         testutil.MakeEQ("ns2", "eq2").Parent("ns2", "eq2").Obj(),
+			wantDenied: []string{"spec.parent: Invalid value", "forms a cycle"},
+		},
+		{
+			name: "min sum more than allocatable",
+			eq: testutil.MakeEQ("ns2", "eq2").
+				Min(testutil.MakeResourceList().CPU(6).Mem(8).Obj()).Obj(),
+			enforceMinSum: true,
+			wantDenied:    []string{"spec.min[cpu]: Forbidden: the min of the root elastic quotas would sum up to 10, more than the 8 allocatable in the cluster"},
+		},
+		{
+			name: "min sum more than allocatable without enforcement",
+			eq: testutil.MakeEQ("ns2", "eq2").
+				Min(testutil.MakeResourceList().CPU(6).Obj()).Obj(),
+		},
+		{
+			name: "min sum more than allocatable for a child",
+			eq: testutil.MakeEQ("ns2", "eq2").Parent("ns1", "eq1").
+				Min(testutil.MakeResourceList().CPU(6).Obj()).Obj(),
+			enforceMinSum: true,
+		},
+		{
+			name: "min not growing while the min sum is more than allocatable",
+			eq: testutil.MakeEQ("ns2", "eq2").
+				Min(testutil.MakeResourceList().CPU(6).Obj()).
+				Max(testutil.MakeResourceList().CPU(8).Obj()).Obj(),
+			old: testutil.MakeEQ("ns2", "eq2").
+				Min(testutil.MakeResourceList().CPU(6).Obj()).Obj(),
+			enforceMinSum: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newOwnerTestScheme(t)
+			validator := &ElasticQuotaValidator{
+				Client:        fake.NewClientBuilder().WithScheme(s).WithObjects(node, existing.DeepCopy()).Build(),
+				Decoder:       admission.NewDecoder(s),
+				EnforceMinSum: c.enforceMinSum,
+			}
+			req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Namespace: c.eq.Namespace,
+				Object:    runtime.RawExtension{Raw: marshal(t, c.eq)},
+			}}
+			if c.old != nil {
+				req.Operation = admissionv1.Update
+				req.OldObject = runtime.RawExtension{Raw: marshal(t, c.old)}
+			}
+			resp := validator.Handle(ctx, req)
+			checkAdmissionResponse(t, resp, c.wantDenied)
+		})
+	}
+}
+
+func marshal(t *testing.T, obj client.Object) []byte {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+// checkAdmissionResponse checks that the response allows the request, or denies it with a message containing
+// all the wanted substrings.
+func checkAdmissionResponse(t *testing.T, resp admission.Response, wantDenied []string) {
+	t.Helper()
+	if len(wantDenied) == 0 {
+		if !resp.Allowed {
+			t.Errorf("want the request to be allowed, got %v", resp.Result.Message)
+		}
+		return
+	}
+	if resp.Allowed {
+		t.Fatalf("want the request to be denied with %q", wantDenied)
+	}
+	for _, want := range wantDenied {
+		if !strings.Contains(resp.Result.Message, want) {
+			t.Errorf("want the denial message to contain %q, got %q", want, resp.Result.Message)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/networkaware/prober"
+	networkawareutil "sigs.k8s.io/scheduler-plugins/pkg/networkaware/util"
+
+	ntv1alpha1 "github.com/diktyo-io/networktopology-api/pkg/apis/networktopology/v1alpha1"
+)
+
+const (
+	// DefaultNetworkCostSyncPeriod is the default period the network costs are updated at.
+	DefaultNetworkCostSyncPeriod = time.Minute
+	// DefaultNetworkCostSmoothingFactor is the default weight of the latest measurements in the network costs.
+	DefaultNetworkCostSmoothingFactor = 0.5
+)
+
+// NetworkTopologyReconciler maintains the NetperfCosts weights of NetworkTopology CRs from the latencies measured
+// between the nodes by per-node probers.
+//
+// The probers publish their results in ConfigMaps labeled with the configmapName of the NetworkTopology CR, in its
+// namespace. Every sync period, the latencies between the nodes of each pair of topology domains are averaged
+// into a cost in milliseconds, smoothed with the previous cost through an exponentially weighted moving average.
+type NetworkTopologyReconciler struct {
+	recorder record.EventRecorder
+
+	client.Client
+	Scheme  *runtime.Scheme
+	Workers int
+	// TopologyKeys are the node label keys of the topology levels costs are measured between.
+	TopologyKeys []string
+	// SmoothingFactor is the weight of the latest measurements in the costs, in (0, 1]. 1 disables smoothing.
+	SmoothingFactor float64
+	// SyncPeriod is the period the costs are updated at.
+	SyncPeriod time.Duration
+	// ProbeTTL is the age beyond which probe results are ignored. It defaults to five sync periods.
+	ProbeTTL time.Duration
+}
+
+// +kubebuilder:rbac:groups=networktopology.diktyo.x-k8s.io,resources=networktopologies,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+func (r *NetworkTopologyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	log.Info("reconciling")
+	nt := &ntv1alpha1.NetworkTopology{}
+	if err := r.Get(ctx, req.NamespacedName, nt); err != nil {
+		if apierrs.IsNotFound(err) {
+			log.V(5).Info("no networktopology found")
+			return ctrl.Result{}, nil
+		}
+		log.V(3).Error(err, "Unable to retrieve networktopology")
+		return ctrl.Result{}, err
+	}
+	if nt.Spec.ConfigmapName == "" {
+		log.V(5).Info("networktopology has no configmapName, skip")
+		return ctrl.Result{}, nil
+	}
+
+	// The costs are smoothed once per sync period, whatever the updates of the networktopology.
+	now := time.Now()
+	if calculated := nt.Status.WeightCalculationTime; !calculated.IsZero() {
+		if elapsed := now.Sub(calculated.Time); elapsed >= 0 && elapsed < r.SyncPeriod {
+			return ctrl.Result{RequeueAfter: r.SyncPeriod - elapsed}, nil
+		}
+	}
+
+	nodeList := &v1.NodeList{}
+	if err := r.List(ctx, nodeList); err != nil {
+		log.V(3).Error(err, "Unable to list nodes")
+		return ctrl.Result{}, err
+	}
+	cmList := &v1.ConfigMapList{}
+	if err := r.List(ctx, cmList, client.InNamespace(nt.Namespace), client.MatchingLabels{prober.ProbeResultsLabel: nt.Spec.ConfigmapName}); err != nil {
+		log.V(3).Error(err, "Unable to list probe results")
+		return ctrl.Result{}, err
+	}
+
+	nodeDomains := make(map[string][]string, len(nodeList.Items))
+	for i := range nodeList.Items {
+		nodeDomains[nodeList.Items[i].Name] = networkawareutil.GetNodeDomains(&nodeList.Items[i], r.TopologyKeys)
+	}
+	latencies := make([]map[networkawareutil.CostKey][]time.Duration, len(r.TopologyKeys))
+	for i := range latencies {
+		latencies[i] = make(map[networkawareutil.CostKey][]time.Duration)
+	}
+	for i := range cmList.Items {
+		cm := &cmList.Items[i]
+		origin, probeTime, results, err := prober.ParseResults(cm)
+		if err != nil {
+			r.recorder.Event(nt, v1.EventTypeWarning, "InvalidProbeResults", fmt.Sprintf("ConfigMap %v: %v", cm.Name, err))
+			continue
+		}
+		if now.Sub(probeTime) > r.ProbeTTL {
+			log.V(4).Info("probe results are stale, skip", "configmap", cm.Name, "probeTime", probeTime)
+			continue
+		}
+		r.addLatencies(latencies, nodeDomains, origin, results)
+	}
+
+	newNT := nt.DeepCopy()
+	setWeight(newNT, ntv1alpha1.WeightInfo{
+		Name:         ntv1alpha1.NetworkTopologyNetperfCosts,
+		TopologyList: computeNetperfCosts(r.TopologyKeys, latencies, getWeight(nt, ntv1alpha1.NetworkTopologyNetperfCosts), nodeDomains, r.SmoothingFactor),
+	})
+	newNT.Status.NodeCount = int64(len(nodeList.Items))
+	newNT.Status.WeightCalculationTime = metav1.NewTime(now)
+	if err := r.Update(ctx, newNT); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.recorder.Event(nt, v1.EventTypeNormal, "Synced", fmt.Sprintf("Network costs of %s synced from %d probe results", req.NamespacedName, len(cmList.Items)))
+	return ctrl.Result{RequeueAfter: r.SyncPeriod}, nil
+}
+
+// addLatencies adds the latencies measured from the origin node to the samples of each pair of topology domains
+// the origin and target nodes belong to, identified by their paths as the NetworkOverhead plugin looks them up.
+// The costs between narrower domains are measured across broader domains as well, so that the cost lookup can fall
+// through to them.
+func (r *NetworkTopologyReconciler) addLatencies(latencies []map[networkawareutil.CostKey][]time.Duration, nodeDomains map[string][]string, origin string, results map[string]time.Duration) {
+	originDomains, ok := nodeDomains[origin]
+	if !ok {
+		return
+	}
+	for target, latency := range results {
+		targetDomains, ok := nodeDomains[target]
+		if !ok {
+			continue
+		}
+		for level := range originDomains {
+			if originDomains[level] == "" || targetDomains[level] == "" {
+				continue
+			}
+			key := networkawareutil.GetDomainsKey(originDomains, targetDomains, level, r.TopologyKeys)
+			if key.Origin == key.Destination {
+				continue
+			}
+			latencies[level][key] = append(latencies[level][key], latency)
+		}
+	}
+}
+
+// computeNetperfCosts returns the costs of each topology level, sorted by topology key, origin and destination as the
+// NetworkOverhead plugin expects, with the domains identified by their paths. The cost of a pair of domains is the average latency in milliseconds of its
+// samples, smoothed with its previous cost. Pairs without samples keep their previous cost as long as both domains
+// exist, and bandwidth capacities are kept.
+func computeNetperfCosts(topologyKeys []string, latencies []map[networkawareutil.CostKey][]time.Duration, previous *ntv1alpha1.WeightInfo,
+	nodeDomains map[string][]string, smoothingFactor float64) ntv1alpha1.TopologyList {
+	topologyList := ntv1alpha1.TopologyList{}
+	for level, key := range topologyKeys {
+		domains := sets.New[string]()
+		for _, d := range nodeDomains {
+			if d[level] != "" {
+				domains.Insert(networkawareutil.GetDomainPath(d, level))
+			}
+		}
+
+		previousCosts := make(map[networkawareutil.CostKey]ntv1alpha1.CostInfo)
+		if previous != nil {
+			for _, t := range previous.TopologyList {
+				if string(t.TopologyKey) != key {
+					continue
+				}
+				for _, o := range t.OriginList {
+					for _, c := range o.CostList {
+						previousCosts[networkawareutil.CostKey{TopologyKey: key, Origin: o.Origin, Destination: c.Destination}] = c
+					}
+				}
+			}
+		}
+
+		costs := make(map[string]ntv1alpha1.CostList)
+		for pair, c := range previousCosts {
+			if _, measured := latencies[level][pair]; !measured && domains.Has(pair.Origin) && domains.Has(pair.Destination) {
+				costs[pair.Origin] = append(costs[pair.Origin], c)
+			}
+		}
+		for pair, samples := range latencies[level] {
+			var sum time.Duration
+			for _, s := range samples {
+				sum += s
+			}
+			cost := float64(sum.Microseconds()) / float64(len(samples)) / 1000
+			c, ok := previousCosts[pair]
+			if ok {
+				cost = smoothingFactor*cost + (1-smoothingFactor)*float64(c.NetworkCost)
+			}
+			c.Destination = pair.Destination
+			c.NetworkCost = int64(math.Max(1, math.Round(cost)))
+			costs[pair.Origin] = append(costs[pair.Origin], c)
+		}
+		if len(costs) == 0 {
+			continue
+		}
+
+		originList := ntv1alpha1.OriginList{}
+		for origin, costList := range costs {
+			sort.Sort(networkawareutil.ByDestination(costList))
+			originList = append(originList, ntv1alpha1.OriginInfo{Origin: origin, CostList: costList})
+		}
+		sort.Sort(networkawareutil.ByOrigin(originList))
+		topologyList = append(topologyList, ntv1alpha1.TopologyInfo{TopologyKey: ntv1alpha1.TopologyKey(key), OriginList: originList})
+	}
+	sort.Sort(networkawareutil.ByTopologyKey(topologyList))
+	return topologyList
+}
+
+// getWeight returns the weights of the given name, or nil.
+func getWeight(nt *ntv1alpha1.NetworkTopology, name string) *ntv1alpha1.WeightInfo {
+	for i := range nt.Spec.Weights {
+		if nt.Spec.Weights[i].Name == name {
+			return &nt.Spec.Weights[i]
+		}
+	}
+	return nil
+}
+
+// setWeight sets the weights of the same name as the given ones.
+func setWeight(nt *ntv1alpha1.NetworkTopology, weight ntv1alpha1.WeightInfo) {
+	if w := getWeight(nt, weight.Name); w != nil {
+		*w = weight
+		return
+	}
+	nt.Spec.Weights = append(nt.Spec.Weights, weight)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NetworkTopologyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("NetworkTopologyController")
+	if r.SyncPeriod <= 0 {
+		r.SyncPeriod = DefaultNetworkCostSyncPeriod
+	}
+	if r.ProbeTTL <= 0 {
+		r.ProbeTTL = 5 * r.SyncPeriod
+	}
+	if r.SmoothingFactor <= 0 || r.SmoothingFactor > 1 {
+		return fmt.Errorf("network cost smoothing factor must be in (0, 1], got %v", r.SmoothingFactor)
+	}
+	// The costs are updated periodically rather than upon every probe result, so that they are smoothed over time.
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&ntv1alpha1.NetworkTopology{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.Workers}).
+		Complete(r)
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/networkaware/prober"
+
+	ntv1alpha1 "github.com/diktyo-io/networktopology-api/pkg/apis/networktopology/v1alpha1"
+)
+
+func makeTopologyNode(name, region, zone string) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{v1.LabelTopologyRegion: region, v1.LabelTopologyZone: zone},
+		},
+	}
+}
+
+func makeCosts(origin string, costs ...ntv1alpha1.CostInfo) ntv1alpha1.OriginInfo {
+	return ntv1alpha1.OriginInfo{Origin: origin, CostList: costs}
+}
+
+func TestNetworkTopologyReconcile(t *testing.T) {
+	ctx := context.TODO()
+	s := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(s))
+	utilruntime.Must(ntv1alpha1.AddToScheme(s))
+
+	nodes := []*v1.Node{
+		makeTopologyNode("n1", "r1", "z1"),
+		makeTopologyNode("n2", "r1", "z2"),
+		makeTopologyNode("n3", "r2", "z3"),
+	}
+	nt := &ntv1alpha1.NetworkTopology{
+		ObjectMeta: metav1.ObjectMeta{Name: "nt-test", Namespace: "default"},
+		Spec: ntv1alpha1.NetworkTopologySpec{
+			ConfigmapName: "netperf-metrics",
+			Weights: ntv1alpha1.WeightList{
+				{
+					Name: "UserDefined",
+					TopologyList: ntv1alpha1.TopologyList{{
+						TopologyKey: ntv1alpha1.NetworkTopologyRegion,
+						OriginList:  ntv1alpha1.OriginList{makeCosts("r1", ntv1alpha1.CostInfo{Destination: "r2", NetworkCost: 50})},
+					}},
+				},
+				{
+					Name: ntv1alpha1.NetworkTopologyNetperfCosts,
+					TopologyList: ntv1alpha1.TopologyList{{
+						TopologyKey: ntv1alpha1.NetworkTopologyRegion,
+						OriginList: ntv1alpha1.OriginList{
+							// Pairs of domains not measured anymore are dropped.
+							makeCosts("r0", ntv1alpha1.CostInfo{Destination: "r1", NetworkCost: 5}),
+							makeCosts("r1", ntv1alpha1.CostInfo{Destination: "r2", NetworkCost: 1, BandwidthCapacity: resource.MustParse("10Gi")}),
+						},
+					}},
+				},
+			},
+		},
+	}
+
+	objs := []client.Object{nt}
+	for _, n := range nodes {
+		objs = append(objs, n)
+	}
+	// Stale results of a previous prober are ignored.
+	objs = append(objs, &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "netperf-metrics-stale",
+			Namespace: "default",
+			Labels:    map[string]string{prober.ProbeResultsLabel: "netperf-metrics"},
+			Annotations: map[string]string{
+				prober.ProbeOriginAnnotation: "n1",
+				prober.ProbeTimeAnnotation:   time.Now().Add(-time.Hour).UTC().Format(time.RFC3339),
+			},
+		},
+		Data: map[string]string{"n2": "100ms", "n3": "100ms"},
+	})
+	kClient := fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+
+	latencies := map[string]map[string]time.Duration{
+		"n1": {"n2": 2 * time.Millisecond, "n3": 10 * time.Millisecond},
+		"n2": {"n1": 2 * time.Millisecond, "n3": 12 * time.Millisecond},
+		// n2 is unreachable from n3.
+		"n3": {"n1": 10 * time.Millisecond},
+	}
+	report := func() {
+		for origin, l := range latencies {
+			reporter := &prober.Reporter{
+				Client:      kClient,
+				Prober:      &prober.FakeProber{Latencies: l},
+				NodeName:    origin,
+				Namespace:   "default",
+				ResultsName: "netperf-metrics",
+			}
+			if err := reporter.Report(ctx); err != nil {
+				t.Fatalf("report: %v", err)
+			}
+		}
+	}
+
+	controller := &NetworkTopologyReconciler{
+		Client:          kClient,
+		Scheme:          s,
+		recorder:        record.NewFakeRecorder(10),
+		TopologyKeys:    []string{v1.LabelTopologyRegion, v1.LabelTopologyZone},
+		SmoothingFactor: 0.5,
+		SyncPeriod:      time.Minute,
+		ProbeTTL:        5 * time.Minute,
+	}
+	reconcile := func() ctrl.Result {
+		result, err := controller.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(nt)})
+		if err != nil {
+			t.Fatalf("reconcile: %v", err)
+		}
+		if err := kClient.Get(ctx, client.ObjectKeyFromObject(nt), nt); err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+	checkCosts := func(want ntv1alpha1.TopologyList) {
+		t.Helper()
+		if nt.Spec.Weights[0].Name != "UserDefined" || nt.Spec.Weights[0].TopologyList[0].OriginList[0].CostList[0].NetworkCost != 50 {
+			t.Errorf("unexpected change of the user defined costs: %v", nt.Spec.Weights[0])
+		}
+		if diff := cmp.Diff(want, getWeight(nt, ntv1alpha1.NetworkTopologyNetperfCosts).TopologyList); diff != "" {
+			t.Errorf("unexpected costs (-want,+got):\n%s", diff)
+		}
+	}
+
+	report()
+	if result := reconcile(); result.RequeueAfter != time.Minute {
+		t.Errorf("want requeue after %v, got %v", time.Minute, result.RequeueAfter)
+	}
+	if nt.Status.NodeCount != 3 || nt.Status.WeightCalculationTime.IsZero() {
+		t.Errorf("unexpected status: %v", nt.Status)
+	}
+	checkCosts(ntv1alpha1.TopologyList{
+		{
+			TopologyKey: ntv1alpha1.NetworkTopologyRegion,
+			OriginList: ntv1alpha1.OriginList{
+				// (1 + (10 + 12) / 2) / 2
+				makeCosts("r1", ntv1alpha1.CostInfo{Destination: "r2", NetworkCost: 6, BandwidthCapacity: resource.MustParse("10Gi")}),
+				makeCosts("r2", ntv1alpha1.CostInfo{Destination: "r1", NetworkCost: 10}),
+			},
+		},
+		{
+			TopologyKey: ntv1alpha1.NetworkTopologyZone,
+			// Zones are identified by their path, as their names may be reused across regions.
+			OriginList: ntv1alpha1.OriginList{
+				makeCosts("r1/z1", ntv1alpha1.CostInfo{Destination: "r1/z2", NetworkCost: 2}, ntv1alpha1.CostInfo{Destination: "r2/z3", NetworkCost: 10}),
+				makeCosts("r1/z2", ntv1alpha1.CostInfo{Destination: "r1/z1", NetworkCost: 2}, ntv1alpha1.CostInfo{Destination: "r2/z3", NetworkCost: 12}),
+				makeCosts("r2/z3", ntv1alpha1.CostInfo{Destination: "r1/z1", NetworkCost: 10}),
+			},
+		},
+	})
+
+	// The costs are not smoothed again before the end of the sync period.
+	latencies["n1"]["n3"] = 30 * time.Millisecond
+	report()
+	if result := reconcile(); result.RequeueAfter <= 0 || result.RequeueAfter > time.Minute {
+		t.Errorf("want requeue within %v, got %v", time.Minute, result.RequeueAfter)
+	}
+	if got := getWeight(nt, ntv1alpha1.NetworkTopologyNetperfCosts).TopologyList[1].OriginList[0].CostList[1].NetworkCost; got != 10 {
+		t.Errorf("want unchanged cost 10 within the sync period, got %v", got)
+	}
+
+	nt.Status.WeightCalculationTime = metav1.NewTime(nt.Status.WeightCalculationTime.Add(-time.Minute))
+	if err := kClient.Update(ctx, nt); err != nil {
+		t.Fatal(err)
+	}
+	reconcile()
+	checkCosts(ntv1alpha1.TopologyList{
+		{
+			TopologyKey: ntv1alpha1.NetworkTopologyRegion,
+			OriginList: ntv1alpha1.OriginList{
+				// (6 + (30 + 12) / 2) / 2
+				makeCosts("r1", ntv1alpha1.CostInfo{Destination: "r2", NetworkCost: 14, BandwidthCapacity: resource.MustParse("10Gi")}),
+				makeCosts("r2", ntv1alpha1.CostInfo{Destination: "r1", NetworkCost: 10}),
+			},
+		},
+		{
+			TopologyKey: ntv1alpha1.NetworkTopologyZone,
+			OriginList: ntv1alpha1.OriginList{
+				makeCosts("r1/z1", ntv1alpha1.CostInfo{Destination: "r1/z2", NetworkCost: 2}, ntv1alpha1.CostInfo{Destination: "r2/z3", NetworkCost: 20}),
+				makeCosts("r1/z2", ntv1alpha1.CostInfo{Destination: "r1/z1", NetworkCost: 2}, ntv1alpha1.CostInfo{Destination: "r2/z3", NetworkCost: 12}),
+				makeCosts("r2/z3", ntv1alpha1.CostInfo{Destination: "r1/z1", NetworkCost: 10}),
+			},
+		},
+	})
+}
@@ -26,10 +26,13 @@ import (
 	"github.com/go-logr/logr"
 	v1 "k8s.io/api/core/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -40,6 +43,9 @@ import (
 	"sigs.k8s.io/scheduler-plugins/pkg/util"
 )
 
+// fieldManager is the prefix of the field managers applying the pod group conditions set by the controller.
+const fieldManager = "podgroup-controller"
+
 // PodGroupReconciler reconciles a PodGroup object
 type PodGroupReconciler struct {
 	log      logr.Logger
@@ -53,6 +59,7 @@ type PodGroupReconciler struct {
 // +kubebuilder:rbac:groups=scheduling.x-k8s.io,resources=podgroups,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=scheduling.x-k8s.io,resources=podgroups/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=scheduling.x-k8s.io,resources=podgroups/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -76,17 +83,8 @@ func (r *PodGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return ctrl.Result{}, err
 	}
 
-	if pg.Status.Phase == schedv1alpha1.PodGroupFinished ||
-		pg.Status.Phase == schedv1alpha1.PodGroupFailed {
-		return ctrl.Result{}, nil
-	}
-	// If startScheduleTime - createTime > 2days,
-	// do not reconcile again because pod may have been GCed
-	if (pg.Status.Phase == schedv1alpha1.PodGroupScheduling || pg.Status.Phase == schedv1alpha1.PodGroupPending) && pg.Status.Running == 0 &&
-		pg.Status.ScheduleStartTime.Sub(pg.CreationTimestamp.Time) > 48*time.Hour {
-		r.recorder.Event(pg, v1.EventTypeWarning,
-			"Timeout", "schedule time longer than 48 hours")
-		return ctrl.Result{}, nil
+	if isPodGroupCompleted(pg) {
+		return r.cleanupPodGroup(ctx, pg)
 	}
 
 	podList := &v1.PodList{}
@@ -101,26 +99,53 @@ func (r *PodGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	pods := podList.Items
 
 	pgCopy := pg.DeepCopy()
+	var requeueAfter time.Duration
 	switch pgCopy.Status.Phase {
 	case "":
 		pgCopy.Status.Phase = schedv1alpha1.PodGroupPending
 	case schedv1alpha1.PodGroupPending:
-		if len(pods) >= int(pg.Spec.MinMember) {
+		pgCopy.Status.Roles = getRoleStats(pg, pods)
+		if len(pods) >= int(pg.Spec.MinMember) && rolesSatisfied(pg, pgCopy.Status.Roles, roleTotal) {
 			pgCopy.Status.Phase = schedv1alpha1.PodGroupScheduling
+			pgCopy.Status.ScheduleStartTime = metav1.Now()
 			fillOccupiedObj(pgCopy, &pods[0])
 		}
 	default:
 		pgCopy.Status.Running, pgCopy.Status.Succeeded, pgCopy.Status.Failed = getCurrentPodStats(pods)
-		if len(pods) < int(pg.Spec.MinMember) {
+		pgCopy.Status.Roles = getRoleStats(pg, pods)
+		pgCopy.Status.Elastic = getElasticPodCount(pg, pgCopy.Status.Running)
+		if len(pods) < int(pg.Spec.MinMember) || !rolesSatisfied(pg, pgCopy.Status.Roles, roleTotal) {
 			pgCopy.Status.Phase = schedv1alpha1.PodGroupPending
+			meta.SetStatusCondition(&pgCopy.Status.Conditions, metav1.Condition{
+				Type:    schedv1alpha1.PodGroupQuorumReached,
+				Status:  metav1.ConditionFalse,
+				Reason:  schedv1alpha1.PodGroupReasonNotEnoughPods,
+				Message: fmt.Sprintf("%d pods exist, fewer than the minMember %d", len(pods), pg.Spec.MinMember),
+			})
 			break
 		}
 
-		if pgCopy.Status.Succeeded+pgCopy.Status.Running < pg.Spec.MinMember {
-			pgCopy.Status.Phase = schedv1alpha1.PodGroupScheduling
+		scheduled := getScheduledPodCount(pods)
+		if scheduled >= pg.Spec.MinMember {
+			meta.SetStatusCondition(&pgCopy.Status.Conditions, metav1.Condition{
+				Type:    schedv1alpha1.PodGroupScheduled,
+				Status:  metav1.ConditionTrue,
+				Reason:  schedv1alpha1.PodGroupReasonPodsBound,
+				Message: fmt.Sprintf("At least %d pods have been bound to nodes", pg.Spec.MinMember),
+			})
+		} else {
+			meta.SetStatusCondition(&pgCopy.Status.Conditions, metav1.Condition{
+				Type:    schedv1alpha1.PodGroupScheduled,
+				Status:  metav1.ConditionFalse,
+				Reason:  schedv1alpha1.PodGroupReasonWaitingForPods,
+				Message: fmt.Sprintf("%d out of %d pods have been bound to nodes", scheduled, pg.Spec.MinMember),
+			})
 		}
 
-		if pgCopy.Status.Succeeded+pgCopy.Status.Running >= pg.Spec.MinMember {
+		if pgCopy.Status.Succeeded+pgCopy.Status.Running < pg.Spec.MinMember ||
+			!rolesSatisfied(pg, pgCopy.Status.Roles, roleRunningOrSucceeded) {
+			pgCopy.Status.Phase = schedv1alpha1.PodGroupScheduling
+		} else {
 			pgCopy.Status.Phase = schedv1alpha1.PodGroupRunning
 		}
 		// Final state of pod group
@@ -128,20 +153,189 @@ func (r *PodGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 			pgCopy.Status.Failed+pgCopy.Status.Running+pgCopy.Status.Succeeded >= pg.Spec.MinMember {
 			pgCopy.Status.Phase = schedv1alpha1.PodGroupFailed
 		}
-		if pgCopy.Status.Succeeded >= pg.Spec.MinMember {
+		if pgCopy.Status.Succeeded >= pg.Spec.MinMember && rolesSatisfied(pg, pgCopy.Status.Roles, roleSucceeded) {
 			pgCopy.Status.Phase = schedv1alpha1.PodGroupFinished
 		}
+
+		// Apply the failure policy of the pod group once one of its members fails.
+		if failed := getFailedPods(pods); len(failed) != 0 && pgCopy.Status.Phase != schedv1alpha1.PodGroupFinished {
+			switch pg.Spec.FailurePolicy {
+			case schedv1alpha1.PodGroupFailurePolicyRestartGroup:
+				if maxRestarts := getMaxRestarts(pg); pg.Status.Restarts >= maxRestarts {
+					if err := r.deletePods(ctx, pods, isPodActive); err != nil {
+						return ctrl.Result{}, err
+					}
+					pgCopy.Status.Phase = schedv1alpha1.PodGroupFailed
+					r.recorder.Eventf(pg, v1.EventTypeWarning, "GroupFailed",
+						"Deleted the remaining members of the pod group as pod %v failed after %d restarts", failed[0].Name, maxRestarts)
+					break
+				}
+				if err := r.deletePods(ctx, pods, func(*v1.Pod) bool { return true }); err != nil {
+					return ctrl.Result{}, err
+				}
+				restartPodGroup(pgCopy)
+				r.recorder.Eventf(pg, v1.EventTypeWarning, "GroupRestarted",
+					"Restarted all members of the pod group as pod %v failed", failed[0].Name)
+			case schedv1alpha1.PodGroupFailurePolicyFailGroup:
+				if err := r.deletePods(ctx, pods, isPodActive); err != nil {
+					return ctrl.Result{}, err
+				}
+				pgCopy.Status.Phase = schedv1alpha1.PodGroupFailed
+				r.recorder.Eventf(pg, v1.EventTypeWarning, "GroupFailed",
+					"Deleted the remaining members of the pod group as pod %v failed", failed[0].Name)
+			}
+		}
+
+		// The pod group is marked as timed out if not enough pods get scheduled within its schedule timeout. It keeps
+		// its phase, as the timeout is also the time its pods wait in Permit, so that it recovers if they get scheduled.
+		if deadline, ok := getScheduleDeadline(pg); ok && pgCopy.Status.Phase == schedv1alpha1.PodGroupScheduling &&
+			scheduled < pg.Spec.MinMember {
+			if remaining := time.Until(deadline); remaining > 0 {
+				requeueAfter = remaining
+				break
+			}
+			if meta.IsStatusConditionTrue(pgCopy.Status.Conditions, schedv1alpha1.PodGroupTimedOut) {
+				break
+			}
+			message := fmt.Sprintf("%d out of %d pods were scheduled within %d seconds",
+				scheduled, pg.Spec.MinMember, *pg.Spec.ScheduleTimeoutSeconds)
+			meta.SetStatusCondition(&pgCopy.Status.Conditions, metav1.Condition{
+				Type:    schedv1alpha1.PodGroupTimedOut,
+				Status:  metav1.ConditionTrue,
+				Reason:  schedv1alpha1.PodGroupReasonScheduleTimeout,
+				Message: message,
+			})
+			r.recorder.Event(pg, v1.EventTypeWarning, "Timeout", message)
+		} else if scheduled >= pg.Spec.MinMember && meta.IsStatusConditionTrue(pgCopy.Status.Conditions, schedv1alpha1.PodGroupTimedOut) {
+			meta.SetStatusCondition(&pgCopy.Status.Conditions, metav1.Condition{
+				Type:    schedv1alpha1.PodGroupTimedOut,
+				Status:  metav1.ConditionFalse,
+				Reason:  schedv1alpha1.PodGroupReasonPodsBound,
+				Message: fmt.Sprintf("At least %d pods have been bound to nodes", pg.Spec.MinMember),
+			})
+		}
 	}
 
-	return r.patchPodGroup(ctx, pg, pgCopy)
+	if isPodGroupCompleted(pgCopy) {
+		now := metav1.Now()
+		pgCopy.Status.CompletionTime = &now
+		if pg.Spec.TTLSecondsAfterFinished != nil {
+			requeueAfter = time.Duration(*pg.Spec.TTLSecondsAfterFinished) * time.Second
+		}
+	}
+
+	if _, err := r.patchPodGroup(ctx, pg, pgCopy); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// cleanupPodGroup deletes the finished or failed pod group once its TTLSecondsAfterFinished expires.
+func (r *PodGroupReconciler) cleanupPodGroup(ctx context.Context, pg *schedv1alpha1.PodGroup) (ctrl.Result, error) {
+	if pg.Spec.TTLSecondsAfterFinished == nil {
+		return ctrl.Result{}, nil
+	}
+	ttl := time.Duration(*pg.Spec.TTLSecondsAfterFinished) * time.Second
+	completionTime := pg.Status.CompletionTime
+	// Pod groups completed before CompletionTime was recorded expire from now on.
+	if completionTime == nil {
+		pgCopy := pg.DeepCopy()
+		now := metav1.Now()
+		pgCopy.Status.CompletionTime = &now
+		if err := r.Status().Patch(ctx, pgCopy, client.MergeFrom(pg)); err != nil {
+			return ctrl.Result{}, err
+		}
+		completionTime = &now
+	}
+	if remaining := time.Until(completionTime.Add(ttl)); remaining > 0 {
+		return ctrl.Result{RequeueAfter: remaining}, nil
+	}
+
+	log.FromContext(ctx).V(3).Info("Deleting pod group after its TTL expired", "podGroup", klog.KObj(pg))
+	if err := r.Delete(ctx, pg, client.Preconditions{UID: &pg.UID}); err != nil && !apierrs.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// deletePods deletes the given pods matching the filter, unless they already succeeded or are being deleted.
+func (r *PodGroupReconciler) deletePods(ctx context.Context, pods []v1.Pod, filter func(*v1.Pod) bool) error {
+	for i := range pods {
+		pod := &pods[i]
+		if pod.DeletionTimestamp != nil || pod.Status.Phase == v1.PodSucceeded || !filter(pod) {
+			continue
+		}
+		if err := r.Delete(ctx, pod); err != nil && !apierrs.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// restartPodGroup resets the status of the pod group whose members have all been deleted to restart.
+func restartPodGroup(pg *schedv1alpha1.PodGroup) {
+	pg.Status.Phase = schedv1alpha1.PodGroupPending
+	pg.Status.Restarts++
+	pg.Status.Running, pg.Status.Succeeded, pg.Status.Failed = 0, 0, 0
+	pg.Status.Elastic = 0
+	pg.Status.Roles = nil
+	pg.Status.ScheduleStartTime = metav1.Time{}
+	meta.SetStatusCondition(&pg.Status.Conditions, metav1.Condition{
+		Type:    schedv1alpha1.PodGroupScheduled,
+		Status:  metav1.ConditionFalse,
+		Reason:  schedv1alpha1.PodGroupReasonRestarted,
+		Message: "All members of the pod group were deleted to restart",
+	})
+	meta.SetStatusCondition(&pg.Status.Conditions, metav1.Condition{
+		Type:    schedv1alpha1.PodGroupQuorumReached,
+		Status:  metav1.ConditionFalse,
+		Reason:  schedv1alpha1.PodGroupReasonRestarted,
+		Message: fmt.Sprintf("All members of the pod group were deleted to restart, %d restarts so far", pg.Status.Restarts),
+	})
+}
+
+// getMaxRestarts returns the maximum number of restarts of the pod group with the RestartGroup failure policy.
+func getMaxRestarts(pg *schedv1alpha1.PodGroup) int32 {
+	if pg.Spec.MaxRestarts == nil {
+		return schedv1alpha1.DefaultPodGroupMaxRestarts
+	}
+	return *pg.Spec.MaxRestarts
+}
+
+// isPodGroupCompleted checks whether the pod group has finished or failed.
+func isPodGroupCompleted(pg *schedv1alpha1.PodGroup) bool {
+	return pg.Status.Phase == schedv1alpha1.PodGroupFinished || pg.Status.Phase == schedv1alpha1.PodGroupFailed
 }
 
+// isPodActive checks whether the pod has not terminated yet.
+func isPodActive(pod *v1.Pod) bool {
+	return pod.Status.Phase != v1.PodSucceeded && pod.Status.Phase != v1.PodFailed
+}
+
+// getFailedPods returns the failed pods which are not being deleted, e.g. by a previous restart.
+func getFailedPods(pods []v1.Pod) []*v1.Pod {
+	var failed []*v1.Pod
+	for i := range pods {
+		if pods[i].Status.Phase == v1.PodFailed && pods[i].DeletionTimestamp == nil {
+			failed = append(failed, &pods[i])
+		}
+	}
+	return failed
+}
+
+// patchPodGroup patches the changes of the pod group but its conditions, which are applied one by one
+// so as not to replace the conditions set by the scheduler meanwhile.
 func (r *PodGroupReconciler) patchPodGroup(ctx context.Context, old, new *schedv1alpha1.PodGroup) (ctrl.Result, error) {
+	conditions := util.ChangedConditions(old.Status.Conditions, new.Status.Conditions...)
+	new.Status.Conditions = old.Status.Conditions
 	patch := client.MergeFrom(old)
 	if err := r.Status().Patch(ctx, new, patch); err != nil {
 		return ctrl.Result{}, err
 	}
-	err := r.Patch(ctx, new, patch)
+	if err := r.Patch(ctx, new, patch); err != nil {
+		return ctrl.Result{}, err
+	}
+	err := util.ApplyPodGroupConditions(ctx, r.Client, new, fieldManager, conditions...)
 	return ctrl.Result{}, err
 }
 
@@ -168,6 +362,80 @@ func getCurrentPodStats(pods []v1.Pod) (int32, int32, int32) {
 	return running, succeeded, failed
 }
 
+// getElasticPodCount returns the number of running pods beyond the minMember of an elastic pod group.
+func getElasticPodCount(pg *schedv1alpha1.PodGroup, running int32) int32 {
+	if _, elastic := util.GetPodGroupMaxMember(pg); !elastic || running <= pg.Spec.MinMember {
+		return 0
+	}
+	return running - pg.Spec.MinMember
+}
+
+// getScheduledPodCount returns the number of pods that have been bound to nodes.
+func getScheduledPodCount(pods []v1.Pod) int32 {
+	var scheduled int32
+	for _, pod := range pods {
+		if pod.Spec.NodeName != "" || pod.Status.Phase == v1.PodRunning ||
+			pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			scheduled++
+		}
+	}
+	return scheduled
+}
+
+// getScheduleDeadline returns the time by which the pod group is expected to be scheduled,
+// if it has a schedule timeout.
+func getScheduleDeadline(pg *schedv1alpha1.PodGroup) (time.Time, bool) {
+	if pg.Spec.ScheduleTimeoutSeconds == nil || pg.Status.ScheduleStartTime.IsZero() {
+		return time.Time{}, false
+	}
+	return pg.Status.ScheduleStartTime.Add(time.Duration(*pg.Spec.ScheduleTimeoutSeconds) * time.Second), true
+}
+
+// getRoleStats returns the pod stats of each role of the pod group, in the order of the roles in its spec.
+func getRoleStats(pg *schedv1alpha1.PodGroup, pods []v1.Pod) []schedv1alpha1.PodGroupRoleStatus {
+	if len(pg.Spec.Roles) == 0 {
+		return nil
+	}
+
+	stats := make([]schedv1alpha1.PodGroupRoleStatus, len(pg.Spec.Roles))
+	index := make(map[string]int, len(pg.Spec.Roles))
+	for i, role := range pg.Spec.Roles {
+		stats[i].Name = role.Name
+		index[role.Name] = i
+	}
+	for i := range pods {
+		idx, ok := index[util.GetPodGroupRole(&pods[i])]
+		if !ok {
+			continue
+		}
+		stats[idx].Total++
+		switch pods[i].Status.Phase {
+		case v1.PodRunning:
+			stats[idx].Running++
+		case v1.PodSucceeded:
+			stats[idx].Succeeded++
+		case v1.PodFailed:
+			stats[idx].Failed++
+		}
+	}
+	return stats
+}
+
+func roleTotal(s schedv1alpha1.PodGroupRoleStatus) int32 { return s.Total }
+
+func roleRunningOrSucceeded(s schedv1alpha1.PodGroupRoleStatus) int32 { return s.Running + s.Succeeded }
+
+func roleSucceeded(s schedv1alpha1.PodGroupRoleStatus) int32 { return s.Succeeded }
+
+// rolesSatisfied checks whether the given count of every role reaches the minMember of that role.
+func rolesSatisfied(pg *schedv1alpha1.PodGroup, stats []schedv1alpha1.PodGroupRoleStatus, count func(schedv1alpha1.PodGroupRoleStatus) int32) bool {
+	podsByRole := make(map[string]int32, len(stats))
+	for _, s := range stats {
+		podsByRole[s.Name] = count(s)
+	}
+	return util.CheckPodGroupRoles(pg, podsByRole) == nil
+}
+
 func fillOccupiedObj(pg *schedv1alpha1.PodGroup, pod *v1.Pod) {
 	if len(pod.OwnerReferences) == 0 {
 		return
@@ -18,25 +18,35 @@ package controllers
 
 import (
 	"context"
+	"slices"
 	"sort"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2/klogr"
 	st "k8s.io/kubernetes/pkg/scheduler/testing"
+	"k8s.io/utils/ptr"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/coscheduling/core"
+	tu "sigs.k8s.io/scheduler-plugins/test/util"
 )
 
 func Test_Run(t *testing.T) {
@@ -128,13 +138,13 @@ func Test_Run(t *testing.T) {
 			podNextPhase:      v1.PodSucceeded,
 		},
 		{
-			name:               "Group should not enqueue, created too long",
+			name:               "Group created long ago is still reconciled",
 			pgName:             "pg8",
 			minMember:          2,
 			podNames:           []string{"pod1", "pod2"},
 			podPhase:           v1.PodRunning,
 			previousPhase:      v1alpha1.PodGroupPending,
-			desiredGroupPhase:  v1alpha1.PodGroupPending,
+			desiredGroupPhase:  v1alpha1.PodGroupRunning,
 			podGroupCreateTime: &createTime,
 		},
 		{
@@ -195,6 +205,440 @@ func Test_Run(t *testing.T) {
 	}
 }
 
+func TestReconcileRoles(t *testing.T) {
+	ctx := context.TODO()
+	roles := []v1alpha1.PodGroupRole{{Name: "launcher", MinMember: 1}, {Name: "worker", MinMember: 2}}
+	cases := []struct {
+		name              string
+		podRoles          map[string]string
+		podPhase          v1.PodPhase
+		previousPhase     v1alpha1.PodGroupPhase
+		desiredGroupPhase v1alpha1.PodGroupPhase
+		desiredRoles      []v1alpha1.PodGroupRoleStatus
+	}{
+		{
+			name:              "Group keeps pending without enough pods of a role",
+			podRoles:          map[string]string{"pod1": "worker", "pod2": "worker", "pod3": "worker"},
+			podPhase:          v1.PodPending,
+			previousPhase:     v1alpha1.PodGroupPending,
+			desiredGroupPhase: v1alpha1.PodGroupPending,
+			desiredRoles: []v1alpha1.PodGroupRoleStatus{
+				{Name: "launcher"},
+				{Name: "worker", Total: 3},
+			},
+		},
+		{
+			name:              "Group status convert from pending to scheduling",
+			podRoles:          map[string]string{"pod1": "launcher", "pod2": "worker", "pod3": "worker"},
+			podPhase:          v1.PodPending,
+			previousPhase:     v1alpha1.PodGroupPending,
+			desiredGroupPhase: v1alpha1.PodGroupScheduling,
+			desiredRoles: []v1alpha1.PodGroupRoleStatus{
+				{Name: "launcher", Total: 1},
+				{Name: "worker", Total: 2},
+			},
+		},
+		{
+			name:              "Group status convert from running to pending when a role loses pods",
+			podRoles:          map[string]string{"pod1": "worker", "pod2": "worker", "pod3": "worker"},
+			podPhase:          v1.PodRunning,
+			previousPhase:     v1alpha1.PodGroupRunning,
+			desiredGroupPhase: v1alpha1.PodGroupPending,
+			desiredRoles: []v1alpha1.PodGroupRoleStatus{
+				{Name: "launcher"},
+				{Name: "worker", Total: 3, Running: 3},
+			},
+		},
+		{
+			name:              "Group running",
+			podRoles:          map[string]string{"pod1": "launcher", "pod2": "worker", "pod3": "worker"},
+			podPhase:          v1.PodRunning,
+			previousPhase:     v1alpha1.PodGroupScheduling,
+			desiredGroupPhase: v1alpha1.PodGroupRunning,
+			desiredRoles: []v1alpha1.PodGroupRoleStatus{
+				{Name: "launcher", Total: 1, Running: 1},
+				{Name: "worker", Total: 2, Running: 2},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := scheme.Scheme
+			pg := makePG("pg", 3, c.previousPhase, nil)
+			pg.Spec.Roles = roles
+			s.AddKnownTypes(v1alpha1.SchemeGroupVersion, pg)
+			objs := []runtime.Object{pg}
+			for name, role := range c.podRoles {
+				pod := makePods([]string{name}, "pg", c.podPhase, nil)[0]
+				pod.Labels[v1alpha1.PodGroupRoleLabel] = role
+				objs = append(objs, pod)
+			}
+			kClient := fake.NewClientBuilder().
+				WithScheme(s).
+				WithStatusSubresource(&v1alpha1.PodGroup{}).
+				WithInterceptorFuncs(tu.PodGroupApplyInterceptor()).
+				WithRuntimeObjects(objs...).
+				Build()
+			controller := &PodGroupReconciler{
+				Client:   kClient,
+				Scheme:   s,
+				recorder: record.NewFakeRecorder(3),
+				log:      klogr.New().WithName("podGroupTest"),
+			}
+
+			if _, err := controller.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "pg", Namespace: metav1.NamespaceDefault}}); err != nil {
+				t.Fatalf("reconcile: (%v)", err)
+			}
+			if err := kClient.Get(ctx, client.ObjectKeyFromObject(pg), pg); err != nil {
+				t.Fatal(err)
+			}
+			if pg.Status.Phase != c.desiredGroupPhase {
+				t.Errorf("want %v, got %v", c.desiredGroupPhase, pg.Status.Phase)
+			}
+			if diff := cmp.Diff(c.desiredRoles, pg.Status.Roles); diff != "" {
+				t.Errorf("unexpected role status (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReconcileScheduleTimeout(t *testing.T) {
+	ctx := context.TODO()
+	cases := []struct {
+		name              string
+		nodeName          string
+		scheduleStartTime time.Time
+		desiredGroupPhase v1alpha1.PodGroupPhase
+		desiredScheduled  metav1.ConditionStatus
+		timedOut          bool
+		desiredTimedOut   bool
+		desiredRequeue    bool
+	}{
+		{
+			name:              "Group keeps scheduling before the timeout",
+			scheduleStartTime: time.Now(),
+			desiredGroupPhase: v1alpha1.PodGroupScheduling,
+			desiredScheduled:  metav1.ConditionFalse,
+			desiredRequeue:    true,
+		},
+		{
+			name:              "Group times out but keeps scheduling after the timeout",
+			scheduleStartTime: time.Now().Add(-time.Minute),
+			desiredGroupPhase: v1alpha1.PodGroupScheduling,
+			desiredScheduled:  metav1.ConditionFalse,
+			desiredTimedOut:   true,
+		},
+		{
+			name:              "Group scheduled after the timeout recovers",
+			nodeName:          "node",
+			scheduleStartTime: time.Now().Add(-time.Minute),
+			timedOut:          true,
+			desiredGroupPhase: v1alpha1.PodGroupScheduling,
+			desiredScheduled:  metav1.ConditionTrue,
+		},
+		{
+			name:              "Group scheduled before the timeout",
+			nodeName:          "node",
+			scheduleStartTime: time.Now().Add(-time.Minute),
+			desiredGroupPhase: v1alpha1.PodGroupScheduling,
+			desiredScheduled:  metav1.ConditionTrue,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := scheme.Scheme
+			pg := makePG("pg", 2, v1alpha1.PodGroupScheduling, nil)
+			pg.Spec.ScheduleTimeoutSeconds = ptr.To[int32](10)
+			pg.Status.ScheduleStartTime = metav1.NewTime(c.scheduleStartTime)
+			if c.timedOut {
+				pg.Status.Conditions = []metav1.Condition{{
+					Type:   v1alpha1.PodGroupTimedOut,
+					Status: metav1.ConditionTrue,
+					Reason: v1alpha1.PodGroupReasonScheduleTimeout,
+				}}
+			}
+			s.AddKnownTypes(v1alpha1.SchemeGroupVersion, pg)
+			objs := []runtime.Object{pg}
+			for _, pod := range makePods([]string{"pod1", "pod2"}, "pg", v1.PodPending, nil) {
+				pod.Spec.NodeName = c.nodeName
+				objs = append(objs, pod)
+			}
+			kClient := fake.NewClientBuilder().
+				WithScheme(s).
+				WithStatusSubresource(&v1alpha1.PodGroup{}).
+				WithInterceptorFuncs(tu.PodGroupApplyInterceptor()).
+				WithRuntimeObjects(objs...).
+				Build()
+			controller := &PodGroupReconciler{
+				Client:   kClient,
+				Scheme:   s,
+				recorder: record.NewFakeRecorder(3),
+				log:      klogr.New().WithName("podGroupTest"),
+			}
+
+			result, err := controller.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "pg", Namespace: metav1.NamespaceDefault}})
+			if err != nil {
+				t.Fatalf("reconcile: (%v)", err)
+			}
+			if requeue := result.RequeueAfter > 0; requeue != c.desiredRequeue {
+				t.Errorf("want requeue %v, got %v", c.desiredRequeue, result.RequeueAfter)
+			}
+			if err := kClient.Get(ctx, client.ObjectKeyFromObject(pg), pg); err != nil {
+				t.Fatal(err)
+			}
+			if pg.Status.Phase != c.desiredGroupPhase {
+				t.Errorf("want %v, got %v", c.desiredGroupPhase, pg.Status.Phase)
+			}
+			if cond := meta.FindStatusCondition(pg.Status.Conditions, v1alpha1.PodGroupScheduled); cond == nil || cond.Status != c.desiredScheduled {
+				t.Errorf("want Scheduled condition %v, got %v", c.desiredScheduled, cond)
+			}
+			if timedOut := meta.IsStatusConditionTrue(pg.Status.Conditions, v1alpha1.PodGroupTimedOut); timedOut != c.desiredTimedOut {
+				t.Errorf("want TimedOut condition %v, got %v", c.desiredTimedOut, pg.Status.Conditions)
+			}
+		})
+	}
+}
+
+func TestReconcileFailurePolicy(t *testing.T) {
+	ctx := context.TODO()
+	cases := []struct {
+		name              string
+		failurePolicy     v1alpha1.PodGroupFailurePolicy
+		maxRestarts       *int32
+		restarts          int32
+		succeededPods     []string
+		desiredGroupPhase v1alpha1.PodGroupPhase
+		desiredPods       []string
+		desiredRestarts   int32
+	}{
+		{
+			name:              "Group fails and keeps its surviving members by default",
+			desiredGroupPhase: v1alpha1.PodGroupFailed,
+			desiredPods:       []string{"pod1", "pod2", "pod3"},
+		},
+		{
+			name:              "Group restarts all its members",
+			failurePolicy:     v1alpha1.PodGroupFailurePolicyRestartGroup,
+			desiredGroupPhase: v1alpha1.PodGroupPending,
+			desiredRestarts:   1,
+		},
+		{
+			name:              "Group restarts its members but the succeeded ones",
+			failurePolicy:     v1alpha1.PodGroupFailurePolicyRestartGroup,
+			succeededPods:     []string{"pod3"},
+			desiredGroupPhase: v1alpha1.PodGroupPending,
+			desiredPods:       []string{"pod3"},
+			desiredRestarts:   1,
+		},
+		{
+			name:              "Group fails and deletes its surviving members after the default max restarts",
+			failurePolicy:     v1alpha1.PodGroupFailurePolicyRestartGroup,
+			restarts:          v1alpha1.DefaultPodGroupMaxRestarts,
+			desiredGroupPhase: v1alpha1.PodGroupFailed,
+			desiredPods:       []string{"pod1"},
+			desiredRestarts:   v1alpha1.DefaultPodGroupMaxRestarts,
+		},
+		{
+			name:              "Group fails and deletes its surviving members after its max restarts",
+			failurePolicy:     v1alpha1.PodGroupFailurePolicyRestartGroup,
+			maxRestarts:       ptr.To[int32](1),
+			restarts:          1,
+			desiredGroupPhase: v1alpha1.PodGroupFailed,
+			desiredPods:       []string{"pod1"},
+			desiredRestarts:   1,
+		},
+		{
+			name:              "Group fails and deletes its surviving members",
+			failurePolicy:     v1alpha1.PodGroupFailurePolicyFailGroup,
+			desiredGroupPhase: v1alpha1.PodGroupFailed,
+			desiredPods:       []string{"pod1"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := scheme.Scheme
+			pg := makePG("pg", 3, v1alpha1.PodGroupRunning, nil)
+			pg.Spec.FailurePolicy = c.failurePolicy
+			pg.Spec.MaxRestarts = c.maxRestarts
+			pg.Status.Restarts = c.restarts
+			s.AddKnownTypes(v1alpha1.SchemeGroupVersion, pg)
+			objs := []runtime.Object{pg}
+			for i, pod := range makePods([]string{"pod1", "pod2", "pod3"}, "pg", v1.PodRunning, nil) {
+				if i == 0 {
+					pod.Status.Phase = v1.PodFailed
+				} else if slices.Contains(c.succeededPods, pod.Name) {
+					pod.Status.Phase = v1.PodSucceeded
+				}
+				objs = append(objs, pod)
+			}
+			kClient := fake.NewClientBuilder().
+				WithScheme(s).
+				WithStatusSubresource(&v1alpha1.PodGroup{}).
+				WithInterceptorFuncs(tu.PodGroupApplyInterceptor()).
+				WithRuntimeObjects(objs...).
+				Build()
+			controller := &PodGroupReconciler{
+				Client:   kClient,
+				Scheme:   s,
+				recorder: record.NewFakeRecorder(3),
+				log:      klogr.New().WithName("podGroupTest"),
+			}
+
+			if _, err := controller.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "pg", Namespace: metav1.NamespaceDefault}}); err != nil {
+				t.Fatalf("reconcile: (%v)", err)
+			}
+			if err := kClient.Get(ctx, client.ObjectKeyFromObject(pg), pg); err != nil {
+				t.Fatal(err)
+			}
+			if pg.Status.Phase != c.desiredGroupPhase {
+				t.Errorf("want %v, got %v", c.desiredGroupPhase, pg.Status.Phase)
+			}
+			if pg.Status.Restarts != c.desiredRestarts {
+				t.Errorf("want %v restarts, got %v", c.desiredRestarts, pg.Status.Restarts)
+			}
+			podList := &v1.PodList{}
+			if err := kClient.List(ctx, podList); err != nil {
+				t.Fatal(err)
+			}
+			var pods []string
+			for _, pod := range podList.Items {
+				pods = append(pods, pod.Name)
+			}
+			sort.Strings(pods)
+			if diff := cmp.Diff(c.desiredPods, pods); diff != "" {
+				t.Errorf("unexpected pods (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReconcileTTLAfterFinished(t *testing.T) {
+	ctx := context.TODO()
+	cases := []struct {
+		name           string
+		ttl            *int32
+		completionTime *metav1.Time
+		desiredDeleted bool
+		desiredRequeue bool
+	}{
+		{
+			name:           "Group without TTL is kept",
+			completionTime: &metav1.Time{Time: time.Now().Add(-time.Hour)},
+		},
+		{
+			name:           "Group is kept before its TTL expires",
+			ttl:            ptr.To[int32](60),
+			completionTime: &metav1.Time{Time: time.Now()},
+			desiredRequeue: true,
+		},
+		{
+			name:           "Group is deleted after its TTL expires",
+			ttl:            ptr.To[int32](60),
+			completionTime: &metav1.Time{Time: time.Now().Add(-time.Hour)},
+			desiredDeleted: true,
+		},
+		{
+			name:           "Group without completion time starts its TTL",
+			ttl:            ptr.To[int32](60),
+			desiredRequeue: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := scheme.Scheme
+			pg := makePG("pg", 2, v1alpha1.PodGroupFinished, nil)
+			pg.Spec.TTLSecondsAfterFinished = c.ttl
+			pg.Status.CompletionTime = c.completionTime
+			s.AddKnownTypes(v1alpha1.SchemeGroupVersion, pg)
+			kClient := fake.NewClientBuilder().
+				WithScheme(s).
+				WithStatusSubresource(&v1alpha1.PodGroup{}).
+				WithInterceptorFuncs(tu.PodGroupApplyInterceptor()).
+				WithRuntimeObjects(pg).
+				Build()
+			controller := &PodGroupReconciler{
+				Client:   kClient,
+				Scheme:   s,
+				recorder: record.NewFakeRecorder(3),
+				log:      klogr.New().WithName("podGroupTest"),
+			}
+
+			result, err := controller.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "pg", Namespace: metav1.NamespaceDefault}})
+			if err != nil {
+				t.Fatalf("reconcile: (%v)", err)
+			}
+			if requeue := result.RequeueAfter > 0; requeue != c.desiredRequeue {
+				t.Errorf("want requeue %v, got %v", c.desiredRequeue, result.RequeueAfter)
+			}
+			err = kClient.Get(ctx, client.ObjectKeyFromObject(pg), pg)
+			if deleted := apierrs.IsNotFound(err); deleted != c.desiredDeleted {
+				t.Errorf("want deleted %v, got %v", c.desiredDeleted, err)
+			}
+			if !c.desiredDeleted && c.ttl != nil && pg.Status.CompletionTime == nil {
+				t.Errorf("want the completion time to be set")
+			}
+		})
+	}
+}
+
+func TestReconcileElastic(t *testing.T) {
+	ctx := context.TODO()
+	cases := []struct {
+		name              string
+		maxMember         *int32
+		desiredGroupPhase v1alpha1.PodGroupPhase
+		desiredElastic    int32
+	}{
+		{
+			name:              "Group without maxMember is not elastic",
+			desiredGroupPhase: v1alpha1.PodGroupRunning,
+		},
+		{
+			name:              "Elastic group reports the pods beyond minMember",
+			maxMember:         ptr.To[int32](4),
+			desiredGroupPhase: v1alpha1.PodGroupRunning,
+			desiredElastic:    1,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := scheme.Scheme
+			pg := makePG("pg", 2, v1alpha1.PodGroupScheduling, nil)
+			pg.Spec.MaxMember = c.maxMember
+			s.AddKnownTypes(v1alpha1.SchemeGroupVersion, pg)
+			objs := []runtime.Object{pg}
+			for _, pod := range makePods([]string{"pod1", "pod2", "pod3"}, "pg", v1.PodRunning, nil) {
+				objs = append(objs, pod)
+			}
+			kClient := fake.NewClientBuilder().
+				WithScheme(s).
+				WithStatusSubresource(&v1alpha1.PodGroup{}).
+				WithInterceptorFuncs(tu.PodGroupApplyInterceptor()).
+				WithRuntimeObjects(objs...).
+				Build()
+			controller := &PodGroupReconciler{
+				Client:   kClient,
+				Scheme:   s,
+				recorder: record.NewFakeRecorder(3),
+				log:      klogr.New().WithName("podGroupTest"),
+			}
+
+			if _, err := controller.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "pg", Namespace: metav1.NamespaceDefault}}); err != nil {
+				t.Fatalf("reconcile: (%v)", err)
+			}
+			if err := kClient.Get(ctx, client.ObjectKeyFromObject(pg), pg); err != nil {
+				t.Fatal(err)
+			}
+			if pg.Status.Phase != c.desiredGroupPhase {
+				t.Errorf("want %v, got %v", c.desiredGroupPhase, pg.Status.Phase)
+			}
+			if pg.Status.Elastic != c.desiredElastic {
+				t.Errorf("want %v elastic pods, got %v", c.desiredElastic, pg.Status.Elastic)
+			}
+		})
+	}
+}
+
 func TestFillGroupStatusOccupied(t *testing.T) {
 	ctx := context.TODO()
 	cases := []struct {
@@ -268,6 +712,69 @@ func TestFillGroupStatusOccupied(t *testing.T) {
 	}
 }
 
+func TestReconcileConditionsInterleaving(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	controller, kClient := setUp(ctx, []string{"pg1-1", "pg1-2"}, "pg1", v1.PodRunning, 2, v1alpha1.PodGroupScheduling, nil, nil)
+	informerFactory := informers.NewSharedInformerFactory(clientsetfake.NewSimpleClientset(), 0)
+	pgMgr := core.NewPodGroupManager(kClient, nil, nil, informerFactory.Core().V1().Pods())
+	pgMgr.Run(ctx)
+	getPodGroup := func() *v1alpha1.PodGroup {
+		pg := &v1alpha1.PodGroup{}
+		if err := kClient.Get(ctx, types.NamespacedName{Namespace: metav1.NamespaceDefault, Name: "pg1"}, pg); err != nil {
+			t.Fatal(err)
+		}
+		return pg
+	}
+	// The scheduler applies its conditions in the background.
+	wantConditions := func(conditionTypes ...string) {
+		t.Helper()
+		var conditions []metav1.Condition
+		if err := wait.PollUntilContextTimeout(ctx, 10*time.Millisecond, time.Second, true, func(ctx context.Context) (bool, error) {
+			conditions = getPodGroup().Status.Conditions
+			for _, conditionType := range conditionTypes {
+				if meta.FindStatusCondition(conditions, conditionType) == nil {
+					return false, nil
+				}
+			}
+			return true, nil
+		}); err != nil {
+			t.Errorf("want the %v conditions, got %v", conditionTypes, conditions)
+		}
+	}
+
+	// The scheduler sets a condition on its stale copy of the pod group after the controller set its own.
+	stale := getPodGroup()
+	if _, err := controller.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "pg1", Namespace: metav1.NamespaceDefault}}); err != nil {
+		t.Fatalf("reconcile: (%v)", err)
+	}
+	pgMgr.SetPodGroupConditions(ctx, stale, metav1.Condition{
+		Type:   v1alpha1.PodGroupQuorumReached,
+		Status: metav1.ConditionFalse,
+		Reason: v1alpha1.PodGroupReasonUnschedulable,
+	})
+	wantConditions(v1alpha1.PodGroupScheduled, v1alpha1.PodGroupQuorumReached)
+
+	// The controller patches the pod group it read before the scheduler set a condition.
+	old := getPodGroup()
+	pgMgr.SetPodGroupConditions(ctx, old, metav1.Condition{
+		Type:   v1alpha1.PodGroupBackoff,
+		Status: metav1.ConditionTrue,
+		Reason: v1alpha1.PodGroupReasonBackedOff,
+	})
+	wantConditions(v1alpha1.PodGroupBackoff)
+	new := old.DeepCopy()
+	meta.SetStatusCondition(&new.Status.Conditions, metav1.Condition{
+		Type:   v1alpha1.PodGroupTimedOut,
+		Status: metav1.ConditionTrue,
+		Reason: v1alpha1.PodGroupReasonScheduleTimeout,
+	})
+	if _, err := controller.patchPodGroup(ctx, old, new); err != nil {
+		t.Fatal(err)
+	}
+	wantConditions(v1alpha1.PodGroupScheduled, v1alpha1.PodGroupQuorumReached, v1alpha1.PodGroupBackoff, v1alpha1.PodGroupTimedOut)
+}
+
 func setUp(ctx context.Context,
 	podNames []string,
 	pgName string,
@@ -287,6 +794,7 @@ func setUp(ctx context.Context,
 	client := fake.NewClientBuilder().
 		WithScheme(s).
 		WithStatusSubresource(&v1alpha1.PodGroup{}).
+		WithInterceptorFuncs(tu.PodGroupApplyInterceptor()).
 		WithRuntimeObjects(objs...).
 		Build()
 
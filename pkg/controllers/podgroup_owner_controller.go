@@ -0,0 +1,215 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	schedv1alpha1 "sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// PodGroupOwnerAdapter adapts a kind of workload whose pods are scheduled as a gang,
+// so that a pod group can be created for it automatically.
+type PodGroupOwnerAdapter interface {
+	// GroupVersionKind returns the kind of the workload.
+	GroupVersionKind() schema.GroupVersionKind
+	// NewObject returns an empty object of the workload kind.
+	NewObject() client.Object
+	// PodTemplate returns the template of the pods of the given workload.
+	PodTemplate(obj client.Object) (*v1.PodTemplateSpec, error)
+	// MinMember returns the number of pods of the given workload to be scheduled together, at least 1
+	// for the pod group to be valid, unless it is overridden by the min-member annotation.
+	MinMember(obj client.Object) (int32, error)
+}
+
+// JobAdapter is the PodGroupOwnerAdapter of batch Jobs.
+type JobAdapter struct{}
+
+var _ PodGroupOwnerAdapter = &JobAdapter{}
+
+func (a *JobAdapter) GroupVersionKind() schema.GroupVersionKind {
+	return batchv1.SchemeGroupVersion.WithKind("Job")
+}
+
+func (a *JobAdapter) NewObject() client.Object {
+	return &batchv1.Job{}
+}
+
+func (a *JobAdapter) PodTemplate(obj client.Object) (*v1.PodTemplateSpec, error) {
+	job, ok := obj.(*batchv1.Job)
+	if !ok {
+		return nil, fmt.Errorf("want a Job, got %T", obj)
+	}
+	return &job.Spec.Template, nil
+}
+
+// MinMember returns the parallelism of the Job, bounded by its completions. Suspended or scaled-to-zero
+// Jobs keep a pod group of 1 member, which gates no pod as they have none.
+func (a *JobAdapter) MinMember(obj client.Object) (int32, error) {
+	job, ok := obj.(*batchv1.Job)
+	if !ok {
+		return 0, fmt.Errorf("want a Job, got %T", obj)
+	}
+	minMember := int32(1)
+	if job.Spec.Parallelism != nil {
+		minMember = *job.Spec.Parallelism
+	}
+	if job.Spec.Completions != nil && *job.Spec.Completions < minMember {
+		minMember = *job.Spec.Completions
+	}
+	return max(minMember, 1), nil
+}
+
+// PodGroupOwnerReconciler creates and updates the pod groups of the workloads of one kind
+// annotated with scheduling.x-k8s.io/gang.
+type PodGroupOwnerReconciler struct {
+	recorder record.EventRecorder
+
+	client.Client
+	Scheme  *runtime.Scheme
+	Adapter PodGroupOwnerAdapter
+	Workers int
+}
+
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch
+// +kubebuilder:rbac:groups=scheduling.x-k8s.io,resources=podgroups,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile creates the pod group of a gang workload, or updates it to match the workload.
+func (r *PodGroupOwnerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	obj := r.Adapter.NewObject()
+	if err := r.Get(ctx, req.NamespacedName, obj); err != nil {
+		if apierrs.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if !isGangOwner(obj) || obj.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
+
+	minMember, err := getOwnerMinMember(r.Adapter, obj)
+	if err != nil {
+		r.recorder.Event(obj, v1.EventTypeWarning, "InvalidMinMember", err.Error())
+		return ctrl.Result{}, nil
+	}
+	template, err := r.Adapter.PodTemplate(obj)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	minResources := getMinResources(template, minMember)
+
+	pg := &schedv1alpha1.PodGroup{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}, pg); err != nil {
+		if !apierrs.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		pg = &schedv1alpha1.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: obj.GetNamespace(),
+				Name:      obj.GetName(),
+			},
+			Spec: schedv1alpha1.PodGroupSpec{
+				MinMember:    minMember,
+				MinResources: minResources,
+			},
+		}
+		if err := controllerutil.SetControllerReference(obj, pg, r.Scheme); err != nil {
+			return ctrl.Result{}, err
+		}
+		log.V(3).Info("Creating pod group for gang workload", "podGroup", pg.Name)
+		return ctrl.Result{}, r.Create(ctx, pg)
+	}
+
+	// Leave alone the pod groups which were not created for the workload.
+	if !metav1.IsControlledBy(pg, obj) {
+		r.recorder.Eventf(obj, v1.EventTypeWarning, "PodGroupConflict",
+			"pod group %v already exists and is not controlled by the workload", pg.Name)
+		return ctrl.Result{}, nil
+	}
+	pgCopy := pg.DeepCopy()
+	pgCopy.Spec.MinMember = minMember
+	pgCopy.Spec.MinResources = minResources
+	return ctrl.Result{}, r.Patch(ctx, pgCopy, client.MergeFrom(pg))
+}
+
+// isGangOwner checks whether the given workload is annotated to be scheduled as a gang.
+func isGangOwner(obj client.Object) bool {
+	return strings.EqualFold(obj.GetAnnotations()[schedv1alpha1.PodGroupGangAnnotation], "true")
+}
+
+// getOwnerMinMember returns the minMember of the pod group of the given workload, from the
+// min-member annotation if any, or from the workload spec otherwise.
+func getOwnerMinMember(adapter PodGroupOwnerAdapter, obj client.Object) (int32, error) {
+	value, ok := obj.GetAnnotations()[schedv1alpha1.PodGroupMinMemberAnnotation]
+	if !ok {
+		return adapter.MinMember(obj)
+	}
+	minMember, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || minMember < 1 {
+		return 0, fmt.Errorf("annotation %v must be a positive integer, got %q", schedv1alpha1.PodGroupMinMemberAnnotation, value)
+	}
+	return int32(minMember), nil
+}
+
+// getMinResources returns the resources requested by minMember pods created from the given template.
+func getMinResources(template *v1.PodTemplateSpec, minMember int32) v1.ResourceList {
+	request := util.GetPodEffectiveRequest(&v1.Pod{Spec: template.Spec})
+	if len(request) == 0 {
+		return nil
+	}
+	minResources := make(v1.ResourceList, len(request))
+	for name, quantity := range request {
+		total := resource.Quantity{Format: quantity.Format}
+		for i := int32(0); i < minMember; i++ {
+			total.Add(quantity)
+		}
+		minResources[name] = total
+	}
+	return minResources
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PodGroupOwnerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("PodGroupOwnerController")
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(strings.ToLower(r.Adapter.GroupVersionKind().Kind) + "-podgroup").
+		For(r.Adapter.NewObject()).
+		Owns(&schedv1alpha1.PodGroup{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.Workers}).
+		Complete(r)
+}
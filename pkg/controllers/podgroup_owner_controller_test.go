@@ -0,0 +1,311 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	admissionv1 "k8s.io/api/admission/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+)
+
+func makeJob(name string, annotations map[string]string, parallelism, completions *int32) *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   metav1.NamespaceDefault,
+			UID:         types.UID(name),
+			Annotations: annotations,
+		},
+		Spec: batchv1.JobSpec{
+			Parallelism: parallelism,
+			Completions: completions,
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{{
+						Name: "worker",
+						Resources: v1.ResourceRequirements{
+							Requests: v1.ResourceList{
+								v1.ResourceCPU:    resource.MustParse("500m"),
+								v1.ResourceMemory: resource.MustParse("1Gi"),
+							},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+func newOwnerTestScheme(t *testing.T) *runtime.Scheme {
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := v1alpha1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestReconcilePodGroupOwner(t *testing.T) {
+	ctx := context.TODO()
+	gang := map[string]string{v1alpha1.PodGroupGangAnnotation: "true"}
+	cases := []struct {
+		name              string
+		job               *batchv1.Job
+		existingPG        *v1alpha1.PodGroup
+		wantPG            bool
+		wantMinMember     int32
+		wantMinResources  v1.ResourceList
+		wantOwnedByTheJob bool
+	}{
+		{
+			name: "job without gang annotation",
+			job:  makeJob("job", nil, ptr.To[int32](3), nil),
+		},
+		{
+			name:              "minMember from parallelism",
+			job:               makeJob("job", gang, ptr.To[int32](3), ptr.To[int32](5)),
+			wantPG:            true,
+			wantMinMember:     3,
+			wantMinResources:  v1.ResourceList{v1.ResourceCPU: resource.MustParse("1500m"), v1.ResourceMemory: resource.MustParse("3Gi")},
+			wantOwnedByTheJob: true,
+		},
+		{
+			name:              "minMember bounded by completions",
+			job:               makeJob("job", gang, ptr.To[int32](3), ptr.To[int32](2)),
+			wantPG:            true,
+			wantMinMember:     2,
+			wantMinResources:  v1.ResourceList{v1.ResourceCPU: resource.MustParse("1"), v1.ResourceMemory: resource.MustParse("2Gi")},
+			wantOwnedByTheJob: true,
+		},
+		{
+			name:              "scaled-to-zero job keeps a valid pod group",
+			job:               makeJob("job", gang, ptr.To[int32](0), ptr.To[int32](5)),
+			wantPG:            true,
+			wantMinMember:     1,
+			wantMinResources:  v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m"), v1.ResourceMemory: resource.MustParse("1Gi")},
+			wantOwnedByTheJob: true,
+		},
+		{
+			name:              "job without completions keeps a valid pod group",
+			job:               makeJob("job", gang, nil, ptr.To[int32](0)),
+			wantPG:            true,
+			wantMinMember:     1,
+			wantMinResources:  v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m"), v1.ResourceMemory: resource.MustParse("1Gi")},
+			wantOwnedByTheJob: true,
+		},
+		{
+			name: "minMember from annotation",
+			job: makeJob("job", map[string]string{
+				v1alpha1.PodGroupGangAnnotation:      "true",
+				v1alpha1.PodGroupMinMemberAnnotation: "4",
+			}, ptr.To[int32](3), nil),
+			wantPG:            true,
+			wantMinMember:     4,
+			wantMinResources:  v1.ResourceList{v1.ResourceCPU: resource.MustParse("2"), v1.ResourceMemory: resource.MustParse("4Gi")},
+			wantOwnedByTheJob: true,
+		},
+		{
+			name: "invalid minMember annotation",
+			job: makeJob("job", map[string]string{
+				v1alpha1.PodGroupGangAnnotation:      "true",
+				v1alpha1.PodGroupMinMemberAnnotation: "zero",
+			}, ptr.To[int32](3), nil),
+		},
+		{
+			name: "pod group not created for the job is left alone",
+			job:  makeJob("job", gang, ptr.To[int32](3), nil),
+			existingPG: &v1alpha1.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: metav1.NamespaceDefault},
+				Spec:       v1alpha1.PodGroupSpec{MinMember: 1},
+			},
+			wantPG:        true,
+			wantMinMember: 1,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newOwnerTestScheme(t)
+			objs := []runtime.Object{c.job}
+			if c.existingPG != nil {
+				objs = append(objs, c.existingPG)
+			}
+			// The pod groups are validated as by the PodGroupValidator webhook
+			validate := func(obj client.Object) error {
+				if pg, ok := obj.(*v1alpha1.PodGroup); ok {
+					if allErrs := validatePodGroup(pg); len(allErrs) > 0 {
+						return apierrs.NewInvalid(v1alpha1.SchemeGroupVersion.WithKind("PodGroup").GroupKind(), pg.Name, allErrs)
+					}
+				}
+				return nil
+			}
+			kClient := fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).WithInterceptorFuncs(interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					if err := validate(obj); err != nil {
+						return err
+					}
+					return c.Create(ctx, obj, opts...)
+				},
+				Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+					if err := validate(obj); err != nil {
+						return err
+					}
+					return c.Patch(ctx, obj, patch, opts...)
+				},
+			}).Build()
+			controller := &PodGroupOwnerReconciler{
+				Client:   kClient,
+				Scheme:   s,
+				Adapter:  &JobAdapter{},
+				recorder: record.NewFakeRecorder(3),
+			}
+
+			// Reconcile twice to cover both creation and update.
+			for i := 0; i < 2; i++ {
+				if _, err := controller.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(c.job)}); err != nil {
+					t.Fatalf("reconcile: (%v)", err)
+				}
+			}
+
+			pg := &v1alpha1.PodGroup{}
+			err := kClient.Get(ctx, client.ObjectKeyFromObject(c.job), pg)
+			if !c.wantPG {
+				if !apierrs.IsNotFound(err) {
+					t.Errorf("want no pod group, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pg.Spec.MinMember != c.wantMinMember {
+				t.Errorf("want minMember %v, got %v", c.wantMinMember, pg.Spec.MinMember)
+			}
+			if diff := cmp.Diff(c.wantMinResources, pg.Spec.MinResources, cmp.Comparer(func(a, b resource.Quantity) bool {
+				return a.Cmp(b) == 0
+			})); diff != "" {
+				t.Errorf("unexpected minResources (-want,+got):\n%s", diff)
+			}
+			if owned := metav1.IsControlledBy(pg, c.job); owned != c.wantOwnedByTheJob {
+				t.Errorf("want owned by the job %v, got %v", c.wantOwnedByTheJob, owned)
+			}
+		})
+	}
+}
+
+func TestPodGroupLabeler(t *testing.T) {
+	ctx := context.TODO()
+	gangJob := makeJob("gang", map[string]string{v1alpha1.PodGroupGangAnnotation: "true"}, ptr.To[int32](2), nil)
+	plainJob := makeJob("plain", nil, ptr.To[int32](2), nil)
+	jobRef := func(job *batchv1.Job) []metav1.OwnerReference {
+		return []metav1.OwnerReference{*metav1.NewControllerRef(job, batchv1.SchemeGroupVersion.WithKind("Job"))}
+	}
+	cases := []struct {
+		name      string
+		pod       *v1.Pod
+		uncached  bool
+		wantLabel string
+	}{
+		{
+			name:      "pod of a gang job",
+			pod:       &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p", OwnerReferences: jobRef(gangJob)}},
+			wantLabel: "gang",
+		},
+		{
+			name:      "pod of a gang job not in the cache yet",
+			pod:       &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p", OwnerReferences: jobRef(gangJob)}},
+			uncached:  true,
+			wantLabel: "gang",
+		},
+		{
+			name: "pod of a job without gang annotation",
+			pod:  &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p", OwnerReferences: jobRef(plainJob)}},
+		},
+		{
+			name: "pod without controller",
+			pod:  &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p"}},
+		},
+		{
+			name: "pod with pod group already set",
+			pod: &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name:            "p",
+				Labels:          map[string]string{v1alpha1.PodGroupLabel: "custom"},
+				OwnerReferences: jobRef(gangJob),
+			}},
+			wantLabel: "custom",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newOwnerTestScheme(t)
+			cached := []runtime.Object{gangJob, plainJob}
+			if c.uncached {
+				cached = nil
+			}
+			labeler := &PodGroupLabeler{
+				Client:    fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(cached...).Build(),
+				APIReader: fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(gangJob, plainJob).Build(),
+				Decoder:   admission.NewDecoder(s),
+				Adapters:  []PodGroupOwnerAdapter{&JobAdapter{}},
+			}
+			raw, err := json.Marshal(c.pod)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp := labeler.Handle(ctx, admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Namespace: metav1.NamespaceDefault,
+				Object:    runtime.RawExtension{Raw: raw},
+			}})
+			if !resp.Allowed {
+				t.Fatalf("want the pod to be allowed, got %v", resp.Result)
+			}
+
+			gotLabel := c.pod.Labels[v1alpha1.PodGroupLabel]
+			for _, patch := range resp.Patches {
+				if patch.Operation == "add" && patch.Path == "/metadata/labels" {
+					gotLabel = patch.Value.(map[string]interface{})[v1alpha1.PodGroupLabel].(string)
+				}
+			}
+			if gotLabel != c.wantLabel {
+				t.Errorf("want pod group label %q, got %q", c.wantLabel, gotLabel)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	schedv1alpha1 "sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// PodGroupLabelerPath is the path serving the PodGroupLabeler webhook.
+const PodGroupLabelerPath = "/mutate-v1-pod"
+
+// +kubebuilder:webhook:path=/mutate-v1-pod,mutating=true,failurePolicy=ignore,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=podgroup-labeler.scheduling.x-k8s.io,admissionReviewVersions=v1
+
+// PodGroupLabeler is a mutating admission webhook which labels the pods of the gang workloads
+// with the pod group created for them by PodGroupOwnerReconciler.
+type PodGroupLabeler struct {
+	Client client.Client
+	// APIReader reads the controllers of the pods missing from the cache of Client, since pods are
+	// usually created right after their controller, before the cache observes it.
+	APIReader client.Reader
+	Decoder   admission.Decoder
+	Adapters  []PodGroupOwnerAdapter
+}
+
+var _ admission.Handler = &PodGroupLabeler{}
+
+// Handle labels the pod with the pod group of its controller, if the controller is a gang workload.
+func (l *PodGroupLabeler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	pod := &v1.Pod{}
+	if err := l.Decoder.Decode(req, pod); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if util.GetPodGroupLabel(pod) != "" {
+		return admission.Allowed("pod group already set")
+	}
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil {
+		return admission.Allowed("pod has no controller")
+	}
+	adapter := l.getAdapter(ref)
+	if adapter == nil {
+		return admission.Allowed("controller of the pod is not supported")
+	}
+
+	// The namespace of the pod may not be set yet upon creation.
+	owner := adapter.NewObject()
+	key := types.NamespacedName{Namespace: req.Namespace, Name: ref.Name}
+	err := l.Client.Get(ctx, key, owner)
+	if apierrs.IsNotFound(err) && l.APIReader != nil {
+		err = l.APIReader.Get(ctx, key, owner)
+	}
+	if err != nil {
+		if apierrs.IsNotFound(err) {
+			return admission.Allowed("controller of the pod not found")
+		}
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if owner.GetUID() != ref.UID || !isGangOwner(owner) {
+		return admission.Allowed("controller of the pod is not a gang")
+	}
+
+	if pod.Labels == nil {
+		pod.Labels = make(map[string]string)
+	}
+	pod.Labels[schedv1alpha1.PodGroupLabel] = owner.GetName()
+	marshaled, err := json.Marshal(pod)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+// getAdapter returns the adapter of the kind of the given owner, or nil if it is not supported.
+func (l *PodGroupLabeler) getAdapter(ref *metav1.OwnerReference) PodGroupOwnerAdapter {
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return nil
+	}
+	for _, adapter := range l.Adapters {
+		gvk := adapter.GroupVersionKind()
+		if gvk.Group == gv.Group && gvk.Kind == ref.Kind {
+			return adapter
+		}
+	}
+	return nil
+}
+
+// SetupWithManager registers the webhook in the webhook server of the Manager.
+func (l *PodGroupLabeler) SetupWithManager(mgr ctrl.Manager) {
+	l.Client = mgr.GetClient()
+	l.APIReader = mgr.GetAPIReader()
+	l.Decoder = admission.NewDecoder(mgr.GetScheme())
+	mgr.GetWebhookServer().Register(PodGroupLabelerPath, &webhook.Admission{Handler: l})
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	schedv1alpha1 "sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+)
+
+// PodGroupValidatorPath is the path serving the PodGroupValidator webhook.
+const PodGroupValidatorPath = "/validate-scheduling-x-k8s-io-v1alpha1-podgroup"
+
+// MaxScheduleTimeoutSeconds is the maximal schedule timeout of a pod group. The members of a pod group
+// reserve their nodes while waiting for their siblings, so they shouldn't wait longer than a day.
+const MaxScheduleTimeoutSeconds = 24 * 60 * 60
+
+// +kubebuilder:webhook:path=/validate-scheduling-x-k8s-io-v1alpha1-podgroup,mutating=false,failurePolicy=fail,sideEffects=None,groups=scheduling.x-k8s.io,resources=podgroups,verbs=create;update,versions=v1alpha1,name=podgroup-validator.scheduling.x-k8s.io,admissionReviewVersions=v1
+
+// PodGroupValidator is a validating admission webhook which denies the pod groups
+// Coscheduling can't honor.
+type PodGroupValidator struct {
+	Decoder admission.Decoder
+}
+
+var _ admission.Handler = &PodGroupValidator{}
+
+// Handle denies the pod group if its pods can't reference it by label, or its members or timeout are invalid.
+func (v *PodGroupValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	pg := &schedv1alpha1.PodGroup{}
+	if err := v.Decoder.Decode(req, pg); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if allErrs := validatePodGroup(pg); len(allErrs) > 0 {
+		return admission.Denied(allErrs.ToAggregate().Error())
+	}
+	return admission.Allowed("")
+}
+
+// validatePodGroup validates the name and the spec of the given pod group.
+func validatePodGroup(pg *schedv1alpha1.PodGroup) field.ErrorList {
+	var allErrs field.ErrorList
+	// The pods reference the pod group by the value of their label, which is shorter than object names.
+	if msgs := validation.IsValidLabelValue(pg.Name); len(msgs) > 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("metadata", "name"), pg.Name,
+			fmt.Sprintf("must be a valid value of the %v label of its pods: %v", schedv1alpha1.PodGroupLabel, strings.Join(msgs, "; "))))
+	}
+
+	specPath := field.NewPath("spec")
+	if pg.Spec.MinMember < 1 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("minMember"), pg.Spec.MinMember, "must be greater than 0"))
+	}
+	if pg.Spec.MaxMember != nil && *pg.Spec.MaxMember < pg.Spec.MinMember {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("maxMember"), *pg.Spec.MaxMember,
+			fmt.Sprintf("must be greater than or equal to %v (%v)", specPath.Child("minMember"), pg.Spec.MinMember)))
+	}
+	if timeout := pg.Spec.ScheduleTimeoutSeconds; timeout != nil && (*timeout < 1 || *timeout > MaxScheduleTimeoutSeconds) {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("scheduleTimeoutSeconds"), *timeout,
+			fmt.Sprintf("must be between 1 and %v", MaxScheduleTimeoutSeconds)))
+	}
+	for _, name := range sortedResourceNames(pg.Spec.MinResources) {
+		if quantity := pg.Spec.MinResources[name]; quantity.Sign() < 0 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("minResources").Key(string(name)), quantity.String(), "must be greater than or equal to 0"))
+		}
+	}
+
+	rolesPath := specPath.Child("roles")
+	var rolesMinMember int32
+	for i, role := range pg.Spec.Roles {
+		// The pods play the role named by the value of their label.
+		if role.Name == "" {
+			allErrs = append(allErrs, field.Required(rolesPath.Index(i).Child("name"), ""))
+		} else if msgs := validation.IsValidLabelValue(role.Name); len(msgs) > 0 {
+			allErrs = append(allErrs, field.Invalid(rolesPath.Index(i).Child("name"), role.Name,
+				fmt.Sprintf("must be a valid value of the %v label of its pods: %v", schedv1alpha1.PodGroupRoleLabel, strings.Join(msgs, "; "))))
+		}
+		if role.MinMember < 1 {
+			allErrs = append(allErrs, field.Invalid(rolesPath.Index(i).Child("minMember"), role.MinMember, "must be greater than 0"))
+		}
+		rolesMinMember += role.MinMember
+	}
+	if pg.Spec.MaxMember != nil && rolesMinMember > *pg.Spec.MaxMember {
+		allErrs = append(allErrs, field.Invalid(rolesPath, rolesMinMember,
+			fmt.Sprintf("the minMember of the roles must sum up to at most %v (%v)", specPath.Child("maxMember"), *pg.Spec.MaxMember)))
+	}
+	return allErrs
+}
+
+// SetupWithManager registers the webhook in the webhook server of the Manager.
+func (v *PodGroupValidator) SetupWithManager(mgr ctrl.Manager) {
+	v.Decoder = admission.NewDecoder(mgr.GetScheme())
+	mgr.GetWebhookServer().Register(PodGroupValidatorPath, &webhook.Admission{Handler: v})
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+)
+
+func TestPodGroupValidator(t *testing.T) {
+	ctx := context.TODO()
+	makePodGroup := func(name string, spec v1alpha1.PodGroupSpec) *v1alpha1.PodGroup {
+		return &v1alpha1.PodGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: metav1.NamespaceDefault},
+			Spec:       spec,
+		}
+	}
+	cases := []struct {
+		name       string
+		pg         *v1alpha1.PodGroup
+		wantDenied []string
+	}{
+		{
+			name: "valid pod group",
+			pg: makePodGroup("pg", v1alpha1.PodGroupSpec{
+				MinMember:              3,
+				MaxMember:              ptr.To[int32](5),
+				ScheduleTimeoutSeconds: ptr.To[int32](60),
+				Roles: []v1alpha1.PodGroupRole{
+					{Name: "launcher", MinMember: 1},
+					{Name: "worker", MinMember: 2},
+				},
+			}),
+		},
+		{
+			name:       "zero minMember",
+			pg:         makePodGroup("pg", v1alpha1.PodGroupSpec{}),
+			wantDenied: []string{"spec.minMember: Invalid value: 0: must be greater than 0"},
+		},
+		{
+			name:       "maxMember less than minMember",
+			pg:         makePodGroup("pg", v1alpha1.PodGroupSpec{MinMember: 3, MaxMember: ptr.To[int32](2)}),
+			wantDenied: []string{"spec.maxMember: Invalid value: 2: must be greater than or equal to spec.minMember (3)"},
+		},
+		{
+			name:       "zero scheduleTimeoutSeconds",
+			pg:         makePodGroup("pg", v1alpha1.PodGroupSpec{MinMember: 1, ScheduleTimeoutSeconds: ptr.To[int32](0)}),
+			wantDenied: []string{"spec.scheduleTimeoutSeconds: Invalid value: 0: must be between 1 and 86400"},
+		},
+		{
+			name:       "name too long for a label",
+			pg:         makePodGroup(strings.Repeat("a", 64), v1alpha1.PodGroupSpec{MinMember: 1}),
+			wantDenied: []string{"metadata.name: Invalid value", "must be a valid value of the scheduling.x-k8s.io/pod-group label"},
+		},
+		{
+			name: "invalid roles",
+			pg: makePodGroup("pg", v1alpha1.PodGroupSpec{
+				MinMember: 1,
+				MaxMember: ptr.To[int32](2),
+				Roles: []v1alpha1.PodGroupRole{
+					{Name: "launcher/0", MinMember: 1},
+					{Name: "worker", MinMember: 2},
+				},
+			}),
+			wantDenied: []string{
+				"spec.roles[0].name: Invalid value: \"launcher/0\"",
+				"spec.roles: Invalid value: 3: the minMember of the roles must sum up to at most spec.maxMember (2)",
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			validator := &PodGroupValidator{Decoder: admission.NewDecoder(newOwnerTestScheme(t))}
+			resp := validator.Handle(ctx, admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Namespace: metav1.NamespaceDefault,
+				Object:    runtime.RawExtension{Raw: marshal(t, c.pg)},
+			}})
+			checkAdmissionResponse(t, resp, c.wantDenied)
+		})
+	}
+}
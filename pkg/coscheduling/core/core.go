@@ -18,20 +18,25 @@ package core
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sync"
 	"time"
 
 	gocache "github.com/patrickmn/go-cache"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	informerv1 "k8s.io/client-go/informers/core/v1"
 	listerv1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -52,8 +57,18 @@ const (
 	Wait             Status = "Wait"
 
 	permitStateKey = "PermitCoscheduling"
+	// fieldManager is the prefix of the field managers applying the podgroup conditions set by the scheduler.
+	fieldManager = "coscheduling"
 )
 
+// backoffState records the consecutive scheduling failures of a podgroup.
+type backoffState struct {
+	// failures is the number of consecutive times the podgroup failed to be scheduled.
+	failures int32
+	// nextRetry is the time before which the podgroup is backed off.
+	nextRetry time.Time
+}
+
 type PermitState struct {
 	Activate bool
 }
@@ -69,10 +84,14 @@ type Manager interface {
 	Unreserve(context.Context, *corev1.Pod)
 	GetPodGroup(context.Context, *corev1.Pod) (string, *v1alpha1.PodGroup)
 	GetAssignedPodCount(string) int
+	GetAssignedPods(string) sets.Set[string]
+	IsQuorumReached(string, *v1alpha1.PodGroup) bool
 	GetCreationTimestamp(context.Context, *corev1.Pod, time.Time) time.Time
 	DeletePermittedPodGroup(context.Context, string)
 	ActivateSiblings(ctx context.Context, pod *corev1.Pod, state *framework.CycleState)
-	BackoffPodGroup(string, time.Duration)
+	BackoffPodGroup(context.Context, string, *v1alpha1.PodGroup, time.Duration, time.Duration)
+	SetPodGroupConditions(context.Context, *v1alpha1.PodGroup, ...metav1.Condition)
+	CountRecentAdmissions(string, time.Duration) int
 }
 
 // PodGroupManager defines the scheduling operation called
@@ -88,10 +107,29 @@ type PodGroupManager struct {
 	permittedPG *gocache.Cache
 	// backedOffPG stores the podgorup name which failed scheudling recently.
 	backedOffPG *gocache.Cache
+	// backoffStates stores the consecutive scheduling failures of podgroups, until they get scheduled.
+	backoffStates map[string]*backoffState
+	// backoffLock protects backoffStates.
+	backoffLock sync.Mutex
 	// podLister is pod lister
 	podLister listerv1.PodLister
 	// assignedPodsByPG stores the pods assumed or bound for podgroups
 	assignedPodsByPG map[string]sets.Set[string]
+	// admittedPG stores the podgroups that reached their quorum, until none of their pods is assigned.
+	admittedPG sets.Set[string]
+	// admissionsByNamespace stores the times podgroups were admitted in each namespace, oldest first.
+	admissionsByNamespace map[string][]time.Time
+	// admissionLock protects admittedPG and admissionsByNamespace.
+	admissionLock sync.Mutex
+	// conditionsQueue queues the podgroups whose status has to be updated, off the scheduling cycles.
+	conditionsQueue workqueue.TypedRateLimitingInterface[string]
+	// pendingConditions stores the last conditions set for the queued podgroups, by podgroup and condition type.
+	pendingConditions map[string]map[string]metav1.Condition
+	// pendingStatus stores the last status fields, other than the conditions, set for the queued podgroups,
+	// by podgroup and field.
+	pendingStatus map[string]map[string]interface{}
+	// conditionsLock protects pendingConditions and pendingStatus.
+	conditionsLock sync.Mutex
 	sync.RWMutex
 }
 
@@ -127,7 +165,14 @@ func NewPodGroupManager(client client.Client, snapshotSharedLister framework.Sha
 		podLister:            podInformer.Lister(),
 		permittedPG:          gocache.New(3*time.Second, 3*time.Second),
 		backedOffPG:          gocache.New(10*time.Second, 10*time.Second),
+		backoffStates:        map[string]*backoffState{},
 		assignedPodsByPG:     map[string]sets.Set[string]{},
+		conditionsQueue: workqueue.NewTypedRateLimitingQueueWithConfig(
+			workqueue.DefaultTypedControllerRateLimiter[string](),
+			workqueue.TypedRateLimitingQueueConfig[string]{Name: "CoschedulingPodGroupConditions"},
+		),
+		pendingConditions: map[string]map[string]metav1.Condition{},
+		pendingStatus:     map[string]map[string]interface{}{},
 	}
 	podInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: AddPodFactory(pgMgr),
@@ -164,11 +209,321 @@ func (pgMgr *PodGroupManager) GetAssignedPodCount(pgName string) int {
 	return len(pgMgr.assignedPodsByPG[pgName])
 }
 
-func (pgMgr *PodGroupManager) BackoffPodGroup(pgName string, backoff time.Duration) {
-	if backoff == time.Duration(0) {
+// GetAssignedPods returns a copy of the names of pods that are assumed or bound for the given podGroup.
+func (pgMgr *PodGroupManager) GetAssignedPods(pgName string) sets.Set[string] {
+	pgMgr.RWMutex.RLock()
+	defer pgMgr.RWMutex.RUnlock()
+	return pgMgr.assignedPodsByPG[pgName].Clone()
+}
+
+// IsQuorumReached checks whether the pods assumed or bound for the given podGroup satisfy
+// its minMember, and the minMember of each of its roles.
+func (pgMgr *PodGroupManager) IsQuorumReached(pgName string, pg *v1alpha1.PodGroup) bool {
+	pgMgr.RWMutex.RLock()
+	defer pgMgr.RWMutex.RUnlock()
+	return pgMgr.isQuorumReached(pg, pgMgr.assignedPodsByPG[pgName], nil)
+}
+
+// isQuorumReached checks whether the given assigned pods satisfy the minMember of the given podGroup,
+// and the minMember of each of its roles. The role of the given pod, if any, is taken from the pod
+// itself rather than the lister. The caller must hold the lock.
+func (pgMgr *PodGroupManager) isQuorumReached(pg *v1alpha1.PodGroup, assigned sets.Set[string], pod *corev1.Pod) bool {
+	if len(assigned) < int(pg.Spec.MinMember) {
+		return false
+	}
+	if len(pg.Spec.Roles) == 0 {
+		return true
+	}
+	pods, err := pgMgr.podLister.Pods(pg.Namespace).List(
+		labels.SelectorFromSet(labels.Set{v1alpha1.PodGroupLabel: pg.Name}),
+	)
+	if err != nil {
+		return false
+	}
+	podsByRole := make(map[string]int32)
+	for _, p := range pods {
+		if assigned.Has(p.Name) && (pod == nil || p.Name != pod.Name) {
+			podsByRole[util.GetPodGroupRole(p)]++
+		}
+	}
+	if pod != nil && assigned.Has(pod.Name) {
+		podsByRole[util.GetPodGroupRole(pod)]++
+	}
+	return util.CheckPodGroupRoles(pg, podsByRole) == nil
+}
+
+// BackoffPodGroup backs off the given podgroup from being scheduled for a while. The backoff starts
+// at initialBackoff and doubles on every consecutive failure of the podgroup, up to maxBackoff.
+// The number of consecutive failures and the next retry time are mirrored into the podgroup status.
+func (pgMgr *PodGroupManager) BackoffPodGroup(ctx context.Context, pgName string, pg *v1alpha1.PodGroup, initialBackoff, maxBackoff time.Duration) {
+	if initialBackoff == time.Duration(0) {
 		return
 	}
+	// Pods rejected while the podgroup is backed off don't count as new failures.
+	if _, exist := pgMgr.backedOffPG.Get(pgName); exist {
+		return
+	}
+
+	pgMgr.backoffLock.Lock()
+	state, exist := pgMgr.backoffStates[pgName]
+	if !exist {
+		state = &backoffState{}
+		pgMgr.backoffStates[pgName] = state
+	}
+	backoff := getBackoffDuration(state.failures, initialBackoff, maxBackoff)
+	state.failures++
+	state.nextRetry = time.Now().Add(backoff)
+	failures, nextRetry := state.failures, metav1.NewTime(state.nextRetry)
+	pgMgr.backoffLock.Unlock()
+
 	pgMgr.backedOffPG.Add(pgName, nil, backoff)
+	klog.FromContext(ctx).V(4).Info("Backing off the pod group", "podGroup", klog.KObj(pg), "failures", failures, "backoff", backoff)
+	pgMgr.setPodGroupStatus(pg, map[string]interface{}{
+		"schedulingFailures": failures,
+		"nextRetryTime":      &nextRetry,
+	})
+	pgMgr.SetPodGroupConditions(ctx, pg, metav1.Condition{
+		Type:    v1alpha1.PodGroupBackoff,
+		Status:  metav1.ConditionTrue,
+		Reason:  v1alpha1.PodGroupReasonBackedOff,
+		Message: fmt.Sprintf("Backed off for %v after %d consecutive scheduling failures", backoff, failures),
+	})
+}
+
+// PodGroupEventHandler returns the event handler forgetting the scheduling failures of the podgroups that are
+// deleted, or that finished or failed for good, which are never scheduled again.
+func (pgMgr *PodGroupManager) PodGroupEventHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(_, newObj interface{}) {
+			pg, ok := newObj.(*v1alpha1.PodGroup)
+			if !ok || (pg.Status.Phase != v1alpha1.PodGroupFinished && pg.Status.Phase != v1alpha1.PodGroupFailed) {
+				return
+			}
+			pgMgr.forgetBackoff(GetNamespacedName(pg))
+		},
+		DeleteFunc: func(obj interface{}) {
+			if t, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = t.Obj
+			}
+			if pg, ok := obj.(*v1alpha1.PodGroup); ok {
+				pgMgr.forgetBackoff(GetNamespacedName(pg))
+			}
+		},
+	}
+}
+
+// forgetBackoff forgets the scheduling failures of the given podgroup.
+func (pgMgr *PodGroupManager) forgetBackoff(pgName string) {
+	pgMgr.backoffLock.Lock()
+	defer pgMgr.backoffLock.Unlock()
+	delete(pgMgr.backoffStates, pgName)
+}
+
+// markQuorumReached records that the given podgroup reached its quorum, and forgets
+// its scheduling failures.
+func (pgMgr *PodGroupManager) markQuorumReached(ctx context.Context, pgName string, pg *v1alpha1.PodGroup) {
+	pgMgr.backoffLock.Lock()
+	_, backedOff := pgMgr.backoffStates[pgName]
+	delete(pgMgr.backoffStates, pgName)
+	pgMgr.backoffLock.Unlock()
+
+	if backedOff {
+		pgMgr.backedOffPG.Delete(pgName)
+		pgMgr.setPodGroupStatus(pg, map[string]interface{}{
+			"schedulingFailures": 0,
+			"nextRetryTime":      nil,
+		})
+	}
+	updated := []metav1.Condition{{
+		Type:    v1alpha1.PodGroupQuorumReached,
+		Status:  metav1.ConditionTrue,
+		Reason:  v1alpha1.PodGroupReasonQuorumReached,
+		Message: fmt.Sprintf("Quorum of %d pods reached", pg.Spec.MinMember),
+	}}
+	// Clear the conditions left over from previous failures.
+	for _, conditionType := range []string{v1alpha1.PodGroupBackoff, v1alpha1.PodGroupTimedOut} {
+		if meta.IsStatusConditionTrue(pg.Status.Conditions, conditionType) {
+			updated = append(updated, metav1.Condition{
+				Type:   conditionType,
+				Status: metav1.ConditionFalse,
+				Reason: v1alpha1.PodGroupReasonQuorumReached,
+			})
+		}
+	}
+	pgMgr.SetPodGroupConditions(ctx, pg, updated...)
+}
+
+// SetPodGroupConditions sets the given conditions in the status of the given podgroup, if they changed.
+// The conditions are applied in the background by Run, so that the scheduling cycles don't wait for the API server:
+// the conditions set for a podgroup meanwhile are merged by type, and only the last ones are applied.
+func (pgMgr *PodGroupManager) SetPodGroupConditions(ctx context.Context, pg *v1alpha1.PodGroup, conditions ...metav1.Condition) {
+	pgName := GetNamespacedName(pg)
+	queued := false
+	pgMgr.conditionsLock.Lock()
+	pending := pgMgr.pendingConditions[pgName]
+	for _, condition := range conditions {
+		// A pending condition has to be overridden even if it's unchanged in the cached podgroup.
+		if _, ok := pending[condition.Type]; !ok && len(util.ChangedConditions(pg.Status.Conditions, condition)) == 0 {
+			continue
+		}
+		if pending == nil {
+			pending = map[string]metav1.Condition{}
+			pgMgr.pendingConditions[pgName] = pending
+		}
+		pending[condition.Type] = condition
+		queued = true
+	}
+	pgMgr.conditionsLock.Unlock()
+	if queued {
+		pgMgr.conditionsQueue.Add(pgName)
+	}
+}
+
+// setPodGroupStatus sets the given fields, other than the conditions, in the status of the given podgroup.
+// The fields are patched in the background by Run along with the conditions, the last value set for each field wins.
+func (pgMgr *PodGroupManager) setPodGroupStatus(pg *v1alpha1.PodGroup, status map[string]interface{}) {
+	pgName := GetNamespacedName(pg)
+	pgMgr.conditionsLock.Lock()
+	pending := pgMgr.pendingStatus[pgName]
+	if pending == nil {
+		pending = map[string]interface{}{}
+		pgMgr.pendingStatus[pgName] = pending
+	}
+	for field, value := range status {
+		pending[field] = value
+	}
+	pgMgr.conditionsLock.Unlock()
+	pgMgr.conditionsQueue.Add(pgName)
+}
+
+// Run applies the podgroup status and conditions set by the scheduling cycles, until the given context is done.
+func (pgMgr *PodGroupManager) Run(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+		pgMgr.conditionsQueue.ShutDown()
+	}()
+	go wait.UntilWithContext(ctx, func(ctx context.Context) {
+		for pgMgr.processNextPodGroupConditions(ctx) {
+		}
+	}, time.Second)
+}
+
+// processNextPodGroupConditions patches the pending status fields of the next queued podgroup, then applies its
+// pending conditions which differ from the cached podgroup. It returns false when the queue is shut down.
+func (pgMgr *PodGroupManager) processNextPodGroupConditions(ctx context.Context) bool {
+	pgName, quit := pgMgr.conditionsQueue.Get()
+	if quit {
+		return false
+	}
+	defer pgMgr.conditionsQueue.Done(pgName)
+
+	pgMgr.conditionsLock.Lock()
+	pendingStatus := pgMgr.pendingStatus[pgName]
+	delete(pgMgr.pendingStatus, pgName)
+	pending := pgMgr.pendingConditions[pgName]
+	delete(pgMgr.pendingConditions, pgName)
+	pgMgr.conditionsLock.Unlock()
+	if len(pendingStatus) == 0 && len(pending) == 0 {
+		pgMgr.conditionsQueue.Forget(pgName)
+		return true
+	}
+
+	err := pgMgr.patchStatus(ctx, pgName, pendingStatus)
+	if err == nil {
+		pendingStatus = nil
+		err = pgMgr.applyPodGroupConditions(ctx, pgName, pending)
+	}
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			pgMgr.conditionsQueue.Forget(pgName)
+			return true
+		}
+		klog.FromContext(ctx).Error(err, "Failed to update the status", "podGroup", pgName)
+		// Retry the status fields and conditions which weren't set again meanwhile.
+		pgMgr.conditionsLock.Lock()
+		if len(pendingStatus) != 0 {
+			current := pgMgr.pendingStatus[pgName]
+			if current == nil {
+				current = map[string]interface{}{}
+				pgMgr.pendingStatus[pgName] = current
+			}
+			for field, value := range pendingStatus {
+				if _, ok := current[field]; !ok {
+					current[field] = value
+				}
+			}
+		}
+		if len(pending) != 0 {
+			current := pgMgr.pendingConditions[pgName]
+			if current == nil {
+				current = map[string]metav1.Condition{}
+				pgMgr.pendingConditions[pgName] = current
+			}
+			for conditionType, condition := range pending {
+				if _, ok := current[conditionType]; !ok {
+					current[conditionType] = condition
+				}
+			}
+		}
+		pgMgr.conditionsLock.Unlock()
+		pgMgr.conditionsQueue.AddRateLimited(pgName)
+		return true
+	}
+	pgMgr.conditionsQueue.Forget(pgName)
+	return true
+}
+
+// applyPodGroupConditions applies the given conditions which differ from the cached podgroup of the given name.
+// Conditions are applied one by one, so that the conditions set by the PodGroup controller are kept.
+func (pgMgr *PodGroupManager) applyPodGroupConditions(ctx context.Context, pgName string, pending map[string]metav1.Condition) error {
+	if len(pending) == 0 {
+		return nil
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(pgName)
+	if err != nil {
+		return err
+	}
+	var pg v1alpha1.PodGroup
+	if err := pgMgr.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &pg); err != nil {
+		return err
+	}
+	conditions := make([]metav1.Condition, 0, len(pending))
+	for _, conditionType := range sets.List(sets.KeySet(pending)) {
+		conditions = append(conditions, pending[conditionType])
+	}
+	changed := util.ChangedConditions(pg.Status.Conditions, conditions...)
+	return util.ApplyPodGroupConditions(ctx, pgMgr.client, &pg, fieldManager, changed...)
+}
+
+// patchStatus patches the given fields, other than the conditions, into the status of the podgroup of the given name.
+// The fields are always sent, as the podgroup in the cache may lag behind the state tracked by the scheduler.
+func (pgMgr *PodGroupManager) patchStatus(ctx context.Context, pgName string, status map[string]interface{}) error {
+	if len(status) == 0 {
+		return nil
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(pgName)
+	if err != nil {
+		return err
+	}
+	patch, err := json.Marshal(map[string]interface{}{"status": status})
+	if err != nil {
+		return err
+	}
+	pg := &v1alpha1.PodGroup{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	return pgMgr.client.Status().Patch(ctx, pg, client.RawPatch(types.MergePatchType, patch))
+}
+
+// getBackoffDuration returns the backoff of a podgroup which failed the given number of times
+// in a row, doubling initialBackoff for each previous failure up to maxBackoff.
+func getBackoffDuration(failures int32, initialBackoff, maxBackoff time.Duration) time.Duration {
+	backoff := initialBackoff
+	for i := int32(0); i < failures && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		return maxBackoff
+	}
+	return backoff
 }
 
 // ActivateSiblings stashes the pods belonging to the same PodGroup of the given pod
@@ -218,8 +573,11 @@ func (pgMgr *PodGroupManager) ActivateSiblings(ctx context.Context, pod *corev1.
 
 // PreFilter filters out a pod if
 // 1. it belongs to a podgroup that was recently denied or
-// 2. the total number of pods in the podgroup is less than the minimum number of pods
-// that is required to be scheduled.
+// 2. it belongs to an elastic podgroup that already has maxMember pods assigned or
+// 3. the total number of pods in the podgroup is less than the minimum number of pods
+// that is required to be scheduled or
+// 4. the number of pods playing any role of the podgroup is less than the minimum number
+// of pods of that role.
 func (pgMgr *PodGroupManager) PreFilter(ctx context.Context, pod *corev1.Pod) error {
 	lh := klog.FromContext(ctx)
 	lh.V(5).Info("Pre-filter", "pod", klog.KObj(pod))
@@ -232,6 +590,16 @@ func (pgMgr *PodGroupManager) PreFilter(ctx context.Context, pod *corev1.Pod) er
 		return fmt.Errorf("podGroup %v failed recently", pgFullName)
 	}
 
+	if maxMember, ok := util.GetPodGroupMaxMember(pg); ok {
+		pgMgr.RWMutex.RLock()
+		assigned := pgMgr.assignedPodsByPG[pgFullName]
+		full := len(assigned) >= int(maxMember) && !assigned.Has(pod.Name)
+		pgMgr.RWMutex.RUnlock()
+		if full {
+			return fmt.Errorf("podGroup %v already has maxMember %v pods assigned", pgFullName, maxMember)
+		}
+	}
+
 	pods, err := pgMgr.podLister.Pods(pod.Namespace).List(
 		labels.SelectorFromSet(labels.Set{v1alpha1.PodGroupLabel: util.GetPodGroupLabel(pod)}),
 	)
@@ -244,6 +612,16 @@ func (pgMgr *PodGroupManager) PreFilter(ctx context.Context, pod *corev1.Pod) er
 			"current pods number: %v, minMember of group: %v", pod.Name, len(pods), pg.Spec.MinMember)
 	}
 
+	if len(pg.Spec.Roles) != 0 {
+		podsByRole := make(map[string]int32)
+		for _, p := range pods {
+			podsByRole[util.GetPodGroupRole(p)]++
+		}
+		if err := util.CheckPodGroupRoles(pg, podsByRole); err != nil {
+			return fmt.Errorf("pre-filter pod %v cannot find enough sibling pods: %w", pod.Name, err)
+		}
+	}
+
 	if pg.Spec.MinResources == nil {
 		return nil
 	}
@@ -284,7 +662,6 @@ func (pgMgr *PodGroupManager) Permit(ctx context.Context, state *framework.Cycle
 	}
 
 	pgMgr.RWMutex.RLock()
-	defer pgMgr.RWMutex.RUnlock()
 	assigned, exist := pgMgr.assignedPodsByPG[pgFullName]
 	if !exist {
 		assigned = sets.Set[string]{}
@@ -293,11 +670,17 @@ func (pgMgr *PodGroupManager) Permit(ctx context.Context, state *framework.Cycle
 	assigned.Insert(pod.Name)
 	// The number of pods that have been assigned nodes is calculated from the snapshot.
 	// The current pod in not included in the snapshot during the current scheduling cycle.
-	if len(assigned) >= int(pg.Spec.MinMember) {
+	quorumReached := pgMgr.isQuorumReached(pg, assigned, pod)
+	assignedCount := len(assigned)
+	pgMgr.RWMutex.RUnlock()
+
+	if quorumReached {
+		pgMgr.recordAdmission(pgFullName, pg.Namespace)
+		pgMgr.markQuorumReached(ctx, pgFullName, pg)
 		return Success
 	}
 
-	if len(assigned) == 1 {
+	if assignedCount == 1 {
 		// Given we've reached Permit(), it's mean all PreFilter checks (minMember & minResource)
 		// already pass through, so if len(assigned) == 1, it could be due to:
 		// - minResource get satisfied
@@ -326,10 +709,51 @@ func (pgMgr *PodGroupManager) Unreserve(ctx context.Context, pod *corev1.Pod) {
 		assigned.Delete(pod.Name)
 		if len(assigned) == 0 {
 			delete(pgMgr.assignedPodsByPG, pgFullName)
+			pgMgr.forgetAdmission(pgFullName)
 		}
 	}
 }
 
+// recordAdmission records the admission of the given podGroup, unless it has already been admitted.
+func (pgMgr *PodGroupManager) recordAdmission(pgFullName, namespace string) {
+	pgMgr.admissionLock.Lock()
+	defer pgMgr.admissionLock.Unlock()
+	if pgMgr.admittedPG == nil {
+		pgMgr.admittedPG = sets.New[string]()
+		pgMgr.admissionsByNamespace = make(map[string][]time.Time)
+	}
+	if pgMgr.admittedPG.Has(pgFullName) {
+		return
+	}
+	pgMgr.admittedPG.Insert(pgFullName)
+	pgMgr.admissionsByNamespace[namespace] = append(pgMgr.admissionsByNamespace[namespace], time.Now())
+}
+
+// forgetAdmission forgets the given podGroup as admitted, so that it's recorded again on its next admission.
+func (pgMgr *PodGroupManager) forgetAdmission(pgFullName string) {
+	pgMgr.admissionLock.Lock()
+	defer pgMgr.admissionLock.Unlock()
+	pgMgr.admittedPG.Delete(pgFullName)
+}
+
+// CountRecentAdmissions returns the number of podGroups admitted in the given namespace within the given window.
+func (pgMgr *PodGroupManager) CountRecentAdmissions(namespace string, window time.Duration) int {
+	pgMgr.admissionLock.Lock()
+	defer pgMgr.admissionLock.Unlock()
+	admissions := pgMgr.admissionsByNamespace[namespace]
+	cutoff := time.Now().Add(-window)
+	i := 0
+	for i < len(admissions) && admissions[i].Before(cutoff) {
+		i++
+	}
+	if i == len(admissions) {
+		delete(pgMgr.admissionsByNamespace, namespace)
+		return 0
+	}
+	pgMgr.admissionsByNamespace[namespace] = admissions[i:]
+	return len(admissions) - i
+}
+
 // GetCreationTimestamp returns the creation time of a podGroup or a pod.
 func (pgMgr *PodGroupManager) GetCreationTimestamp(ctx context.Context, pod *corev1.Pod, ts time.Time) time.Time {
 	pgName := util.GetPodGroupLabel(pod)
@@ -18,13 +18,17 @@ package core
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
 	gocache "github.com/patrickmn/go-cache"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/informers"
 	clientsetfake "k8s.io/client-go/kubernetes/fake"
@@ -50,6 +54,7 @@ func TestPreFilter(t *testing.T) {
 		pod             *corev1.Pod
 		pendingPods     []*corev1.Pod
 		pgs             []*v1alpha1.PodGroup
+		assignedPods    sets.Set[string]
 		expectedSuccess bool
 	}{
 		{
@@ -139,6 +144,56 @@ func TestPreFilter(t *testing.T) {
 			},
 			expectedSuccess: false,
 		},
+		{
+			name: "pod count of a role less than its minMember",
+			pod:  st.MakePod().Name("p1a").Namespace("ns").UID("p1a").Label(v1alpha1.PodGroupLabel, "pg1").Label(v1alpha1.PodGroupRoleLabel, "worker").Obj(),
+			pendingPods: []*corev1.Pod{
+				st.MakePod().Name("p1b").Namespace("ns").UID("p1b").Label(v1alpha1.PodGroupLabel, "pg1").Label(v1alpha1.PodGroupRoleLabel, "worker").Obj(),
+				st.MakePod().Name("p1c").Namespace("ns").UID("p1c").Label(v1alpha1.PodGroupLabel, "pg1").Label(v1alpha1.PodGroupRoleLabel, "worker").Obj(),
+			},
+			pgs: []*v1alpha1.PodGroup{
+				tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).Role("launcher", 1).Role("worker", 1).Obj(),
+			},
+			expectedSuccess: false,
+		},
+		{
+			name: "pod count of every role equal its minMember",
+			pod:  st.MakePod().Name("p1a").Namespace("ns").UID("p1a").Label(v1alpha1.PodGroupLabel, "pg1").Label(v1alpha1.PodGroupRoleLabel, "worker").Obj(),
+			pendingPods: []*corev1.Pod{
+				st.MakePod().Name("p1b").Namespace("ns").UID("p1b").Label(v1alpha1.PodGroupLabel, "pg1").Label(v1alpha1.PodGroupRoleLabel, "launcher").Obj(),
+				st.MakePod().Name("p1c").Namespace("ns").UID("p1c").Label(v1alpha1.PodGroupLabel, "pg1").Label(v1alpha1.PodGroupRoleLabel, "worker").Obj(),
+			},
+			pgs: []*v1alpha1.PodGroup{
+				tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).Role("launcher", 1).Role("worker", 1).Obj(),
+			},
+			expectedSuccess: true,
+		},
+		{
+			name: "elastic pg with fewer than maxMember pods assigned",
+			pod:  st.MakePod().Name("p1c").Namespace("ns").UID("p1c").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+			pendingPods: []*corev1.Pod{
+				st.MakePod().Name("p1a").Namespace("ns").UID("p1a").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+				st.MakePod().Name("p1b").Namespace("ns").UID("p1b").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+			},
+			pgs: []*v1alpha1.PodGroup{
+				tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).MaxMember(3).Obj(),
+			},
+			assignedPods:    sets.New("p1a", "p1b"),
+			expectedSuccess: true,
+		},
+		{
+			name: "elastic pg with maxMember pods assigned",
+			pod:  st.MakePod().Name("p1c").Namespace("ns").UID("p1c").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+			pendingPods: []*corev1.Pod{
+				st.MakePod().Name("p1a").Namespace("ns").UID("p1a").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+				st.MakePod().Name("p1b").Namespace("ns").UID("p1b").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+			},
+			pgs: []*v1alpha1.PodGroup{
+				tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).MaxMember(2).Obj(),
+			},
+			assignedPods:    sets.New("p1a", "p1b"),
+			expectedSuccess: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -181,6 +236,9 @@ func TestPreFilter(t *testing.T) {
 			for _, p := range tt.pendingPods {
 				podInformer.Informer().GetStore().Add(p)
 			}
+			if tt.assignedPods != nil {
+				pgMgr.assignedPodsByPG["ns/pg1"] = tt.assignedPods
+			}
 
 			err = pgMgr.PreFilter(ctx, tt.pod)
 			if (err == nil) != tt.expectedSuccess {
@@ -241,6 +299,28 @@ func TestPermit(t *testing.T) {
 			},
 			want: Success,
 		},
+		{
+			name: "pod belongs to a pg that has enough pods but not for every role",
+			pod:  st.MakePod().Name("p1a").Namespace("ns").UID("p1a").Label(v1alpha1.PodGroupLabel, "pg1").Label(v1alpha1.PodGroupRoleLabel, "worker").Obj(),
+			existingPods: []*corev1.Pod{
+				st.MakePod().Name("p1b").Namespace("ns").UID("p1b").Label(v1alpha1.PodGroupLabel, "pg1").Label(v1alpha1.PodGroupRoleLabel, "worker").Node("node").Obj(),
+			},
+			pgs: []*v1alpha1.PodGroup{
+				tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).Role("launcher", 1).Role("worker", 1).Obj(),
+			},
+			want: Wait,
+		},
+		{
+			name: "pod belongs to a pg that have quorum satisfied for every role",
+			pod:  st.MakePod().Name("p1a").Namespace("ns").UID("p1a").Label(v1alpha1.PodGroupLabel, "pg1").Label(v1alpha1.PodGroupRoleLabel, "launcher").Obj(),
+			existingPods: []*corev1.Pod{
+				st.MakePod().Name("p1b").Namespace("ns").UID("p1b").Label(v1alpha1.PodGroupLabel, "pg1").Label(v1alpha1.PodGroupRoleLabel, "worker").Node("node").Obj(),
+			},
+			pgs: []*v1alpha1.PodGroup{
+				tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).Role("launcher", 1).Role("worker", 1).Obj(),
+			},
+			want: Success,
+		},
 	}
 
 	for _, tt := range tests {
@@ -286,6 +366,216 @@ func TestPermit(t *testing.T) {
 	}
 }
 
+func TestGetBackoffDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int32
+		want     time.Duration
+	}{
+		{
+			name:     "first failure",
+			failures: 0,
+			want:     10 * time.Second,
+		},
+		{
+			name:     "doubled on consecutive failures",
+			failures: 2,
+			want:     40 * time.Second,
+		},
+		{
+			name:     "capped by max backoff",
+			failures: 5,
+			want:     time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getBackoffDuration(tt.failures, 10*time.Second, time.Minute); got != tt.want {
+				t.Errorf("Want %v, but got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBackoffPodGroup(t *testing.T) {
+	ctx := context.Background()
+	pg := tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).Obj()
+	client, err := tu.NewFakeClient(pg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := clientsetfake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(cs, 0)
+	pgMgr := NewPodGroupManager(client, nil, nil, informerFactory.Core().V1().Pods())
+
+	getStatus := func() v1alpha1.PodGroupStatus {
+		var got v1alpha1.PodGroup
+		if err := client.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "pg1"}, &got); err != nil {
+			t.Fatal(err)
+		}
+		return got.Status
+	}
+
+	pgMgr.BackoffPodGroup(ctx, "ns/pg1", pg, time.Minute, 10*time.Minute)
+	if _, exist := pgMgr.backedOffPG.Get("ns/pg1"); !exist {
+		t.Fatal("Want the pod group to be backed off")
+	}
+	// The status is updated in the background, not by the scheduling cycle.
+	if status := getStatus(); status.SchedulingFailures != 0 {
+		t.Errorf("Want the status not to be patched before it is flushed, but got %v failures", status.SchedulingFailures)
+	}
+	flushConditions(ctx, pgMgr)
+	status := getStatus()
+	if status.SchedulingFailures != 1 || status.NextRetryTime == nil {
+		t.Errorf("Want 1 failure with a next retry time, but got %v failures with next retry time %v", status.SchedulingFailures, status.NextRetryTime)
+	}
+
+	// Failures while the pod group is backed off are not counted.
+	pgMgr.BackoffPodGroup(ctx, "ns/pg1", pg, time.Minute, 10*time.Minute)
+	if got := pgMgr.backoffStates["ns/pg1"].failures; got != 1 {
+		t.Errorf("Want 1 failure, but got %v", got)
+	}
+
+	// The backoff doubles once the previous one has expired.
+	pgMgr.backedOffPG.Delete("ns/pg1")
+	before := time.Now()
+	pgMgr.BackoffPodGroup(ctx, "ns/pg1", pg, time.Minute, 10*time.Minute)
+	state := pgMgr.backoffStates["ns/pg1"]
+	if state.failures != 2 || state.nextRetry.Before(before.Add(2*time.Minute)) {
+		t.Errorf("Want 2 failures with a backoff of 2m, but got %v failures with next retry at %v", state.failures, state.nextRetry)
+	}
+	flushConditions(ctx, pgMgr)
+	if status := getStatus(); status.SchedulingFailures != 2 {
+		t.Errorf("Want 2 failures in status, but got %v", status.SchedulingFailures)
+	}
+
+	if status := getStatus(); !meta.IsStatusConditionTrue(status.Conditions, v1alpha1.PodGroupBackoff) {
+		t.Errorf("Want the Backoff condition to be true, but got %v", status.Conditions)
+	}
+
+	pgMgr.markQuorumReached(ctx, "ns/pg1", pg)
+	flushConditions(ctx, pgMgr)
+	if _, exist := pgMgr.backedOffPG.Get("ns/pg1"); exist {
+		t.Error("Want the pod group not to be backed off after reaching the quorum")
+	}
+	status = getStatus()
+	if status.SchedulingFailures != 0 || status.NextRetryTime != nil {
+		t.Errorf("Want the backoff status to be cleared, but got %v failures with next retry time %v", status.SchedulingFailures, status.NextRetryTime)
+	}
+	if !meta.IsStatusConditionTrue(status.Conditions, v1alpha1.PodGroupQuorumReached) {
+		t.Errorf("Want the QuorumReached condition to be true, but got %v", status.Conditions)
+	}
+}
+
+func TestSetPodGroupConditions(t *testing.T) {
+	ctx := context.Background()
+	pg := tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).Obj()
+	client, err := tu.NewFakeClient(pg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	informerFactory := informers.NewSharedInformerFactory(clientsetfake.NewSimpleClientset(), 0)
+	pgMgr := NewPodGroupManager(client, nil, nil, informerFactory.Core().V1().Pods())
+
+	// The failed members of a gang set the conditions of the same stale podgroup one after another.
+	for i := 0; i < 3; i++ {
+		pgMgr.SetPodGroupConditions(ctx, pg, metav1.Condition{
+			Type:    v1alpha1.PodGroupQuorumReached,
+			Status:  metav1.ConditionFalse,
+			Reason:  v1alpha1.PodGroupReasonUnschedulable,
+			Message: fmt.Sprintf("pod %d is unschedulable", i),
+		})
+	}
+	// A condition set back to the state of the cached podgroup is still applied over the pending one.
+	pgMgr.SetPodGroupConditions(ctx, pg, metav1.Condition{Type: v1alpha1.PodGroupBackoff, Status: metav1.ConditionTrue, Reason: v1alpha1.PodGroupReasonBackedOff})
+	pgMgr.SetPodGroupConditions(ctx, pg, metav1.Condition{Type: v1alpha1.PodGroupBackoff, Status: metav1.ConditionFalse, Reason: v1alpha1.PodGroupReasonBackedOff})
+	if got := pgMgr.conditionsQueue.Len(); got != 1 {
+		t.Errorf("Want the pod group to be queued once, but got %v", got)
+	}
+
+	flushConditions(ctx, pgMgr)
+	var got v1alpha1.PodGroup
+	if err := client.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "pg1"}, &got); err != nil {
+		t.Fatal(err)
+	}
+	if c := meta.FindStatusCondition(got.Status.Conditions, v1alpha1.PodGroupQuorumReached); c == nil || c.Message != "pod 2 is unschedulable" {
+		t.Errorf("Want the last QuorumReached condition to be applied, but got %v", got.Status.Conditions)
+	}
+	if c := meta.FindStatusCondition(got.Status.Conditions, v1alpha1.PodGroupBackoff); c == nil || c.Status != metav1.ConditionFalse {
+		t.Errorf("Want the last Backoff condition to be applied, but got %v", got.Status.Conditions)
+	}
+}
+
+// flushConditions applies the pending status and conditions of all the queued podgroups.
+func flushConditions(ctx context.Context, pgMgr *PodGroupManager) {
+	for pgMgr.conditionsQueue.Len() > 0 {
+		pgMgr.processNextPodGroupConditions(ctx)
+	}
+}
+
+func TestPodGroupEventHandlerForgetsBackoff(t *testing.T) {
+	ctx := context.Background()
+	running := tu.MakePodGroup().Name("running").Namespace("ns").MinMember(2).Obj()
+	failed := tu.MakePodGroup().Name("failed").Namespace("ns").MinMember(2).Phase(v1alpha1.PodGroupFailed).Obj()
+	deleted := tu.MakePodGroup().Name("deleted").Namespace("ns").MinMember(2).Obj()
+	client, err := tu.NewFakeClient(running, failed, deleted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	informerFactory := informers.NewSharedInformerFactory(clientsetfake.NewSimpleClientset(), 0)
+	pgMgr := NewPodGroupManager(client, nil, nil, informerFactory.Core().V1().Pods())
+	for _, pg := range []*v1alpha1.PodGroup{running, failed, deleted} {
+		pgMgr.BackoffPodGroup(ctx, GetNamespacedName(pg), pg, time.Minute, 10*time.Minute)
+	}
+
+	handler := pgMgr.PodGroupEventHandler()
+	handler.OnUpdate(running, running)
+	handler.OnUpdate(failed, failed)
+	handler.OnDelete(clicache.DeletedFinalStateUnknown{Key: "ns/deleted", Obj: deleted})
+
+	if got := sets.KeySet(pgMgr.backoffStates); !got.Equal(sets.New("ns/running")) {
+		t.Errorf("Want only the running pod group to keep its backoff state, but got %v", sets.List(got))
+	}
+}
+
+func TestCountRecentAdmissions(t *testing.T) {
+	cs := clientsetfake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(cs, 0)
+	pgMgr := NewPodGroupManager(nil, nil, nil, informerFactory.Core().V1().Pods())
+
+	if got := pgMgr.CountRecentAdmissions("ns1", time.Minute); got != 0 {
+		t.Errorf("Want no admission before any pod group is admitted, but got %v", got)
+	}
+
+	pgMgr.recordAdmission("ns1/pg1", "ns1")
+	// The same admission is recorded only once.
+	pgMgr.recordAdmission("ns1/pg1", "ns1")
+	pgMgr.recordAdmission("ns1/pg2", "ns1")
+	pgMgr.recordAdmission("ns2/pg1", "ns2")
+	if got := pgMgr.CountRecentAdmissions("ns1", time.Minute); got != 2 {
+		t.Errorf("Want 2 admissions in ns1, but got %v", got)
+	}
+	if got := pgMgr.CountRecentAdmissions("ns2", time.Minute); got != 1 {
+		t.Errorf("Want 1 admission in ns2, but got %v", got)
+	}
+
+	// A pod group admitted again after losing all its assigned pods is recorded again.
+	pgMgr.forgetAdmission("ns1/pg1")
+	pgMgr.recordAdmission("ns1/pg1", "ns1")
+	if got := pgMgr.CountRecentAdmissions("ns1", time.Minute); got != 3 {
+		t.Errorf("Want 3 admissions in ns1, but got %v", got)
+	}
+
+	// Admissions older than the window are pruned.
+	pgMgr.admissionsByNamespace["ns1"][0] = time.Now().Add(-2 * time.Minute)
+	if got := pgMgr.CountRecentAdmissions("ns1", time.Minute); got != 2 {
+		t.Errorf("Want 2 admissions in ns1 within the window, but got %v", got)
+	}
+	if got := len(pgMgr.admissionsByNamespace["ns1"]); got != 2 {
+		t.Errorf("Want 2 admissions kept for ns1, but got %v", got)
+	}
+}
+
 func TestCheckClusterResource(t *testing.T) {
 	capacity := map[corev1.ResourceName]string{
 		corev1.ResourceCPU: "3",
@@ -19,12 +19,21 @@ package coscheduling
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	clientscheme "k8s.io/client-go/kubernetes/scheme"
+	listerv1 "k8s.io/client-go/listers/core/v1"
+	schedulinglisters "k8s.io/client-go/listers/scheduling/v1"
 	"k8s.io/client-go/tools/cache"
 	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
 	"k8s.io/klog/v2"
@@ -44,11 +53,45 @@ type Coscheduling struct {
 	pgMgr            core.Manager
 	scheduleTimeout  *time.Duration
 	pgBackoff        *time.Duration
+	pgMaxBackoff     time.Duration
+	topologyKey      string
+	// earlyRejectionThreshold is the percentage of minMember below which the gap of pods to
+	// reach the quorum is small enough not to reject the PodGroup in PostFilter.
+	earlyRejectionThreshold int32
+	// enableGangPreemption allows PostFilter to preempt lower-priority pods for the whole PodGroup.
+	enableGangPreemption bool
+	// minCandidateNodesPercentage and minCandidateNodesAbsolute bound the nodes of the gang preemption dry runs.
+	minCandidateNodesPercentage int32
+	minCandidateNodesAbsolute   int32
+	// queueSortMode is the granularity at which pods are ordered in the scheduling queue.
+	queueSortMode string
+	// podGroupAging is the time after which a waiting PodGroup moves ahead of the PodGroups of the same priority.
+	podGroupAging time.Duration
+	// fairShareWindow is the time window during which admitted PodGroups count against the fair share of their namespaces.
+	fairShareWindow time.Duration
+	// priorityClassLister gets the PriorityClasses of PodGroups in the PodGroup queue sort mode.
+	priorityClassLister schedulinglisters.PriorityClassLister
+	// podLister gets the pods of the snapshotted queued groups in the PodGroup queue sort mode.
+	podLister listerv1.PodLister
+	// queueSortKeys stores the sort keys of the queued pods by UID, frozen when they enter the activeQ, so that the
+	// order of a pod doesn't change while it waits in the activeQ. It's read without queuedGroupsLock by Less.
+	queueSortKeys sync.Map
+	// queuedGroups stores the PodGroup view of the queued pods by group key, snapshotted when the first pod of the
+	// group enters the activeQ, from which the sort keys of its pods are frozen.
+	queuedGroups map[string]*queuedGroup
+	// namespaceAdmissions stores the number of PodGroups recently admitted in the namespaces of the queued groups,
+	// refreshed when a pod of the namespace enters the activeQ.
+	namespaceAdmissions map[string]int
+	// queuedGroupsLock protects queuedGroups and namespaceAdmissions.
+	queuedGroupsLock sync.Mutex
 }
 
 var _ framework.QueueSortPlugin = &Coscheduling{}
+var _ framework.PreEnqueuePlugin = &Coscheduling{}
 var _ framework.PreFilterPlugin = &Coscheduling{}
 var _ framework.PostFilterPlugin = &Coscheduling{}
+var _ framework.PreScorePlugin = &Coscheduling{}
+var _ framework.ScorePlugin = &Coscheduling{}
 var _ framework.PermitPlugin = &Coscheduling{}
 var _ framework.ReservePlugin = &Coscheduling{}
 
@@ -57,6 +100,13 @@ var _ framework.EnqueueExtensions = &Coscheduling{}
 const (
 	// Name is the name of the plugin used in Registry and configurations.
 	Name = "Coscheduling"
+
+	preScoreStateKey   = "PreScore" + Name
+	permitWaitStateKey = "PermitWait" + Name
+
+	// queuedGroupsResyncPeriod is the period at which aged PodGroups are moved back to the activeQ
+	// and the snapshots of the pods that left the scheduling queue are dropped.
+	queuedGroupsResyncPeriod = 10 * time.Second
 )
 
 // New initializes and returns a new Coscheduling plugin.
@@ -74,7 +124,7 @@ func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (fram
 	_ = clientscheme.AddToScheme(scheme)
 	_ = v1.AddToScheme(scheme)
 	_ = v1alpha1.AddToScheme(scheme)
-	c, _, err := util.NewClientWithCachedReader(ctx, handle.KubeConfig(), scheme)
+	c, ccache, err := util.NewClientWithCachedReader(ctx, handle.KubeConfig(), scheme)
 	if err != nil {
 		return nil, err
 	}
@@ -90,11 +140,29 @@ func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (fram
 		// Keep the podInformer (from frameworkHandle) as the single source of Pods.
 		handle.SharedInformerFactory().Core().V1().Pods(),
 	)
+	pgMgr.Run(ctx)
+	// Forget the scheduling failures of the PodGroups that are gone or done.
+	podGroupInformer, err := ccache.GetInformer(ctx, &v1alpha1.PodGroup{})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := podGroupInformer.AddEventHandler(pgMgr.PodGroupEventHandler()); err != nil {
+		return nil, err
+	}
 	plugin := &Coscheduling{
 		logger:           lh,
 		frameworkHandler: handle,
 		pgMgr:            pgMgr,
 		scheduleTimeout:  &scheduleTimeDuration,
+		topologyKey:      args.TopologyKey,
+
+		earlyRejectionThreshold:     args.EarlyRejectionThresholdPercentage,
+		enableGangPreemption:        args.EnableGangPreemption,
+		minCandidateNodesPercentage: args.MinCandidateNodesPercentage,
+		minCandidateNodesAbsolute:   args.MinCandidateNodesAbsolute,
+		queueSortMode:               args.QueueSortMode,
+		podGroupAging:               time.Duration(args.PodGroupAgingSeconds) * time.Second,
+		fairShareWindow:             time.Duration(args.FairShareWindowSeconds) * time.Second,
 	}
 	if args.PodGroupBackoffSeconds < 0 {
 		err := fmt.Errorf("parse arguments failed")
@@ -103,6 +171,37 @@ func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (fram
 	} else if args.PodGroupBackoffSeconds > 0 {
 		pgBackoff := time.Duration(args.PodGroupBackoffSeconds) * time.Second
 		plugin.pgBackoff = &pgBackoff
+		// A max backoff lower than the initial one means a fixed backoff.
+		plugin.pgMaxBackoff = max(time.Duration(args.PodGroupMaxBackoffSeconds)*time.Second, pgBackoff)
+	}
+	if args.EarlyRejectionThresholdPercentage < 0 || args.EarlyRejectionThresholdPercentage > 100 {
+		err := fmt.Errorf("parse arguments failed")
+		lh.Error(err, "EarlyRejectionThresholdPercentage must be between 0 and 100")
+		return nil, err
+	}
+	if args.EnableGangPreemption && (args.MinCandidateNodesPercentage < 0 || args.MinCandidateNodesPercentage > 100 ||
+		args.MinCandidateNodesAbsolute < 0 || args.MinCandidateNodesPercentage == 0 && args.MinCandidateNodesAbsolute == 0) {
+		err := fmt.Errorf("parse arguments failed")
+		lh.Error(err, "MinCandidateNodesPercentage must be between 0 and 100 and MinCandidateNodesAbsolute cannot be negative, and they cannot be both zero")
+		return nil, err
+	}
+	switch args.QueueSortMode {
+	case "", config.QueueSortModePod:
+	case config.QueueSortModePodGroup:
+		if args.PodGroupAgingSeconds < 0 || args.FairShareWindowSeconds < 0 {
+			err := fmt.Errorf("parse arguments failed")
+			lh.Error(err, "PodGroupAgingSeconds and FairShareWindowSeconds cannot be negative")
+			return nil, err
+		}
+		plugin.priorityClassLister = handle.SharedInformerFactory().Scheduling().V1().PriorityClasses().Lister()
+		plugin.podLister = handle.SharedInformerFactory().Core().V1().Pods().Lister()
+		plugin.queuedGroups = make(map[string]*queuedGroup)
+		plugin.namespaceAdmissions = make(map[string]int)
+		go wait.UntilWithContext(ctx, plugin.resyncQueuedGroups, queuedGroupsResyncPeriod)
+	default:
+		err := fmt.Errorf("parse arguments failed")
+		lh.Error(err, "QueueSortMode must be either Pod or PodGroup", "queueSortMode", args.QueueSortMode)
+		return nil, err
 	}
 	return plugin, nil
 }
@@ -127,7 +226,11 @@ func (cs *Coscheduling) Name() string {
 // 1. Compare the priorities of Pods.
 // 2. Compare the initialization timestamps of PodGroups or Pods.
 // 3. Compare the keys of PodGroups/Pods: <namespace>/<podname>.
+// In the PodGroup queue sort mode, pods are ordered by lessPodGroup instead.
 func (cs *Coscheduling) Less(podInfo1, podInfo2 *framework.QueuedPodInfo) bool {
+	if cs.queueSortMode == config.QueueSortModePodGroup {
+		return cs.lessPodGroup(podInfo1, podInfo2)
+	}
 	prio1 := corev1helpers.PodPriority(podInfo1.Pod)
 	prio2 := corev1helpers.PodPriority(podInfo2.Pod)
 	if prio1 != prio2 {
@@ -141,6 +244,176 @@ func (cs *Coscheduling) Less(podInfo1, podInfo2 *framework.QueuedPodInfo) bool {
 	return creationTime1.Before(creationTime2)
 }
 
+// queuedGroup is the view of a PodGroup in the scheduling queue, shared by all its queued pods.
+// A pod that doesn't belong to any PodGroup is a group on its own.
+type queuedGroup struct {
+	// key is <namespace>/<name> of the PodGroup, or of the pod itself.
+	key       string
+	namespace string
+	priority  int32
+	timestamp time.Time
+	// aged is whether the group has been waiting longer than the aging time.
+	aged bool
+}
+
+// queueSortKey is the sort key of a pod in the scheduling queue, frozen when the pod enters the activeQ.
+type queueSortKey struct {
+	pod *v1.Pod
+	// groupKey is the key of the queuedGroup of the pod.
+	groupKey  string
+	priority  int32
+	timestamp time.Time
+	aged      bool
+	// admitted is the number of PodGroups recently admitted in the namespace of the pod.
+	admitted int
+}
+
+// PreEnqueue freezes the sort key of the pod from the PodGroup view before it enters the activeQ in the PodGroup
+// queue sort mode, and refreshes the number of PodGroups recently admitted in its namespace. It never gates the pod.
+func (cs *Coscheduling) PreEnqueue(ctx context.Context, pod *v1.Pod) *framework.Status {
+	if cs.queueSortMode != config.QueueSortModePodGroup {
+		return nil
+	}
+	// A pod that doesn't belong to any PodGroup keeps the time it was first enqueued.
+	timestamp := time.Now()
+	if k, ok := cs.queueSortKeys.Load(pod.UID); ok {
+		timestamp = k.(*queueSortKey).timestamp
+	}
+	key := core.GetNamespacedName(pod)
+	pgFullName, pg := cs.pgMgr.GetPodGroup(ctx, pod)
+	if pg != nil {
+		key = pgFullName
+	}
+	admitted := cs.pgMgr.CountRecentAdmissions(pod.Namespace, cs.fairShareWindow)
+
+	cs.queuedGroupsLock.Lock()
+	defer cs.queuedGroupsLock.Unlock()
+	g, ok := cs.queuedGroups[key]
+	if !ok {
+		g = &queuedGroup{
+			key:       key,
+			namespace: pod.Namespace,
+			priority:  corev1helpers.PodPriority(pod),
+			timestamp: timestamp,
+		}
+		if pg != nil {
+			g.timestamp = pg.CreationTimestamp.Time
+			if pg.Spec.PriorityClassName != "" && cs.priorityClassLister != nil {
+				if pc, err := cs.priorityClassLister.Get(pg.Spec.PriorityClassName); err == nil {
+					g.priority = pc.Value
+				}
+			}
+		}
+		cs.queuedGroups[key] = g
+	}
+	g.aged = g.aged || cs.isAged(g)
+	cs.namespaceAdmissions[pod.Namespace] = admitted
+	cs.queueSortKeys.Store(pod.UID, &queueSortKey{
+		pod:       pod,
+		groupKey:  g.key,
+		priority:  g.priority,
+		timestamp: g.timestamp,
+		aged:      g.aged,
+		admitted:  admitted,
+	})
+	return nil
+}
+
+// lessPodGroup sorts pods in the scheduling queue at PodGroup granularity, in the following order.
+// 1. Compare the priorities of PodGroups, given by their PriorityClasses or else by their pods.
+// 2. PodGroups waiting longer than the aging time go first.
+// 3. PodGroups of the namespaces with fewer PodGroups admitted recently go first.
+// 4. Compare the creation timestamps of PodGroups.
+// 5. Compare the keys of PodGroups, so that the siblings of a PodGroup stay contiguous.
+// 6. Compare the keys of Pods.
+// Pods that don't belong to any PodGroup are ordered as PodGroups of one pod.
+// The sort keys are frozen when the pods enter the activeQ, from the views shared by the pods of a PodGroup and by
+// the PodGroups of a namespace, so that the order of the pods in the activeQ never changes.
+func (cs *Coscheduling) lessPodGroup(podInfo1, podInfo2 *framework.QueuedPodInfo) bool {
+	k1, k2 := cs.getQueueSortKey(podInfo1), cs.getQueueSortKey(podInfo2)
+	if k1.priority != k2.priority {
+		return k1.priority > k2.priority
+	}
+	if k1.aged != k2.aged {
+		return k1.aged
+	}
+	if k1.admitted != k2.admitted {
+		return k1.admitted < k2.admitted
+	}
+	if !k1.timestamp.Equal(k2.timestamp) {
+		return k1.timestamp.Before(k2.timestamp)
+	}
+	if k1.groupKey != k2.groupKey {
+		return k1.groupKey < k2.groupKey
+	}
+	return core.GetNamespacedName(podInfo1.Pod) < core.GetNamespacedName(podInfo2.Pod)
+}
+
+// getQueueSortKey returns the frozen sort key of the given queued pod. A pod that entered the activeQ without going
+// through PreEnqueue is sorted as a PodGroup of one pod.
+func (cs *Coscheduling) getQueueSortKey(podInfo *framework.QueuedPodInfo) *queueSortKey {
+	if k, ok := cs.queueSortKeys.Load(podInfo.Pod.UID); ok {
+		return k.(*queueSortKey)
+	}
+	return &queueSortKey{
+		pod:       podInfo.Pod,
+		groupKey:  core.GetNamespacedName(podInfo.Pod),
+		priority:  corev1helpers.PodPriority(podInfo.Pod),
+		timestamp: *podInfo.InitialAttemptTimestamp,
+	}
+}
+
+// isAged returns whether the given group has been waiting longer than the aging time.
+func (cs *Coscheduling) isAged(g *queuedGroup) bool {
+	return cs.podGroupAging > 0 && time.Since(g.timestamp) >= cs.podGroupAging
+}
+
+// resyncQueuedGroups marks the PodGroups that aged since they were snapshotted and activates their pods, so that the
+// pods waiting out of the activeQ are enqueued again with an aged sort key, and drops the sort keys of the pods that
+// left the scheduling queue, the groups that no longer have queued pods and the namespaces that no longer have queued
+// groups. The sort keys of the pods already in the activeQ are left untouched.
+func (cs *Coscheduling) resyncQueuedGroups(ctx context.Context) {
+	lh := klog.FromContext(klog.NewContext(ctx, cs.logger))
+	toActivate := make(map[string]*v1.Pod)
+	cs.queuedGroupsLock.Lock()
+	groups := make(map[string][]*v1.Pod)
+	cs.queueSortKeys.Range(func(uid, k interface{}) bool {
+		key := k.(*queueSortKey)
+		pod, err := cs.podLister.Pods(key.pod.Namespace).Get(key.pod.Name)
+		if err != nil || pod.UID != uid.(types.UID) || pod.Spec.NodeName != "" {
+			cs.queueSortKeys.Delete(uid)
+			return true
+		}
+		groups[key.groupKey] = append(groups[key.groupKey], pod)
+		return true
+	})
+	namespaces := sets.New[string]()
+	for key, g := range cs.queuedGroups {
+		pods, ok := groups[key]
+		if !ok {
+			delete(cs.queuedGroups, key)
+			continue
+		}
+		namespaces.Insert(g.namespace)
+		if !g.aged && cs.isAged(g) {
+			g.aged = true
+			for _, pod := range pods {
+				toActivate[core.GetNamespacedName(pod)] = pod
+			}
+		}
+	}
+	for ns := range cs.namespaceAdmissions {
+		if !namespaces.Has(ns) {
+			delete(cs.namespaceAdmissions, ns)
+		}
+	}
+	cs.queuedGroupsLock.Unlock()
+	if len(toActivate) != 0 {
+		lh.V(4).Info("Activate the pods of aged pod groups", "pods", len(toActivate))
+		cs.frameworkHandler.Activate(lh, toActivate)
+	}
+}
+
 // PreFilter performs the following validations.
 // 1. Whether the PodGroup that the Pod belongs to is on the deny list.
 // 2. Whether the total number of pods in a PodGroup is less than its `minMember`.
@@ -156,6 +429,7 @@ func (cs *Coscheduling) PreFilter(ctx context.Context, state *framework.CycleSta
 }
 
 // PostFilter is used to reject a group of pods if a pod does not pass PreFilter or Filter.
+// If gang preemption is enabled, it first tries to preempt lower-priority pods for the whole group.
 func (cs *Coscheduling) PostFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod,
 	filteredNodeStatusReader framework.NodeToStatusReader) (*framework.PostFilterResult, *framework.Status) {
 	lh := klog.FromContext(klog.NewContext(ctx, cs.logger)).WithValues("ExtensionPoint", "PostFilter")
@@ -168,19 +442,29 @@ func (cs *Coscheduling) PostFilter(ctx context.Context, state *framework.CycleSt
 	// This indicates there are already enough Pods satisfying the PodGroup,
 	// so don't bother to reject the whole PodGroup.
 	assigned := cs.pgMgr.GetAssignedPodCount(pgName)
-	if assigned >= int(pg.Spec.MinMember) {
+	if cs.pgMgr.IsQuorumReached(pgName, pg) {
 		lh.V(4).Info("Assigned pods", "podGroup", klog.KObj(pg), "assigned", assigned)
 		return &framework.PostFilterResult{}, framework.NewStatus(framework.Unschedulable)
 	}
 
-	// If the gap is less than/equal 10%, we may want to try subsequent Pods
-	// to see they can satisfy the PodGroup
+	// If the gap is less than/equal the early rejection threshold (10% by default),
+	// we may want to try subsequent Pods to see they can satisfy the PodGroup
 	notAssignedPercentage := float32(int(pg.Spec.MinMember)-assigned) / float32(pg.Spec.MinMember)
-	if notAssignedPercentage <= 0.1 {
+	if notAssignedPercentage <= float32(cs.earlyRejectionThreshold)/100 {
 		lh.V(4).Info("A small gap of pods to reach the quorum", "podGroup", klog.KObj(pg), "percentage", notAssignedPercentage)
 		return &framework.PostFilterResult{}, framework.NewStatus(framework.Unschedulable)
 	}
 
+	// Try to make room for the whole PodGroup by preempting lower-priority pods before rejecting it.
+	if cs.enableGangPreemption {
+		nominatedNodeName, err := cs.preemptPodGroup(ctx, state, pod, pgName, pg, filteredNodeStatusReader)
+		if err != nil {
+			lh.Error(err, "Failed to preempt for the pod group", "podGroup", klog.KObj(pg))
+		} else if nominatedNodeName != "" {
+			return framework.NewPostFilterResultWithNominatedNode(nominatedNodeName), framework.NewStatus(framework.Success)
+		}
+	}
+
 	// It's based on an implicit assumption: if the nth Pod failed,
 	// it's inferrable other Pods belonging to the same PodGroup would be very likely to fail.
 	cs.frameworkHandler.IterateOverWaitingPods(func(waitingPod framework.WaitingPod) {
@@ -195,20 +479,166 @@ func (cs *Coscheduling) PostFilter(ctx context.Context, state *framework.CycleSt
 			labels.SelectorFromSet(labels.Set{v1alpha1.PodGroupLabel: util.GetPodGroupLabel(pod)}),
 		)
 		if err == nil && len(pods) >= int(pg.Spec.MinMember) {
-			cs.pgMgr.BackoffPodGroup(pgName, *cs.pgBackoff)
+			cs.pgMgr.BackoffPodGroup(ctx, pgName, pg, *cs.pgBackoff, cs.pgMaxBackoff)
 		}
 	}
 
 	cs.pgMgr.DeletePermittedPodGroup(ctx, pgName)
+	cs.pgMgr.SetPodGroupConditions(ctx, pg, metav1.Condition{
+		Type:    v1alpha1.PodGroupQuorumReached,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.PodGroupReasonUnschedulable,
+		Message: cs.unschedulableMessage(pod, filteredNodeStatusReader),
+	})
 	return &framework.PostFilterResult{}, framework.NewStatus(framework.Unschedulable,
 		fmt.Sprintf("PodGroup %v gets rejected due to Pod %v is unschedulable even after PostFilter", pgName, pod.Name))
 }
 
+// unschedulableMessage aggregates the reasons why the given pod fits none of the nodes,
+// e.g. "Pod p is unschedulable, 0/3 nodes are available: 1 Insufficient cpu, 2 node(s) had untolerated taint".
+func (cs *Coscheduling) unschedulableMessage(pod *v1.Pod, filteredNodeStatusReader framework.NodeToStatusReader) string {
+	if filteredNodeStatusReader == nil {
+		return fmt.Sprintf("Pod %v is unschedulable", pod.Name)
+	}
+	nodeInfos, err := cs.frameworkHandler.SnapshotSharedLister().NodeInfos().List()
+	if err != nil {
+		return fmt.Sprintf("Pod %v is unschedulable", pod.Name)
+	}
+	counts := make(map[string]int)
+	for _, nodeInfo := range nodeInfos {
+		if nodeInfo.Node() == nil {
+			continue
+		}
+		if status := filteredNodeStatusReader.Get(nodeInfo.Node().Name); status != nil {
+			for _, reason := range status.Reasons() {
+				counts[reason]++
+			}
+		}
+	}
+	reasons := make([]string, 0, len(counts))
+	for reason, count := range counts {
+		reasons = append(reasons, fmt.Sprintf("%d %v", count, reason))
+	}
+	sort.Strings(reasons)
+	return fmt.Sprintf("Pod %v is unschedulable, 0/%d nodes are available: %v", pod.Name, len(nodeInfos), strings.Join(reasons, ", "))
+}
+
 // PreFilterExtensions returns a PreFilterExtensions interface if the plugin implements one.
 func (cs *Coscheduling) PreFilterExtensions() framework.PreFilterExtensions {
 	return nil
 }
 
+// preScoreState computed at PreScore and used at Score.
+type preScoreState struct {
+	// preference is the topology preference of the PodGroup that the pod belongs to.
+	preference v1alpha1.TopologyPreference
+	// siblingsByDomain is the number of assigned siblings in each topology domain.
+	siblingsByDomain map[string]int
+	// total is the number of assigned siblings located in any topology domain.
+	total int
+}
+
+// Clone implements the mandatory Clone interface. We don't really copy the data since
+// there is no need for that.
+func (s *preScoreState) Clone() framework.StateData {
+	return s
+}
+
+// permitWaitState computed at Permit and used at Unreserve to tell whether the pod timed out.
+type permitWaitState struct {
+	// waitTime is how long the pod waits for its siblings in Permit.
+	waitTime time.Duration
+	// deadline is the time until which the pod waits for its siblings in Permit.
+	deadline time.Time
+}
+
+// Clone implements the mandatory Clone interface. We don't really copy the data since
+// there is no need for that.
+func (s *permitWaitState) Clone() framework.StateData {
+	return s
+}
+
+// PreScore counts the siblings that are already assumed or bound, grouped by the topology
+// domain of their nodes. Scoring is skipped if the PodGroup has no topology preference or
+// none of its siblings has been assigned yet.
+func (cs *Coscheduling) PreScore(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodes []*framework.NodeInfo) *framework.Status {
+	pgName, pg := cs.pgMgr.GetPodGroup(ctx, pod)
+	if pg == nil || pg.Spec.TopologyPreference == "" || pg.Spec.TopologyPreference == v1alpha1.TopologyPreferenceNone {
+		return framework.NewStatus(framework.Skip)
+	}
+	assigned := cs.pgMgr.GetAssignedPods(pgName)
+	if len(assigned) == 0 {
+		return framework.NewStatus(framework.Skip)
+	}
+
+	nodeInfos, err := cs.frameworkHandler.SnapshotSharedLister().NodeInfos().List()
+	if err != nil {
+		return framework.AsStatus(fmt.Errorf("listing nodes from snapshot: %w", err))
+	}
+
+	s := &preScoreState{
+		preference:       pg.Spec.TopologyPreference,
+		siblingsByDomain: make(map[string]int),
+	}
+	for _, nodeInfo := range nodeInfos {
+		if nodeInfo.Node() == nil {
+			continue
+		}
+		domain, ok := nodeInfo.Node().Labels[cs.topologyKey]
+		if !ok {
+			continue
+		}
+		for _, podInfo := range nodeInfo.Pods {
+			p := podInfo.Pod
+			if p.UID == pod.UID || p.Namespace != pod.Namespace || util.GetPodGroupLabel(p) != pg.Name || !assigned.Has(p.Name) {
+				continue
+			}
+			s.siblingsByDomain[domain]++
+			s.total++
+		}
+	}
+	if s.total == 0 {
+		return framework.NewStatus(framework.Skip)
+	}
+
+	state.Write(preScoreStateKey, s)
+	return nil
+}
+
+// Score prefers nodes in the topology domains that hold more of the assigned siblings.
+// The score is proportional to the share of assigned siblings in the node's domain, and
+// is halved if the PodGroup has a low topology preference.
+func (cs *Coscheduling) Score(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) (int64, *framework.Status) {
+	c, err := state.Read(preScoreStateKey)
+	if err != nil {
+		return 0, framework.AsStatus(fmt.Errorf("reading %q from cycleState: %w", preScoreStateKey, err))
+	}
+	s, ok := c.(*preScoreState)
+	if !ok {
+		return 0, framework.AsStatus(fmt.Errorf("%+v convert to coscheduling.preScoreState error", c))
+	}
+
+	nodeInfo, err := cs.frameworkHandler.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	if err != nil {
+		return 0, framework.AsStatus(fmt.Errorf("getting node %q from snapshot: %w", nodeName, err))
+	}
+	domain, ok := nodeInfo.Node().Labels[cs.topologyKey]
+	if !ok {
+		return 0, nil
+	}
+
+	score := int64(s.siblingsByDomain[domain]) * framework.MaxNodeScore / int64(s.total)
+	if s.preference == v1alpha1.TopologyPreferenceLow {
+		score /= 2
+	}
+	return score, nil
+}
+
+// ScoreExtensions of the Score plugin.
+func (cs *Coscheduling) ScoreExtensions() framework.ScoreExtensions {
+	return nil
+}
+
 // Permit is the functions invoked by the framework at "Permit" extension point.
 func (cs *Coscheduling) Permit(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) (*framework.Status, time.Duration) {
 	lh := klog.FromContext(klog.NewContext(ctx, cs.logger)).WithValues("ExtensionPoint", "Permit")
@@ -227,6 +657,7 @@ func (cs *Coscheduling) Permit(ctx context.Context, state *framework.CycleState,
 			waitTime = wait
 		}
 		retStatus = framework.NewStatus(framework.Wait)
+		state.Write(permitWaitStateKey, &permitWaitState{waitTime: waitTime, deadline: time.Now().Add(waitTime)})
 		// We will also request to move the sibling pods back to activeQ.
 		cs.pgMgr.ActivateSiblings(ctx, pod, state)
 	case core.Success:
@@ -251,13 +682,39 @@ func (cs *Coscheduling) Reserve(ctx context.Context, state *framework.CycleState
 }
 
 // Unreserve rejects all other Pods in the PodGroup when one of the pods in the group times out.
+// An extra member of an elastic PodGroup that still has its quorum is unreserved on its own.
 func (cs *Coscheduling) Unreserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) {
 	lh := klog.FromContext(klog.NewContext(ctx, cs.logger)).WithValues("ExtensionPoint", "Unreserve")
 	pgName, pg := cs.pgMgr.GetPodGroup(ctx, pod)
 	if pg == nil {
 		return
 	}
+	if c, err := state.Read(permitWaitStateKey); err == nil {
+		if s, ok := c.(*permitWaitState); ok && !time.Now().Before(s.deadline) {
+			message := fmt.Sprintf("Pod %v timed out after waiting %v for its siblings, %d out of %d pods were assigned",
+				pod.Name, s.waitTime, cs.pgMgr.GetAssignedPodCount(pgName), pg.Spec.MinMember)
+			lh.V(3).Info("Pod timed out in Permit", "pod", klog.KObj(pod), "podGroup", klog.KObj(pg))
+			cs.pgMgr.SetPodGroupConditions(ctx, pg,
+				metav1.Condition{
+					Type:    v1alpha1.PodGroupTimedOut,
+					Status:  metav1.ConditionTrue,
+					Reason:  v1alpha1.PodGroupReasonPermitTimeout,
+					Message: message,
+				},
+				metav1.Condition{
+					Type:    v1alpha1.PodGroupQuorumReached,
+					Status:  metav1.ConditionFalse,
+					Reason:  v1alpha1.PodGroupReasonPermitTimeout,
+					Message: message,
+				},
+			)
+		}
+	}
 	cs.pgMgr.Unreserve(ctx, pod)
+	if _, elastic := util.GetPodGroupMaxMember(pg); elastic && cs.pgMgr.IsQuorumReached(pgName, pg) {
+		lh.V(4).Info("Unreserve an extra member of the elastic pod group", "pod", klog.KObj(pod), "podGroup", klog.KObj(pg))
+		return
+	}
 	cs.frameworkHandler.IterateOverWaitingPods(func(waitingPod framework.WaitingPod) {
 		if waitingPod.GetPod().Namespace == pod.Namespace && util.GetPodGroupLabel(waitingPod.GetPod()) == pg.Name {
 			lh.V(3).Info("Unreserve rejects", "pod", klog.KObj(waitingPod.GetPod()), "podGroup", klog.KObj(pg))
@@ -25,11 +25,18 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	v1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/informers"
 	clientsetfake "k8s.io/client-go/kubernetes/fake"
 	clicache "k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
@@ -38,6 +45,7 @@ import (
 	tf "k8s.io/kubernetes/pkg/scheduler/testing/framework"
 	"k8s.io/utils/pointer"
 
+	"sigs.k8s.io/scheduler-plugins/apis/config"
 	_ "sigs.k8s.io/scheduler-plugins/apis/config/scheme"
 	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
 	"sigs.k8s.io/scheduler-plugins/pkg/coscheduling/core"
@@ -179,6 +187,424 @@ func TestPodGroupBackoffTime(t *testing.T) {
 	}
 }
 
+func TestLessPodGroup(t *testing.T) {
+	lowPriority, highPriority := int32(10), int32(100)
+	now := time.Now()
+	priorityClass := &schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "high"}, Value: 1000}
+
+	tests := []struct {
+		name string
+		p1   *framework.QueuedPodInfo
+		p2   *framework.QueuedPodInfo
+		pgs  []*v1alpha1.PodGroup
+		// admitted are the pods admitted right away as pod groups of one pod.
+		admitted []*v1.Pod
+		want     bool
+	}{
+		{
+			name: "group priority from PriorityClass beats pod priority",
+			p1: &framework.QueuedPodInfo{
+				PodInfo: tu.MustNewPodInfo(t, st.MakePod().Name("p1").UID("p1").Namespace("ns1").Priority(lowPriority).
+					Label(v1alpha1.PodGroupLabel, "pg1").Obj()),
+				InitialAttemptTimestamp: ptrTime(now),
+			},
+			p2: &framework.QueuedPodInfo{
+				PodInfo:                 tu.MustNewPodInfo(t, st.MakePod().Name("p2").UID("p2").Namespace("ns2").Priority(highPriority).Obj()),
+				InitialAttemptTimestamp: ptrTime(now),
+			},
+			pgs: []*v1alpha1.PodGroup{
+				tu.MakePodGroup().Name("pg1").Namespace("ns1").PriorityClassName("high").Time(now).Obj(),
+			},
+			want: true,
+		},
+		{
+			name: "group with unknown PriorityClass falls back to pod priority",
+			p1: &framework.QueuedPodInfo{
+				PodInfo: tu.MustNewPodInfo(t, st.MakePod().Name("p1").UID("p1").Namespace("ns1").Priority(lowPriority).
+					Label(v1alpha1.PodGroupLabel, "pg1").Obj()),
+				InitialAttemptTimestamp: ptrTime(now),
+			},
+			p2: &framework.QueuedPodInfo{
+				PodInfo:                 tu.MustNewPodInfo(t, st.MakePod().Name("p2").UID("p2").Namespace("ns2").Priority(highPriority).Obj()),
+				InitialAttemptTimestamp: ptrTime(now),
+			},
+			pgs: []*v1alpha1.PodGroup{
+				tu.MakePodGroup().Name("pg1").Namespace("ns1").PriorityClassName("unknown").Time(now).Obj(),
+			},
+			want: false,
+		},
+		{
+			name: "siblings stay contiguous regardless of pod names",
+			p1: &framework.QueuedPodInfo{
+				PodInfo: tu.MustNewPodInfo(t, st.MakePod().Name("z").UID("z").Namespace("ns1").Priority(highPriority).
+					Label(v1alpha1.PodGroupLabel, "pg1").Obj()),
+				InitialAttemptTimestamp: ptrTime(now),
+			},
+			p2: &framework.QueuedPodInfo{
+				PodInfo: tu.MustNewPodInfo(t, st.MakePod().Name("a").UID("a").Namespace("ns1").Priority(highPriority).
+					Label(v1alpha1.PodGroupLabel, "pg2").Obj()),
+				InitialAttemptTimestamp: ptrTime(now),
+			},
+			pgs: []*v1alpha1.PodGroup{
+				tu.MakePodGroup().Name("pg1").Namespace("ns1").Time(now).Obj(),
+				tu.MakePodGroup().Name("pg2").Namespace("ns1").Time(now).Obj(),
+			},
+			want: true,
+		},
+		{
+			name: "namespace with fewer recent admissions goes first",
+			p1: &framework.QueuedPodInfo{
+				PodInfo: tu.MustNewPodInfo(t, st.MakePod().Name("p1").UID("p1").Namespace("ns1").Priority(highPriority).
+					Label(v1alpha1.PodGroupLabel, "pg1").Obj()),
+				InitialAttemptTimestamp: ptrTime(now),
+			},
+			p2: &framework.QueuedPodInfo{
+				PodInfo: tu.MustNewPodInfo(t, st.MakePod().Name("p2").UID("p2").Namespace("ns2").Priority(highPriority).
+					Label(v1alpha1.PodGroupLabel, "pg2").Obj()),
+				InitialAttemptTimestamp: ptrTime(now),
+			},
+			pgs: []*v1alpha1.PodGroup{
+				tu.MakePodGroup().Name("pg1").Namespace("ns1").Time(now.Add(-time.Minute)).Obj(),
+				tu.MakePodGroup().Name("pg2").Namespace("ns2").Time(now).Obj(),
+				tu.MakePodGroup().Name("admitted").Namespace("ns1").MinMember(1).Obj(),
+			},
+			admitted: []*v1.Pod{
+				st.MakePod().Name("admitted").Namespace("ns1").Label(v1alpha1.PodGroupLabel, "admitted").Obj(),
+			},
+			want: false,
+		},
+		{
+			name: "aged group goes first regardless of fair share",
+			p1: &framework.QueuedPodInfo{
+				PodInfo: tu.MustNewPodInfo(t, st.MakePod().Name("p1").UID("p1").Namespace("ns1").Priority(highPriority).
+					Label(v1alpha1.PodGroupLabel, "pg1").Obj()),
+				InitialAttemptTimestamp: ptrTime(now),
+			},
+			p2: &framework.QueuedPodInfo{
+				PodInfo: tu.MustNewPodInfo(t, st.MakePod().Name("p2").UID("p2").Namespace("ns2").Priority(highPriority).
+					Label(v1alpha1.PodGroupLabel, "pg2").Obj()),
+				InitialAttemptTimestamp: ptrTime(now),
+			},
+			pgs: []*v1alpha1.PodGroup{
+				tu.MakePodGroup().Name("pg1").Namespace("ns1").Time(now.Add(-time.Hour)).Obj(),
+				tu.MakePodGroup().Name("pg2").Namespace("ns2").Time(now).Obj(),
+				tu.MakePodGroup().Name("admitted").Namespace("ns1").MinMember(1).Obj(),
+			},
+			admitted: []*v1.Pod{
+				st.MakePod().Name("admitted").Namespace("ns1").Label(v1alpha1.PodGroupLabel, "admitted").Obj(),
+			},
+			want: true,
+		},
+		{
+			name: "higher priority goes first regardless of aging",
+			p1: &framework.QueuedPodInfo{
+				PodInfo: tu.MustNewPodInfo(t, st.MakePod().Name("p1").UID("p1").Namespace("ns1").Priority(lowPriority).
+					Label(v1alpha1.PodGroupLabel, "pg1").Obj()),
+				InitialAttemptTimestamp: ptrTime(now),
+			},
+			p2: &framework.QueuedPodInfo{
+				PodInfo: tu.MustNewPodInfo(t, st.MakePod().Name("p2").UID("p2").Namespace("ns2").Priority(highPriority).
+					Label(v1alpha1.PodGroupLabel, "pg2").Obj()),
+				InitialAttemptTimestamp: ptrTime(now),
+			},
+			pgs: []*v1alpha1.PodGroup{
+				tu.MakePodGroup().Name("pg1").Namespace("ns1").Time(now.Add(-time.Hour)).Obj(),
+				tu.MakePodGroup().Name("pg2").Namespace("ns2").Time(now).Obj(),
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var objs []runtime.Object
+			for _, pg := range tt.pgs {
+				objs = append(objs, pg)
+			}
+			client, err := tu.NewFakeClient(objs...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cs := clientsetfake.NewSimpleClientset(priorityClass)
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+			podInformer := informerFactory.Core().V1().Pods()
+			priorityClassInformer := informerFactory.Scheduling().V1().PriorityClasses()
+
+			pgMgr := core.NewPodGroupManager(client, nil, nil, podInformer)
+			pl := &Coscheduling{
+				pgMgr:               pgMgr,
+				queueSortMode:       config.QueueSortModePodGroup,
+				podGroupAging:       10 * time.Minute,
+				fairShareWindow:     5 * time.Minute,
+				priorityClassLister: priorityClassInformer.Lister(),
+				queuedGroups:        make(map[string]*queuedGroup),
+				namespaceAdmissions: make(map[string]int),
+			}
+
+			informerFactory.Start(ctx.Done())
+			if !clicache.WaitForCacheSync(ctx.Done(), podInformer.Informer().HasSynced, priorityClassInformer.Informer().HasSynced) {
+				t.Fatal("WaitForCacheSync failed")
+			}
+			for _, pod := range tt.admitted {
+				if got := pgMgr.Permit(ctx, framework.NewCycleState(), pod); got != core.Success {
+					t.Fatalf("Want the pod group of %v to be admitted, got %v", pod.Name, got)
+				}
+			}
+
+			for _, p := range []*framework.QueuedPodInfo{tt.p1, tt.p2} {
+				if s := pl.PreEnqueue(ctx, p.Pod); !s.IsSuccess() {
+					t.Fatalf("Want PreEnqueue to succeed, got %v", s)
+				}
+			}
+			if got := pl.Less(tt.p1, tt.p2); got != tt.want {
+				t.Errorf("Want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLessPodGroupSnapshot(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	now := time.Now()
+	client, err := tu.NewFakeClient(
+		tu.MakePodGroup().Name("pg1").Namespace("ns1").Time(now.Add(-time.Minute)).Obj(),
+		tu.MakePodGroup().Name("pg2").Namespace("ns2").Time(now).Obj(),
+		tu.MakePodGroup().Name("admitted").Namespace("ns1").MinMember(1).Obj(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	informerFactory := informers.NewSharedInformerFactory(clientsetfake.NewSimpleClientset(), 0)
+	podInformer := informerFactory.Core().V1().Pods()
+	pgMgr := core.NewPodGroupManager(client, nil, nil, podInformer)
+	pl := &Coscheduling{
+		pgMgr:               pgMgr,
+		queueSortMode:       config.QueueSortModePodGroup,
+		fairShareWindow:     5 * time.Minute,
+		queuedGroups:        make(map[string]*queuedGroup),
+		namespaceAdmissions: make(map[string]int),
+	}
+	informerFactory.Start(ctx.Done())
+	if !clicache.WaitForCacheSync(ctx.Done(), podInformer.Informer().HasSynced) {
+		t.Fatal("WaitForCacheSync failed")
+	}
+
+	p1 := &framework.QueuedPodInfo{
+		PodInfo: tu.MustNewPodInfo(t, st.MakePod().Name("p1").UID("p1").Namespace("ns1").
+			Label(v1alpha1.PodGroupLabel, "pg1").Obj()),
+		InitialAttemptTimestamp: ptrTime(now),
+	}
+	p2 := &framework.QueuedPodInfo{
+		PodInfo: tu.MustNewPodInfo(t, st.MakePod().Name("p2").UID("p2").Namespace("ns2").
+			Label(v1alpha1.PodGroupLabel, "pg2").Obj()),
+		InitialAttemptTimestamp: ptrTime(now),
+	}
+	for _, pod := range []*v1.Pod{p1.Pod, p2.Pod} {
+		if s := pl.PreEnqueue(ctx, pod); !s.IsSuccess() {
+			t.Fatalf("Want PreEnqueue to succeed, got %v", s)
+		}
+	}
+	if !pl.Less(p1, p2) {
+		t.Fatal("Want the older pod group to go first")
+	}
+
+	admitted := st.MakePod().Name("admitted").Namespace("ns1").Label(v1alpha1.PodGroupLabel, "admitted").Obj()
+	if got := pgMgr.Permit(ctx, framework.NewCycleState(), admitted); got != core.Success {
+		t.Fatalf("Want the pod group of %v to be admitted, got %v", admitted.Name, got)
+	}
+	if !pl.Less(p1, p2) || pl.Less(p2, p1) {
+		t.Error("Want the order of queued pods to stay the same until they are enqueued again")
+	}
+
+	if s := pl.PreEnqueue(ctx, p1.Pod); !s.IsSuccess() {
+		t.Fatalf("Want PreEnqueue to succeed, got %v", s)
+	}
+	if pl.Less(p1, p2) {
+		t.Error("Want the pod group of the namespace with more recent admissions to go last once enqueued again")
+	}
+}
+
+func TestLessPodGroupTransitive(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	now := time.Now()
+	client, err := tu.NewFakeClient(
+		tu.MakePodGroup().Name("a").Namespace("ns1").Time(now).Obj(),
+		tu.MakePodGroup().Name("b").Namespace("ns1").Time(now.Add(-2*time.Minute)).Obj(),
+		tu.MakePodGroup().Name("c").Namespace("ns2").Time(now.Add(-time.Minute)).Obj(),
+		tu.MakePodGroup().Name("admitted1").Namespace("ns1").MinMember(1).Obj(),
+		tu.MakePodGroup().Name("admitted2").Namespace("ns1").MinMember(1).Obj(),
+		tu.MakePodGroup().Name("admitted3").Namespace("ns2").MinMember(1).Obj(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	informerFactory := informers.NewSharedInformerFactory(clientsetfake.NewSimpleClientset(), 0)
+	podInformer := informerFactory.Core().V1().Pods()
+	pgMgr := core.NewPodGroupManager(client, nil, nil, podInformer)
+	pl := &Coscheduling{
+		pgMgr:               pgMgr,
+		queueSortMode:       config.QueueSortModePodGroup,
+		fairShareWindow:     5 * time.Minute,
+		queuedGroups:        make(map[string]*queuedGroup),
+		namespaceAdmissions: make(map[string]int),
+	}
+	informerFactory.Start(ctx.Done())
+	if !clicache.WaitForCacheSync(ctx.Done(), podInformer.Informer().HasSynced) {
+		t.Fatal("WaitForCacheSync failed")
+	}
+	admit := func(ns, name string) {
+		pod := st.MakePod().Name(name).Namespace(ns).Label(v1alpha1.PodGroupLabel, name).Obj()
+		if got := pgMgr.Permit(ctx, framework.NewCycleState(), pod); got != core.Success {
+			t.Fatalf("Want the pod group of %v to be admitted, got %v", name, got)
+		}
+	}
+	newPodInfo := func(ns, name string) *framework.QueuedPodInfo {
+		return &framework.QueuedPodInfo{
+			PodInfo: tu.MustNewPodInfo(t, st.MakePod().Name(name).UID(name).Namespace(ns).
+				Label(v1alpha1.PodGroupLabel, name).Obj()),
+			InitialAttemptTimestamp: ptrTime(now),
+		}
+	}
+
+	// The pods of ns1 are enqueued before and after the admissions in ns1, so that their frozen sort keys hold
+	// different numbers of admissions, which must still give a transitive order.
+	a, b, c := newPodInfo("ns1", "a"), newPodInfo("ns1", "b"), newPodInfo("ns2", "c")
+	pl.PreEnqueue(ctx, a.Pod)
+	admit("ns1", "admitted1")
+	admit("ns1", "admitted2")
+	pl.PreEnqueue(ctx, b.Pod)
+	admit("ns2", "admitted3")
+	pl.PreEnqueue(ctx, c.Pod)
+
+	pods := []*framework.QueuedPodInfo{a, b, c}
+	for _, p1 := range pods {
+		for _, p2 := range pods {
+			for _, p3 := range pods {
+				if pl.Less(p1, p2) && pl.Less(p2, p3) && !pl.Less(p1, p3) {
+					t.Errorf("Want Less to be transitive, got %v < %v < %v but not %v < %v",
+						p1.Pod.Name, p2.Pod.Name, p3.Pod.Name, p1.Pod.Name, p3.Pod.Name)
+				}
+			}
+		}
+	}
+	sort.Slice(pods, func(i, j int) bool { return pl.Less(pods[i], pods[j]) })
+	var got []string
+	for _, p := range pods {
+		got = append(got, p.Pod.Name)
+	}
+	if diff := cmp.Diff([]string{"a", "c", "b"}, got); diff != "" {
+		t.Errorf("Unexpected order (-want, +got): %s", diff)
+	}
+}
+
+// activatorHandle records the pods activated through the framework handle.
+type activatorHandle struct {
+	framework.Handle
+	activated map[string]*v1.Pod
+}
+
+func (h *activatorHandle) Activate(_ klog.Logger, pods map[string]*v1.Pod) {
+	for k, pod := range pods {
+		h.activated[k] = pod
+	}
+}
+
+func TestResyncQueuedGroups(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	now := time.Now()
+	pending1 := st.MakePod().Name("pending1").UID("pending1").Namespace("ns1").Obj()
+	pending2 := st.MakePod().Name("pending2").UID("pending2").Namespace("ns1").Obj()
+	aged := st.MakePod().Name("aged").UID("aged").Namespace("ns1").Obj()
+	young := st.MakePod().Name("young").UID("young").Namespace("ns1").Obj()
+	bound := st.MakePod().Name("bound").UID("bound").Namespace("ns2").Node("node1").Obj()
+	deleted := st.MakePod().Name("deleted").UID("deleted").Namespace("ns2").Obj()
+
+	pendingGroup := &queuedGroup{key: "ns1/pg1", namespace: "ns1", timestamp: now.Add(-time.Hour)}
+	agedGroup := &queuedGroup{key: "ns1/aged", namespace: "ns1", timestamp: now.Add(-time.Hour), aged: true}
+	youngGroup := &queuedGroup{key: "ns1/young", namespace: "ns1", timestamp: now}
+	boundGroup := &queuedGroup{key: "ns2/bound", namespace: "ns2", timestamp: now.Add(-time.Hour)}
+	deletedGroup := &queuedGroup{key: "ns2/deleted", namespace: "ns2", timestamp: now.Add(-time.Hour)}
+
+	informerFactory := informers.NewSharedInformerFactory(clientsetfake.NewSimpleClientset(pending1, pending2, aged, young, bound), 0)
+	podInformer := informerFactory.Core().V1().Pods()
+	handle := &activatorHandle{activated: make(map[string]*v1.Pod)}
+	pl := &Coscheduling{
+		logger:           klog.FromContext(ctx),
+		frameworkHandler: handle,
+		queueSortMode:    config.QueueSortModePodGroup,
+		podGroupAging:    10 * time.Minute,
+		podLister:        podInformer.Lister(),
+		queuedGroups: map[string]*queuedGroup{
+			pendingGroup.key: pendingGroup,
+			agedGroup.key:    agedGroup,
+			youngGroup.key:   youngGroup,
+			boundGroup.key:   boundGroup,
+			deletedGroup.key: deletedGroup,
+		},
+		namespaceAdmissions: map[string]int{"ns1": 1, "ns2": 1},
+	}
+	for pod, g := range map[*v1.Pod]*queuedGroup{
+		pending1: pendingGroup,
+		pending2: pendingGroup,
+		aged:     agedGroup,
+		young:    youngGroup,
+		bound:    boundGroup,
+		deleted:  deletedGroup,
+	} {
+		pl.queueSortKeys.Store(pod.UID, &queueSortKey{pod: pod, groupKey: g.key, timestamp: g.timestamp, aged: g.aged})
+	}
+	informerFactory.Start(ctx.Done())
+	if !clicache.WaitForCacheSync(ctx.Done(), podInformer.Informer().HasSynced) {
+		t.Fatal("WaitForCacheSync failed")
+	}
+
+	pl.resyncQueuedGroups(ctx)
+
+	var gotActivated []string
+	for k := range handle.activated {
+		gotActivated = append(gotActivated, k)
+	}
+	sort.Strings(gotActivated)
+	if diff := cmp.Diff([]string{"ns1/pending1", "ns1/pending2"}, gotActivated); diff != "" {
+		t.Errorf("Unexpected activated pods (-want, +got): %s", diff)
+	}
+	if !pendingGroup.aged {
+		t.Error("Want the pending pod group to be marked as aged")
+	}
+	var gotQueued []string
+	pl.queueSortKeys.Range(func(uid, k interface{}) bool {
+		gotQueued = append(gotQueued, string(uid.(types.UID)))
+		if uid == pending1.UID && k.(*queueSortKey).aged {
+			t.Error("Want the sort key of a queued pod to stay frozen")
+		}
+		return true
+	})
+	sort.Strings(gotQueued)
+	if diff := cmp.Diff([]string{"aged", "pending1", "pending2", "young"}, gotQueued); diff != "" {
+		t.Errorf("Unexpected queued pods (-want, +got): %s", diff)
+	}
+	var gotGroups []string
+	for key := range pl.queuedGroups {
+		gotGroups = append(gotGroups, key)
+	}
+	sort.Strings(gotGroups)
+	if diff := cmp.Diff([]string{"ns1/aged", "ns1/pg1", "ns1/young"}, gotGroups); diff != "" {
+		t.Errorf("Unexpected snapshotted groups (-want, +got): %s", diff)
+	}
+	if diff := cmp.Diff(map[string]int{"ns1": 1}, pl.namespaceAdmissions); diff != "" {
+		t.Errorf("Unexpected snapshotted namespaces (-want, +got): %s", diff)
+	}
+}
+
 func ptrTime(tm time.Time) *time.Time {
 	return &tm
 }
@@ -428,6 +854,11 @@ func TestLess(t *testing.T) {
 				t.Fatal("WaitForCacheSync failed")
 			}
 
+			for _, p := range []*framework.QueuedPodInfo{tt.p1, tt.p2} {
+				if s := pl.PreEnqueue(ctx, p.Pod); !s.IsSuccess() {
+					t.Fatalf("Want PreEnqueue to succeed, got %v", s)
+				}
+			}
 			if got := pl.Less(tt.p1, tt.p2); got != tt.want {
 				t.Errorf("Want %v, got %v", tt.want, got)
 			}
@@ -533,6 +964,90 @@ func TestPermit(t *testing.T) {
 	}
 }
 
+func TestUnreserve(t *testing.T) {
+	scheduleTimeout := 10 * time.Second
+	nodes := []*v1.Node{
+		st.MakeNode().Name("node").Obj(),
+	}
+
+	tests := []struct {
+		name         string
+		waited       bool
+		timeLeft     time.Duration
+		wantTimedOut bool
+	}{
+		{
+			name: "pod did not wait in permit",
+		},
+		{
+			name:     "pod rejected before the deadline",
+			waited:   true,
+			timeLeft: time.Minute,
+		},
+		{
+			name:         "pod timed out in permit",
+			waited:       true,
+			timeLeft:     -time.Second,
+			wantTimedOut: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			pod := st.MakePod().Name("p").Namespace("ns").UID("p").Label(v1alpha1.PodGroupLabel, "pg1").Obj()
+			pg := tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).Obj()
+			client, err := tu.NewFakeClient(pod, pg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			registeredPlugins := []tf.RegisterPluginFunc{
+				tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+				tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			}
+			f, err := tf.NewFramework(
+				ctx,
+				registeredPlugins,
+				"default-scheduler",
+				fwkruntime.WithWaitingPods(fwkruntime.NewWaitingPodsMap()),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cs := clientsetfake.NewSimpleClientset()
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+			podInformer := informerFactory.Core().V1().Pods()
+			pgMgr := core.NewPodGroupManager(client, tu.NewFakeSharedLister(nil, nodes), nil, podInformer)
+
+			pl := &Coscheduling{
+				frameworkHandler: f,
+				pgMgr:            pgMgr,
+				scheduleTimeout:  &scheduleTimeout,
+			}
+			pgMgr.Run(ctx)
+
+			state := framework.NewCycleState()
+			if tt.waited {
+				state.Write(permitWaitStateKey, &permitWaitState{waitTime: scheduleTimeout, deadline: time.Now().Add(tt.timeLeft)})
+			}
+			pl.Unreserve(ctx, state, pod, "node")
+
+			var got v1alpha1.PodGroup
+			if err := wait.PollUntilContextTimeout(ctx, 10*time.Millisecond, time.Second, true, func(ctx context.Context) (bool, error) {
+				if err := client.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "pg1"}, &got); err != nil {
+					return false, err
+				}
+				return meta.IsStatusConditionTrue(got.Status.Conditions, v1alpha1.PodGroupTimedOut) == tt.wantTimedOut, nil
+			}); err != nil {
+				t.Errorf("Want TimedOut condition to be %v, but got %v", tt.wantTimedOut, got.Status.Conditions)
+			}
+		})
+	}
+}
+
 func TestPostFilter(t *testing.T) {
 	scheduleTimeout := 10 * time.Second
 	capacity := map[v1.ResourceName]string{
@@ -543,14 +1058,16 @@ func TestPostFilter(t *testing.T) {
 	}
 
 	nodeStatusReader := framework.NewDefaultNodeToStatus()
-	nodeStatusReader.Set("node", framework.NewStatus(framework.Success, ""))
+	nodeStatusReader.Set("node", framework.NewStatus(framework.Unschedulable, "Insufficient cpu"))
 
 	tests := []struct {
-		name         string
-		pod          *v1.Pod
-		existingPods []*v1.Pod
-		pgs          []*v1alpha1.PodGroup
-		want         *framework.Status
+		name                    string
+		pod                     *v1.Pod
+		existingPods            []*v1.Pod
+		pgs                     []*v1alpha1.PodGroup
+		earlyRejectionThreshold int32
+		want                    *framework.Status
+		wantCondition           *metav1.Condition
 	}{
 		{
 			name: "pod does not belong to any pod group",
@@ -584,6 +1101,43 @@ func TestPostFilter(t *testing.T) {
 				framework.Unschedulable,
 				"PodGroup ns/pg1 gets rejected due to Pod p is unschedulable even after PostFilter",
 			),
+			wantCondition: &metav1.Condition{
+				Type:    v1alpha1.PodGroupQuorumReached,
+				Status:  metav1.ConditionFalse,
+				Reason:  v1alpha1.PodGroupReasonUnschedulable,
+				Message: "Pod p is unschedulable, 0/1 nodes are available: 1 Insufficient cpu",
+			},
+		},
+		{
+			name: "gap of pods above the early rejection threshold, reject all pods",
+			pod:  st.MakePod().Name("p").Namespace("ns").UID("p").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+			existingPods: []*v1.Pod{
+				st.MakePod().Name("p1").Namespace("ns").UID("p1").Node("node").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+				st.MakePod().Name("p2").Namespace("ns").UID("p2").Node("node").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+				st.MakePod().Name("p3").Namespace("ns").UID("p3").Node("node").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+			},
+			pgs: []*v1alpha1.PodGroup{
+				tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(4).Obj(),
+			},
+			earlyRejectionThreshold: 10,
+			want: framework.NewStatus(
+				framework.Unschedulable,
+				"PodGroup ns/pg1 gets rejected due to Pod p is unschedulable even after PostFilter",
+			),
+		},
+		{
+			name: "gap of pods within the early rejection threshold, do not reject all",
+			pod:  st.MakePod().Name("p").Namespace("ns").UID("p").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+			existingPods: []*v1.Pod{
+				st.MakePod().Name("p1").Namespace("ns").UID("p1").Node("node").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+				st.MakePod().Name("p2").Namespace("ns").UID("p2").Node("node").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+				st.MakePod().Name("p3").Namespace("ns").UID("p3").Node("node").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+			},
+			pgs: []*v1alpha1.PodGroup{
+				tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(4).Obj(),
+			},
+			earlyRejectionThreshold: 25,
+			want:                    framework.NewStatus(framework.Unschedulable),
 		},
 	}
 
@@ -611,10 +1165,12 @@ func TestPostFilter(t *testing.T) {
 				tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
 				tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
 			}
+			snapshot := tu.NewFakeSharedLister(tt.existingPods, nodes)
 			f, err := tf.NewFramework(
 				ctx,
 				registeredPlugins,
 				"default-scheduler",
+				fwkruntime.WithSnapshotSharedLister(snapshot),
 				fwkruntime.WithWaitingPods(fwkruntime.NewWaitingPodsMap()),
 			)
 			if err != nil {
@@ -626,15 +1182,17 @@ func TestPostFilter(t *testing.T) {
 			podInformer := informerFactory.Core().V1().Pods()
 			pgMgr := core.NewPodGroupManager(
 				client,
-				tu.NewFakeSharedLister(tt.existingPods, nodes),
+				snapshot,
 				&scheduleTimeout,
 				podInformer,
 			)
 			pl := &Coscheduling{
-				frameworkHandler: f,
-				pgMgr:            pgMgr,
-				scheduleTimeout:  &scheduleTimeout,
+				frameworkHandler:        f,
+				pgMgr:                   pgMgr,
+				scheduleTimeout:         &scheduleTimeout,
+				earlyRejectionThreshold: tt.earlyRejectionThreshold,
 			}
+			pgMgr.Run(ctx)
 
 			informerFactory.Start(ctx.Done())
 			if !clicache.WaitForCacheSync(ctx.Done(), podInformer.Informer().HasSynced) {
@@ -651,6 +1209,149 @@ func TestPostFilter(t *testing.T) {
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Want %v, but got %v", tt.want, got)
 			}
+
+			if tt.wantCondition != nil {
+				var got *metav1.Condition
+				if err := wait.PollUntilContextTimeout(ctx, 10*time.Millisecond, time.Second, true, func(ctx context.Context) (bool, error) {
+					var pg v1alpha1.PodGroup
+					if err := client.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "pg1"}, &pg); err != nil {
+						return false, err
+					}
+					got = meta.FindStatusCondition(pg.Status.Conditions, tt.wantCondition.Type)
+					return got != nil, nil
+				}); err != nil {
+					t.Fatalf("Want condition %v, but got none", tt.wantCondition.Type)
+				}
+				if diff := cmp.Diff(tt.wantCondition, got, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime")); diff != "" {
+					t.Errorf("Unexpected condition (-want,+got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestScore(t *testing.T) {
+	scheduleTimeout := 10 * time.Second
+	nodes := []*v1.Node{
+		st.MakeNode().Name("node-a1").Label(v1.LabelTopologyZone, "zone-a").Obj(),
+		st.MakeNode().Name("node-a2").Label(v1.LabelTopologyZone, "zone-a").Obj(),
+		st.MakeNode().Name("node-b1").Label(v1.LabelTopologyZone, "zone-b").Obj(),
+		st.MakeNode().Name("node-c1").Label(v1.LabelTopologyZone, "zone-c").Obj(),
+		st.MakeNode().Name("node-x").Obj(),
+	}
+	existingPods := []*v1.Pod{
+		st.MakePod().Name("p1").Namespace("ns").UID("p1").Node("node-a1").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+		st.MakePod().Name("p2").Namespace("ns").UID("p2").Node("node-a2").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+		st.MakePod().Name("p3").Namespace("ns").UID("p3").Node("node-b1").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+		st.MakePod().Name("p4").Namespace("ns").UID("p4").Node("node-a1").Label(v1alpha1.PodGroupLabel, "pg2").Obj(),
+		st.MakePod().Name("p5").Namespace("ns").UID("p5").Node("node-c1").Label(v1alpha1.PodGroupLabel, "pg2").Obj(),
+	}
+
+	tests := []struct {
+		name       string
+		pod        *v1.Pod
+		pg         *v1alpha1.PodGroup
+		wantStatus *framework.Status
+		want       map[string]int64
+	}{
+		{
+			name:       "pod does not belong to any pod group",
+			pod:        st.MakePod().Name("p").Namespace("ns").UID("p").Obj(),
+			pg:         tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(4).TopologyPreference(v1alpha1.TopologyPreferenceHigh).Obj(),
+			wantStatus: framework.NewStatus(framework.Skip),
+		},
+		{
+			name:       "pod group without topology preference",
+			pod:        st.MakePod().Name("p").Namespace("ns").UID("p").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+			pg:         tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(4).Obj(),
+			wantStatus: framework.NewStatus(framework.Skip),
+		},
+		{
+			name:       "pod group without assigned siblings",
+			pod:        st.MakePod().Name("p").Namespace("ns").UID("p").Label(v1alpha1.PodGroupLabel, "pg3").Obj(),
+			pg:         tu.MakePodGroup().Name("pg3").Namespace("ns").MinMember(4).TopologyPreference(v1alpha1.TopologyPreferenceHigh).Obj(),
+			wantStatus: framework.NewStatus(framework.Skip),
+		},
+		{
+			name: "high topology preference",
+			pod:  st.MakePod().Name("p").Namespace("ns").UID("p").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+			pg:   tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(4).TopologyPreference(v1alpha1.TopologyPreferenceHigh).Obj(),
+			want: map[string]int64{"node-a1": 66, "node-a2": 66, "node-b1": 33, "node-c1": 0, "node-x": 0},
+		},
+		{
+			name: "low topology preference",
+			pod:  st.MakePod().Name("p").Namespace("ns").UID("p").Label(v1alpha1.PodGroupLabel, "pg2").Obj(),
+			pg:   tu.MakePodGroup().Name("pg2").Namespace("ns").MinMember(3).TopologyPreference(v1alpha1.TopologyPreferenceLow).Obj(),
+			want: map[string]int64{"node-a1": 25, "node-a2": 25, "node-b1": 0, "node-c1": 25, "node-x": 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var objs []runtime.Object
+			for _, pod := range append(existingPods, tt.pod) {
+				objs = append(objs, pod)
+			}
+			objs = append(objs, tt.pg)
+
+			client, err := tu.NewFakeClient(objs...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			snapshot := tu.NewFakeSharedLister(existingPods, nodes)
+			registeredPlugins := []tf.RegisterPluginFunc{
+				tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+				tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+			}
+			f, err := tf.NewFramework(
+				ctx,
+				registeredPlugins,
+				"default-scheduler",
+				fwkruntime.WithSnapshotSharedLister(snapshot),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cs := clientsetfake.NewSimpleClientset()
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+			podInformer := informerFactory.Core().V1().Pods()
+			pgMgr := core.NewPodGroupManager(client, snapshot, &scheduleTimeout, podInformer)
+			pl := &Coscheduling{
+				frameworkHandler: f,
+				pgMgr:            pgMgr,
+				scheduleTimeout:  &scheduleTimeout,
+				topologyKey:      v1.LabelTopologyZone,
+			}
+			addFunc := core.AddPodFactory(pgMgr)
+			for _, p := range existingPods {
+				addFunc(p)
+			}
+
+			state := framework.NewCycleState()
+			status := pl.PreScore(ctx, state, tt.pod, nil)
+			if !reflect.DeepEqual(status, tt.wantStatus) {
+				t.Fatalf("Want status %v, but got %v", tt.wantStatus, status)
+			}
+			if !status.IsSuccess() {
+				return
+			}
+
+			got := make(map[string]int64)
+			for _, n := range nodes {
+				score, status := pl.Score(ctx, state, tt.pod, n.Name)
+				if !status.IsSuccess() {
+					t.Fatalf("Unexpected Score status: %v", status)
+				}
+				got[n.Name] = score
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Unexpected scores (-want,+got):\n%s", diff)
+			}
 		})
 	}
 }
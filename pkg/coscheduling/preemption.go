@@ -0,0 +1,493 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coscheduling
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	schedutil "k8s.io/kubernetes/pkg/scheduler/util"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/coscheduling/core"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// evictionUnit is a set of pods that can only be evicted together: either a single pod,
+// all the assigned members of another PodGroup, or a surplus member of an elastic PodGroup.
+type evictionUnit struct {
+	pods []*v1.Pod
+	// priority is the highest priority among the pods of the unit.
+	priority int32
+	// key identifies the unit for deterministic ordering.
+	key string
+	// surplus tells whether the unit is a preemptible surplus member of an elastic PodGroup.
+	surplus bool
+}
+
+// gangPreemption is the outcome of a successful gang preemption dry run.
+type gangPreemption struct {
+	// victims are the pods to be evicted.
+	victims []*v1.Pod
+	// nominations maps the unassigned members of the PodGroup to the nodes they are nominated to.
+	nominations map[*v1.Pod]string
+}
+
+// preemptPodGroup tries to make room for the unassigned members of the PodGroup that the given pod
+// belongs to, by evicting lower-priority pods across several nodes. Victims belonging to other
+// PodGroups are always evicted as whole groups. On success, victims are evicted, all unassigned
+// members are nominated together and the node nominated for the given pod is returned.
+func (cs *Coscheduling) preemptPodGroup(ctx context.Context, state *framework.CycleState, pod *v1.Pod, pgName string,
+	pg *v1alpha1.PodGroup, filteredNodeStatusReader framework.NodeToStatusReader) (string, error) {
+	lh := klog.FromContext(ctx)
+	if pod.Spec.PreemptionPolicy != nil && *pod.Spec.PreemptionPolicy == v1.PreemptNever {
+		return "", nil
+	}
+
+	members, err := cs.unassignedMembers(pod, pgName, pg)
+	if err != nil || len(members) == 0 {
+		return "", err
+	}
+
+	nodeInfos, err := cs.frameworkHandler.SnapshotSharedLister().NodeInfos().List()
+	if err != nil {
+		return "", err
+	}
+	var potentialNodes []*framework.NodeInfo
+	for _, nodeInfo := range nodeInfos {
+		if nodeInfo.Node() == nil {
+			continue
+		}
+		// Preemption won't help on nodes rejected as unresolvable; siblings are assumed to share the pod spec.
+		if filteredNodeStatusReader.Get(nodeInfo.Node().Name).Code() == framework.UnschedulableAndUnresolvable {
+			continue
+		}
+		potentialNodes = append(potentialNodes, nodeInfo)
+	}
+	if len(potentialNodes) == 0 {
+		return "", nil
+	}
+	sort.Slice(potentialNodes, func(i, j int) bool { return potentialNodes[i].Node().Name < potentialNodes[j].Node().Name })
+	potentialNodes = candidateNodes(potentialNodes, cs.getNumCandidates(len(potentialNodes), len(members)), rand.Intn(len(potentialNodes)))
+
+	units := evictionUnits(nodeInfos, potentialNodes, pgName, corev1helpers.PodPriority(pod), cs.preemptibleSurplus(ctx, nodeInfos, pgName))
+	plan := planGangPreemption(ctx, cs.frameworkHandler, state, pgName, pg, members, nodeInfos, potentialNodes, units)
+	if plan == nil {
+		lh.V(4).Info("Gang preemption cannot make room for the pod group", "podGroup", klog.KObj(pg))
+		return "", nil
+	}
+
+	if err := cs.evictVictims(ctx, pod, pgName, plan.victims); err != nil {
+		return "", err
+	}
+
+	nominatedNodeName := ""
+	for member, nodeName := range plan.nominations {
+		if member.UID == pod.UID {
+			nominatedNodeName = nodeName
+			continue
+		}
+		if member.Status.NominatedNodeName == nodeName {
+			continue
+		}
+		newStatus := member.Status.DeepCopy()
+		newStatus.NominatedNodeName = nodeName
+		if err := schedutil.PatchPodStatus(ctx, cs.frameworkHandler.ClientSet(), member, newStatus); err != nil {
+			lh.Error(err, "Failed to nominate the pod group member", "pod", klog.KObj(member), "node", nodeName)
+		}
+	}
+	lh.V(2).Info("Preempted pods for the pod group", "podGroup", klog.KObj(pg), "victims", len(plan.victims), "nominated", len(plan.nominations))
+	return nominatedNodeName, nil
+}
+
+// getNumCandidates returns the number of nodes the gang preemption dry runs are bounded to, out of the given
+// number of potential nodes, which is the larger of minCandidateNodesPercentage and minCandidateNodesAbsolute like
+// DefaultPreemption, but never lower than the number of members to place.
+func (cs *Coscheduling) getNumCandidates(numNodes, numMembers int) int {
+	n := max(numNodes*int(cs.minCandidateNodesPercentage)/100, int(cs.minCandidateNodesAbsolute), numMembers)
+	return min(n, numNodes)
+}
+
+// candidateNodes returns the given number of potential nodes, starting from the given offset and wrapping around,
+// so that the same nodes aren't always the ones disrupted.
+func candidateNodes(potentialNodes []*framework.NodeInfo, numCandidates, offset int) []*framework.NodeInfo {
+	if numCandidates >= len(potentialNodes) {
+		return potentialNodes
+	}
+	candidates := make([]*framework.NodeInfo, 0, numCandidates)
+	for i := 0; i < numCandidates; i++ {
+		candidates = append(candidates, potentialNodes[(offset+i)%len(potentialNodes)])
+	}
+	return candidates
+}
+
+// unassignedMembers returns the members of the PodGroup that are neither assumed nor bound, with
+// the given pod first, limited to the number that's still needed to reach the minMember of the
+// PodGroup and of each of its roles. Members of the roles short of their minMember are picked first.
+func (cs *Coscheduling) unassignedMembers(pod *v1.Pod, pgName string, pg *v1alpha1.PodGroup) ([]*v1.Pod, error) {
+	pods, err := cs.frameworkHandler.SharedInformerFactory().Core().V1().Pods().Lister().Pods(pod.Namespace).List(
+		labels.SelectorFromSet(labels.Set{v1alpha1.PodGroupLabel: pg.Name}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+
+	assigned := cs.pgMgr.GetAssignedPods(pgName)
+	deficits := make(map[string]int32, len(pg.Spec.Roles))
+	for _, role := range pg.Spec.Roles {
+		deficits[role.Name] = role.MinMember
+	}
+	var candidates []*v1.Pod
+	for _, p := range pods {
+		if assigned.Has(p.Name) {
+			deficits[util.GetPodGroupRole(p)]--
+			continue
+		}
+		if p.UID == pod.UID || p.Spec.NodeName != "" || p.DeletionTimestamp != nil {
+			continue
+		}
+		candidates = append(candidates, p)
+	}
+
+	required := int(pg.Spec.MinMember) - len(assigned)
+	roleRequired := 0
+	for _, deficit := range deficits {
+		if deficit > 0 {
+			roleRequired += int(deficit)
+		}
+	}
+	if roleRequired > required {
+		required = roleRequired
+	}
+	if required <= 0 {
+		return nil, nil
+	}
+
+	members := []*v1.Pod{pod}
+	deficits[util.GetPodGroupRole(pod)]--
+	picked := sets.New[types.UID]()
+	for _, p := range candidates {
+		if role := util.GetPodGroupRole(p); deficits[role] > 0 && len(members) < required {
+			members = append(members, p)
+			picked.Insert(p.UID)
+			deficits[role]--
+		}
+	}
+	for _, p := range candidates {
+		if len(members) == required {
+			break
+		}
+		if !picked.Has(p.UID) {
+			members = append(members, p)
+		}
+	}
+	if len(members) < required {
+		return nil, nil
+	}
+	return members, nil
+}
+
+// preemptibleSurplus returns the number of members beyond minMember of the other elastic PodGroups
+// on the nodes which allow their surplus members to be preempted, keyed by the full PodGroup names.
+func (cs *Coscheduling) preemptibleSurplus(ctx context.Context, nodeInfos []*framework.NodeInfo, pgName string) map[string]int {
+	surplus := make(map[string]int)
+	seen := sets.New[string]()
+	for _, nodeInfo := range nodeInfos {
+		for _, podInfo := range nodeInfo.Pods {
+			fullName := util.GetPodGroupFullName(podInfo.Pod)
+			if fullName == "" || fullName == pgName || seen.Has(fullName) {
+				continue
+			}
+			seen.Insert(fullName)
+			_, pg := cs.pgMgr.GetPodGroup(ctx, podInfo.Pod)
+			if pg == nil || !pg.Spec.PreemptibleSurplus {
+				continue
+			}
+			if _, elastic := util.GetPodGroupMaxMember(pg); !elastic {
+				continue
+			}
+			if n := cs.pgMgr.GetAssignedPodCount(fullName) - int(pg.Spec.MinMember); n > 0 {
+				surplus[fullName] = n
+			}
+		}
+	}
+	return surplus
+}
+
+// evictionUnits collects the pods with a lower priority than the preemptor on the potential nodes.
+// A pod that belongs to a PodGroup forms a unit with all the assigned members of that group, which
+// can only be evicted if none of them has a priority equal to or higher than the preemptor.
+// Besides, up to the given number of surplus members of an elastic PodGroup form a unit each,
+// regardless of their priorities, and are preferred over any other unit.
+func evictionUnits(nodeInfos, potentialNodes []*framework.NodeInfo, pgName string, priority int32, surplus map[string]int) []*evictionUnit {
+	groups := make(map[string]*evictionUnit)
+	for _, nodeInfo := range nodeInfos {
+		for _, podInfo := range nodeInfo.Pods {
+			fullName := util.GetPodGroupFullName(podInfo.Pod)
+			if fullName == "" || fullName == pgName {
+				continue
+			}
+			unit, ok := groups[fullName]
+			if !ok {
+				unit = &evictionUnit{key: fullName, priority: corev1helpers.PodPriority(podInfo.Pod)}
+				groups[fullName] = unit
+			}
+			unit.pods = append(unit.pods, podInfo.Pod)
+			if p := corev1helpers.PodPriority(podInfo.Pod); p > unit.priority {
+				unit.priority = p
+			}
+		}
+	}
+
+	var units []*evictionUnit
+	seen := make(map[string]bool)
+	for _, nodeInfo := range potentialNodes {
+		for _, podInfo := range nodeInfo.Pods {
+			fullName := util.GetPodGroupFullName(podInfo.Pod)
+			if fullName == "" {
+				if p := corev1helpers.PodPriority(podInfo.Pod); p < priority {
+					units = append(units, &evictionUnit{pods: []*v1.Pod{podInfo.Pod}, priority: p, key: core.GetNamespacedName(podInfo.Pod)})
+				}
+				continue
+			}
+			if fullName == pgName {
+				continue
+			}
+			if surplus[fullName] > 0 {
+				surplus[fullName]--
+				units = append(units, &evictionUnit{pods: []*v1.Pod{podInfo.Pod}, priority: corev1helpers.PodPriority(podInfo.Pod),
+					key: core.GetNamespacedName(podInfo.Pod), surplus: true})
+			}
+			if seen[fullName] {
+				continue
+			}
+			seen[fullName] = true
+			if unit := groups[fullName]; unit.priority < priority {
+				units = append(units, unit)
+			}
+		}
+	}
+
+	// Prefer evicting the surplus members, and then the lowest priority and the smallest units first.
+	sort.SliceStable(units, func(i, j int) bool {
+		if units[i].surplus != units[j].surplus {
+			return units[i].surplus
+		}
+		if units[i].priority != units[j].priority {
+			return units[i].priority < units[j].priority
+		}
+		if len(units[i].pods) != len(units[j].pods) {
+			return len(units[i].pods) < len(units[j].pods)
+		}
+		return units[i].key < units[j].key
+	})
+	return units
+}
+
+// planGangPreemption finds the smallest prefix of the ordered eviction units that makes room for all
+// the members, and then reprieves as many of the chosen units as possible, starting from the one with
+// the highest priority. It returns nil if evicting all the units still doesn't make enough room.
+func planGangPreemption(ctx context.Context, fh framework.Handle, state *framework.CycleState, pgName string, pg *v1alpha1.PodGroup,
+	members []*v1.Pod, nodeInfos, potentialNodes []*framework.NodeInfo, units []*evictionUnit) *gangPreemption {
+	nodesByName := make(map[string]*framework.NodeInfo, len(nodeInfos))
+	for _, nodeInfo := range nodeInfos {
+		if nodeInfo.Node() != nil {
+			nodesByName[nodeInfo.Node().Name] = nodeInfo
+		}
+	}
+	dryRun := func(units []*evictionUnit) map[*v1.Pod]string {
+		return dryRunGangPreemption(ctx, fh, state, pgName, pg, members, nodeInfos, nodesByName, potentialNodes, units)
+	}
+
+	nominations := dryRun(units)
+	if nominations == nil {
+		// Preemption doesn't help.
+		return nil
+	}
+	// Evicting more units only makes more room, so the smallest prefix is found by a binary search
+	// among the prefixes shorter than the last one known to make enough room.
+	lo, hi := 0, len(units)
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		if n := dryRun(units[:mid]); n != nil {
+			hi, nominations = mid, n
+		} else {
+			lo = mid + 1
+		}
+	}
+	if hi == 0 {
+		// Preemption isn't needed at all.
+		return nil
+	}
+
+	// The last chosen unit can't be reprieved, since the shorter prefix doesn't make enough room.
+	chosen := units[:hi:hi]
+	for i := len(chosen) - 2; i >= 0; i-- {
+		reprieved := append(append([]*evictionUnit{}, chosen[:i]...), chosen[i+1:]...)
+		if n := dryRun(reprieved); n != nil {
+			chosen, nominations = reprieved, n
+		}
+	}
+
+	plan := &gangPreemption{nominations: nominations}
+	evicted := sets.New[types.UID]()
+	for _, unit := range chosen {
+		for _, victim := range unit.pods {
+			// A surplus member is also part of the unit of its whole PodGroup.
+			if !evicted.Has(victim.UID) {
+				evicted.Insert(victim.UID)
+				plan.victims = append(plan.victims, victim)
+			}
+		}
+	}
+	return plan
+}
+
+// dryRunGangPreemption evicts the given units from a copy of the potential nodes and of the nodes of the victims,
+// and then checks whether the PodGroup's minResources is satisfied and all the members can be placed on the potential
+// nodes, one after the other. A member is placed on a node only if it passes the filter plugins, with the victims
+// removed and the members placed before added. The cycle state of the first member, the preemptor, is used for all
+// the members, which are assumed to share its pod spec. It returns the nodes where the members are placed, or nil if
+// they don't fit.
+func dryRunGangPreemption(ctx context.Context, fh framework.Handle, state *framework.CycleState, pgName string,
+	pg *v1alpha1.PodGroup, members []*v1.Pod, nodeInfos []*framework.NodeInfo, nodesByName map[string]*framework.NodeInfo,
+	potentialNodes []*framework.NodeInfo, units []*evictionUnit) map[*v1.Pod]string {
+	logger := klog.FromContext(ctx)
+	preemptor := members[0]
+	state = state.Clone()
+	nodeCopies := make(map[string]*framework.NodeInfo, len(potentialNodes))
+	for _, nodeInfo := range potentialNodes {
+		nodeCopies[nodeInfo.Node().Name] = nodeInfo.Snapshot()
+	}
+	evicted := sets.New[types.UID]()
+	for _, unit := range units {
+		for _, victim := range unit.pods {
+			if evicted.Has(victim.UID) {
+				continue
+			}
+			evicted.Insert(victim.UID)
+			nodeCopy, ok := nodeCopies[victim.Spec.NodeName]
+			if !ok {
+				nodeInfo, ok := nodesByName[victim.Spec.NodeName]
+				if !ok {
+					continue
+				}
+				nodeCopy = nodeInfo.Snapshot()
+				nodeCopies[victim.Spec.NodeName] = nodeCopy
+			}
+			if err := nodeCopy.RemovePod(logger, victim); err != nil {
+				return nil
+			}
+			podInfo, err := framework.NewPodInfo(victim)
+			if err != nil {
+				return nil
+			}
+			if status := fh.RunPreFilterExtensionRemovePod(ctx, state, preemptor, podInfo, nodeCopy); !status.IsSuccess() {
+				return nil
+			}
+		}
+	}
+
+	if pg.Spec.MinResources != nil {
+		allNodes := make([]*framework.NodeInfo, 0, len(nodeInfos))
+		for _, nodeInfo := range nodeInfos {
+			if nodeInfo.Node() == nil {
+				continue
+			}
+			if nodeCopy, ok := nodeCopies[nodeInfo.Node().Name]; ok {
+				nodeInfo = nodeCopy
+			}
+			allNodes = append(allNodes, nodeInfo)
+		}
+		minResources := pg.Spec.MinResources.DeepCopy()
+		minResources[v1.ResourcePods] = *resource.NewQuantity(int64(pg.Spec.MinMember), resource.DecimalSI)
+		if err := core.CheckClusterResource(ctx, allNodes, minResources, pgName); err != nil {
+			return nil
+		}
+	}
+
+	nominations := make(map[*v1.Pod]string, len(members))
+	for _, member := range members {
+		placed := false
+		for _, nodeInfo := range potentialNodes {
+			nodeCopy := nodeCopies[nodeInfo.Node().Name]
+			if !fh.RunFilterPluginsWithNominatedPods(ctx, state, member, nodeCopy).IsSuccess() {
+				continue
+			}
+			podInfo, err := framework.NewPodInfo(member)
+			if err != nil {
+				return nil
+			}
+			nodeCopy.AddPodInfo(podInfo)
+			if status := fh.RunPreFilterExtensionAddPod(ctx, state, preemptor, podInfo, nodeCopy); !status.IsSuccess() {
+				return nil
+			}
+			nominations[member] = nodeCopy.Node().Name
+			placed = true
+			break
+		}
+		if !placed {
+			return nil
+		}
+	}
+	return nominations
+}
+
+// evictVictims evicts all the given victims for the PodGroup of the given preemptor. The victims waiting on Permit are
+// rejected first, which can't fail, and every other victim is deleted even if the deletion of another one fails, so
+// that a victim gang is never left partially preempted by a failure midway. The errors are aggregated.
+func (cs *Coscheduling) evictVictims(ctx context.Context, preemptor *v1.Pod, pgName string, victims []*v1.Pod) error {
+	var toDelete []*v1.Pod
+	for _, victim := range victims {
+		if waitingPod := cs.frameworkHandler.GetWaitingPod(victim.UID); waitingPod != nil {
+			waitingPod.Reject(cs.Name(), fmt.Sprintf("preempted by pod group %v", pgName))
+			cs.recordEviction(ctx, preemptor, pgName, victim)
+			continue
+		}
+		toDelete = append(toDelete, victim)
+	}
+	var errs []error
+	for _, victim := range toDelete {
+		if err := schedutil.DeletePod(ctx, cs.frameworkHandler.ClientSet(), victim); err != nil && !apierrors.IsNotFound(err) {
+			klog.FromContext(ctx).Error(err, "Failed to preempt the pod", "pod", klog.KObj(victim), "podGroup", pgName)
+			errs = append(errs, err)
+			continue
+		}
+		cs.recordEviction(ctx, preemptor, pgName, victim)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// recordEviction logs and records an event for the given victim evicted for the PodGroup of the given preemptor.
+func (cs *Coscheduling) recordEviction(ctx context.Context, preemptor *v1.Pod, pgName string, victim *v1.Pod) {
+	klog.FromContext(ctx).V(2).Info("Preempted the pod for the pod group", "pod", klog.KObj(victim), "podGroup", pgName, "node", victim.Spec.NodeName)
+	cs.frameworkHandler.EventRecorder().Eventf(victim, preemptor, v1.EventTypeNormal, "Preempted", "Preempting",
+		"Preempted by pod group %v on node %v", pgName, victim.Spec.NodeName)
+}
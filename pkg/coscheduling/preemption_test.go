@@ -0,0 +1,445 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coscheduling
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	plfeature "k8s.io/kubernetes/pkg/scheduler/framework/plugins/feature"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/nodeports"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/noderesources"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/tainttoleration"
+	fwkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	"k8s.io/kubernetes/pkg/scheduler/metrics"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+	tf "k8s.io/kubernetes/pkg/scheduler/testing/framework"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	tu "sigs.k8s.io/scheduler-plugins/test/util"
+)
+
+func TestPlanGangPreemption(t *testing.T) {
+	capacity := map[v1.ResourceName]string{
+		v1.ResourceCPU:  "2",
+		v1.ResourcePods: "10",
+	}
+	nodes := []*v1.Node{
+		st.MakeNode().Name("node1").Capacity(capacity).Obj(),
+		st.MakeNode().Name("node2").Capacity(capacity).Obj(),
+	}
+	members := []*v1.Pod{
+		st.MakePod().Name("m1").UID("m1").Namespace("ns").Priority(100).Req(map[v1.ResourceName]string{v1.ResourceCPU: "2"}).Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+		st.MakePod().Name("m2").UID("m2").Namespace("ns").Priority(100).Req(map[v1.ResourceName]string{v1.ResourceCPU: "2"}).Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+	}
+
+	tests := []struct {
+		name            string
+		existingPods    []*v1.Pod
+		members         []*v1.Pod
+		surplus         map[string]int
+		wantVictims     []string
+		wantNominations map[string]string
+	}{
+		{
+			name: "evict lower-priority pods across nodes",
+			existingPods: []*v1.Pod{
+				st.MakePod().Name("p1").UID("p1").Namespace("ns").Node("node1").Priority(10).Req(map[v1.ResourceName]string{v1.ResourceCPU: "2"}).Obj(),
+				st.MakePod().Name("p2").UID("p2").Namespace("ns").Node("node2").Priority(20).Req(map[v1.ResourceName]string{v1.ResourceCPU: "2"}).Obj(),
+			},
+			members:         members,
+			wantVictims:     []string{"p1", "p2"},
+			wantNominations: map[string]string{"m1": "node1", "m2": "node2"},
+		},
+		{
+			name: "evict a lower-priority pod group as a whole",
+			existingPods: []*v1.Pod{
+				st.MakePod().Name("p1").UID("p1").Namespace("ns").Node("node1").Priority(10).Req(map[v1.ResourceName]string{v1.ResourceCPU: "1"}).Label(v1alpha1.PodGroupLabel, "pg2").Obj(),
+				st.MakePod().Name("p2").UID("p2").Namespace("ns").Node("node2").Priority(10).Req(map[v1.ResourceName]string{v1.ResourceCPU: "1"}).Label(v1alpha1.PodGroupLabel, "pg2").Obj(),
+				st.MakePod().Name("p3").UID("p3").Namespace("ns").Node("node2").Priority(50).Req(map[v1.ResourceName]string{v1.ResourceCPU: "1"}).Obj(),
+			},
+			members:         members[:1],
+			wantVictims:     []string{"p1", "p2"},
+			wantNominations: map[string]string{"m1": "node1"},
+		},
+		{
+			name: "reprieve victims that are not needed",
+			existingPods: []*v1.Pod{
+				st.MakePod().Name("p1").UID("p1").Namespace("ns").Node("node1").Priority(10).Req(map[v1.ResourceName]string{v1.ResourceCPU: "1"}).Obj(),
+				st.MakePod().Name("p2").UID("p2").Namespace("ns").Node("node2").Priority(20).Req(map[v1.ResourceName]string{v1.ResourceCPU: "2"}).Obj(),
+				st.MakePod().Name("p3").UID("p3").Namespace("ns").Node("node1").Priority(30).Req(map[v1.ResourceName]string{v1.ResourceCPU: "1"}).Obj(),
+			},
+			members:         members[:1],
+			wantVictims:     []string{"p2"},
+			wantNominations: map[string]string{"m1": "node2"},
+		},
+		{
+			name: "pod group with a member of higher priority cannot be evicted",
+			existingPods: []*v1.Pod{
+				st.MakePod().Name("p1").UID("p1").Namespace("ns").Node("node1").Priority(10).Req(map[v1.ResourceName]string{v1.ResourceCPU: "2"}).Label(v1alpha1.PodGroupLabel, "pg2").Obj(),
+				st.MakePod().Name("p2").UID("p2").Namespace("ns").Node("node2").Priority(200).Req(map[v1.ResourceName]string{v1.ResourceCPU: "2"}).Label(v1alpha1.PodGroupLabel, "pg2").Obj(),
+			},
+			members: members[:1],
+		},
+		{
+			name: "evict a surplus member of an elastic pod group regardless of its priority",
+			existingPods: []*v1.Pod{
+				st.MakePod().Name("p1").UID("p1").Namespace("ns").Node("node1").Priority(200).Req(map[v1.ResourceName]string{v1.ResourceCPU: "2"}).Label(v1alpha1.PodGroupLabel, "pg2").Obj(),
+				st.MakePod().Name("p2").UID("p2").Namespace("ns").Node("node2").Priority(200).Req(map[v1.ResourceName]string{v1.ResourceCPU: "2"}).Label(v1alpha1.PodGroupLabel, "pg2").Obj(),
+			},
+			members:         members[:1],
+			surplus:         map[string]int{"ns/pg2": 1},
+			wantVictims:     []string{"p1"},
+			wantNominations: map[string]string{"m1": "node1"},
+		},
+		{
+			name: "surplus members beyond the surplus count are not evicted",
+			existingPods: []*v1.Pod{
+				st.MakePod().Name("p1").UID("p1").Namespace("ns").Node("node1").Priority(200).Req(map[v1.ResourceName]string{v1.ResourceCPU: "2"}).Label(v1alpha1.PodGroupLabel, "pg2").Obj(),
+				st.MakePod().Name("p2").UID("p2").Namespace("ns").Node("node2").Priority(200).Req(map[v1.ResourceName]string{v1.ResourceCPU: "2"}).Label(v1alpha1.PodGroupLabel, "pg2").Obj(),
+			},
+			members: members,
+			surplus: map[string]int{"ns/pg2": 1},
+		},
+		{
+			name: "evict a lower-priority elastic pod group as a whole",
+			existingPods: []*v1.Pod{
+				st.MakePod().Name("p1").UID("p1").Namespace("ns").Node("node1").Priority(10).Req(map[v1.ResourceName]string{v1.ResourceCPU: "2"}).Label(v1alpha1.PodGroupLabel, "pg2").Obj(),
+				st.MakePod().Name("p2").UID("p2").Namespace("ns").Node("node2").Priority(10).Req(map[v1.ResourceName]string{v1.ResourceCPU: "2"}).Label(v1alpha1.PodGroupLabel, "pg2").Obj(),
+			},
+			members:         members,
+			surplus:         map[string]int{"ns/pg2": 1},
+			wantVictims:     []string{"p1", "p2"},
+			wantNominations: map[string]string{"m1": "node1", "m2": "node2"},
+		},
+		{
+			name: "not enough lower-priority pods to evict",
+			existingPods: []*v1.Pod{
+				st.MakePod().Name("p1").UID("p1").Namespace("ns").Node("node1").Priority(10).Req(map[v1.ResourceName]string{v1.ResourceCPU: "2"}).Obj(),
+				st.MakePod().Name("p2").UID("p2").Namespace("ns").Node("node2").Priority(200).Req(map[v1.ResourceName]string{v1.ResourceCPU: "2"}).Obj(),
+			},
+			members: members,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			pg := tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(int32(len(tt.members))).Obj()
+			nodeInfos, err := tu.NewFakeSharedLister(tt.existingPods, nodes).NodeInfos().List()
+			if err != nil {
+				t.Fatal(err)
+			}
+			sort.Slice(nodeInfos, func(i, j int) bool { return nodeInfos[i].Node().Name < nodeInfos[j].Node().Name })
+
+			fh := newPreemptionFramework(t, nil)
+			state := framework.NewCycleState()
+			if _, status, _ := fh.RunPreFilterPlugins(ctx, state, tt.members[0]); !status.IsSuccess() {
+				t.Fatalf("Unexpected PreFilter status: %v", status)
+			}
+
+			units := evictionUnits(nodeInfos, nodeInfos, "ns/pg1", 100, tt.surplus)
+			plan := planGangPreemption(ctx, fh, state, "ns/pg1", pg, tt.members, nodeInfos, nodeInfos, units)
+			if tt.wantNominations == nil {
+				if plan != nil {
+					t.Fatalf("Want no preemption, but got %v victims", len(plan.victims))
+				}
+				return
+			}
+			if plan == nil {
+				t.Fatal("Want preemption, but got none")
+			}
+
+			var gotVictims []string
+			for _, victim := range plan.victims {
+				gotVictims = append(gotVictims, victim.Name)
+			}
+			sort.Strings(gotVictims)
+			if diff := cmp.Diff(tt.wantVictims, gotVictims); diff != "" {
+				t.Errorf("Unexpected victims (-want,+got):\n%s", diff)
+			}
+			gotNominations := make(map[string]string)
+			for member, nodeName := range plan.nominations {
+				gotNominations[member.Name] = nodeName
+			}
+			if diff := cmp.Diff(tt.wantNominations, gotNominations); diff != "" {
+				t.Errorf("Unexpected nominations (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPlanGangPreemptionFilters(t *testing.T) {
+	capacity := map[v1.ResourceName]string{
+		v1.ResourceCPU:  "4",
+		v1.ResourcePods: "10",
+	}
+	req := map[v1.ResourceName]string{v1.ResourceCPU: "1"}
+	port := []v1.ContainerPort{{HostPort: 8080, ContainerPort: 8080, Protocol: v1.ProtocolTCP}}
+
+	tests := []struct {
+		name            string
+		nodes           []*v1.Node
+		existingPods    []*v1.Pod
+		members         []*v1.Pod
+		wantVictims     []string
+		wantNominations map[string]string
+	}{
+		{
+			name: "nodes rejected by filter plugins are not nominated",
+			nodes: []*v1.Node{
+				st.MakeNode().Name("node1").Capacity(capacity).Taints([]v1.Taint{{Key: "dedicated", Effect: v1.TaintEffectNoSchedule}}).Obj(),
+				st.MakeNode().Name("node2").Capacity(capacity).Obj(),
+			},
+			existingPods: []*v1.Pod{
+				st.MakePod().Name("p1").UID("p1").Namespace("ns").Node("node1").Priority(10).Req(capacity).Obj(),
+				st.MakePod().Name("p2").UID("p2").Namespace("ns").Node("node2").Priority(20).Req(capacity).Obj(),
+			},
+			members: []*v1.Pod{
+				st.MakePod().Name("m1").UID("m1").Namespace("ns").Priority(100).Req(req).Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+			},
+			wantVictims:     []string{"p2"},
+			wantNominations: map[string]string{"m1": "node2"},
+		},
+		{
+			name: "members placed before are considered by filter plugins",
+			nodes: []*v1.Node{
+				st.MakeNode().Name("node1").Capacity(capacity).Obj(),
+				st.MakeNode().Name("node2").Capacity(capacity).Obj(),
+			},
+			existingPods: []*v1.Pod{
+				st.MakePod().Name("p1").UID("p1").Namespace("ns").Node("node1").Priority(10).Req(req).ContainerPort(port).Obj(),
+			},
+			members: []*v1.Pod{
+				st.MakePod().Name("m1").UID("m1").Namespace("ns").Priority(100).Req(req).ContainerPort(port).Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+				st.MakePod().Name("m2").UID("m2").Namespace("ns").Priority(100).Req(req).ContainerPort(port).Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+			},
+			wantVictims:     []string{"p1"},
+			wantNominations: map[string]string{"m1": "node1", "m2": "node2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			pg := tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(int32(len(tt.members))).Obj()
+			snapshot := tu.NewFakeSharedLister(tt.existingPods, tt.nodes)
+			nodeInfos, err := snapshot.NodeInfos().List()
+			if err != nil {
+				t.Fatal(err)
+			}
+			sort.Slice(nodeInfos, func(i, j int) bool { return nodeInfos[i].Node().Name < nodeInfos[j].Node().Name })
+
+			fh := newPreemptionFramework(t, snapshot,
+				tf.RegisterPluginAsExtensions(tainttoleration.Name, func(ctx context.Context, plArgs runtime.Object, fh framework.Handle) (framework.Plugin, error) {
+					return tainttoleration.New(ctx, plArgs, fh, plfeature.Features{})
+				}, "Filter"),
+				tf.RegisterPluginAsExtensions(nodeports.Name, func(ctx context.Context, plArgs runtime.Object, fh framework.Handle) (framework.Plugin, error) {
+					return nodeports.New(ctx, plArgs, fh, plfeature.Features{})
+				}, "PreFilter", "Filter"),
+			)
+			state := framework.NewCycleState()
+			if _, status, _ := fh.RunPreFilterPlugins(ctx, state, tt.members[0]); !status.IsSuccess() {
+				t.Fatalf("Unexpected PreFilter status: %v", status)
+			}
+
+			units := evictionUnits(nodeInfos, nodeInfos, "ns/pg1", 100, nil)
+			plan := planGangPreemption(ctx, fh, state, "ns/pg1", pg, tt.members, nodeInfos, nodeInfos, units)
+			if plan == nil {
+				t.Fatal("Want preemption, but got none")
+			}
+			var gotVictims []string
+			for _, victim := range plan.victims {
+				gotVictims = append(gotVictims, victim.Name)
+			}
+			sort.Strings(gotVictims)
+			if diff := cmp.Diff(tt.wantVictims, gotVictims); diff != "" {
+				t.Errorf("Unexpected victims (-want,+got):\n%s", diff)
+			}
+			gotNominations := make(map[string]string)
+			for member, nodeName := range plan.nominations {
+				gotNominations[member.Name] = nodeName
+			}
+			if diff := cmp.Diff(tt.wantNominations, gotNominations); diff != "" {
+				t.Errorf("Unexpected nominations (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// newPreemptionFramework returns a framework running NodeResourcesFit and the given plugins, for the gang preemption
+// dry runs.
+func newPreemptionFramework(t *testing.T, snapshot framework.SharedLister, plugins ...tf.RegisterPluginFunc) framework.Framework {
+	metrics.Register()
+	registeredPlugins := append([]tf.RegisterPluginFunc{
+		tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+		tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		tf.RegisterPluginAsExtensions(noderesources.Name, func(ctx context.Context, plArgs runtime.Object, fh framework.Handle) (framework.Plugin, error) {
+			return noderesources.NewFit(ctx, plArgs, fh, plfeature.Features{})
+		}, "PreFilter", "Filter"),
+	}, plugins...)
+	fh, err := tf.NewFramework(context.Background(), registeredPlugins, "default-scheduler",
+		fwkruntime.WithSnapshotSharedLister(snapshot),
+		fwkruntime.WithPodNominator(tu.NewPodNominator(nil)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fh
+}
+
+func TestCandidateNodes(t *testing.T) {
+	var potentialNodes []*framework.NodeInfo
+	for i := 0; i < 300; i++ {
+		nodeInfo := framework.NewNodeInfo()
+		nodeInfo.SetNode(st.MakeNode().Name(fmt.Sprintf("node%03d", i)).Obj())
+		potentialNodes = append(potentialNodes, nodeInfo)
+	}
+
+	tests := []struct {
+		name       string
+		percentage int32
+		absolute   int32
+		numNodes   int
+		numMembers int
+		offset     int
+		wantFirst  string
+		wantNum    int
+	}{
+		{
+			name:       "absolute minimum",
+			percentage: 10,
+			absolute:   100,
+			numNodes:   300,
+			numMembers: 2,
+			wantFirst:  "node000",
+			wantNum:    100,
+		},
+		{
+			name:       "percentage minimum",
+			percentage: 50,
+			absolute:   100,
+			numNodes:   300,
+			numMembers: 2,
+			offset:     250,
+			wantFirst:  "node250",
+			wantNum:    150,
+		},
+		{
+			name:       "not fewer nodes than members",
+			percentage: 10,
+			absolute:   10,
+			numNodes:   300,
+			numMembers: 50,
+			offset:     10,
+			wantFirst:  "node010",
+			wantNum:    50,
+		},
+		{
+			name:       "all the nodes of small clusters",
+			percentage: 10,
+			absolute:   100,
+			numNodes:   50,
+			numMembers: 2,
+			offset:     10,
+			wantFirst:  "node000",
+			wantNum:    50,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := &Coscheduling{minCandidateNodesPercentage: tt.percentage, minCandidateNodesAbsolute: tt.absolute}
+			numCandidates := cs.getNumCandidates(tt.numNodes, tt.numMembers)
+			got := candidateNodes(potentialNodes[:tt.numNodes], numCandidates, tt.offset)
+			if len(got) != tt.wantNum {
+				t.Fatalf("Want %v candidate nodes, but got %v", tt.wantNum, len(got))
+			}
+			if got[0].Node().Name != tt.wantFirst {
+				t.Errorf("Want the candidates to start from %v, but got %v", tt.wantFirst, got[0].Node().Name)
+			}
+			names := sets.New[string]()
+			for _, nodeInfo := range got {
+				names.Insert(nodeInfo.Node().Name)
+			}
+			if names.Len() != len(got) {
+				t.Errorf("Want distinct candidate nodes, but got %v distinct out of %v", names.Len(), len(got))
+			}
+		})
+	}
+}
+
+func TestEvictVictims(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	preemptor := st.MakePod().Name("preemptor").UID("preemptor").Namespace("ns").Obj()
+	victims := []*v1.Pod{
+		st.MakePod().Name("v1").UID("v1").Namespace("ns").Label(v1alpha1.PodGroupLabel, "pg2").Node("n1").Obj(),
+		st.MakePod().Name("v2").UID("v2").Namespace("ns").Label(v1alpha1.PodGroupLabel, "pg2").Node("n1").Obj(),
+		st.MakePod().Name("v3").UID("v3").Namespace("ns").Label(v1alpha1.PodGroupLabel, "pg2").Node("n2").Obj(),
+	}
+	cs := clientsetfake.NewSimpleClientset(victims[0], victims[1], victims[2])
+	cs.PrependReactor("delete", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.(k8stesting.DeleteAction).GetName() == "v2" {
+			return true, nil, fmt.Errorf("injected error")
+		}
+		return false, nil, nil
+	})
+	f, err := tf.NewFramework(
+		ctx,
+		[]tf.RegisterPluginFunc{
+			tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+			tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		},
+		"default-scheduler",
+		fwkruntime.WithClientSet(cs),
+		fwkruntime.WithEventRecorder(&events.FakeRecorder{}),
+		fwkruntime.WithWaitingPods(fwkruntime.NewWaitingPodsMap()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pl := &Coscheduling{frameworkHandler: f}
+
+	if err := pl.evictVictims(ctx, preemptor, "ns/pg1", victims); err == nil {
+		t.Error("Want the failed deletion to be reported")
+	}
+	var remaining []string
+	pods, err := cs.CoreV1().Pods("ns").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pod := range pods.Items {
+		remaining = append(remaining, pod.Name)
+	}
+	if diff := cmp.Diff([]string{"v2"}, remaining); diff != "" {
+		t.Errorf("Want the victims after the failed one to be deleted too (-want, +got): %s", diff)
+	}
+}
@@ -20,13 +20,19 @@ package v1alpha1
 
 import (
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/client-go/applyconfigurations/meta/v1"
 )
 
 // ElasticQuotaSpecApplyConfiguration represents a declarative configuration of the ElasticQuotaSpec type for use
 // with apply.
 type ElasticQuotaSpecApplyConfiguration struct {
-	Min *v1.ResourceList `json:"min,omitempty"`
-	Max *v1.ResourceList `json:"max,omitempty"`
+	Min                       *v1.ResourceList                         `json:"min,omitempty"`
+	Max                       *v1.ResourceList                         `json:"max,omitempty"`
+	Parent                    *ElasticQuotaReferenceApplyConfiguration `json:"parent,omitempty"`
+	Weight                    *int32                                   `json:"weight,omitempty"`
+	NamespaceSelector         *metav1.LabelSelectorApplyConfiguration  `json:"namespaceSelector,omitempty"`
+	PodSelector               *metav1.LabelSelectorApplyConfiguration  `json:"podSelector,omitempty"`
+	ReclaimGracePeriodSeconds *int32                                   `json:"reclaimGracePeriodSeconds,omitempty"`
 }
 
 // ElasticQuotaSpecApplyConfiguration constructs a declarative configuration of the ElasticQuotaSpec type for use with
@@ -50,3 +56,43 @@ func (b *ElasticQuotaSpecApplyConfiguration) WithMax(value v1.ResourceList) *Ela
 	b.Max = &value
 	return b
 }
+
+// WithParent sets the Parent field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Parent field is set to the value of the last call.
+func (b *ElasticQuotaSpecApplyConfiguration) WithParent(value *ElasticQuotaReferenceApplyConfiguration) *ElasticQuotaSpecApplyConfiguration {
+	b.Parent = value
+	return b
+}
+
+// WithWeight sets the Weight field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Weight field is set to the value of the last call.
+func (b *ElasticQuotaSpecApplyConfiguration) WithWeight(value int32) *ElasticQuotaSpecApplyConfiguration {
+	b.Weight = &value
+	return b
+}
+
+// WithNamespaceSelector sets the NamespaceSelector field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the NamespaceSelector field is set to the value of the last call.
+func (b *ElasticQuotaSpecApplyConfiguration) WithNamespaceSelector(value *metav1.LabelSelectorApplyConfiguration) *ElasticQuotaSpecApplyConfiguration {
+	b.NamespaceSelector = value
+	return b
+}
+
+// WithPodSelector sets the PodSelector field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PodSelector field is set to the value of the last call.
+func (b *ElasticQuotaSpecApplyConfiguration) WithPodSelector(value *metav1.LabelSelectorApplyConfiguration) *ElasticQuotaSpecApplyConfiguration {
+	b.PodSelector = value
+	return b
+}
+
+// WithReclaimGracePeriodSeconds sets the ReclaimGracePeriodSeconds field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ReclaimGracePeriodSeconds field is set to the value of the last call.
+func (b *ElasticQuotaSpecApplyConfiguration) WithReclaimGracePeriodSeconds(value int32) *ElasticQuotaSpecApplyConfiguration {
+	b.ReclaimGracePeriodSeconds = &value
+	return b
+}
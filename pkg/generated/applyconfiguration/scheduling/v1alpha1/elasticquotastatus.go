@@ -20,12 +20,19 @@ package v1alpha1
 
 import (
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/client-go/applyconfigurations/meta/v1"
 )
 
 // ElasticQuotaStatusApplyConfiguration represents a declarative configuration of the ElasticQuotaStatus type for use
 // with apply.
 type ElasticQuotaStatusApplyConfiguration struct {
-	Used *v1.ResourceList `json:"used,omitempty"`
+	Used        *v1.ResourceList                     `json:"used,omitempty"`
+	FairShare   *v1.ResourceList                     `json:"fairShare,omitempty"`
+	Conditions  []metav1.ConditionApplyConfiguration `json:"conditions,omitempty"`
+	Borrowed    *v1.ResourceList                     `json:"borrowed,omitempty"`
+	Lent        *v1.ResourceList                     `json:"lent,omitempty"`
+	Available   *v1.ResourceList                     `json:"available,omitempty"`
+	PendingPods *int32                               `json:"pendingPods,omitempty"`
 }
 
 // ElasticQuotaStatusApplyConfiguration constructs a declarative configuration of the ElasticQuotaStatus type for use with
@@ -41,3 +48,56 @@ func (b *ElasticQuotaStatusApplyConfiguration) WithUsed(value v1.ResourceList) *
 	b.Used = &value
 	return b
 }
+
+// WithFairShare sets the FairShare field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the FairShare field is set to the value of the last call.
+func (b *ElasticQuotaStatusApplyConfiguration) WithFairShare(value v1.ResourceList) *ElasticQuotaStatusApplyConfiguration {
+	b.FairShare = &value
+	return b
+}
+
+// WithConditions adds the given value to the Conditions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Conditions field.
+func (b *ElasticQuotaStatusApplyConfiguration) WithConditions(values ...*metav1.ConditionApplyConfiguration) *ElasticQuotaStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithConditions")
+		}
+		b.Conditions = append(b.Conditions, *values[i])
+	}
+	return b
+}
+
+// WithBorrowed sets the Borrowed field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Borrowed field is set to the value of the last call.
+func (b *ElasticQuotaStatusApplyConfiguration) WithBorrowed(value v1.ResourceList) *ElasticQuotaStatusApplyConfiguration {
+	b.Borrowed = &value
+	return b
+}
+
+// WithLent sets the Lent field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Lent field is set to the value of the last call.
+func (b *ElasticQuotaStatusApplyConfiguration) WithLent(value v1.ResourceList) *ElasticQuotaStatusApplyConfiguration {
+	b.Lent = &value
+	return b
+}
+
+// WithAvailable sets the Available field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Available field is set to the value of the last call.
+func (b *ElasticQuotaStatusApplyConfiguration) WithAvailable(value v1.ResourceList) *ElasticQuotaStatusApplyConfiguration {
+	b.Available = &value
+	return b
+}
+
+// WithPendingPods sets the PendingPods field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PendingPods field is set to the value of the last call.
+func (b *ElasticQuotaStatusApplyConfiguration) WithPendingPods(value int32) *ElasticQuotaStatusApplyConfiguration {
+	b.PendingPods = &value
+	return b
+}
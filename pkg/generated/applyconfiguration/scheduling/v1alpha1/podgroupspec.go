@@ -20,14 +20,23 @@ package v1alpha1
 
 import (
 	v1 "k8s.io/api/core/v1"
+	schedulingv1alpha1 "sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
 )
 
 // PodGroupSpecApplyConfiguration represents a declarative configuration of the PodGroupSpec type for use
 // with apply.
 type PodGroupSpecApplyConfiguration struct {
-	MinMember              *int32           `json:"minMember,omitempty"`
-	MinResources           *v1.ResourceList `json:"minResources,omitempty"`
-	ScheduleTimeoutSeconds *int32           `json:"scheduleTimeoutSeconds,omitempty"`
+	MinMember               *int32                                    `json:"minMember,omitempty"`
+	MaxMember               *int32                                    `json:"maxMember,omitempty"`
+	PreemptibleSurplus      *bool                                     `json:"preemptibleSurplus,omitempty"`
+	MinResources            *v1.ResourceList                          `json:"minResources,omitempty"`
+	ScheduleTimeoutSeconds  *int32                                    `json:"scheduleTimeoutSeconds,omitempty"`
+	TopologyPreference      *schedulingv1alpha1.TopologyPreference    `json:"topologyPreference,omitempty"`
+	Roles                   []PodGroupRoleApplyConfiguration          `json:"roles,omitempty"`
+	TTLSecondsAfterFinished *int32                                    `json:"ttlSecondsAfterFinished,omitempty"`
+	FailurePolicy           *schedulingv1alpha1.PodGroupFailurePolicy `json:"failurePolicy,omitempty"`
+	MaxRestarts             *int32                                    `json:"maxRestarts,omitempty"`
+	PriorityClassName       *string                                   `json:"priorityClassName,omitempty"`
 }
 
 // PodGroupSpecApplyConfiguration constructs a declarative configuration of the PodGroupSpec type for use with
@@ -44,6 +53,22 @@ func (b *PodGroupSpecApplyConfiguration) WithMinMember(value int32) *PodGroupSpe
 	return b
 }
 
+// WithMaxMember sets the MaxMember field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MaxMember field is set to the value of the last call.
+func (b *PodGroupSpecApplyConfiguration) WithMaxMember(value int32) *PodGroupSpecApplyConfiguration {
+	b.MaxMember = &value
+	return b
+}
+
+// WithPreemptibleSurplus sets the PreemptibleSurplus field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PreemptibleSurplus field is set to the value of the last call.
+func (b *PodGroupSpecApplyConfiguration) WithPreemptibleSurplus(value bool) *PodGroupSpecApplyConfiguration {
+	b.PreemptibleSurplus = &value
+	return b
+}
+
 // WithMinResources sets the MinResources field in the declarative configuration to the given value
 // and returns the receiver, so that objects can be built by chaining "With" function invocations.
 // If called multiple times, the MinResources field is set to the value of the last call.
@@ -59,3 +84,56 @@ func (b *PodGroupSpecApplyConfiguration) WithScheduleTimeoutSeconds(value int32)
 	b.ScheduleTimeoutSeconds = &value
 	return b
 }
+
+// WithTopologyPreference sets the TopologyPreference field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TopologyPreference field is set to the value of the last call.
+func (b *PodGroupSpecApplyConfiguration) WithTopologyPreference(value schedulingv1alpha1.TopologyPreference) *PodGroupSpecApplyConfiguration {
+	b.TopologyPreference = &value
+	return b
+}
+
+// WithRoles adds the given value to the Roles field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Roles field.
+func (b *PodGroupSpecApplyConfiguration) WithRoles(values ...*PodGroupRoleApplyConfiguration) *PodGroupSpecApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithRoles")
+		}
+		b.Roles = append(b.Roles, *values[i])
+	}
+	return b
+}
+
+// WithTTLSecondsAfterFinished sets the TTLSecondsAfterFinished field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the TTLSecondsAfterFinished field is set to the value of the last call.
+func (b *PodGroupSpecApplyConfiguration) WithTTLSecondsAfterFinished(value int32) *PodGroupSpecApplyConfiguration {
+	b.TTLSecondsAfterFinished = &value
+	return b
+}
+
+// WithFailurePolicy sets the FailurePolicy field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the FailurePolicy field is set to the value of the last call.
+func (b *PodGroupSpecApplyConfiguration) WithFailurePolicy(value schedulingv1alpha1.PodGroupFailurePolicy) *PodGroupSpecApplyConfiguration {
+	b.FailurePolicy = &value
+	return b
+}
+
+// WithMaxRestarts sets the MaxRestarts field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the MaxRestarts field is set to the value of the last call.
+func (b *PodGroupSpecApplyConfiguration) WithMaxRestarts(value int32) *PodGroupSpecApplyConfiguration {
+	b.MaxRestarts = &value
+	return b
+}
+
+// WithPriorityClassName sets the PriorityClassName field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the PriorityClassName field is set to the value of the last call.
+func (b *PodGroupSpecApplyConfiguration) WithPriorityClassName(value string) *PodGroupSpecApplyConfiguration {
+	b.PriorityClassName = &value
+	return b
+}
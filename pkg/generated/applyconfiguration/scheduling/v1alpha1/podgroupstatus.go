@@ -20,18 +20,26 @@ package v1alpha1
 
 import (
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1 "k8s.io/client-go/applyconfigurations/meta/v1"
 	schedulingv1alpha1 "sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
 )
 
 // PodGroupStatusApplyConfiguration represents a declarative configuration of the PodGroupStatus type for use
 // with apply.
 type PodGroupStatusApplyConfiguration struct {
-	Phase             *schedulingv1alpha1.PodGroupPhase `json:"phase,omitempty"`
-	OccupiedBy        *string                           `json:"occupiedBy,omitempty"`
-	Running           *int32                            `json:"running,omitempty"`
-	Succeeded         *int32                            `json:"succeeded,omitempty"`
-	Failed            *int32                            `json:"failed,omitempty"`
-	ScheduleStartTime *v1.Time                          `json:"scheduleStartTime,omitempty"`
+	Phase              *schedulingv1alpha1.PodGroupPhase      `json:"phase,omitempty"`
+	OccupiedBy         *string                                `json:"occupiedBy,omitempty"`
+	Running            *int32                                 `json:"running,omitempty"`
+	Succeeded          *int32                                 `json:"succeeded,omitempty"`
+	Failed             *int32                                 `json:"failed,omitempty"`
+	ScheduleStartTime  *v1.Time                               `json:"scheduleStartTime,omitempty"`
+	SchedulingFailures *int32                                 `json:"schedulingFailures,omitempty"`
+	NextRetryTime      *v1.Time                               `json:"nextRetryTime,omitempty"`
+	Conditions         []metav1.ConditionApplyConfiguration   `json:"conditions,omitempty"`
+	Roles              []PodGroupRoleStatusApplyConfiguration `json:"roles,omitempty"`
+	CompletionTime     *v1.Time                               `json:"completionTime,omitempty"`
+	Restarts           *int32                                 `json:"restarts,omitempty"`
+	Elastic            *int32                                 `json:"elastic,omitempty"`
 }
 
 // PodGroupStatusApplyConfiguration constructs a declarative configuration of the PodGroupStatus type for use with
@@ -87,3 +95,69 @@ func (b *PodGroupStatusApplyConfiguration) WithScheduleStartTime(value v1.Time)
 	b.ScheduleStartTime = &value
 	return b
 }
+
+// WithSchedulingFailures sets the SchedulingFailures field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the SchedulingFailures field is set to the value of the last call.
+func (b *PodGroupStatusApplyConfiguration) WithSchedulingFailures(value int32) *PodGroupStatusApplyConfiguration {
+	b.SchedulingFailures = &value
+	return b
+}
+
+// WithNextRetryTime sets the NextRetryTime field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the NextRetryTime field is set to the value of the last call.
+func (b *PodGroupStatusApplyConfiguration) WithNextRetryTime(value v1.Time) *PodGroupStatusApplyConfiguration {
+	b.NextRetryTime = &value
+	return b
+}
+
+// WithConditions adds the given value to the Conditions field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Conditions field.
+func (b *PodGroupStatusApplyConfiguration) WithConditions(values ...*metav1.ConditionApplyConfiguration) *PodGroupStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithConditions")
+		}
+		b.Conditions = append(b.Conditions, *values[i])
+	}
+	return b
+}
+
+// WithRoles adds the given value to the Roles field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the Roles field.
+func (b *PodGroupStatusApplyConfiguration) WithRoles(values ...*PodGroupRoleStatusApplyConfiguration) *PodGroupStatusApplyConfiguration {
+	for i := range values {
+		if values[i] == nil {
+			panic("nil value passed to WithRoles")
+		}
+		b.Roles = append(b.Roles, *values[i])
+	}
+	return b
+}
+
+// WithCompletionTime sets the CompletionTime field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the CompletionTime field is set to the value of the last call.
+func (b *PodGroupStatusApplyConfiguration) WithCompletionTime(value v1.Time) *PodGroupStatusApplyConfiguration {
+	b.CompletionTime = &value
+	return b
+}
+
+// WithRestarts sets the Restarts field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Restarts field is set to the value of the last call.
+func (b *PodGroupStatusApplyConfiguration) WithRestarts(value int32) *PodGroupStatusApplyConfiguration {
+	b.Restarts = &value
+	return b
+}
+
+// WithElastic sets the Elastic field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the Elastic field is set to the value of the last call.
+func (b *PodGroupStatusApplyConfiguration) WithElastic(value int32) *PodGroupStatusApplyConfiguration {
+	b.Elastic = &value
+	return b
+}
@@ -0,0 +1,413 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkoverhead
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	networkawareutil "sigs.k8s.io/scheduler-plugins/pkg/networkaware/util"
+
+	agv1alpha1 "github.com/diktyo-io/appgroup-api/pkg/apis/appgroup/v1alpha1"
+)
+
+// bandwidthReservations : bandwidth reserved by pods on the links between topology domains (origin / destination)
+type bandwidthReservations struct {
+	sync.RWMutex
+
+	// bandwidth requested by each pod on each link
+	podRequests map[types.UID]map[networkawareutil.CostKey]int64
+
+	// bandwidth reserved on each link by all pods
+	reserved map[networkawareutil.CostKey]int64
+
+	// pods bound to nodes whose bandwidth is derived from the index rather than reserved by the plugin
+	boundPods map[types.UID]*boundPod
+}
+
+// boundPod : pod bound to a node whose bandwidth is derived from the index
+type boundPod struct {
+	pod *corev1.Pod
+
+	// selectors of the workloads the pod requests bandwidth towards
+	dependencies []string
+
+	// version of its AppGroup and of the pods of its dependencies in the index the bandwidth was derived at
+	version int64
+}
+
+// reserve : reserve the bandwidth requested by the given pod, replacing its previous reservation if any
+func (b *bandwidthReservations) reserve(uid types.UID, requests map[networkawareutil.CostKey]int64) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.releaseLocked(uid)
+	delete(b.boundPods, uid)
+	b.reserveLocked(uid, requests)
+}
+
+func (b *bandwidthReservations) reserveLocked(uid types.UID, requests map[networkawareutil.CostKey]int64) {
+	if len(requests) == 0 {
+		return
+	}
+	if b.podRequests == nil {
+		b.podRequests = make(map[types.UID]map[networkawareutil.CostKey]int64)
+		b.reserved = make(map[networkawareutil.CostKey]int64)
+	}
+	b.podRequests[uid] = requests
+	for link, bandwidth := range requests {
+		b.reserved[link] += bandwidth
+	}
+}
+
+// release : release the bandwidth reserved by the given pod
+func (b *bandwidthReservations) release(uid types.UID) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.releaseLocked(uid)
+	delete(b.boundPods, uid)
+}
+
+func (b *bandwidthReservations) releaseLocked(uid types.UID) {
+	for link, bandwidth := range b.podRequests[uid] {
+		b.reserved[link] -= bandwidth
+		if b.reserved[link] <= 0 {
+			delete(b.reserved, link)
+		}
+	}
+	delete(b.podRequests, uid)
+}
+
+// isReservedByPlugin : return whether the given pod holds a reservation made by the plugin
+func (b *bandwidthReservations) isReservedByPlugin(uid types.UID) bool {
+	b.RLock()
+	defer b.RUnlock()
+
+	_, reserved := b.podRequests[uid]
+	_, bound := b.boundPods[uid]
+	return reserved && !bound
+}
+
+// trackBoundPod : keep track of a bound pod, for its bandwidth to be derived again once stale
+func (b *bandwidthReservations) trackBoundPod(bp *boundPod) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.trackBoundPodLocked(bp)
+}
+
+// trackBoundPodLocked : keep track of a bound pod not tracked yet, and return whether it is the tracked one, i.e.,
+// it was neither replaced nor reserved by the plugin meanwhile
+func (b *bandwidthReservations) trackBoundPodLocked(bp *boundPod) bool {
+	if current, ok := b.boundPods[bp.pod.UID]; ok {
+		return current == bp
+	}
+	if _, reserved := b.podRequests[bp.pod.UID]; reserved {
+		return false
+	}
+	if b.boundPods == nil {
+		b.boundPods = make(map[types.UID]*boundPod)
+	}
+	b.boundPods[bp.pod.UID] = bp
+	return true
+}
+
+// reserveBoundPod : reserve the bandwidth derived for a bound pod towards the given workloads at the given version of
+// the index, replacing its previous reservation
+func (b *bandwidthReservations) reserveBoundPod(bp *boundPod, requests map[networkawareutil.CostKey]int64, dependencies []string, version int64) {
+	b.Lock()
+	defer b.Unlock()
+
+	if !b.trackBoundPodLocked(bp) {
+		return
+	}
+	b.releaseLocked(bp.pod.UID)
+	b.reserveLocked(bp.pod.UID, requests)
+	bp.dependencies = dependencies
+	bp.version = version
+}
+
+// getStaleBoundPods : return the bound pods of the given AppGroup whose AppGroup or dependencies changed in the index
+// since their bandwidth was derived
+func (b *bandwidthReservations) getStaleBoundPods(agName string, getVersion func(agName string, selectors []string) int64) []*boundPod {
+	b.RLock()
+	defer b.RUnlock()
+
+	var stale []*boundPod
+	for _, bp := range b.boundPods {
+		if networkawareutil.GetPodAppGroupLabel(bp.pod) != agName {
+			continue
+		}
+		if bp.version != getVersion(agName, bp.dependencies) {
+			stale = append(stale, bp)
+		}
+	}
+	return stale
+}
+
+// getReserved : return the bandwidth reserved on the given link
+func (b *bandwidthReservations) getReserved(link networkawareutil.CostKey) int64 {
+	b.RLock()
+	defer b.RUnlock()
+
+	return b.reserved[link]
+}
+
+// getLink : return the link between the topology domains of two nodes at the broadest level where they differ,
+// or false if both nodes belong to the same domains or the topology of the other node is unknown.
+// The domains of the link are identified by their paths, as domains of the same name under different parents are
+// connected by different links.
+func getLink(domains []string, otherDomains []string, topologyKeys []string) (networkawareutil.CostKey, bool) {
+	if networkawareutil.IsDomainUnknown(otherDomains) { // Node has no topology domain defined
+		return networkawareutil.CostKey{}, false
+	}
+	level := networkawareutil.GetDifferingLevel(domains, otherDomains)
+	if level < 0 { // Traffic between Nodes in the same domains does not cross any link
+		return networkawareutil.CostKey{}, false
+	}
+	return networkawareutil.GetDomainsKey(domains, otherDomains, level, topologyKeys), true
+}
+
+// getBandwidthRequests : calculate the bandwidth requested by a pod allocated on the given node.
+// The minBandwidth of each dependency is requested once on every link towards a topology domain hosting pods of the
+// dependency, since the traffic towards several replicas in the same domain shares the same link.
+func getBandwidthRequests(
+	dependencies []dependencyHosts,
+	nodeName string,
+	domains []string,
+	topologyKeys []string) map[networkawareutil.CostKey]int64 {
+	// Allocated lazily, most pods do not request bandwidth
+	var requests map[networkawareutil.CostKey]int64
+
+	for _, d := range dependencies { // For each pod dependency
+		bandwidth := d.dependency.MinBandwidth.Value()
+		if bandwidth <= 0 { // No minBandwidth requirement, continue
+			continue
+		}
+
+		links := make(map[networkawareutil.CostKey]bool)
+		for _, h := range d.hosts { // For each host of the pods already allocated
+			// If the pods run on the same node or their node was removed, their traffic does not cross any link.
+			if h.hostname == nodeName || h.domains == nil {
+				continue
+			}
+
+			if link, ok := getLink(domains, h.domains, topologyKeys); ok && !links[link] {
+				links[link] = true
+				if requests == nil {
+					requests = make(map[networkawareutil.CostKey]int64)
+				}
+				requests[link] += bandwidth
+			}
+		}
+	}
+	return requests
+}
+
+// hasBandwidthRequirements : return whether a workload of the AppGroup requests bandwidth towards a dependency
+func hasBandwidthRequirements(appGroup *agv1alpha1.AppGroup) bool {
+	for _, w := range appGroup.Spec.Workloads {
+		for _, d := range w.Dependencies {
+			if d.MinBandwidth.Value() > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkBandwidthCapacity : verify that the bandwidth requested by the pod on the given node fits in the capacity of
+// the links not yet reserved
+func (no *NetworkOverhead) checkBandwidthCapacity(preFilterState *PreFilterState, nodeName string) *framework.Status {
+	requests := preFilterState.bandwidthRequestMap[nodeName]
+
+	// Check links in order for a stable status message
+	links := make([]networkawareutil.CostKey, 0, len(requests))
+	for link := range requests {
+		links = append(links, link)
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].TopologyKey != links[j].TopologyKey {
+			return links[i].TopologyKey < links[j].TopologyKey
+		}
+		if links[i].Origin != links[j].Origin {
+			return links[i].Origin < links[j].Origin
+		}
+		return links[i].Destination < links[j].Destination
+	})
+
+	for _, link := range links {
+		capacity, ok := networkawareutil.FindCost(link, preFilterState.bandwidthCapacityMap)
+		if !ok { // Link not limited
+			continue
+		}
+		reserved := no.bandwidth.getReserved(link)
+		if reserved+requests[link] > capacity {
+			return framework.NewStatus(framework.Unschedulable,
+				fmt.Sprintf("Node %v would oversubscribe the bandwidth between %v and %v: Requested: %v Reserved: %v Capacity: %v",
+					nodeName, link.Origin, link.Destination, formatBandwidth(requests[link]), formatBandwidth(reserved), formatBandwidth(capacity)))
+		}
+	}
+	return nil
+}
+
+func formatBandwidth(bandwidth int64) string {
+	return resource.NewQuantity(bandwidth, resource.BinarySI).String()
+}
+
+// Reserve : reserve the bandwidth requested by the pod on the links of the selected node
+func (no *NetworkOverhead) Reserve(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeName string) *framework.Status {
+	preFilterState, err := getPreFilterState(state)
+	if err != nil {
+		// PreFilter was skipped since the pod does not belong to an AppGroup
+		return nil
+	}
+	if preFilterState.scoreEqually {
+		return nil
+	}
+
+	no.bandwidth.reserve(pod.UID, preFilterState.bandwidthRequestMap[nodeName])
+	return nil
+}
+
+// Unreserve : release the bandwidth reserved by the pod
+func (no *NetworkOverhead) Unreserve(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeName string) {
+	no.bandwidth.release(pod.UID)
+}
+
+// assignedAppGroupPod : return whether the pod is bound to a node and belongs to an AppGroup
+func assignedAppGroupPod(pod *corev1.Pod) bool {
+	return len(pod.Spec.NodeName) != 0 && len(networkawareutil.GetPodAppGroupLabel(pod)) != 0
+}
+
+// podEventHandler : handler keeping track of the bandwidth reserved by bound AppGroup pods
+func (no *NetworkOverhead) podEventHandler() cache.ResourceEventHandler {
+	return cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			switch t := obj.(type) {
+			case *corev1.Pod:
+				return assignedAppGroupPod(t)
+			case cache.DeletedFinalStateUnknown:
+				if pod, ok := t.Obj.(*corev1.Pod); ok {
+					return assignedAppGroupPod(pod)
+				}
+				return false
+			default:
+				return false
+			}
+		},
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    no.addPod,
+			UpdateFunc: no.updatePod,
+			DeleteFunc: no.deletePod,
+		},
+	}
+}
+
+// addPod : reserve the bandwidth of a bound pod that has not been reserved by the plugin,
+// e.g., pods bound before the scheduler started or by another scheduler
+func (no *NetworkOverhead) addPod(obj interface{}) {
+	pod := obj.(*corev1.Pod)
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return
+	}
+	if no.bandwidth.isReservedByPlugin(pod.UID) {
+		return
+	}
+	no.deriveBoundPodBandwidth(context.TODO(), &boundPod{pod: pod})
+}
+
+// refreshBoundPods : derive again the bandwidth of the bound pods of the given AppGroup whose AppGroup or the pods of
+// their dependencies changed since, e.g., pods listed before their AppGroup or their dependencies were indexed
+func (no *NetworkOverhead) refreshBoundPods(ctx context.Context, agName string) {
+	for _, bp := range no.bandwidth.getStaleBoundPods(agName, no.index.getBandwidthVersion) {
+		no.deriveBoundPodBandwidth(ctx, bp)
+	}
+}
+
+// deriveBoundPodBandwidth : reserve the bandwidth of a bound pod derived from the current index
+func (no *NetworkOverhead) deriveBoundPodBandwidth(ctx context.Context, bp *boundPod) {
+	// Get the version first, so that changes of the index while deriving the bandwidth leave the pod stale
+	appGroup, dependencies, version := no.index.getBandwidthDependencies(bp.pod)
+	requests, err := no.getBoundPodBandwidthRequests(ctx, bp.pod, appGroup)
+	if err != nil {
+		no.logger.Error(err, "Failed to get the bandwidth requested by pod", "pod", klog.KObj(bp.pod))
+		// Retry once the pod is stale
+		no.bandwidth.trackBoundPod(bp)
+		return
+	}
+	no.bandwidth.reserveBoundPod(bp, requests, dependencies, version)
+}
+
+// updatePod : release the bandwidth of pods that terminated
+func (no *NetworkOverhead) updatePod(oldObj, newObj interface{}) {
+	newPod := newObj.(*corev1.Pod)
+	if newPod.Status.Phase == corev1.PodSucceeded || newPod.Status.Phase == corev1.PodFailed {
+		no.bandwidth.release(newPod.UID)
+	}
+}
+
+// deletePod : release the bandwidth of deleted pods
+func (no *NetworkOverhead) deletePod(obj interface{}) {
+	var pod *corev1.Pod
+	switch t := obj.(type) {
+	case *corev1.Pod:
+		pod = t
+	case cache.DeletedFinalStateUnknown:
+		pod = t.Obj.(*corev1.Pod)
+	}
+	no.bandwidth.release(pod.UID)
+}
+
+// getBoundPodBandwidthRequests : calculate the bandwidth requested by a pod already bound to its node
+func (no *NetworkOverhead) getBoundPodBandwidthRequests(ctx context.Context, pod *corev1.Pod, appGroup *agv1alpha1.AppGroup) (map[networkawareutil.CostKey]int64, error) {
+	if appGroup == nil {
+		return nil, nil
+	}
+
+	dependencyList := networkawareutil.GetDependencyList(pod, appGroup)
+	if dependencyList == nil {
+		return nil, nil
+	}
+
+	node, err := no.nodeLister.Get(pod.Spec.NodeName)
+	if err != nil {
+		return nil, err
+	}
+	dependencyHosts, err := no.getDependencyHosts(dependencyList, no.index.getWorkloadHosts(appGroup.Name), func(name string) (*corev1.Node, error) {
+		node, err := no.nodeLister.Get(name)
+		if apierrors.IsNotFound(err) { // The node of the dependency was removed, its traffic does not cross any link
+			return nil, nil
+		}
+		return node, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return getBandwidthRequests(dependencyHosts, node.Name, networkawareutil.GetNodeDomains(node, no.topologyKeys), no.topologyKeys), nil
+}
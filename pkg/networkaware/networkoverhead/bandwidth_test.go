@@ -0,0 +1,305 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkoverhead
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+
+	networkawareutil "sigs.k8s.io/scheduler-plugins/pkg/networkaware/util"
+
+	agv1alpha1 "github.com/diktyo-io/appgroup-api/pkg/apis/appgroup/v1alpha1"
+	ntv1alpha1 "github.com/diktyo-io/networktopology-api/pkg/apis/networktopology/v1alpha1"
+)
+
+func GetAppGroupCRBandwidth() *agv1alpha1.AppGroup {
+	// Return AppGroup CRD (basic version with bandwidth requirements): basic
+	appGroup := GetAppGroupCRBasic()
+	for i := range appGroup.Spec.Workloads {
+		for j := range appGroup.Spec.Workloads[i].Dependencies {
+			appGroup.Spec.Workloads[i].Dependencies[j].MinBandwidth = resource.MustParse("1Gi")
+			appGroup.Spec.Workloads[i].Dependencies[j].MaxNetworkCost = 100
+		}
+	}
+	return appGroup
+}
+
+func GetNetworkTopologyCRBandwidth() *ntv1alpha1.NetworkTopology {
+	// Return NetworkTopology CR (basic version with bandwidth capacities): nt-test
+	networkTopology := GetNetworkTopologyCRBasic()
+	for _, t := range networkTopology.Spec.Weights[0].TopologyList {
+		for _, o := range t.OriginList {
+			for i := range o.CostList {
+				if t.TopologyKey == ntv1alpha1.NetworkTopologyRegion {
+					o.CostList[i].BandwidthCapacity = resource.MustParse("2Gi")
+				} else if o.Origin == "Z4" {
+					o.CostList[i].BandwidthCapacity = resource.MustParse("1Gi")
+				}
+			}
+		}
+	}
+	return networkTopology
+}
+
+func TestNetworkOverheadFilterBandwidth(t *testing.T) {
+	// Create Pods
+	pods := []*v1.Pod{
+		makePodAllocated("p2", "p2-deployment", "n-5", 0, "basic", nil, nil),
+		makePodAllocated("p3", "p3-deployment", "n-8", 0, "basic", nil, nil),
+	}
+
+	// Create Nodes
+	nodes := []*v1.Node{
+		st.MakeNode().Name("n-1").Label(v1.LabelTopologyRegion, "us-west-1").Label(v1.LabelTopologyZone, "Z1").Obj(),
+		st.MakeNode().Name("n-5").Label(v1.LabelTopologyRegion, "us-east-1").Label(v1.LabelTopologyZone, "Z3").Obj(),
+		st.MakeNode().Name("n-6").Label(v1.LabelTopologyRegion, "us-east-1").Label(v1.LabelTopologyZone, "Z3").Obj(),
+		st.MakeNode().Name("n-7").Label(v1.LabelTopologyRegion, "us-east-1").Label(v1.LabelTopologyZone, "Z4").Obj(),
+		st.MakeNode().Name("n-8").Label(v1.LabelTopologyRegion, "us-east-1").Label(v1.LabelTopologyZone, "Z4").Obj(),
+	}
+
+	regionLink := networkawareutil.CostKey{TopologyKey: v1.LabelTopologyRegion, Origin: "us-west-1", Destination: "us-east-1"}
+	zoneLink := networkawareutil.CostKey{TopologyKey: v1.LabelTopologyZone, Origin: "us-east-1/Z4", Destination: "us-east-1/Z3"}
+	// Zones of the same names in another region are connected by another link
+	otherZoneLink := networkawareutil.CostKey{TopologyKey: v1.LabelTopologyZone, Origin: "us-west-1/Z4", Destination: "us-west-1/Z3"}
+
+	tests := []struct {
+		name         string
+		pod          *v1.Pod
+		reserved     map[networkawareutil.CostKey]int64
+		nodeToFilter *v1.Node
+		wantStatus   *framework.Status
+	}{
+		{
+			name:         "p1 to allocate, n-1 to filter: region link has enough bandwidth",
+			pod:          makePod("p1", "p1-deployment", 0, "basic", nil, nil),
+			reserved:     map[networkawareutil.CostKey]int64{regionLink: 1 << 30},
+			nodeToFilter: nodes[0],
+			wantStatus:   nil,
+		},
+		{
+			name:         "p1 to allocate, n-1 to filter: region link would be oversubscribed",
+			pod:          makePod("p1", "p1-deployment", 0, "basic", nil, nil),
+			reserved:     map[networkawareutil.CostKey]int64{regionLink: 3 << 29},
+			nodeToFilter: nodes[0],
+			wantStatus: framework.NewStatus(framework.Unschedulable,
+				"Node n-1 would oversubscribe the bandwidth between us-west-1 and us-east-1: Requested: 1Gi Reserved: 1536Mi Capacity: 2Gi"),
+		},
+		{
+			name:         "p1 to allocate, n-6 to filter: same zone as p2, no link crossed",
+			pod:          makePod("p1", "p1-deployment", 0, "basic", nil, nil),
+			reserved:     map[networkawareutil.CostKey]int64{regionLink: 2 << 30, zoneLink: 1 << 30},
+			nodeToFilter: nodes[2],
+			wantStatus:   nil,
+		},
+		{
+			name:         "p1 to allocate, n-7 to filter: zone link would be oversubscribed",
+			pod:          makePod("p1", "p1-deployment", 0, "basic", nil, nil),
+			reserved:     map[networkawareutil.CostKey]int64{zoneLink: 1 << 29},
+			nodeToFilter: nodes[3],
+			wantStatus: framework.NewStatus(framework.Unschedulable,
+				"Node n-7 would oversubscribe the bandwidth between us-east-1/Z4 and us-east-1/Z3: Requested: 1Gi Reserved: 512Mi Capacity: 1Gi"),
+		},
+		{
+			name:         "p1 to allocate, n-7 to filter: zone link of the same names in another region is not crossed",
+			pod:          makePod("p1", "p1-deployment", 0, "basic", nil, nil),
+			reserved:     map[networkawareutil.CostKey]int64{otherZoneLink: 1 << 30},
+			nodeToFilter: nodes[3],
+			wantStatus:   nil,
+		},
+		{
+			name:         "p2 to allocate, n-1 to filter: region link not limited by reservations of other links",
+			pod:          makePod("p2", "p2-deployment", 0, "basic", nil, nil),
+			reserved:     map[networkawareutil.CostKey]int64{zoneLink: 1 << 30},
+			nodeToFilter: nodes[0],
+			wantStatus:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pl := newTestPlugin(t, GetAppGroupCRBandwidth(), GetNetworkTopologyCRBandwidth(), pods, nodes)
+
+			for link, bandwidth := range tt.reserved {
+				pl.bandwidth.reserve(types.UID(link.Origin+link.Destination), map[networkawareutil.CostKey]int64{link: bandwidth})
+			}
+
+			state := framework.NewCycleState()
+			if _, got := pl.PreFilter(context.TODO(), state, tt.pod); !got.IsSuccess() {
+				t.Fatalf("expected success, got %v : %v", got.Code(), got.Message())
+			}
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(tt.nodeToFilter)
+			gotStatus := pl.Filter(context.Background(), state, tt.pod, nodeInfo)
+
+			if !reflect.DeepEqual(gotStatus, tt.wantStatus) {
+				t.Errorf("status does not match: %v, want: %v", gotStatus, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestNetworkOverheadReserveBandwidth(t *testing.T) {
+	pods := []*v1.Pod{
+		makePodAllocated("p2", "p2-deployment", "n-5", 0, "basic", nil, nil),
+	}
+	nodes := []*v1.Node{
+		st.MakeNode().Name("n-1").Label(v1.LabelTopologyRegion, "us-west-1").Label(v1.LabelTopologyZone, "Z1").Obj(),
+		st.MakeNode().Name("n-5").Label(v1.LabelTopologyRegion, "us-east-1").Label(v1.LabelTopologyZone, "Z3").Obj(),
+	}
+	regionLink := networkawareutil.CostKey{TopologyKey: v1.LabelTopologyRegion, Origin: "us-west-1", Destination: "us-east-1"}
+
+	pl := newTestPlugin(t, GetAppGroupCRBandwidth(), GetNetworkTopologyCRBandwidth(), pods, nodes)
+
+	// Two replicas of p1 fill up the 2Gi capacity of the region link
+	for i, uid := range []types.UID{"p1-a", "p1-b"} {
+		pod := makePod("p1", "p1-deployment", 0, "basic", nil, nil)
+		pod.UID = uid
+
+		state := framework.NewCycleState()
+		if _, got := pl.PreFilter(context.TODO(), state, pod); !got.IsSuccess() {
+			t.Fatalf("expected success, got %v : %v", got.Code(), got.Message())
+		}
+		nodeInfo := framework.NewNodeInfo()
+		nodeInfo.SetNode(nodes[0])
+		if got := pl.Filter(context.Background(), state, pod, nodeInfo); !got.IsSuccess() {
+			t.Fatalf("replica %d: expected n-1 to fit, got %v", i, got.Message())
+		}
+		if got := pl.Reserve(context.TODO(), state, pod, "n-1"); !got.IsSuccess() {
+			t.Fatalf("replica %d: expected reserve to succeed, got %v", i, got.Message())
+		}
+	}
+	if got := pl.bandwidth.getReserved(regionLink); got != 2<<30 {
+		t.Errorf("expected 2Gi reserved on the region link, got %v", got)
+	}
+
+	// A third replica does not fit anymore
+	pod := makePod("p1", "p1-deployment", 0, "basic", nil, nil)
+	pod.UID = "p1-c"
+	state := framework.NewCycleState()
+	if _, got := pl.PreFilter(context.TODO(), state, pod); !got.IsSuccess() {
+		t.Fatalf("expected success, got %v : %v", got.Code(), got.Message())
+	}
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(nodes[0])
+	if got := pl.Filter(context.Background(), state, pod, nodeInfo); got.Code() != framework.Unschedulable {
+		t.Errorf("expected n-1 to be filtered out, got %v", got)
+	}
+
+	// Unreserving one replica releases its bandwidth
+	pl.Unreserve(context.TODO(), state, &v1.Pod{ObjectMeta: metav1.ObjectMeta{UID: "p1-a"}}, "n-1")
+	if got := pl.bandwidth.getReserved(regionLink); got != 1<<30 {
+		t.Errorf("expected 1Gi reserved on the region link, got %v", got)
+	}
+	if got := pl.Filter(context.Background(), state, pod, nodeInfo); !got.IsSuccess() {
+		t.Errorf("expected n-1 to fit, got %v", got.Message())
+	}
+}
+
+func TestNetworkOverheadBoundPodBandwidth(t *testing.T) {
+	pods := []*v1.Pod{
+		makePodAllocated("p2", "p2-deployment", "n-5", 0, "basic", nil, nil),
+	}
+	nodes := []*v1.Node{
+		st.MakeNode().Name("n-1").Label(v1.LabelTopologyRegion, "us-west-1").Label(v1.LabelTopologyZone, "Z1").Obj(),
+		st.MakeNode().Name("n-5").Label(v1.LabelTopologyRegion, "us-east-1").Label(v1.LabelTopologyZone, "Z3").Obj(),
+	}
+	regionLink := networkawareutil.CostKey{TopologyKey: v1.LabelTopologyRegion, Origin: "us-west-1", Destination: "us-east-1"}
+
+	pl := newTestPlugin(t, GetAppGroupCRBandwidth(), GetNetworkTopologyCRBandwidth(), pods, nodes)
+
+	// A pod bound by another scheduler reserves its bandwidth
+	bound := makePodAllocated("p1", "p1-deployment", "n-1", 0, "basic", nil, nil)
+	bound.UID = "p1-bound"
+	pl.addPod(bound)
+	if got := pl.bandwidth.getReserved(regionLink); got != 1<<30 {
+		t.Errorf("expected 1Gi reserved on the region link, got %v", got)
+	}
+
+	// The bandwidth is released once the pod terminates
+	terminated := bound.DeepCopy()
+	terminated.Status.Phase = v1.PodSucceeded
+	pl.updatePod(bound, terminated)
+	if got := pl.bandwidth.getReserved(regionLink); got != 0 {
+		t.Errorf("expected no bandwidth reserved on the region link, got %v", got)
+	}
+
+	// The bandwidth is released once the pod is deleted
+	pl.addPod(bound)
+	pl.deletePod(bound)
+	if got := pl.bandwidth.getReserved(regionLink); got != 0 {
+		t.Errorf("expected no bandwidth reserved on the region link, got %v", got)
+	}
+}
+
+func TestNetworkOverheadBoundPodBandwidthRefresh(t *testing.T) {
+	nodes := []*v1.Node{
+		st.MakeNode().Name("n-1").Label(v1.LabelTopologyRegion, "us-west-1").Label(v1.LabelTopologyZone, "Z1").Obj(),
+		st.MakeNode().Name("n-5").Label(v1.LabelTopologyRegion, "us-east-1").Label(v1.LabelTopologyZone, "Z3").Obj(),
+	}
+	regionLink := networkawareutil.CostKey{TopologyKey: v1.LabelTopologyRegion, Origin: "us-west-1", Destination: "us-east-1"}
+	appGroup := GetAppGroupCRBandwidth()
+
+	pl := newTestPlugin(t, appGroup, GetNetworkTopologyCRBandwidth(), nil, nodes)
+	pl.index.deleteAppGroup(appGroup)
+
+	// Pods listed before their AppGroup and their dependency do not request any bandwidth yet
+	bound := makePodAllocated("p1", "p1-deployment", "n-1", 0, "basic", nil, nil)
+	bound.UID = "p1-bound"
+	pl.index.addPod(bound)
+	pl.addPod(bound)
+	if got := pl.bandwidth.getReserved(regionLink); got != 0 {
+		t.Errorf("expected no bandwidth reserved on the region link, got %v", got)
+	}
+	pl.index.addAppGroup(appGroup)
+	pl.refreshBoundPods(context.TODO(), "basic")
+	if got := pl.bandwidth.getReserved(regionLink); got != 0 {
+		t.Errorf("expected no bandwidth reserved on the region link, got %v", got)
+	}
+
+	// Their bandwidth is derived again at PreFilter once the index changed
+	pl.index.addPod(makePodAllocated("p2", "p2-deployment", "n-5", 0, "basic", nil, nil))
+	pod := makePod("p1", "p1-deployment", 0, "basic", nil, nil)
+	if _, got := pl.PreFilter(context.TODO(), framework.NewCycleState(), pod); !got.IsSuccess() {
+		t.Fatalf("expected success, got %v : %v", got.Code(), got.Message())
+	}
+	if got := pl.bandwidth.getReserved(regionLink); got != 1<<30 {
+		t.Errorf("expected 1Gi reserved on the region link, got %v", got)
+	}
+
+	// Pods bound to workloads they do not depend on leave them up to date
+	pl.index.addPod(makePodAllocated("p1-2", "p1-deployment", "n-5", 0, "basic", nil, nil))
+	pl.index.addPod(makePodAllocated("p3", "p3-deployment", "n-5", 0, "basic", nil, nil))
+	if got := pl.bandwidth.getStaleBoundPods("basic", pl.index.getBandwidthVersion); len(got) != 0 {
+		t.Errorf("expected no stale bound pods, got %v", len(got))
+	}
+
+	// Released pods are not reserved again
+	pl.deletePod(bound)
+	pl.index.deletePod(bound)
+	pl.refreshBoundPods(context.TODO(), "basic")
+	if got := pl.bandwidth.getReserved(regionLink); got != 0 {
+		t.Errorf("expected no bandwidth reserved on the region link, got %v", got)
+	}
+}
@@ -0,0 +1,505 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkoverhead
+
+import (
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	networkawareutil "sigs.k8s.io/scheduler-plugins/pkg/networkaware/util"
+
+	agv1alpha1 "github.com/diktyo-io/appgroup-api/pkg/apis/appgroup/v1alpha1"
+	ntv1alpha1 "github.com/diktyo-io/networktopology-api/pkg/apis/networktopology/v1alpha1"
+)
+
+// networkIndex : index of the AppGroups, the network costs of the NetworkTopology and the AppGroup pods bound to
+// nodes, updated incrementally from informer events so that PreFilter neither fetches the CRs, sorts the costs nor
+// lists the pods. Every change of the index increases its version.
+type networkIndex struct {
+	sync.RWMutex
+
+	// namespaces the AppGroups and the NetworkTopology are looked up in, in order
+	namespaces []string
+
+	// name of the NetworkTopology CR
+	ntName string
+
+	// name of the weights the costs are read from
+	weightsName string
+
+	// topology keys with network costs, from the broadest to the narrowest level
+	topologyKeys []string
+
+	// version of the index, increased on every change
+	version int64
+
+	// AppGroups by namespace and name
+	appGroups map[string]map[string]*agv1alpha1.AppGroup
+
+	// version of the index at the last change of the AppGroups by name
+	appGroupVersions map[string]int64
+
+	// NetworkTopologies named ntName by namespace
+	networkTopologies map[string]*ntv1alpha1.NetworkTopology
+
+	// costs of the NetworkTopology found first in the namespaces
+	costs *networkCosts
+
+	// pods bound to nodes by AppGroup name
+	pods map[string]*appGroupPods
+}
+
+// networkCosts : network costs and bandwidth capacities between topology domains (origin / destination) defined in
+// a NetworkTopology CR for the preferred weights. It is never modified once built.
+type networkCosts struct {
+	// NetworkTopology CR the costs are built from
+	networkTopology *ntv1alpha1.NetworkTopology
+
+	// costs between domains of every topology level
+	costMap map[networkawareutil.CostKey]int64
+
+	// bandwidth capacity of the links limited in the NetworkTopology CR
+	bandwidthCapacityMap map[networkawareutil.CostKey]int64
+}
+
+// appGroupPods : pods of an AppGroup bound to nodes
+type appGroupPods struct {
+	// version of the index at the last change of the pods
+	version int64
+
+	// version of the index at the last change of the pods by workload selector
+	workloadVersions map[string]int64
+
+	// hostname of the pods by workload selector and namespaced name
+	pods map[string]map[types.NamespacedName]string
+
+	// number of pods on each host by workload selector, built at snapshotVersion and never modified
+	snapshot        map[string]map[string]int
+	snapshotVersion int64
+}
+
+// newNetworkIndex : create an empty index
+func newNetworkIndex(namespaces []string, ntName string, weightsName string, topologyKeys []string) *networkIndex {
+	return &networkIndex{
+		namespaces:        namespaces,
+		ntName:            ntName,
+		weightsName:       weightsName,
+		topologyKeys:      topologyKeys,
+		appGroups:         make(map[string]map[string]*agv1alpha1.AppGroup),
+		appGroupVersions:  make(map[string]int64),
+		networkTopologies: make(map[string]*ntv1alpha1.NetworkTopology),
+		costs:             &networkCosts{},
+		pods:              make(map[string]*appGroupPods),
+	}
+}
+
+// getVersion : return the version of the index
+func (idx *networkIndex) getVersion() int64 {
+	idx.RLock()
+	defer idx.RUnlock()
+
+	return idx.version
+}
+
+// getAppGroup : return the AppGroup of the given name found first in the namespaces, or nil
+func (idx *networkIndex) getAppGroup(agName string) *agv1alpha1.AppGroup {
+	idx.RLock()
+	defer idx.RUnlock()
+
+	// AppGroup could not be placed in several namespaces simultaneously
+	for _, namespace := range idx.namespaces {
+		if appGroup, ok := idx.appGroups[namespace][agName]; ok {
+			return appGroup
+		}
+	}
+	return nil
+}
+
+// getBandwidthDependencies : return the AppGroup of the given pod, the selectors of the workloads the pod requests
+// bandwidth towards and the version of the index at the last change of the AppGroup or of the pods of these
+// workloads. The version is 0 if neither was ever indexed.
+func (idx *networkIndex) getBandwidthDependencies(pod *corev1.Pod) (*agv1alpha1.AppGroup, []string, int64) {
+	idx.RLock()
+	defer idx.RUnlock()
+
+	agName := networkawareutil.GetPodAppGroupLabel(pod)
+	version := idx.appGroupVersions[agName]
+
+	var appGroup *agv1alpha1.AppGroup
+	// AppGroup could not be placed in several namespaces simultaneously
+	for _, namespace := range idx.namespaces {
+		if ag, ok := idx.appGroups[namespace][agName]; ok {
+			appGroup = ag
+			break
+		}
+	}
+	if appGroup == nil {
+		return nil, nil, version
+	}
+
+	var selectors []string
+	for _, d := range networkawareutil.GetDependencyList(pod, appGroup) {
+		if d.MinBandwidth.Value() > 0 {
+			selectors = append(selectors, d.Workload.Selector)
+		}
+	}
+	return appGroup, selectors, idx.getWorkloadsVersionLocked(agName, selectors, version)
+}
+
+// getBandwidthVersion : return the version of the index at the last change of the AppGroup of the given name or of
+// the pods of the given workloads, 0 if none was ever indexed
+func (idx *networkIndex) getBandwidthVersion(agName string, selectors []string) int64 {
+	idx.RLock()
+	defer idx.RUnlock()
+
+	return idx.getWorkloadsVersionLocked(agName, selectors, idx.appGroupVersions[agName])
+}
+
+func (idx *networkIndex) getWorkloadsVersionLocked(agName string, selectors []string, version int64) int64 {
+	if p, ok := idx.pods[agName]; ok {
+		for _, selector := range selectors {
+			version = max(version, p.workloadVersions[selector])
+		}
+	}
+	return version
+}
+
+// getCosts : return the network costs of the NetworkTopology. Costs are empty if the NetworkTopology is not found.
+func (idx *networkIndex) getCosts() *networkCosts {
+	idx.RLock()
+	defer idx.RUnlock()
+
+	return idx.costs
+}
+
+// getWorkloadHosts : return the number of pods of the AppGroup on each host by workload selector.
+// The returned map must not be modified.
+func (idx *networkIndex) getWorkloadHosts(agName string) map[string]map[string]int {
+	idx.Lock()
+	defer idx.Unlock()
+
+	p, ok := idx.pods[agName]
+	if !ok {
+		return nil
+	}
+	if p.snapshot == nil || p.snapshotVersion != p.version {
+		p.snapshot = make(map[string]map[string]int, len(p.pods))
+		for selector, pods := range p.pods {
+			hosts := make(map[string]int)
+			for _, hostname := range pods {
+				hosts[hostname]++
+			}
+			p.snapshot[selector] = hosts
+		}
+		p.snapshotVersion = p.version
+	}
+	return p.snapshot
+}
+
+// rebuildCostsLocked : rebuild the network costs from the NetworkTopology found first in the namespaces
+func (idx *networkIndex) rebuildCostsLocked() {
+	// NetworkTopology could not be placed in several namespaces simultaneously
+	for _, namespace := range idx.namespaces {
+		if networkTopology, ok := idx.networkTopologies[namespace]; ok {
+			idx.costs = buildNetworkCosts(networkTopology, idx.weightsName, idx.topologyKeys)
+			return
+		}
+	}
+	idx.costs = &networkCosts{}
+}
+
+// buildNetworkCosts : index the costs and bandwidth capacities of the given weights of the NetworkTopology.
+// Costs of the topology keys not considered by the plugin are ignored.
+func buildNetworkCosts(networkTopology *ntv1alpha1.NetworkTopology, weightsName string, topologyKeys []string) *networkCosts {
+	costs := &networkCosts{
+		networkTopology:      networkTopology,
+		costMap:              make(map[networkawareutil.CostKey]int64),
+		bandwidthCapacityMap: make(map[networkawareutil.CostKey]int64),
+	}
+	for _, w := range networkTopology.Spec.Weights { // Check the weights List
+		if w.Name != weightsName { // If it is not the Preferred algorithm, continue
+			continue
+		}
+
+		for _, key := range topologyKeys { // Add Costs of each topology level (e.g., region, zone)
+			for _, t := range w.TopologyList {
+				if string(t.TopologyKey) != key {
+					continue
+				}
+				for _, o := range t.OriginList {
+					for _, c := range o.CostList {
+						costs.costMap[networkawareutil.CostKey{TopologyKey: key, Origin: o.Origin, Destination: c.Destination}] = c.NetworkCost
+					}
+				}
+			}
+		}
+
+		for _, t := range w.TopologyList {
+			for _, o := range t.OriginList {
+				for _, c := range o.CostList {
+					if capacity := c.BandwidthCapacity.Value(); capacity > 0 {
+						costs.bandwidthCapacityMap[networkawareutil.CostKey{TopologyKey: string(t.TopologyKey), Origin: o.Origin, Destination: c.Destination}] = capacity
+					}
+				}
+			}
+		}
+	}
+	return costs
+}
+
+// addAppGroup : index an added or updated AppGroup
+func (idx *networkIndex) addAppGroup(obj interface{}) {
+	appGroup := obj.(*agv1alpha1.AppGroup)
+
+	idx.Lock()
+	defer idx.Unlock()
+
+	if idx.appGroups[appGroup.Namespace] == nil {
+		idx.appGroups[appGroup.Namespace] = make(map[string]*agv1alpha1.AppGroup)
+	}
+	idx.appGroups[appGroup.Namespace][appGroup.Name] = appGroup
+	idx.version++
+	idx.appGroupVersions[appGroup.Name] = idx.version
+}
+
+// updateAppGroup : index an updated AppGroup
+func (idx *networkIndex) updateAppGroup(oldObj, newObj interface{}) {
+	idx.addAppGroup(newObj)
+}
+
+// deleteAppGroup : remove a deleted AppGroup from the index
+func (idx *networkIndex) deleteAppGroup(obj interface{}) {
+	var appGroup *agv1alpha1.AppGroup
+	switch t := obj.(type) {
+	case *agv1alpha1.AppGroup:
+		appGroup = t
+	case cache.DeletedFinalStateUnknown:
+		appGroup = t.Obj.(*agv1alpha1.AppGroup)
+	}
+
+	idx.Lock()
+	defer idx.Unlock()
+
+	delete(idx.appGroups[appGroup.Namespace], appGroup.Name)
+	idx.version++
+	idx.appGroupVersions[appGroup.Name] = idx.version
+}
+
+// addNetworkTopology : index an added or updated NetworkTopology
+func (idx *networkIndex) addNetworkTopology(obj interface{}) {
+	networkTopology := obj.(*ntv1alpha1.NetworkTopology)
+	if networkTopology.Name != idx.ntName {
+		return
+	}
+
+	idx.Lock()
+	defer idx.Unlock()
+
+	idx.networkTopologies[networkTopology.Namespace] = networkTopology
+	idx.rebuildCostsLocked()
+	idx.version++
+}
+
+// updateNetworkTopology : index an updated NetworkTopology
+func (idx *networkIndex) updateNetworkTopology(oldObj, newObj interface{}) {
+	idx.addNetworkTopology(newObj)
+}
+
+// deleteNetworkTopology : remove a deleted NetworkTopology from the index
+func (idx *networkIndex) deleteNetworkTopology(obj interface{}) {
+	var networkTopology *ntv1alpha1.NetworkTopology
+	switch t := obj.(type) {
+	case *ntv1alpha1.NetworkTopology:
+		networkTopology = t
+	case cache.DeletedFinalStateUnknown:
+		networkTopology = t.Obj.(*ntv1alpha1.NetworkTopology)
+	}
+	if networkTopology.Name != idx.ntName {
+		return
+	}
+
+	idx.Lock()
+	defer idx.Unlock()
+
+	delete(idx.networkTopologies, networkTopology.Namespace)
+	idx.rebuildCostsLocked()
+	idx.version++
+}
+
+// addPod : index a pod bound to a node
+func (idx *networkIndex) addPod(obj interface{}) {
+	pod := obj.(*corev1.Pod)
+	if !assignedAppGroupPod(pod) {
+		return
+	}
+
+	idx.Lock()
+	defer idx.Unlock()
+
+	agName := networkawareutil.GetPodAppGroupLabel(pod)
+	selector := networkawareutil.GetPodAppGroupSelector(pod)
+	p, ok := idx.pods[agName]
+	if !ok {
+		p = &appGroupPods{
+			pods:             make(map[string]map[types.NamespacedName]string),
+			workloadVersions: make(map[string]int64),
+		}
+		idx.pods[agName] = p
+	}
+	if p.pods[selector] == nil {
+		p.pods[selector] = make(map[types.NamespacedName]string)
+	}
+	key := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+	if hostname, ok := p.pods[selector][key]; ok && hostname == pod.Spec.NodeName {
+		return
+	}
+	p.pods[selector][key] = pod.Spec.NodeName
+	idx.version++
+	p.version = idx.version
+	p.workloadVersions[selector] = idx.version
+}
+
+// updatePod : re-index a pod whose AppGroup, workload or node changed
+func (idx *networkIndex) updatePod(oldObj, newObj interface{}) {
+	oldPod := oldObj.(*corev1.Pod)
+	newPod := newObj.(*corev1.Pod)
+	if networkawareutil.GetPodAppGroupLabel(oldPod) == networkawareutil.GetPodAppGroupLabel(newPod) &&
+		networkawareutil.GetPodAppGroupSelector(oldPod) == networkawareutil.GetPodAppGroupSelector(newPod) &&
+		oldPod.Spec.NodeName == newPod.Spec.NodeName {
+		return
+	}
+	idx.deletePod(oldPod)
+	idx.addPod(newPod)
+}
+
+// deletePod : remove a deleted pod from the index
+func (idx *networkIndex) deletePod(obj interface{}) {
+	var pod *corev1.Pod
+	switch t := obj.(type) {
+	case *corev1.Pod:
+		pod = t
+	case cache.DeletedFinalStateUnknown:
+		pod = t.Obj.(*corev1.Pod)
+	}
+
+	idx.Lock()
+	defer idx.Unlock()
+
+	agName := networkawareutil.GetPodAppGroupLabel(pod)
+	selector := networkawareutil.GetPodAppGroupSelector(pod)
+	p, ok := idx.pods[agName]
+	if !ok {
+		return
+	}
+	key := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+	if _, ok := p.pods[selector][key]; !ok {
+		return
+	}
+	delete(p.pods[selector], key)
+	if len(p.pods[selector]) == 0 {
+		delete(p.pods, selector)
+	}
+	idx.version++
+	p.version = idx.version
+	p.workloadVersions[selector] = idx.version
+	if len(p.pods) == 0 {
+		delete(idx.pods, agName)
+		// keep the version of its workloads increasing once the AppGroup has no pods
+		idx.appGroupVersions[agName] = idx.version
+	}
+}
+
+// appGroupEventHandler : handler keeping the AppGroups of the index up to date
+func (idx *networkIndex) appGroupEventHandler() cache.ResourceEventHandler {
+	return cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			switch t := obj.(type) {
+			case *agv1alpha1.AppGroup:
+				return true
+			case cache.DeletedFinalStateUnknown:
+				if _, ok := t.Obj.(*agv1alpha1.AppGroup); ok {
+					return true
+				}
+				utilruntime.HandleError(fmt.Errorf("cannot convert to *v1alpha1.AppGroup: %v", obj))
+				return false
+			default:
+				utilruntime.HandleError(fmt.Errorf("unable to handle object in %T", obj))
+				return false
+			}
+		},
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    idx.addAppGroup,
+			UpdateFunc: idx.updateAppGroup,
+			DeleteFunc: idx.deleteAppGroup,
+		},
+	}
+}
+
+// networkTopologyEventHandler : handler keeping the network costs of the index up to date
+func (idx *networkIndex) networkTopologyEventHandler() cache.ResourceEventHandler {
+	return cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			switch t := obj.(type) {
+			case *ntv1alpha1.NetworkTopology:
+				return true
+			case cache.DeletedFinalStateUnknown:
+				if _, ok := t.Obj.(*ntv1alpha1.NetworkTopology); ok {
+					return true
+				}
+				utilruntime.HandleError(fmt.Errorf("cannot convert to *v1alpha1.NetworkTopology: %v", obj))
+				return false
+			default:
+				utilruntime.HandleError(fmt.Errorf("unable to handle object in %T", obj))
+				return false
+			}
+		},
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    idx.addNetworkTopology,
+			UpdateFunc: idx.updateNetworkTopology,
+			DeleteFunc: idx.deleteNetworkTopology,
+		},
+	}
+}
+
+// podEventHandler : handler keeping the AppGroup pods bound to nodes of the index up to date
+func (idx *networkIndex) podEventHandler() cache.ResourceEventHandler {
+	return cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			switch t := obj.(type) {
+			case *corev1.Pod:
+				return assignedAppGroupPod(t)
+			case cache.DeletedFinalStateUnknown:
+				if pod, ok := t.Obj.(*corev1.Pod); ok {
+					return assignedAppGroupPod(pod)
+				}
+				return false
+			default:
+				return false
+			}
+		},
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    idx.addPod,
+			UpdateFunc: idx.updatePod,
+			DeleteFunc: idx.deletePod,
+		},
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkoverhead
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	networkawareutil "sigs.k8s.io/scheduler-plugins/pkg/networkaware/util"
+
+	agv1alpha1 "github.com/diktyo-io/appgroup-api/pkg/apis/appgroup/v1alpha1"
+	ntv1alpha1 "github.com/diktyo-io/networktopology-api/pkg/apis/networktopology/v1alpha1"
+)
+
+func makeIndexNetworkTopology(namespace string, name string, cost int64) *ntv1alpha1.NetworkTopology {
+	return &ntv1alpha1.NetworkTopology{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: ntv1alpha1.NetworkTopologySpec{
+			Weights: ntv1alpha1.WeightList{
+				ntv1alpha1.WeightInfo{Name: "UserDefined",
+					TopologyList: ntv1alpha1.TopologyList{
+						ntv1alpha1.TopologyInfo{
+							TopologyKey: ntv1alpha1.NetworkTopologyZone,
+							OriginList: ntv1alpha1.OriginList{
+								ntv1alpha1.OriginInfo{Origin: "Z1", CostList: []ntv1alpha1.CostInfo{
+									{Destination: "Z2", NetworkCost: cost, BandwidthCapacity: resource.MustParse("1Gi")}}},
+							}},
+						ntv1alpha1.TopologyInfo{
+							TopologyKey: ntv1alpha1.NetworkTopologyRegion,
+							OriginList: ntv1alpha1.OriginList{
+								ntv1alpha1.OriginInfo{Origin: "R1", CostList: []ntv1alpha1.CostInfo{{Destination: "R2", NetworkCost: 10 * cost}}},
+							}},
+						// Costs of topology keys not considered by the plugin are ignored
+						ntv1alpha1.TopologyInfo{
+							TopologyKey: "example.com/rack",
+							OriginList: ntv1alpha1.OriginList{
+								ntv1alpha1.OriginInfo{Origin: "rack-a", CostList: []ntv1alpha1.CostInfo{{Destination: "rack-b", NetworkCost: 1}}},
+							}},
+					},
+				},
+				ntv1alpha1.WeightInfo{Name: ntv1alpha1.NetworkTopologyNetperfCosts,
+					TopologyList: ntv1alpha1.TopologyList{
+						ntv1alpha1.TopologyInfo{
+							TopologyKey: ntv1alpha1.NetworkTopologyZone,
+							OriginList: ntv1alpha1.OriginList{
+								ntv1alpha1.OriginInfo{Origin: "Z1", CostList: []ntv1alpha1.CostInfo{{Destination: "Z3", NetworkCost: 42}}},
+							}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestNetworkIndexCosts(t *testing.T) {
+	index := newNetworkIndex([]string{"default", "other"}, "nt-test", "UserDefined", []string{v1.LabelTopologyRegion, v1.LabelTopologyZone})
+	capacities := map[networkawareutil.CostKey]int64{{TopologyKey: v1.LabelTopologyZone, Origin: "Z1", Destination: "Z2"}: 1024 * 1024 * 1024}
+	check := func(wantNT *ntv1alpha1.NetworkTopology, wantCosts map[networkawareutil.CostKey]int64, wantCapacities map[networkawareutil.CostKey]int64) {
+		t.Helper()
+		costs := index.getCosts()
+		if costs.networkTopology != wantNT {
+			t.Errorf("expected costs of %v, got %v", wantNT, costs.networkTopology)
+		}
+		if len(costs.costMap)+len(wantCosts) > 0 && !reflect.DeepEqual(costs.costMap, wantCosts) {
+			t.Errorf("expected costs %v, got %v", wantCosts, costs.costMap)
+		}
+		if len(costs.bandwidthCapacityMap)+len(wantCapacities) > 0 && !reflect.DeepEqual(costs.bandwidthCapacityMap, wantCapacities) {
+			t.Errorf("expected bandwidth capacities %v, got %v", wantCapacities, costs.bandwidthCapacityMap)
+		}
+	}
+	check(nil, nil, nil)
+
+	// The NetworkTopology of the first namespace is considered, other names are ignored
+	other := makeIndexNetworkTopology("other", "nt-test", 2)
+	defaultNT := makeIndexNetworkTopology("default", "nt-test", 5)
+	index.addNetworkTopology(other)
+	index.addNetworkTopology(makeIndexNetworkTopology("default", "nt-ignored", 3))
+	index.addNetworkTopology(defaultNT)
+	check(defaultNT, map[networkawareutil.CostKey]int64{
+		{TopologyKey: v1.LabelTopologyRegion, Origin: "R1", Destination: "R2"}: 50,
+		{TopologyKey: v1.LabelTopologyZone, Origin: "Z1", Destination: "Z2"}:   5,
+	}, capacities)
+
+	version := index.getVersion()
+	updated := makeIndexNetworkTopology("default", "nt-test", 7)
+	index.updateNetworkTopology(defaultNT, updated)
+	if index.getVersion() <= version {
+		t.Errorf("expected the version to increase from %v, got %v", version, index.getVersion())
+	}
+	check(updated, map[networkawareutil.CostKey]int64{
+		{TopologyKey: v1.LabelTopologyRegion, Origin: "R1", Destination: "R2"}: 70,
+		{TopologyKey: v1.LabelTopologyZone, Origin: "Z1", Destination: "Z2"}:   7,
+	}, capacities)
+
+	index.deleteNetworkTopology(cache.DeletedFinalStateUnknown{Key: "default/nt-test", Obj: updated})
+	check(other, map[networkawareutil.CostKey]int64{
+		{TopologyKey: v1.LabelTopologyRegion, Origin: "R1", Destination: "R2"}: 20,
+		{TopologyKey: v1.LabelTopologyZone, Origin: "Z1", Destination: "Z2"}:   2,
+	}, capacities)
+
+	index.deleteNetworkTopology(other)
+	check(nil, nil, nil)
+}
+
+func TestNetworkIndexAppGroups(t *testing.T) {
+	index := newNetworkIndex([]string{"default", "other"}, "nt-test", "UserDefined", []string{v1.LabelTopologyRegion, v1.LabelTopologyZone})
+
+	other := &agv1alpha1.AppGroup{ObjectMeta: metav1.ObjectMeta{Name: "basic", Namespace: "other"}}
+	defaultAG := &agv1alpha1.AppGroup{ObjectMeta: metav1.ObjectMeta{Name: "basic", Namespace: "default"}}
+	ignored := &agv1alpha1.AppGroup{ObjectMeta: metav1.ObjectMeta{Name: "basic", Namespace: "ignored"}}
+	index.addAppGroup(ignored)
+	if got := index.getAppGroup("basic"); got != nil {
+		t.Errorf("expected no AppGroup out of the namespaces, got %v", got)
+	}
+
+	index.addAppGroup(other)
+	index.addAppGroup(defaultAG)
+	if got := index.getAppGroup("basic"); got != defaultAG {
+		t.Errorf("expected AppGroup of the first namespace, got %v", got)
+	}
+
+	updated := defaultAG.DeepCopy()
+	updated.Spec.NumMembers = 3
+	index.updateAppGroup(defaultAG, updated)
+	if got := index.getAppGroup("basic"); got != updated {
+		t.Errorf("expected updated AppGroup, got %v", got)
+	}
+
+	index.deleteAppGroup(updated)
+	if got := index.getAppGroup("basic"); got != other {
+		t.Errorf("expected AppGroup of the second namespace, got %v", got)
+	}
+}
+
+func TestNetworkIndexPods(t *testing.T) {
+	index := newNetworkIndex([]string{"default"}, "nt-test", "UserDefined", []string{v1.LabelTopologyRegion, v1.LabelTopologyZone})
+
+	p1 := makePodAllocated("p1", "p1-deployment-1", "n-1", 0, "basic", nil, nil)
+	p2 := makePodAllocated("p1", "p1-deployment-2", "n-1", 0, "basic", nil, nil)
+	p3 := makePodAllocated("p2", "p2-deployment-1", "n-2", 0, "basic", nil, nil)
+	index.addPod(p1)
+	index.addPod(p2)
+	index.addPod(p3)
+	// Pods not bound to nodes are ignored
+	index.addPod(makePod("p2", "p2-deployment-2", 0, "basic", nil, nil))
+
+	if got, want := index.getWorkloadHosts("basic"), map[string]map[string]int{
+		"p1": {"n-1": 2},
+		"p2": {"n-2": 1},
+	}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected workload hosts %v, got %v", want, got)
+	}
+
+	// Updates not changing the AppGroup, workload or node of the pod keep the snapshot
+	version := index.getVersion()
+	running := p1.DeepCopy()
+	running.Status.Phase = v1.PodRunning
+	index.updatePod(p1, running)
+	index.addPod(running)
+	if index.getVersion() != version {
+		t.Errorf("expected version %v, got %v", version, index.getVersion())
+	}
+
+	moved := p2.DeepCopy()
+	moved.Spec.NodeName = "n-3"
+	index.updatePod(p2, moved)
+	index.deletePod(cache.DeletedFinalStateUnknown{Key: "default/p2-deployment-1", Obj: p3})
+	if got, want := index.getWorkloadHosts("basic"), map[string]map[string]int{
+		"p1": {"n-1": 1, "n-3": 1},
+	}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected workload hosts %v, got %v", want, got)
+	}
+
+	index.deletePod(running)
+	index.deletePod(moved)
+	if got := index.getWorkloadHosts("basic"); got != nil {
+		t.Errorf("expected no workload hosts, got %v", got)
+	}
+}
@@ -21,18 +21,18 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	pluginconfig "sigs.k8s.io/scheduler-plugins/apis/config"
+	cfgv1 "sigs.k8s.io/scheduler-plugins/apis/config/v1"
 	networkawareutil "sigs.k8s.io/scheduler-plugins/pkg/networkaware/util"
 	"sigs.k8s.io/scheduler-plugins/pkg/util"
 
@@ -42,7 +42,9 @@ import (
 
 var _ framework.PreFilterPlugin = &NetworkOverhead{}
 var _ framework.FilterPlugin = &NetworkOverhead{}
+var _ framework.PostFilterPlugin = &NetworkOverhead{}
 var _ framework.ScorePlugin = &NetworkOverhead{}
+var _ framework.ReservePlugin = &NetworkOverhead{}
 
 const (
 	// Name : name of plugin used in the plugin registry and configurations.
@@ -54,7 +56,7 @@ const (
 	// SameHostname : If pods belong to the same host, then consider cost as 0
 	SameHostname = 0
 
-	// SameZone : If pods belong to hosts in the same zone, then consider cost as 1
+	// SameZone : If pods belong to hosts in the same zone (the narrowest topology domain), then consider cost as 1
 	SameZone = 1
 
 	// preFilterStateKey is the key in CycleState to NetworkOverhead pre-computed data.
@@ -70,15 +72,21 @@ func init() {
 	utilruntime.Must(ntv1alpha1.AddToScheme(scheme))
 }
 
-// NetworkOverhead : Filter and Score nodes based on Pod's AppGroup requirements: MaxNetworkCosts requirements among Pods with dependencies
+// NetworkOverhead : Filter and Score nodes based on Pod's AppGroup requirements: MaxNetworkCosts and MinBandwidth
+// requirements among Pods with dependencies
 type NetworkOverhead struct {
-	client.Client
 	logger      klog.Logger
-	podLister   corelisters.PodLister
+	nodeLister  corelisters.NodeLister
 	handle      framework.Handle
+	index       *networkIndex
+	planner     *placementPlanner
+	bandwidth   bandwidthReservations
 	namespaces  []string
 	weightsName string
 	ntName      string
+
+	// topology keys with network costs, from the broadest to the narrowest level
+	topologyKeys []string
 }
 
 // PreFilterState computed at PreFilter and used at Filter and Score.
@@ -98,11 +106,14 @@ type PreFilterState struct {
 	// Dependency List of the given pod
 	dependencyList []agv1alpha1.DependenciesInfo
 
-	// Pods already scheduled based on the dependency list
-	scheduledList networkawareutil.ScheduledList
+	// topology domains planned for the pod, joined with "/", empty if not planned
+	plannedDomain string
+
+	// Pods already scheduled based on the dependency list, by dependency and host
+	dependencyHosts []dependencyHosts
 
-	// node map for cost / destinations. Search for requirements faster...
-	nodeCostMap map[string]map[networkawareutil.CostKey]int64
+	// map for cost / destinations of all topology levels. Search for requirements faster...
+	costMap map[networkawareutil.CostKey]int64
 
 	// node map for satisfied dependencies
 	satisfiedMap map[string]int64
@@ -112,6 +123,12 @@ type PreFilterState struct {
 
 	// node map for costs
 	finalCostMap map[string]int64
+
+	// bandwidth capacity of the links (origin / destination) limited in the NetworkTopology CR
+	bandwidthCapacityMap map[networkawareutil.CostKey]int64
+
+	// node map for the bandwidth requested on each link
+	bandwidthRequestMap map[string]map[networkawareutil.CostKey]int64
 }
 
 // Clone the preFilter state.
@@ -147,30 +164,69 @@ func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (fram
 	if err != nil {
 		return nil, err
 	}
-	c, _, err := util.NewClientWithCachedReader(ctx, handle.KubeConfig(), scheme)
+	_, ccache, err := util.NewClientWithCachedReader(ctx, handle.KubeConfig(), scheme)
 	if err != nil {
 		return nil, err
 	}
 
+	topologyKeys := args.TopologyKeys
+	if len(topologyKeys) == 0 {
+		topologyKeys = cfgv1.DefaultNetworkTopologyKeys
+	}
+
 	no := &NetworkOverhead{
-		Client:      c,
 		logger:      logger,
-		podLister:   handle.SharedInformerFactory().Core().V1().Pods().Lister(),
+		nodeLister:  handle.SharedInformerFactory().Core().V1().Nodes().Lister(),
 		handle:      handle,
+		index:       newNetworkIndex(args.Namespaces, args.NetworkTopologyName, args.WeightsName, topologyKeys),
 		namespaces:  args.Namespaces,
 		weightsName: args.WeightsName,
 		ntName:      args.NetworkTopologyName,
+
+		topologyKeys: topologyKeys,
+	}
+
+	// Keep the index of AppGroups, network costs and AppGroup pods up to date
+	appGroupInformer, err := ccache.GetInformer(ctx, &agv1alpha1.AppGroup{})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := appGroupInformer.AddEventHandler(no.index.appGroupEventHandler()); err != nil {
+		return nil, err
+	}
+	if args.PlacementPlanning {
+		no.planner = newPlacementPlanner(topologyKeys)
+		if _, err := appGroupInformer.AddEventHandler(no.planner.appGroupEventHandler()); err != nil {
+			return nil, err
+		}
+	}
+	networkTopologyInformer, err := ccache.GetInformer(ctx, &ntv1alpha1.NetworkTopology{})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := networkTopologyInformer.AddEventHandler(no.index.networkTopologyEventHandler()); err != nil {
+		return nil, err
+	}
+	podInformer := handle.SharedInformerFactory().Core().V1().Pods().Informer()
+	if _, err := podInformer.AddEventHandler(no.index.podEventHandler()); err != nil {
+		return nil, err
+	}
+
+	// Keep track of the bandwidth reserved by pods bound to nodes
+	if _, err := podInformer.AddEventHandler(no.podEventHandler()); err != nil {
+		return nil, err
 	}
 	return no, nil
 }
 
 // PreFilter performs the following operations:
-// 1. Get appGroup name and respective appGroup CR.
-// 2. Get networkTopology CR.
-// 3. Get dependency and scheduled list for the given pod
-// 4. Update cost map of all nodes
+// 1. Get appGroup name and respective appGroup CR from the index.
+// 2. Get network costs of the networkTopology CR from the index.
+// 3. Get the topology domains planned for the pod, if placement planning is enabled
+// 4. Get dependency list and hosts of the pods already scheduled for each dependency
 // 5. Get number of satisfied and violated dependencies
 // 6. Get final cost of the given node to be used in the score plugin
+// 7. Get bandwidth requested by the given pod on the links of each node
 func (no *NetworkOverhead) PreFilter(ctx context.Context, state *framework.CycleState, pod *corev1.Pod) (*framework.PreFilterResult, *framework.Status) {
 	// Init PreFilter State
 	preFilterState := &PreFilterState{
@@ -188,95 +244,147 @@ func (no *NetworkOverhead) PreFilter(ctx context.Context, state *framework.Cycle
 	}
 
 	// Get AppGroup CR
-	appGroup := no.findAppGroupNetworkOverhead(ctx, agName)
+	appGroup := no.index.getAppGroup(agName)
+	if appGroup == nil {
+		return nil, framework.NewStatus(framework.Success, "AppGroup not found, return")
+	}
 
-	// Get NetworkTopology CR
-	networkTopology := no.findNetworkTopologyNetworkOverhead(ctx)
+	// Get costs of the NetworkTopology CR, empty if not found
+	costs := no.index.getCosts()
 
-	// Sort Costs if manual weights were selected
-	no.sortNetworkTopologyCosts(networkTopology)
+	// Derive again the bandwidth of the bound pods of the AppGroup whose dependencies changed since, before checking it
+	// in Filter
+	if hasBandwidthRequirements(appGroup) {
+		no.refreshBoundPods(ctx, agName)
+	}
 
 	// Get Dependencies of the given pod
 	dependencyList := networkawareutil.GetDependencyList(pod, appGroup)
 
-	// If the pod has no dependencies, return
-	if dependencyList == nil {
-		return nil, framework.NewStatus(framework.Success, "Pod has no dependencies, return")
-	}
+	// Pods already scheduled: number of pods per workload and host
+	workloadHosts := no.index.getWorkloadHosts(agName)
 
-	// Get pods from lister
-	selector := labels.Set(map[string]string{agv1alpha1.AppGroupLabel: agName}).AsSelector()
-	pods, err := no.podLister.List(selector)
+	// Get all nodes
+	nodeList, err := no.handle.SnapshotSharedLister().NodeInfos().List()
 	if err != nil {
-		return nil, framework.NewStatus(framework.Success, "Error while returning pods from appGroup, return")
+		return nil, framework.NewStatus(framework.Error, fmt.Sprintf("Error getting the nodelist: %v", err))
+	}
+
+	// Get the topology domains planned for the pod, from the first pod of the AppGroup on
+	plannedDomain := ""
+	if no.planner != nil {
+		plannedDomain = no.planner.getPlannedDomain(logger, appGroup, pod, workloadHosts, nodeList, costs.costMap)
 	}
 
-	// Return if pods are not yet allocated for the AppGroup...
-	if len(pods) == 0 {
-		return nil, framework.NewStatus(framework.Success, "No pods yet allocated, return")
+	// If the pod has no dependencies, return
+	if dependencyList == nil && plannedDomain == "" {
+		return nil, framework.NewStatus(framework.Success, "Pod has no dependencies, return")
 	}
 
-	// Pods already scheduled: Get Scheduled List (Deployment name, replicaID, hostname)
-	scheduledList := networkawareutil.GetScheduledList(pods)
-	// Check if scheduledList is empty...
-	if len(scheduledList) == 0 {
-		logger.Error(nil, "Scheduled list is empty, return")
+	// Return if pods are not yet scheduled for the AppGroup...
+	if len(workloadHosts) == 0 && plannedDomain == "" {
 		return nil, framework.NewStatus(framework.Success, "Scheduled list is empty, return")
 	}
+	logger.V(6).Info("Index", "version", no.index.getVersion(), "workloadHosts", workloadHosts, "plannedDomain", plannedDomain)
 
-	// Get all nodes
-	nodeList, err := no.handle.SnapshotSharedLister().NodeInfos().List()
+	// Get hosts and topology domains of the pods scheduled for each dependency
+	dependencyHosts, err := no.getDependencyHosts(dependencyList, workloadHosts, func(name string) (*corev1.Node, error) {
+		nodeInfo, err := no.handle.SnapshotSharedLister().NodeInfos().Get(name)
+		if err != nil {
+			return nil, err
+		}
+		return nodeInfo.Node(), nil
+	})
 	if err != nil {
-		return nil, framework.NewStatus(framework.Error, fmt.Sprintf("Error getting the nodelist: %v", err))
+		return nil, framework.NewStatus(framework.Error, fmt.Sprintf("pod hostname not found: %v", err))
 	}
 
 	// Create variables to fill PreFilterState
-	nodeCostMap := make(map[string]map[networkawareutil.CostKey]int64)
-	satisfiedMap := make(map[string]int64)
-	violatedMap := make(map[string]int64)
-	finalCostMap := make(map[string]int64)
+	satisfiedMap := make(map[string]int64, len(nodeList))
+	violatedMap := make(map[string]int64, len(nodeList))
+	finalCostMap := make(map[string]int64, len(nodeList))
+	bandwidthRequestMap := make(map[string]map[networkawareutil.CostKey]int64, len(nodeList))
 
 	// For each node:
-	// 1 - Get region and zone labels
+	// 1 - Get topology domains (e.g., region and zone labels)
 	// 2 - Calculate satisfied and violated number of dependencies
 	// 3 - Calculate the final cost of the node to be used by the scoring plugin
-	for _, nodeInfo := range nodeList {
-		// retrieve region and zone labels
-		region := networkawareutil.GetNodeRegion(nodeInfo.Node())
-		zone := networkawareutil.GetNodeZone(nodeInfo.Node())
-		logger.V(6).Info("Node info",
-			"name", nodeInfo.Node().Name,
-			"region", region,
-			"zone", zone)
-
-		// Create map for cost / destinations. Search for requirements faster...
-		costMap := make(map[networkawareutil.CostKey]int64)
-
-		// Populate cost map for the given node
-		no.populateCostMap(costMap, networkTopology, region, zone)
-		logger.V(6).Info("Map", "costMap", costMap)
-
-		// Update nodeCostMap
-		nodeCostMap[nodeInfo.Node().Name] = costMap
-
-		// Get Satisfied and Violated number of dependencies
-		satisfied, violated, ok := checkMaxNetworkCostRequirements(logger, scheduledList, dependencyList, nodeInfo, region, zone, costMap, no)
-		if ok != nil {
-			return nil, framework.NewStatus(framework.Error, fmt.Sprintf("pod hostname not found: %v", ok))
+	// 4 - Calculate the bandwidth requested on the links of the node
+	// Hosts of the pods of the dependencies by hostname
+	podsByHost := make(map[string][]scheduledHost)
+	var dependencyPods int64
+	for _, d := range dependencyHosts {
+		for _, h := range d.hosts {
+			podsByHost[h.hostname] = append(podsByHost[h.hostname], h)
+			dependencyPods += h.pods
 		}
+	}
+
+	// Nodes out of the planned domains cost more than any node in them: the accumulated cost is at most MaxCost
+	// per dependency pod
+	planPenalty := MaxCost * (dependencyPods + 1)
+
+	// Nodes of the same topology domains get the same results, except for the pods running on the node itself
+	domainResults := make(map[string]*nodeResult)
+
+	// Logging arguments are only evaluated at high verbosity, since the loop runs over all nodes
+	loggerV := logger.V(6)
+	for _, nodeInfo := range nodeList {
+		nodeName := nodeInfo.Node().Name
+
+		// retrieve topology domains
+		domains := networkawareutil.GetNodeDomains(nodeInfo.Node(), no.topologyKeys)
+
+		domainsKey := strings.Join(domains, "/")
+		result, ok := domainResults[domainsKey]
+		if !ok {
+			result = &nodeResult{}
 
-		// Update Satisfied and Violated maps
-		satisfiedMap[nodeInfo.Node().Name] = satisfied
-		violatedMap[nodeInfo.Node().Name] = violated
-		logger.V(6).Info("Number of dependencies", "satisfied", satisfied, "violated", violated)
+			// Get Satisfied and Violated number of dependencies
+			result.satisfied, result.violated = checkMaxNetworkCostRequirements(dependencyHosts, "", domains, no.topologyKeys, costs.costMap)
 
-		// Get accumulated cost based on pod dependencies
-		cost, ok := no.getAccumulatedCost(scheduledList, dependencyList, nodeInfo.Node().Name, region, zone, costMap)
-		if ok != nil {
-			return nil, framework.NewStatus(framework.Error, fmt.Sprintf("getting pod hostname from Snapshot: %v", ok))
+			// Get accumulated cost based on pod dependencies
+			result.cost = getAccumulatedCost(dependencyHosts, "", domains, no.topologyKeys, costs.costMap)
+
+			// Get bandwidth requested based on pod dependencies
+			result.requests = getBandwidthRequests(dependencyHosts, "", domains, no.topologyKeys)
+
+			// Favor the planned domains
+			if plannedDomain != "" && domainsKey != plannedDomain {
+				result.cost += planPenalty
+			}
+
+			domainResults[domainsKey] = result
+		}
+		satisfied, violated, cost := result.satisfied, result.violated, result.cost
+
+		// The pods running on the node itself were accounted as pods of a node in the same domains.
+		// Their traffic does not cross any link either way.
+		for _, h := range podsByHost[nodeName] {
+			if networkawareutil.IsDomainUnknown(h.domains) {
+				satisfied += h.pods
+				violated -= h.pods
+				cost += (SameHostname - MaxCost) * h.pods
+			} else {
+				cost += (SameHostname - SameZone) * h.pods
+			}
+		}
+
+		// Update Satisfied, Violated, cost and bandwidth maps
+		satisfiedMap[nodeName] = satisfied
+		violatedMap[nodeName] = violated
+		finalCostMap[nodeName] = cost
+		bandwidthRequestMap[nodeName] = result.requests
+
+		if loggerV.Enabled() {
+			loggerV.Info("Node info",
+				"name", nodeName,
+				"topologyKeys", no.topologyKeys,
+				"domains", domains)
+			loggerV.Info("Number of dependencies", "satisfied", satisfied, "violated", violated)
+			loggerV.Info("Node final cost", "cost", cost)
+			loggerV.Info("Node bandwidth requests", "requests", result.requests)
 		}
-		logger.V(6).Info("Node final cost", "cost", cost)
-		finalCostMap[nodeInfo.Node().Name] = cost
 	}
 
 	// Update PreFilter State
@@ -284,13 +392,17 @@ func (no *NetworkOverhead) PreFilter(ctx context.Context, state *framework.Cycle
 		scoreEqually:    false,
 		agName:          agName,
 		appGroup:        appGroup,
-		networkTopology: networkTopology,
+		networkTopology: costs.networkTopology,
 		dependencyList:  dependencyList,
-		scheduledList:   scheduledList,
-		nodeCostMap:     nodeCostMap,
+		plannedDomain:   plannedDomain,
+		dependencyHosts: dependencyHosts,
+		costMap:         costs.costMap,
 		satisfiedMap:    satisfiedMap,
 		violatedMap:     violatedMap,
 		finalCostMap:    finalCostMap,
+
+		bandwidthCapacityMap: costs.bandwidthCapacityMap,
+		bandwidthRequestMap:  bandwidthRequestMap,
 	}
 
 	state.Write(preFilterStateKey, preFilterState)
@@ -322,7 +434,7 @@ func (no *NetworkOverhead) RemovePod(ctx context.Context,
 	return framework.NewStatus(framework.Success, "")
 }
 
-// Filter : evaluate if node can respect maxNetworkCost requirements
+// Filter : evaluate if node can respect maxNetworkCost and minBandwidth requirements
 func (no *NetworkOverhead) Filter(ctx context.Context,
 	cycleState *framework.CycleState,
 	pod *corev1.Pod,
@@ -350,12 +462,75 @@ func (no *NetworkOverhead) Filter(ctx context.Context,
 	violated := preFilterState.violatedMap[nodeInfo.Node().Name]
 	logger.V(6).Info("Number of dependencies:", "satisfied", satisfied, "violated", violated)
 
-	// The pod is filtered out if the number of violated dependencies is higher than the satisfied ones
+	// The pod is filtered out if the number of violated dependencies is higher than the satisfied ones.
+	// The status lists the violated dependencies.
 	if violated > satisfied {
-		return framework.NewStatus(framework.Unschedulable,
-			fmt.Sprintf("Node %v does not meet several network requirements from Workload dependencies: Satisfied: %v Violated: %v", nodeInfo.Node().Name, satisfied, violated))
+		reasons := []string{fmt.Sprintf("Node %v does not meet several network requirements from Workload dependencies: Satisfied: %v Violated: %v",
+			nodeInfo.Node().Name, satisfied, violated)}
+		for _, v := range no.getViolatedDependencies(preFilterState, nodeInfo.Node()) {
+			reasons = append(reasons, v.String())
+		}
+		return framework.NewStatus(framework.Unschedulable, reasons...)
 	}
-	return nil
+
+	// The pod is filtered out if its bandwidth requirements would oversubscribe the links of the node
+	return no.checkBandwidthCapacity(preFilterState, nodeInfo.Node().Name)
+}
+
+// PostFilter : explain the dependencies violated by the nodes the plugin filtered out, so that AppGroup authors can
+// tune the maxNetworkCost of the dependencies. The explanation is added to the message of the PodScheduled condition
+// of the pod. The plugin never makes the pod schedulable.
+func (no *NetworkOverhead) PostFilter(ctx context.Context,
+	cycleState *framework.CycleState,
+	pod *corev1.Pod,
+	filteredNodeStatusReader framework.NodeToStatusReader) (*framework.PostFilterResult, *framework.Status) {
+	logger := klog.FromContext(klog.NewContext(ctx, no.logger)).WithValues("ExtensionPoint", "PostFilter")
+
+	// Get PreFilterState
+	preFilterState, err := getPreFilterState(cycleState)
+	if err != nil {
+		logger.V(4).Info("Failed to read preFilterState from cycleState", "preFilterStateKey", preFilterStateKey, "err", err)
+		return nil, framework.NewStatus(framework.Unschedulable)
+	}
+	if preFilterState.scoreEqually {
+		return nil, framework.NewStatus(framework.Unschedulable)
+	}
+
+	nodeList, err := no.handle.SnapshotSharedLister().NodeInfos().List()
+	if err != nil {
+		logger.Error(err, "Failed to get the nodelist")
+		return nil, framework.NewStatus(framework.Unschedulable)
+	}
+
+	// Violations of the nodes filtered out by the plugin, by dependency
+	summaries := make(map[string]*violationSummary)
+	for _, nodeInfo := range nodeList {
+		status := filteredNodeStatusReader.Get(nodeInfo.Node().Name)
+		if status.Code() != framework.Unschedulable || status.Plugin() != Name {
+			continue
+		}
+		for _, v := range no.getViolatedDependencies(preFilterState, nodeInfo.Node()) {
+			summary, ok := summaries[v.selector]
+			if !ok {
+				summary = &violationSummary{selector: v.selector, maxNetworkCost: v.maxNetworkCost, minCost: v.cost}
+				summaries[v.selector] = summary
+			}
+			summary.nodes++
+			summary.minCost = min(summary.minCost, v.cost)
+		}
+	}
+	if len(summaries) == 0 {
+		return nil, framework.NewStatus(framework.Unschedulable)
+	}
+
+	explanations := make([]string, 0, len(summaries))
+	for _, summary := range summaries {
+		explanations = append(explanations, summary.String())
+	}
+	sort.Strings(explanations)
+	msg := fmt.Sprintf("%v: %v", Name, strings.Join(explanations, "; "))
+	logger.V(4).Info("Violated dependencies", "pod", klog.KObj(pod), "explanation", msg)
+	return nil, framework.NewStatus(framework.Unschedulable, msg)
 }
 
 // Score : evaluate score for a node
@@ -433,207 +608,192 @@ func getMinMaxScores(scores framework.NodeScoreList) (int64, int64) {
 	return min, max
 }
 
-// sortNetworkTopologyCosts : sort costs if manual weights were selected
-func (no *NetworkOverhead) sortNetworkTopologyCosts(networkTopology *ntv1alpha1.NetworkTopology) {
-	if no.weightsName != ntv1alpha1.NetworkTopologyNetperfCosts { // Manual weights were selected
-		for _, w := range networkTopology.Spec.Weights {
-			// Sort Costs by TopologyKey, might not be sorted since were manually defined
-			sort.Sort(networkawareutil.ByTopologyKey(w.TopologyList))
-		}
-	}
+// nodeResult : dependencies, cost and bandwidth requests computed for the nodes of the same topology domains.
+// Bandwidth requests are shared by the nodes and must not be modified.
+type nodeResult struct {
+	satisfied int64
+	violated  int64
+	cost      int64
+	requests  map[networkawareutil.CostKey]int64
 }
 
-// populateCostMap : Populates costMap based on the node being filtered/scored
-func (no *NetworkOverhead) populateCostMap(
-	costMap map[networkawareutil.CostKey]int64,
-	networkTopology *ntv1alpha1.NetworkTopology,
-	region string,
-	zone string) {
-	for _, w := range networkTopology.Spec.Weights { // Check the weights List
-		if w.Name != no.weightsName { // If it is not the Preferred algorithm, continue
-			continue
-		}
-
-		if region != "" { // Add Region Costs
-			// Binary search through CostList: find the Topology Key for region
-			topologyList := networkawareutil.FindTopologyKey(w.TopologyList, ntv1alpha1.NetworkTopologyRegion)
-
-			if no.weightsName != ntv1alpha1.NetworkTopologyNetperfCosts {
-				// Sort Costs by origin, might not be sorted since were manually defined
-				sort.Sort(networkawareutil.ByOrigin(topologyList))
-			}
-
-			// Binary search through TopologyList: find the costs for the given Region
-			costs := networkawareutil.FindOriginCosts(topologyList, region)
+// dependencyHosts : hosts of the pods already scheduled for a dependency of the pod
+type dependencyHosts struct {
+	dependency agv1alpha1.DependenciesInfo
+	hosts      []scheduledHost
+}
 
-			// Add Region Costs
-			for _, c := range costs {
-				costMap[networkawareutil.CostKey{ // Add the cost to the map
-					Origin:      region,
-					Destination: c.Destination}] = c.NetworkCost
-			}
-		}
-		if zone != "" { // Add Zone Costs
-			// Binary search through CostList: find the Topology Key for zone
-			topologyList := networkawareutil.FindTopologyKey(w.TopologyList, ntv1alpha1.NetworkTopologyZone)
+// scheduledHost : node hosting pods of a dependency
+type scheduledHost struct {
+	hostname string
 
-			if no.weightsName != ntv1alpha1.NetworkTopologyNetperfCosts {
-				// Sort Costs by origin, might not be sorted since were manually defined
-				sort.Sort(networkawareutil.ByOrigin(topologyList))
-			}
+	// topology domains of the node, nil if the node is not found
+	domains []string
 
-			// Binary search through TopologyList: find the costs for the given Region
-			costs := networkawareutil.FindOriginCosts(topologyList, zone)
+	// number of pods of the dependency on the node
+	pods int64
+}
 
-			// Add Zone Costs
-			for _, c := range costs {
-				costMap[networkawareutil.CostKey{ // Add the cost to the map
-					Origin:      zone,
-					Destination: c.Destination}] = c.NetworkCost
+// getDependencyHosts : get the hosts of the pods scheduled for each dependency, with their topology domains.
+// Nodes are retrieved once per host.
+func (no *NetworkOverhead) getDependencyHosts(
+	dependencyList []agv1alpha1.DependenciesInfo,
+	workloadHosts map[string]map[string]int,
+	getNode func(string) (*corev1.Node, error)) ([]dependencyHosts, error) {
+	domainsByHost := make(map[string][]string)
+	dependencies := make([]dependencyHosts, 0, len(dependencyList))
+	for _, d := range dependencyList { // For each pod dependency
+		hosts := make([]scheduledHost, 0, len(workloadHosts[d.Workload.Selector]))
+		for hostname, pods := range workloadHosts[d.Workload.Selector] { // For each host of the dependency pods
+			domains, ok := domainsByHost[hostname]
+			if !ok {
+				node, err := getNode(hostname)
+				if err != nil {
+					return nil, err
+				}
+				if node != nil {
+					domains = networkawareutil.GetNodeDomains(node, no.topologyKeys)
+				}
+				domainsByHost[hostname] = domains
 			}
+			hosts = append(hosts, scheduledHost{hostname: hostname, domains: domains, pods: int64(pods)})
 		}
+		dependencies = append(dependencies, dependencyHosts{dependency: d, hosts: hosts})
 	}
+	return dependencies, nil
 }
 
 // checkMaxNetworkCostRequirements : verifies the number of met and unmet dependencies based on the pod being filtered
 func checkMaxNetworkCostRequirements(
-	logger klog.Logger,
-	scheduledList networkawareutil.ScheduledList,
-	dependencyList []agv1alpha1.DependenciesInfo,
-	nodeInfo *framework.NodeInfo,
-	region string,
-	zone string,
-	costMap map[networkawareutil.CostKey]int64,
-	no *NetworkOverhead) (int64, int64, error) {
+	dependencies []dependencyHosts,
+	nodeName string,
+	domains []string,
+	topologyKeys []string,
+	costMap map[networkawareutil.CostKey]int64) (int64, int64) {
 	var satisfied int64 = 0
 	var violated int64 = 0
 
 	// check if maxNetworkCost fits
-	for _, podAllocated := range scheduledList { // For each pod already allocated
-		if podAllocated.Hostname != "" { // if hostname not empty...
-			for _, d := range dependencyList { // For each pod dependency
-				// If the pod allocated is not an established dependency, continue.
-				if podAllocated.Selector != d.Workload.Selector {
-					continue
-				}
+	for _, d := range dependencies { // For each pod dependency
+		for _, h := range d.hosts { // For each host of the pods already allocated
+			// If the Pod hostname is the node being filtered, requirements are checked via extended resources
+			if h.hostname == nodeName {
+				satisfied += h.pods
+				continue
+			}
 
-				// If the Pod hostname is the node being filtered, requirements are checked via extended resources
-				if podAllocated.Hostname == nodeInfo.Node().Name {
-					satisfied += 1
-					continue
+			if networkawareutil.IsDomainUnknown(h.domains) { // Node has no topology domain defined
+				violated += h.pods
+			} else if level := networkawareutil.GetDifferingLevel(domains, h.domains); level < 0 { // If Nodes belong to the same domains
+				satisfied += h.pods
+			} else { // belong to a different domain, check maxNetworkCost
+				// Retrieve the cost from the map at the broadest level where domains differ. Time Complexity: O(levels)
+				cost, costOK := networkawareutil.FindDomainsCost(domains, h.domains, level, topologyKeys, costMap)
+				if costOK {
+					if cost <= d.dependency.MaxNetworkCost {
+						satisfied += h.pods
+					} else {
+						violated += h.pods
+					}
 				}
+			}
+		}
+	}
+	return satisfied, violated
+}
 
-				// If Nodes are not the same, get NodeInfo from pod Hostname
-				podNodeInfo, err := no.handle.SnapshotSharedLister().NodeInfos().Get(podAllocated.Hostname)
-				if err != nil {
-					logger.Error(err, "getting pod's NodeInfo from snapshot", "nodeInfo", podNodeInfo)
-					return satisfied, violated, err
-				}
+// dependencyViolation : pods of a dependency whose network cost from a node exceeds the maxNetworkCost of the dependency
+type dependencyViolation struct {
+	selector       string
+	maxNetworkCost int64
 
-				// Get zone and region from Pod Hostname
-				regionPodNodeInfo := networkawareutil.GetNodeRegion(podNodeInfo.Node())
-				zonePodNodeInfo := networkawareutil.GetNodeZone(podNodeInfo.Node())
-
-				if regionPodNodeInfo == "" && zonePodNodeInfo == "" { // Node has no zone and region defined
-					violated += 1
-				} else if region == regionPodNodeInfo { // If Nodes belong to the same region
-					if zone == zonePodNodeInfo { // If Nodes belong to the same zone
-						satisfied += 1
-					} else { // belong to a different zone, check maxNetworkCost
-						cost, costOK := costMap[networkawareutil.CostKey{ // Retrieve the cost from the map (origin: zone, destination: pod zoneHostname)
-							Origin:      zone, // Time Complexity: O(1)
-							Destination: zonePodNodeInfo,
-						}]
-						if costOK {
-							if cost <= d.MaxNetworkCost {
-								satisfied += 1
-							} else {
-								violated += 1
-							}
-						}
-					}
-				} else { // belong to a different region
-					cost, costOK := costMap[networkawareutil.CostKey{ // Retrieve the cost from the map (origin: zone, destination: pod zoneHostname)
-						Origin:      region, // Time Complexity: O(1)
-						Destination: regionPodNodeInfo,
-					}]
-					if costOK {
-						if cost <= d.MaxNetworkCost {
-							satisfied += 1
-						} else {
-							violated += 1
-						}
-					}
+	// highest network cost from the node to the pods of the dependency, MaxCost if their node has no topology domain
+	cost int64
+
+	// number of pods of the dependency exceeding the maxNetworkCost
+	pods int64
+}
+
+func (v dependencyViolation) String() string {
+	return fmt.Sprintf("Dependency %v: network cost %v exceeds maxNetworkCost %v for %v pod(s)", v.selector, v.cost, v.maxNetworkCost, v.pods)
+}
+
+// violationSummary : violations of a dependency on the nodes filtered out
+type violationSummary struct {
+	selector       string
+	maxNetworkCost int64
+
+	// lowest network cost of the nodes violating the dependency
+	minCost int64
+
+	// number of nodes violating the dependency
+	nodes int64
+}
+
+func (s *violationSummary) String() string {
+	return fmt.Sprintf("dependency %v (maxNetworkCost %v) violated on %v node(s), lowest network cost %v", s.selector, s.maxNetworkCost, s.nodes, s.minCost)
+}
+
+// getViolatedDependencies : get the dependencies of the pod whose maxNetworkCost is exceeded from the given node,
+// as counted by checkMaxNetworkCostRequirements
+func (no *NetworkOverhead) getViolatedDependencies(preFilterState *PreFilterState, node *corev1.Node) []dependencyViolation {
+	domains := networkawareutil.GetNodeDomains(node, no.topologyKeys)
+
+	var violations []dependencyViolation
+	for _, d := range preFilterState.dependencyHosts { // For each pod dependency
+		v := dependencyViolation{selector: d.dependency.Workload.Selector, maxNetworkCost: d.dependency.MaxNetworkCost}
+		for _, h := range d.hosts { // For each host of the pods already allocated
+			if h.hostname == node.Name {
+				continue
+			}
+			if networkawareutil.IsDomainUnknown(h.domains) { // Node has no topology domain defined
+				v.cost = max(v.cost, MaxCost)
+				v.pods += h.pods
+			} else if level := networkawareutil.GetDifferingLevel(domains, h.domains); level >= 0 { // belong to a different domain
+				cost, ok := networkawareutil.FindDomainsCost(domains, h.domains, level, no.topologyKeys, preFilterState.costMap)
+				if ok && cost > d.dependency.MaxNetworkCost {
+					v.cost = max(v.cost, cost)
+					v.pods += h.pods
 				}
 			}
 		}
+		if v.pods > 0 {
+			violations = append(violations, v)
+		}
 	}
-	return satisfied, violated, nil
+	return violations
 }
 
 // getAccumulatedCost : calculate the accumulated cost based on the Pod's dependencies
-func (no *NetworkOverhead) getAccumulatedCost(
-	scheduledList networkawareutil.ScheduledList,
-	dependencyList []agv1alpha1.DependenciesInfo,
+func getAccumulatedCost(
+	dependencies []dependencyHosts,
 	nodeName string,
-	region string,
-	zone string,
-	costMap map[networkawareutil.CostKey]int64) (int64, error) {
+	domains []string,
+	topologyKeys []string,
+	costMap map[networkawareutil.CostKey]int64) int64 {
 	// keep track of the accumulated cost
 	var cost int64 = 0
 
 	// calculate accumulated shortest path
-	for _, podAllocated := range scheduledList { // For each pod already allocated
-		for _, d := range dependencyList { // For each pod dependency
-			// If the pod allocated is not an established dependency, continue.
-			if podAllocated.Selector != d.Workload.Selector {
-				continue
-			}
-
-			if podAllocated.Hostname == nodeName { // If the Pod hostname is the node being scored
-				cost += SameHostname
-			} else { // If Nodes are not the same
-				// Get NodeInfo from pod Hostname
-				podNodeInfo, err := no.handle.SnapshotSharedLister().NodeInfos().Get(podAllocated.Hostname)
-				if err != nil {
-					no.logger.Error(err, "getting pod hostname from Snapshot", "nodeInfo", podNodeInfo)
-					return cost, err
-				}
-				// Get zone and region from Pod Hostname
-				regionPodNodeInfo := networkawareutil.GetNodeRegion(podNodeInfo.Node())
-				zonePodNodeInfo := networkawareutil.GetNodeZone(podNodeInfo.Node())
-
-				if regionPodNodeInfo == "" && zonePodNodeInfo == "" { // Node has no zone and region defined
-					cost += MaxCost
-				} else if region == regionPodNodeInfo { // If Nodes belong to the same region
-					if zone == zonePodNodeInfo { // If Nodes belong to the same zone
-						cost += SameZone
-					} else { // belong to a different zone
-						value, ok := costMap[networkawareutil.CostKey{ // Retrieve the cost from the map (origin: zone, destination: pod zoneHostname)
-							Origin:      zone, // Time Complexity: O(1)
-							Destination: zonePodNodeInfo,
-						}]
-						if ok {
-							cost += value // Add the cost to the sum
-						} else {
-							cost += MaxCost
-						}
-					}
-				} else { // belong to a different region
-					value, ok := costMap[networkawareutil.CostKey{ // Retrieve the cost from the map (origin: region, destination: pod regionHostname)
-						Origin:      region, // Time Complexity: O(1)
-						Destination: regionPodNodeInfo,
-					}]
-					if ok {
-						cost += value // Add the cost to the sum
-					} else {
-						cost += MaxCost
-					}
+	for _, d := range dependencies { // For each pod dependency
+		for _, h := range d.hosts { // For each host of the pods already allocated
+			if h.hostname == nodeName { // If the Pod hostname is the node being scored
+				cost += SameHostname * h.pods
+			} else if networkawareutil.IsDomainUnknown(h.domains) { // Node has no topology domain defined
+				cost += MaxCost * h.pods
+			} else if level := networkawareutil.GetDifferingLevel(domains, h.domains); level < 0 { // If Nodes belong to the same domains
+				cost += SameZone * h.pods
+			} else { // belong to a different domain
+				// Retrieve the cost from the map at the broadest level where domains differ. Time Complexity: O(levels)
+				if value, ok := networkawareutil.FindDomainsCost(domains, h.domains, level, topologyKeys, costMap); ok {
+					cost += value * h.pods // Add the cost to the sum
+				} else {
+					cost += MaxCost * h.pods
 				}
 			}
 		}
 	}
-	return cost, nil
+	return cost
 }
 
 func getPreFilterState(cycleState *framework.CycleState) (*PreFilterState, error) {
@@ -649,45 +809,3 @@ func getPreFilterState(cycleState *framework.CycleState) (*PreFilterState, error
 	}
 	return state, nil
 }
-
-func (no *NetworkOverhead) findAppGroupNetworkOverhead(ctx context.Context, agName string) *agv1alpha1.AppGroup {
-	no.logger.V(6).Info("Debugging namespaces", "namespaces", no.namespaces)
-	for _, namespace := range no.namespaces {
-		no.logger.V(6).Info("appGroup CR", "namespace", namespace, "name", agName)
-		// AppGroup could not be placed in several namespaces simultaneously
-		appGroup := &agv1alpha1.AppGroup{}
-		err := no.Get(ctx, client.ObjectKey{
-			Namespace: namespace,
-			Name:      agName,
-		}, appGroup)
-		if err != nil {
-			no.logger.V(4).Error(err, "Cannot get AppGroup from AppGroupNamespaceLister:")
-			continue
-		}
-		if appGroup != nil && appGroup.GetUID() != "" {
-			return appGroup
-		}
-	}
-	return nil
-}
-
-func (no *NetworkOverhead) findNetworkTopologyNetworkOverhead(ctx context.Context) *ntv1alpha1.NetworkTopology {
-	no.logger.V(6).Info("Debugging namespaces", "namespaces", no.namespaces)
-	for _, namespace := range no.namespaces {
-		no.logger.V(6).Info("networkTopology CR:", "namespace", namespace, "name", no.ntName)
-		// NetworkTopology could not be placed in several namespaces simultaneously
-		networkTopology := &ntv1alpha1.NetworkTopology{}
-		err := no.Get(ctx, client.ObjectKey{
-			Namespace: namespace,
-			Name:      no.ntName,
-		}, networkTopology)
-		if err != nil {
-			no.logger.V(4).Error(err, "Cannot get networkTopology from networkTopologyNamespaceLister:")
-			continue
-		}
-		if networkTopology != nil && networkTopology.GetUID() != "" {
-			return networkTopology
-		}
-	}
-	return nil
-}
@@ -41,8 +41,6 @@ import (
 	st "k8s.io/kubernetes/pkg/scheduler/testing"
 	tf "k8s.io/kubernetes/pkg/scheduler/testing/framework"
 
-	"sigs.k8s.io/controller-runtime/pkg/client/fake"
-
 	agv1alpha1 "github.com/diktyo-io/appgroup-api/pkg/apis/appgroup/v1alpha1"
 	ntv1alpha1 "github.com/diktyo-io/networktopology-api/pkg/apis/networktopology/v1alpha1"
 	"github.com/stretchr/testify/assert"
@@ -368,6 +366,13 @@ func BenchmarkNetworkOverheadPreFilter(b *testing.B) {
 		makePodAllocated("p10", "p10-deployment", "n-2", 0, "onlineboutique", nil, nil),
 	}
 
+	// Large AppGroup: 100 replicas of each workload spread over 5000 nodes
+	var largePods []*v1.Pod
+	for i := 0; i < 1000; i++ {
+		selector := fmt.Sprintf("p%v", i%10+1)
+		largePods = append(largePods, makePodAllocated(selector, fmt.Sprintf("%v-deployment-%v", selector, i), fmt.Sprintf("n-%v", randomInt(1, 5001)), 0, "onlineboutique", nil, nil))
+	}
+
 	tests := []struct {
 		name            string
 		nodesNum        int64
@@ -486,6 +491,19 @@ func BenchmarkNetworkOverheadPreFilter(b *testing.B) {
 			pods:            pods,
 			expected:        framework.Success,
 		},
+		{
+			name:            "AppGroup: onlineboutique, 1000 pods allocated, 5000 nodes, 1 pod to allocate",
+			nodesNum:        5000,
+			dependenciesNum: 10,
+			agName:          "onlineboutique",
+			regionNames:     regionNames,
+			zoneNames:       zoneNames,
+			appGroup:        onlineBoutiqueAppGroup,
+			networkTopology: networkTopology,
+			pod:             makePod("p1", "p1-deployment", 0, "onlineboutique", nil, nil),
+			pods:            largePods,
+			expected:        framework.Success,
+		},
 	}
 
 	for _, tt := range tests {
@@ -502,25 +520,12 @@ func BenchmarkNetworkOverheadPreFilter(b *testing.B) {
 
 			cs := testClientSet.NewSimpleClientset()
 			informerFactory := informers.NewSharedInformerFactory(cs, 0)
-			builder := fake.NewClientBuilder().
-				WithScheme(s).
-				WithObjects(tt.appGroup, tt.networkTopology).
-				WithStatusSubresource(&agv1alpha1.AppGroup{}).
-				WithStatusSubresource(&ntv1alpha1.NetworkTopology{})
-			for _, p := range tt.pods {
-				builder.WithObjects(p.DeepCopy())
-			}
-			client := builder.Build()
-
 			// create plugin
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 
 			snapshot := newTestSharedLister(nil, nodes)
 
-			podInformer := informerFactory.Core().V1().Pods()
-			podLister := podInformer.Lister()
-
 			informerFactory.Start(ctx.Done())
 
 			for _, p := range tt.pods {
@@ -539,12 +544,12 @@ func BenchmarkNetworkOverheadPreFilter(b *testing.B) {
 				schedruntime.WithInformerFactory(informerFactory), schedruntime.WithSnapshotSharedLister(snapshot))
 
 			pl := &NetworkOverhead{
-				Client:      client,
-				podLister:   podLister,
-				handle:      fh,
-				namespaces:  []string{"default"},
-				weightsName: "UserDefined",
-				ntName:      "nt-test",
+				index:        newTestIndex(tt.appGroup, tt.networkTopology, tt.pods),
+				handle:       fh,
+				namespaces:   []string{"default"},
+				weightsName:  "UserDefined",
+				ntName:       "nt-test",
+				topologyKeys: []string{v1.LabelTopologyRegion, v1.LabelTopologyZone},
 			}
 
 			state := framework.NewCycleState()
@@ -725,22 +730,9 @@ func TestNetworkOverheadScore(t *testing.T) {
 
 			ctx := context.Background()
 			cs := testClientSet.NewSimpleClientset()
-			builder := fake.NewClientBuilder().
-				WithScheme(s).
-				WithObjects(tt.appGroup, tt.networkTopology).
-				WithStatusSubresource(&agv1alpha1.AppGroup{}).
-				WithStatusSubresource(&ntv1alpha1.NetworkTopology{})
-			for _, p := range tt.pods {
-				builder.WithObjects(p.DeepCopy())
-			}
-			client := builder.Build()
-
 			informerFactory := informers.NewSharedInformerFactory(cs, 0)
 			snapshot := newTestSharedLister(nil, tt.nodes)
 
-			podInformer := informerFactory.Core().V1().Pods()
-			podLister := podInformer.Lister()
-
 			informerFactory.Start(ctx.Done())
 
 			for _, p := range tt.pods {
@@ -759,12 +751,12 @@ func TestNetworkOverheadScore(t *testing.T) {
 				schedruntime.WithInformerFactory(informerFactory), schedruntime.WithSnapshotSharedLister(snapshot))
 
 			pl := &NetworkOverhead{
-				Client:      client,
-				podLister:   podLister,
-				handle:      fh,
-				namespaces:  []string{"default"},
-				weightsName: "UserDefined",
-				ntName:      "nt-test",
+				index:        newTestIndex(tt.appGroup, tt.networkTopology, tt.pods),
+				handle:       fh,
+				namespaces:   []string{"default"},
+				weightsName:  "UserDefined",
+				ntName:       "nt-test",
+				topologyKeys: []string{v1.LabelTopologyRegion, v1.LabelTopologyZone},
 			}
 
 			// Wait for the pods to be scheduled.
@@ -972,22 +964,9 @@ func BenchmarkNetworkOverheadScore(b *testing.B) {
 			// create plugin
 			ctx := context.Background()
 			cs := testClientSet.NewSimpleClientset()
-			builder := fake.NewClientBuilder().
-				WithScheme(s).
-				WithStatusSubresource(&agv1alpha1.AppGroup{}).
-				WithStatusSubresource(&ntv1alpha1.NetworkTopology{}).
-				WithObjects(tt.appGroup, tt.networkTopology)
-			for _, p := range tt.pods {
-				builder.WithObjects(p.DeepCopy())
-			}
-			client := builder.Build()
-
 			informerFactory := informers.NewSharedInformerFactory(cs, 0)
 
 			snapshot := newTestSharedLister(nil, nodes)
-			podInformer := informerFactory.Core().V1().Pods()
-			podLister := podInformer.Lister()
-
 			informerFactory.Start(ctx.Done())
 
 			for _, p := range tt.pods {
@@ -1006,12 +985,12 @@ func BenchmarkNetworkOverheadScore(b *testing.B) {
 				schedruntime.WithInformerFactory(informerFactory), schedruntime.WithSnapshotSharedLister(snapshot))
 
 			pl := &NetworkOverhead{
-				Client:      client,
-				podLister:   podLister,
-				handle:      fh,
-				namespaces:  []string{"default"},
-				weightsName: "UserDefined",
-				ntName:      "nt-test",
+				index:        newTestIndex(tt.appGroup, tt.networkTopology, tt.pods),
+				handle:       fh,
+				namespaces:   []string{"default"},
+				weightsName:  "UserDefined",
+				ntName:       "nt-test",
+				topologyKeys: []string{v1.LabelTopologyRegion, v1.LabelTopologyZone},
 			}
 
 			state := framework.NewCycleState()
@@ -1100,7 +1079,7 @@ func TestNetworkOverheadFilter(t *testing.T) {
 			networkTopology: networkTopology,
 			pod:             makePod("p1", "p1-deployment", 0, "basic", nil, nil),
 			nodes:           nodes,
-			wantStatus:      framework.NewStatus(framework.Unschedulable, "Node n-1 does not meet several network requirements from Workload dependencies: Satisfied: 0 Violated: 1"),
+			wantStatus:      framework.NewStatus(framework.Unschedulable, "Node n-1 does not meet several network requirements from Workload dependencies: Satisfied: 0 Violated: 1", "Dependency p2: network cost 20 exceeds maxNetworkCost 0 for 1 pod(s)"),
 			nodeToFilter:    nodes[0],
 			pods:            pods,
 			expected:        framework.Success,
@@ -1124,7 +1103,7 @@ func TestNetworkOverheadFilter(t *testing.T) {
 			networkTopology: networkTopology,
 			pod:             makePod("p2", "p2-deployment", 0, "basic", nil, nil),
 			nodes:           nodes,
-			wantStatus:      framework.NewStatus(framework.Unschedulable, "Node n-5 does not meet several network requirements from Workload dependencies: Satisfied: 0 Violated: 1"),
+			wantStatus:      framework.NewStatus(framework.Unschedulable, "Node n-5 does not meet several network requirements from Workload dependencies: Satisfied: 0 Violated: 1", "Dependency p3: network cost 10 exceeds maxNetworkCost 0 for 1 pod(s)"),
 			nodeToFilter:    nodes[4],
 			pods:            pods,
 			expected:        framework.Success,
@@ -1172,7 +1151,7 @@ func TestNetworkOverheadFilter(t *testing.T) {
 			networkTopology: networkTopology,
 			pod:             makePod("p1", "p1-deployment", 0, "basic", nil, nil),
 			nodes:           nodes,
-			wantStatus:      framework.NewStatus(framework.Unschedulable, "Node n-1 does not meet several network requirements from Workload dependencies: Satisfied: 0 Violated: 1"),
+			wantStatus:      framework.NewStatus(framework.Unschedulable, "Node n-1 does not meet several network requirements from Workload dependencies: Satisfied: 0 Violated: 1", "Dependency p2: network cost 20 exceeds maxNetworkCost 0 for 1 pod(s)"),
 			nodeToFilter:    nodes[0],
 			pods:            pods,
 			expected:        framework.Success,
@@ -1200,23 +1179,10 @@ func TestNetworkOverheadFilter(t *testing.T) {
 			ctx := context.Background()
 			cs := testClientSet.NewSimpleClientset()
 
-			builder := fake.NewClientBuilder().
-				WithScheme(s).
-				WithStatusSubresource(&agv1alpha1.AppGroup{}).
-				WithStatusSubresource(&ntv1alpha1.NetworkTopology{}).
-				WithObjects(tt.appGroup, tt.networkTopology)
-			for _, p := range tt.pods {
-				builder.WithObjects(p.DeepCopy())
-			}
-			client := builder.Build()
-
 			informerFactory := informers.NewSharedInformerFactory(cs, 0)
 
 			snapshot := newTestSharedLister(nil, nodes)
 
-			podInformer := informerFactory.Core().V1().Pods()
-			podLister := podInformer.Lister()
-
 			informerFactory.Start(ctx.Done())
 
 			for _, p := range tt.pods {
@@ -1237,12 +1203,12 @@ func TestNetworkOverheadFilter(t *testing.T) {
 				schedruntime.WithSnapshotSharedLister(snapshot))
 
 			pl := &NetworkOverhead{
-				Client:      client,
-				podLister:   podLister,
-				handle:      fh,
-				namespaces:  []string{"default"},
-				weightsName: "UserDefined",
-				ntName:      "nt-test",
+				index:        newTestIndex(tt.appGroup, tt.networkTopology, tt.pods),
+				handle:       fh,
+				namespaces:   []string{"default"},
+				weightsName:  "UserDefined",
+				ntName:       "nt-test",
+				topologyKeys: []string{v1.LabelTopologyRegion, v1.LabelTopologyZone},
 			}
 
 			// Wait for the pods to be scheduled.
@@ -1270,6 +1236,158 @@ func TestNetworkOverheadFilter(t *testing.T) {
 	}
 }
 
+func TestNetworkOverheadTopologyKeys(t *testing.T) {
+	rackKey := "example.com/rack"
+
+	// Get AppGroup CRD: basic
+	basicAppGroup := GetAppGroupCRBasic()
+	basicAppGroup.Spec.Workloads[0].Dependencies[0].MaxNetworkCost = 5
+
+	// Get Network Topology CR with zone and rack costs
+	networkTopology := &ntv1alpha1.NetworkTopology{
+		ObjectMeta: metav1.ObjectMeta{Name: "nt-test", Namespace: "default", UID: types.UID("fake-uid")},
+		Spec: ntv1alpha1.NetworkTopologySpec{
+			Weights: ntv1alpha1.WeightList{
+				ntv1alpha1.WeightInfo{Name: "UserDefined",
+					TopologyList: ntv1alpha1.TopologyList{
+						ntv1alpha1.TopologyInfo{
+							TopologyKey: ntv1alpha1.TopologyKey(rackKey),
+							OriginList: ntv1alpha1.OriginList{
+								ntv1alpha1.OriginInfo{Origin: "rack-a", CostList: []ntv1alpha1.CostInfo{{Destination: "rack-b", NetworkCost: 3}}},
+								ntv1alpha1.OriginInfo{Origin: "rack-b", CostList: []ntv1alpha1.CostInfo{{Destination: "rack-a", NetworkCost: 3}}},
+								ntv1alpha1.OriginInfo{Origin: "rack-d", CostList: []ntv1alpha1.CostInfo{{Destination: "rack-a", NetworkCost: 8}}},
+								// Racks named after zones do not define the costs of the zones
+								ntv1alpha1.OriginInfo{Origin: "Z3", CostList: []ntv1alpha1.CostInfo{{Destination: "Z1", NetworkCost: 1}}},
+							}},
+						ntv1alpha1.TopologyInfo{
+							TopologyKey: ntv1alpha1.NetworkTopologyZone,
+							OriginList: ntv1alpha1.OriginList{
+								ntv1alpha1.OriginInfo{Origin: "Z2", CostList: []ntv1alpha1.CostInfo{{Destination: "Z1", NetworkCost: 5}}},
+								// Costs between the paths of zones only apply to the zones of the same names in their region
+								ntv1alpha1.OriginInfo{Origin: "R2/Z2", CostList: []ntv1alpha1.CostInfo{{Destination: "R2/Z1", NetworkCost: 1}}},
+							}},
+					},
+				},
+			},
+		},
+	}
+
+	// Create Pods
+	pods := []*v1.Pod{
+		makePodAllocated("p2", "p2-deployment", "n-1", 0, "basic", nil, nil),
+	}
+
+	// Create Nodes
+	nodes := []*v1.Node{
+		st.MakeNode().Name("n-1").Label(v1.LabelTopologyRegion, "R1").Label(v1.LabelTopologyZone, "Z1").Label(rackKey, "rack-a").Obj(),
+		st.MakeNode().Name("n-2").Label(v1.LabelTopologyRegion, "R1").Label(v1.LabelTopologyZone, "Z1").Label(rackKey, "rack-a").Obj(),
+		st.MakeNode().Name("n-3").Label(v1.LabelTopologyRegion, "R1").Label(v1.LabelTopologyZone, "Z1").Label(rackKey, "rack-b").Obj(),
+		st.MakeNode().Name("n-4").Label(v1.LabelTopologyRegion, "R1").Label(v1.LabelTopologyZone, "Z2").Label(rackKey, "rack-c").Obj(),
+		st.MakeNode().Name("n-5").Label(v1.LabelTopologyRegion, "R1").Label(v1.LabelTopologyZone, "Z3").Label(rackKey, "rack-d").Obj(),
+		st.MakeNode().Name("n-6").Label(v1.LabelTopologyRegion, "R1").Label(v1.LabelTopologyZone, "Z3").Label(rackKey, "rack-e").Obj(),
+	}
+
+	pl := newTestPlugin(t, basicAppGroup, networkTopology, pods, nodes)
+	pl.topologyKeys = []string{v1.LabelTopologyRegion, v1.LabelTopologyZone, rackKey}
+	pl.index = newNetworkIndex(pl.namespaces, pl.ntName, pl.weightsName, pl.topologyKeys)
+	pl.index.addAppGroup(basicAppGroup)
+	pl.index.addNetworkTopology(networkTopology)
+	pl.index.addPod(pods[0])
+
+	state := framework.NewCycleState()
+	pod := makePod("p1", "p1-deployment", 0, "basic", nil, nil)
+	if _, got := pl.PreFilter(context.TODO(), state, pod); !got.IsSuccess() {
+		t.Fatalf("expected success, got %v : %v", got.Code(), got.Message())
+	}
+
+	tests := []struct {
+		node       *v1.Node
+		wantCost   int64
+		wantFilter framework.Code
+	}{
+		{node: nodes[0], wantCost: SameHostname, wantFilter: framework.Success}, // same node
+		{node: nodes[1], wantCost: SameZone, wantFilter: framework.Success},     // same rack
+		{node: nodes[2], wantCost: 3, wantFilter: framework.Success},            // rack cost
+		{node: nodes[3], wantCost: 5, wantFilter: framework.Success},            // zone cost
+		{node: nodes[4], wantCost: 8, wantFilter: framework.Unschedulable},      // zone cost undefined, falls through to rack cost
+		{node: nodes[5], wantCost: MaxCost, wantFilter: framework.Success},      // no cost defined at any level
+	}
+	for _, tt := range tests {
+		t.Run(tt.node.Name, func(t *testing.T) {
+			gotCost, status := pl.Score(context.TODO(), state, pod, tt.node.Name)
+			if !status.IsSuccess() {
+				t.Fatalf("expected success, got %v : %v", status.Code(), status.Message())
+			}
+			if gotCost != tt.wantCost {
+				t.Errorf("expected cost %v, got %v", tt.wantCost, gotCost)
+			}
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(tt.node)
+			if got := pl.Filter(context.TODO(), state, pod, nodeInfo); got.Code() != tt.wantFilter {
+				t.Errorf("expected filter %v, got %v", tt.wantFilter, got)
+			}
+		})
+	}
+}
+
+func TestNetworkOverheadViolations(t *testing.T) {
+	basicAppGroup := GetAppGroupCRBasic()
+	basicAppGroup.Spec.Workloads[0].Dependencies[0].MaxNetworkCost = 5
+
+	pods := []*v1.Pod{
+		makePodAllocated("p2", "p2-deployment-1", "n-1", 0, "basic", nil, nil),
+		makePodAllocated("p2", "p2-deployment-2", "n-1", 0, "basic", nil, nil),
+	}
+	nodes := []*v1.Node{
+		st.MakeNode().Name("n-1").Label(v1.LabelTopologyRegion, "us-west-1").Label(v1.LabelTopologyZone, "Z1").Obj(),
+		st.MakeNode().Name("n-2").Label(v1.LabelTopologyRegion, "us-west-1").Label(v1.LabelTopologyZone, "Z2").Obj(),
+		st.MakeNode().Name("n-3").Label(v1.LabelTopologyRegion, "us-east-1").Label(v1.LabelTopologyZone, "Z3").Obj(),
+		st.MakeNode().Name("n-4").Label(v1.LabelTopologyRegion, "us-east-1").Label(v1.LabelTopologyZone, "Z4").Obj(),
+	}
+	pl := newTestPlugin(t, basicAppGroup, GetNetworkTopologyCRBasic(), pods, nodes)
+
+	state := framework.NewCycleState()
+	pod := makePod("p1", "p1-deployment", 0, "basic", nil, nil)
+	if _, got := pl.PreFilter(context.TODO(), state, pod); !got.IsSuccess() {
+		t.Fatalf("expected success, got %v : %v", got.Code(), got.Message())
+	}
+
+	// The Filter status lists the violated dependencies
+	wantReasons := map[string][]string{
+		"n-3": {
+			"Node n-3 does not meet several network requirements from Workload dependencies: Satisfied: 0 Violated: 2",
+			"Dependency p2: network cost 20 exceeds maxNetworkCost 5 for 2 pod(s)",
+		},
+		"n-4": {
+			"Node n-4 does not meet several network requirements from Workload dependencies: Satisfied: 0 Violated: 2",
+			"Dependency p2: network cost 20 exceeds maxNetworkCost 5 for 2 pod(s)",
+		},
+	}
+	nodeToStatus := framework.NewDefaultNodeToStatus()
+	for _, n := range nodes {
+		nodeInfo := framework.NewNodeInfo()
+		nodeInfo.SetNode(n)
+		status := pl.Filter(context.TODO(), state, pod, nodeInfo)
+		if want, ok := wantReasons[n.Name]; !ok {
+			if !status.IsSuccess() {
+				t.Errorf("node %v: expected success, got %v", n.Name, status)
+			}
+		} else if status.Code() != framework.Unschedulable || !reflect.DeepEqual(status.Reasons(), want) {
+			t.Errorf("node %v: expected reasons %q, got %v : %q", n.Name, want, status.Code(), status.Reasons())
+		} else {
+			nodeToStatus.Set(n.Name, status.WithPlugin(Name))
+		}
+	}
+
+	// PostFilter aggregates the violations of the nodes
+	_, status := pl.PostFilter(context.TODO(), state, pod, nodeToStatus)
+	want := "NetworkOverhead: dependency p2 (maxNetworkCost 5) violated on 2 node(s), lowest network cost 20"
+	if status.Code() != framework.Unschedulable || status.Message() != want {
+		t.Errorf("expected %q, got %v : %q", want, status.Code(), status.Message())
+	}
+}
+
 func BenchmarkNetworkOverheadFilter(b *testing.B) {
 	// Get AppGroup CRD: onlineboutique
 	onlineBoutiqueAppGroup := GetAppGroupCROnlineBoutique()
@@ -1426,23 +1544,10 @@ func BenchmarkNetworkOverheadFilter(b *testing.B) {
 			// create plugin
 			ctx := context.Background()
 			cs := testClientSet.NewSimpleClientset()
-			builder := fake.NewClientBuilder().
-				WithScheme(s).
-				WithStatusSubresource(&agv1alpha1.AppGroup{}).
-				WithStatusSubresource(&ntv1alpha1.NetworkTopology{}).
-				WithObjects(tt.appGroup, tt.networkTopology)
-			for _, p := range tt.pods {
-				builder.WithObjects(p.DeepCopy())
-			}
-			client := builder.Build()
-
 			informerFactory := informers.NewSharedInformerFactory(cs, 0)
 
 			snapshot := newTestSharedLister(nil, nodes)
 
-			podInformer := informerFactory.Core().V1().Pods()
-			podLister := podInformer.Lister()
-
 			informerFactory.Start(ctx.Done())
 
 			for _, p := range tt.pods {
@@ -1463,12 +1568,12 @@ func BenchmarkNetworkOverheadFilter(b *testing.B) {
 				schedruntime.WithSnapshotSharedLister(snapshot))
 
 			pl := &NetworkOverhead{
-				Client:      client,
-				podLister:   podLister,
-				handle:      fh,
-				namespaces:  []string{"default"},
-				weightsName: "UserDefined",
-				ntName:      "nt-test",
+				index:        newTestIndex(tt.appGroup, tt.networkTopology, tt.pods),
+				handle:       fh,
+				namespaces:   []string{"default"},
+				weightsName:  "UserDefined",
+				ntName:       "nt-test",
+				topologyKeys: []string{v1.LabelTopologyRegion, v1.LabelTopologyZone},
 			}
 
 			// Wait for the pods to be scheduled.
@@ -1619,3 +1724,51 @@ func makePodAllocated(selector string, podName string, hostname string, priority
 		},
 	}
 }
+
+// newTestPlugin : create a NetworkOverhead plugin with the given CRs, pods and nodes in its index and listers,
+// considering region and zone topology levels
+func newTestPlugin(t *testing.T, appGroup *agv1alpha1.AppGroup, networkTopology *ntv1alpha1.NetworkTopology, pods []*v1.Pod, nodes []*v1.Node) *NetworkOverhead {
+	ctx := context.Background()
+	cs := testClientSet.NewSimpleClientset()
+
+	informerFactory := informers.NewSharedInformerFactory(cs, 0)
+	nodeInformer := informerFactory.Core().V1().Nodes()
+	for _, n := range nodes {
+		if err := nodeInformer.Informer().GetStore().Add(n); err != nil {
+			t.Fatalf("Failed to add Node %q: %v", n.Name, err)
+		}
+	}
+
+	registeredPlugins := []tf.RegisterPluginFunc{
+		tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+	}
+	fh, err := tf.NewFramework(ctx, registeredPlugins, "default-scheduler",
+		schedruntime.WithClientSet(cs),
+		schedruntime.WithInformerFactory(informerFactory),
+		schedruntime.WithSnapshotSharedLister(newTestSharedLister(nil, nodes)))
+	if err != nil {
+		t.Fatalf("Failed to create framework: %v", err)
+	}
+
+	return &NetworkOverhead{
+		nodeLister:   nodeInformer.Lister(),
+		handle:       fh,
+		index:        newTestIndex(appGroup, networkTopology, pods),
+		namespaces:   []string{"default"},
+		weightsName:  "UserDefined",
+		ntName:       "nt-test",
+		topologyKeys: []string{v1.LabelTopologyRegion, v1.LabelTopologyZone},
+	}
+}
+
+// newTestIndex : create an index of the given CRs and pods, considering region and zone topology levels
+func newTestIndex(appGroup *agv1alpha1.AppGroup, networkTopology *ntv1alpha1.NetworkTopology, pods []*v1.Pod) *networkIndex {
+	index := newNetworkIndex([]string{"default"}, "nt-test", "UserDefined", []string{v1.LabelTopologyRegion, v1.LabelTopologyZone})
+	index.addAppGroup(appGroup)
+	index.addNetworkTopology(networkTopology)
+	for _, p := range pods {
+		index.addPod(p)
+	}
+	return index
+}
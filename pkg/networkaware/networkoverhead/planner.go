@@ -0,0 +1,436 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkoverhead
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	resourcehelper "k8s.io/component-helpers/resource"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	networkawareutil "sigs.k8s.io/scheduler-plugins/pkg/networkaware/util"
+
+	agv1alpha1 "github.com/diktyo-io/appgroup-api/pkg/apis/appgroup/v1alpha1"
+)
+
+const (
+	// maxPlanRefinements : maximum number of passes moving workloads between domains to lower the plan cost
+	maxPlanRefinements = 10
+)
+
+// placementPlanner : placement plans of the AppGroups. A plan assigns every workload of an AppGroup to the narrowest
+// topology domains, so that the pods of dependent workloads are placed close to each other from the first one on.
+type placementPlanner struct {
+	sync.Mutex
+
+	// topology keys with network costs, from the broadest to the narrowest level
+	topologyKeys []string
+
+	// plans by AppGroup UID
+	plans map[types.UID]*placementPlan
+}
+
+// placementPlan : topology domains planned for the workloads of an AppGroup
+type placementPlan struct {
+	// generation of the AppGroup the plan was computed for
+	generation int64
+
+	// topology domains of each workload by selector, joined with "/"
+	domains map[string]string
+
+	// number of pods bound out of the planned domains of their workload when the plan was computed
+	drifted int64
+}
+
+// planDomain : nodes of the same topology domains, with their free resources
+type planDomain struct {
+	key      string
+	domains  []string
+	milliCPU int64
+	memory   int64
+}
+
+// planRequest : resources requested by the pods of a workload not bound yet
+type planRequest struct {
+	milliCPU int64
+	memory   int64
+}
+
+// newPlacementPlanner : create a planner without plans
+func newPlacementPlanner(topologyKeys []string) *placementPlanner {
+	return &placementPlanner{
+		topologyKeys: topologyKeys,
+		plans:        make(map[types.UID]*placementPlan),
+	}
+}
+
+// getPlannedDomain : return the topology domains planned for the workload of the pod, joined with "/". The plan of
+// the AppGroup is computed when its first pod is scheduled, and computed again when the AppGroup is updated, when a
+// planned domain has no nodes anymore, or when pods drift away from the plan, i.e. more pods than when the plan was
+// computed are bound out of their planned domains. Returns an empty string if no domain is planned for the pod.
+func (p *placementPlanner) getPlannedDomain(
+	logger klog.Logger,
+	appGroup *agv1alpha1.AppGroup,
+	pod *corev1.Pod,
+	workloadHosts map[string]map[string]int,
+	nodeList []*framework.NodeInfo,
+	costMap map[networkawareutil.CostKey]int64) string {
+	domains, hostDomains := p.getPlanDomains(nodeList)
+	if len(domains) == 0 {
+		return ""
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	plan := p.plans[appGroup.UID]
+	reason := ""
+	switch {
+	case plan == nil:
+		reason = "first pod"
+	case plan.generation != appGroup.Generation:
+		reason = "AppGroup updated"
+	case !plan.hasDomains(domains):
+		reason = "planned domain not found"
+	case getDriftedPods(plan.domains, workloadHosts, hostDomains) > plan.drifted:
+		reason = "pods drifted from the plan"
+	}
+
+	if reason != "" {
+		plannedDomains := computePlacementPlan(appGroup.Spec.Workloads, domains,
+			getPinnedDomains(workloadHosts, hostDomains, domains),
+			getPlanRequests(appGroup, pod, workloadHosts),
+			p.topologyKeys, costMap)
+		plan = &placementPlan{
+			generation: appGroup.Generation,
+			domains:    plannedDomains,
+			drifted:    getDriftedPods(plannedDomains, workloadHosts, hostDomains),
+		}
+		p.plans[appGroup.UID] = plan
+		logger.V(4).Info("Placement plan computed", "appGroup", klog.KObj(appGroup), "reason", reason, "domains", plan.domains)
+	}
+	return plan.domains[networkawareutil.GetPodAppGroupSelector(pod)]
+}
+
+// deletePlan : delete the plan of a deleted AppGroup
+func (p *placementPlanner) deletePlan(obj interface{}) {
+	var appGroup *agv1alpha1.AppGroup
+	switch t := obj.(type) {
+	case *agv1alpha1.AppGroup:
+		appGroup = t
+	case cache.DeletedFinalStateUnknown:
+		var ok bool
+		if appGroup, ok = t.Obj.(*agv1alpha1.AppGroup); !ok {
+			return
+		}
+	default:
+		return
+	}
+
+	p.Lock()
+	defer p.Unlock()
+	delete(p.plans, appGroup.UID)
+}
+
+// appGroupEventHandler : drop the plans of the deleted AppGroups
+func (p *placementPlanner) appGroupEventHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		DeleteFunc: p.deletePlan,
+	}
+}
+
+// hasDomains : check that the planned domains have nodes
+func (plan *placementPlan) hasDomains(domains []*planDomain) bool {
+	keys := make(map[string]bool, len(domains))
+	for _, d := range domains {
+		keys[d.key] = true
+	}
+	for _, key := range plan.domains {
+		if !keys[key] {
+			return false
+		}
+	}
+	return true
+}
+
+// getPlanDomains : get the nodes of the same topology domains with their free resources, sorted by key, and the
+// topology domains of each node. Nodes with unknown topology domains are not planned for.
+func (p *placementPlanner) getPlanDomains(nodeList []*framework.NodeInfo) ([]*planDomain, map[string]string) {
+	domainsByKey := make(map[string]*planDomain)
+	hostDomains := make(map[string]string, len(nodeList))
+	for _, nodeInfo := range nodeList {
+		node := nodeInfo.Node()
+		if node == nil {
+			continue
+		}
+		nodeDomains := networkawareutil.GetNodeDomains(node, p.topologyKeys)
+		if networkawareutil.IsDomainUnknown(nodeDomains) {
+			continue
+		}
+		key := strings.Join(nodeDomains, "/")
+		hostDomains[node.Name] = key
+
+		d, ok := domainsByKey[key]
+		if !ok {
+			d = &planDomain{key: key, domains: nodeDomains}
+			domainsByKey[key] = d
+		}
+		d.milliCPU += max(nodeInfo.Allocatable.MilliCPU-nodeInfo.Requested.MilliCPU, 0)
+		d.memory += max(nodeInfo.Allocatable.Memory-nodeInfo.Requested.Memory, 0)
+	}
+
+	domains := make([]*planDomain, 0, len(domainsByKey))
+	for _, d := range domainsByKey {
+		domains = append(domains, d)
+	}
+	sort.Slice(domains, func(i, j int) bool { return domains[i].key < domains[j].key })
+	return domains, hostDomains
+}
+
+// getDriftedPods : get the number of pods bound out of the planned domains of their workload
+func getDriftedPods(plannedDomains map[string]string, workloadHosts map[string]map[string]int, hostDomains map[string]string) int64 {
+	var drifted int64
+	for selector, hosts := range workloadHosts {
+		planned, ok := plannedDomains[selector]
+		if !ok {
+			continue
+		}
+		for hostname, pods := range hosts {
+			if hostDomains[hostname] != planned {
+				drifted += int64(pods)
+			}
+		}
+	}
+	return drifted
+}
+
+// getPinnedDomains : get the domains of the workloads with pods already bound, i.e. the domains hosting most of
+// their pods. Plans keep these workloads in place.
+func getPinnedDomains(workloadHosts map[string]map[string]int, hostDomains map[string]string, domains []*planDomain) map[string]int {
+	index := make(map[string]int, len(domains))
+	for i, d := range domains {
+		index[d.key] = i
+	}
+
+	pinned := make(map[string]int)
+	for selector, hosts := range workloadHosts {
+		pods := make(map[int]int)
+		for hostname, n := range hosts {
+			if i, ok := index[hostDomains[hostname]]; ok {
+				pods[i] += n
+			}
+		}
+		best := -1
+		for i, n := range pods {
+			if best < 0 || n > pods[best] || (n == pods[best] && i < best) {
+				best = i
+			}
+		}
+		if best >= 0 {
+			pinned[selector] = best
+		}
+	}
+	return pinned
+}
+
+// getPlanRequests : estimate the resources requested by the pods of each workload not bound yet. AppGroups do not
+// define the number of pods of each workload, so their members are assumed to be evenly spread over the workloads,
+// and to request as much as the pod being scheduled.
+func getPlanRequests(appGroup *agv1alpha1.AppGroup, pod *corev1.Pod, workloadHosts map[string]map[string]int) map[string]planRequest {
+	workloads := len(appGroup.Spec.Workloads)
+	if workloads == 0 {
+		return nil
+	}
+	replicas := max((int(appGroup.Spec.NumMembers)+workloads-1)/workloads, 1)
+
+	podRequests := resourcehelper.PodRequests(pod, resourcehelper.PodResourcesOptions{})
+	requests := make(map[string]planRequest, workloads)
+	for _, w := range appGroup.Spec.Workloads {
+		bound := 0
+		for _, n := range workloadHosts[w.Workload.Selector] {
+			bound += n
+		}
+		pending := int64(max(replicas-bound, 0))
+		if w.Workload.Selector == networkawareutil.GetPodAppGroupSelector(pod) {
+			pending = max(pending, 1)
+		}
+		requests[w.Workload.Selector] = planRequest{
+			milliCPU: pending * podRequests.Cpu().MilliValue(),
+			memory:   pending * podRequests.Memory().Value(),
+		}
+	}
+	return requests
+}
+
+// computePlacementPlan : assign the workloads of an AppGroup to topology domains, minimizing the total cost of their
+// dependencies under the free resources of the domains. The heuristic is a greedy graph partition: workloads are
+// assigned from the most connected one to the domain of lowest cost to the workloads assigned so far, preferring the
+// domain with the most free CPU on ties. The assignment is then refined by moving single workloads to other domains
+// as long as the total cost decreases. Pinned workloads are not moved.
+func computePlacementPlan(
+	workloads agv1alpha1.AppGroupWorkloadList,
+	domains []*planDomain,
+	pinned map[string]int,
+	requests map[string]planRequest,
+	topologyKeys []string,
+	costMap map[networkawareutil.CostKey]int64) map[string]string {
+	if len(domains) == 0 {
+		return nil
+	}
+
+	// Dependency graph between the workloads, regardless of the direction of the dependencies
+	weights := make(map[string]map[string]int64, len(workloads))
+	selectors := make([]string, 0, len(workloads))
+	for _, w := range workloads {
+		weights[w.Workload.Selector] = make(map[string]int64)
+		selectors = append(selectors, w.Workload.Selector)
+	}
+	for _, w := range workloads {
+		for _, d := range w.Dependencies {
+			if _, ok := weights[d.Workload.Selector]; !ok || d.Workload.Selector == w.Workload.Selector {
+				continue
+			}
+			weights[w.Workload.Selector][d.Workload.Selector]++
+			weights[d.Workload.Selector][w.Workload.Selector]++
+		}
+	}
+	degree := func(selector string) int64 {
+		var sum int64
+		for _, w := range weights[selector] {
+			sum += w
+		}
+		return sum
+	}
+	sort.SliceStable(selectors, func(i, j int) bool { return degree(selectors[i]) > degree(selectors[j]) })
+
+	// Costs between every pair of domains
+	costs := make([][]int64, len(domains))
+	for i := range domains {
+		costs[i] = make([]int64, len(domains))
+		for j := range domains {
+			costs[i][j] = getDomainsCost(domains[i], domains[j], topologyKeys, costMap)
+		}
+	}
+
+	free := make([]planRequest, len(domains))
+	for i, d := range domains {
+		free[i] = planRequest{milliCPU: d.milliCPU, memory: d.memory}
+	}
+	fits := func(i int, r planRequest) bool {
+		return free[i].milliCPU >= r.milliCPU && free[i].memory >= r.memory
+	}
+	move := func(r planRequest, from, to int) {
+		if from >= 0 {
+			free[from].milliCPU += r.milliCPU
+			free[from].memory += r.memory
+		}
+		free[to].milliCPU -= r.milliCPU
+		free[to].memory -= r.memory
+	}
+
+	assigned := make(map[string]int, len(selectors))
+	connectionCost := func(selector string, i int) int64 {
+		var cost int64
+		for neighbor, w := range weights[selector] {
+			if j, ok := assigned[neighbor]; ok {
+				cost += w * costs[i][j]
+			}
+		}
+		return cost
+	}
+
+	for _, selector := range selectors {
+		if i, ok := pinned[selector]; ok {
+			assigned[selector] = i
+			move(requests[selector], -1, i)
+		}
+	}
+	for _, selector := range selectors {
+		if _, ok := assigned[selector]; ok {
+			continue
+		}
+		best, bestCost := -1, int64(0)
+		for i := range domains {
+			if !fits(i, requests[selector]) {
+				continue
+			}
+			cost := connectionCost(selector, i)
+			if best < 0 || cost < bestCost || (cost == bestCost && free[i].milliCPU > free[best].milliCPU) {
+				best, bestCost = i, cost
+			}
+		}
+		if best < 0 { // No domain fits: best effort on the domain with the most free CPU
+			best = 0
+			for i := range domains {
+				if free[i].milliCPU > free[best].milliCPU {
+					best = i
+				}
+			}
+		}
+		assigned[selector] = best
+		move(requests[selector], -1, best)
+	}
+
+	for pass := 0; pass < maxPlanRefinements; pass++ {
+		improved := false
+		for _, selector := range selectors {
+			if _, ok := pinned[selector]; ok {
+				continue
+			}
+			current := assigned[selector]
+			currentCost := connectionCost(selector, current)
+			for i := range domains {
+				if i == current || !fits(i, requests[selector]) {
+					continue
+				}
+				if cost := connectionCost(selector, i); cost < currentCost {
+					move(requests[selector], current, i)
+					assigned[selector] = i
+					current, currentCost = i, cost
+					improved = true
+				}
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+
+	plan := make(map[string]string, len(assigned))
+	for selector, i := range assigned {
+		plan[selector] = domains[i].key
+	}
+	return plan
+}
+
+// getDomainsCost : get the cost between two domains, as the accumulated cost of a dependency pod
+func getDomainsCost(origin *planDomain, destination *planDomain, topologyKeys []string, costMap map[networkawareutil.CostKey]int64) int64 {
+	level := networkawareutil.GetDifferingLevel(origin.domains, destination.domains)
+	if level < 0 {
+		return SameZone
+	}
+	if cost, ok := networkawareutil.FindDomainsCost(origin.domains, destination.domains, level, topologyKeys, costMap); ok {
+		return cost
+	}
+	return MaxCost
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkoverhead
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+
+	networkawareutil "sigs.k8s.io/scheduler-plugins/pkg/networkaware/util"
+)
+
+var planCostMap = map[networkawareutil.CostKey]int64{
+	{TopologyKey: v1.LabelTopologyZone, Origin: "Z1", Destination: "Z2"}:   5,
+	{TopologyKey: v1.LabelTopologyZone, Origin: "Z2", Destination: "Z1"}:   5,
+	{TopologyKey: v1.LabelTopologyRegion, Origin: "R1", Destination: "R2"}: 20,
+	{TopologyKey: v1.LabelTopologyRegion, Origin: "R2", Destination: "R1"}: 20,
+}
+
+func makePlanNodes(cpus ...string) []*v1.Node {
+	domains := [][]string{{"R1", "Z1"}, {"R1", "Z2"}, {"R2", "Z3"}}
+	nodes := make([]*v1.Node, 0, len(cpus))
+	for i, cpu := range cpus {
+		nodes = append(nodes, st.MakeNode().Name(domains[i][1]+"-node").
+			Label(v1.LabelTopologyRegion, domains[i][0]).Label(v1.LabelTopologyZone, domains[i][1]).
+			Capacity(map[v1.ResourceName]string{v1.ResourceCPU: cpu, v1.ResourceMemory: "16Gi"}).Obj())
+	}
+	return nodes
+}
+
+func makePlanNodeInfos(nodes []*v1.Node) []*framework.NodeInfo {
+	nodeInfos := make([]*framework.NodeInfo, 0, len(nodes))
+	for _, n := range nodes {
+		nodeInfo := framework.NewNodeInfo()
+		nodeInfo.SetNode(n)
+		nodeInfos = append(nodeInfos, nodeInfo)
+	}
+	return nodeInfos
+}
+
+func TestComputePlacementPlan(t *testing.T) {
+	planner := newPlacementPlanner([]string{v1.LabelTopologyRegion, v1.LabelTopologyZone})
+	requests := map[string]planRequest{
+		"p1": {milliCPU: 1000},
+		"p2": {milliCPU: 1000},
+		"p3": {milliCPU: 1000},
+	}
+
+	tests := []struct {
+		name   string
+		nodes  []*v1.Node
+		pinned map[string]int
+		want   map[string]string
+	}{
+		{
+			name:  "dependent workloads in the domain with the most free CPU",
+			nodes: makePlanNodes("4000m", "8000m", "2000m"),
+			want:  map[string]string{"p1": "R1/Z2", "p2": "R1/Z2", "p3": "R1/Z2"},
+		},
+		{
+			name:  "workloads not fitting in a domain spread to the closest one",
+			nodes: makePlanNodes("2000m", "2000m", "1000m"),
+			want:  map[string]string{"p1": "R1/Z1", "p2": "R1/Z1", "p3": "R1/Z2"},
+		},
+		{
+			name:   "workloads placed next to the pinned ones",
+			nodes:  makePlanNodes("4000m", "8000m", "4000m"),
+			pinned: map[string]int{"p3": 2},
+			want:   map[string]string{"p1": "R2/Z3", "p2": "R2/Z3", "p3": "R2/Z3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			domains, _ := planner.getPlanDomains(makePlanNodeInfos(tt.nodes))
+			got := computePlacementPlan(GetAppGroupCRBasic().Spec.Workloads, domains, tt.pinned, requests, planner.topologyKeys, planCostMap)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected plan %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPlacementPlannerReplan(t *testing.T) {
+	logger := klog.Background()
+	planner := newPlacementPlanner([]string{v1.LabelTopologyRegion, v1.LabelTopologyZone})
+	appGroup := GetAppGroupCRBasic()
+	nodeInfos := makePlanNodeInfos(makePlanNodes("4000m", "8000m", "2000m"))
+	pod := makePod("p1", "p1-deployment", 0, "basic", v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}, nil)
+
+	// The plan is computed upon the first pod of the AppGroup
+	if got := planner.getPlannedDomain(logger, appGroup, pod, nil, nodeInfos, planCostMap); got != "R1/Z2" {
+		t.Errorf("expected planned domain R1/Z2, got %v", got)
+	}
+	plan := planner.plans[appGroup.UID]
+
+	// Pods bound in their planned domains keep the plan
+	workloadHosts := map[string]map[string]int{"p2": {"Z2-node": 1}}
+	if got := planner.getPlannedDomain(logger, appGroup, pod, workloadHosts, nodeInfos, planCostMap); got != "R1/Z2" {
+		t.Errorf("expected planned domain R1/Z2, got %v", got)
+	}
+	if planner.plans[appGroup.UID] != plan {
+		t.Errorf("expected the plan to be kept")
+	}
+
+	// Pods drifting from the plan trigger a new plan, keeping the bound workloads in place
+	workloadHosts["p3"] = map[string]int{"Z1-node": 1}
+	if got := planner.getPlannedDomain(logger, appGroup, pod, workloadHosts, nodeInfos, planCostMap); got != "R1/Z2" {
+		t.Errorf("expected planned domain R1/Z2, got %v", got)
+	}
+	if want := map[string]string{"p1": "R1/Z2", "p2": "R1/Z2", "p3": "R1/Z1"}; !reflect.DeepEqual(planner.plans[appGroup.UID].domains, want) {
+		t.Errorf("expected plan %v, got %v", want, planner.plans[appGroup.UID].domains)
+	}
+	plan = planner.plans[appGroup.UID]
+	planner.getPlannedDomain(logger, appGroup, pod, workloadHosts, nodeInfos, planCostMap)
+	if planner.plans[appGroup.UID] != plan {
+		t.Errorf("expected the plan to be kept")
+	}
+
+	// Updates of the AppGroup trigger a new plan
+	appGroup.Generation++
+	planner.getPlannedDomain(logger, appGroup, pod, workloadHosts, nodeInfos, planCostMap)
+	if planner.plans[appGroup.UID] == plan {
+		t.Errorf("expected a new plan")
+	}
+
+	planner.deletePlan(cache.DeletedFinalStateUnknown{Key: "default/basic", Obj: appGroup})
+	if len(planner.plans) != 0 {
+		t.Errorf("expected no plans, got %v", planner.plans)
+	}
+}
+
+func TestNetworkOverheadPlacementPlanning(t *testing.T) {
+	nodes := makePlanNodes("4000m", "8000m", "2000m")
+	pl := newTestPlugin(t, GetAppGroupCRBasic(), GetNetworkTopologyCRBasic(), nil, nodes)
+	pl.planner = newPlacementPlanner(pl.topologyKeys)
+
+	// The first pod of the AppGroup has no dependency scheduled, but favors its planned domain
+	state := framework.NewCycleState()
+	pod := makePod("p3", "p3-deployment", 0, "basic", nil, nil)
+	if _, got := pl.PreFilter(context.TODO(), state, pod); !got.IsSuccess() {
+		t.Fatalf("expected success, got %v : %v", got.Code(), got.Message())
+	}
+
+	for _, tt := range []struct {
+		node     *v1.Node
+		wantCost int64
+	}{
+		{node: nodes[0], wantCost: MaxCost},
+		{node: nodes[1], wantCost: 0},
+		{node: nodes[2], wantCost: MaxCost},
+	} {
+		gotCost, status := pl.Score(context.TODO(), state, pod, tt.node.Name)
+		if !status.IsSuccess() {
+			t.Fatalf("expected success, got %v : %v", status.Code(), status.Message())
+		}
+		if gotCost != tt.wantCost {
+			t.Errorf("node %v: expected cost %v, got %v", tt.node.Name, tt.wantCost, gotCost)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prober
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ProbeResultsLabel : label of the ConfigMaps holding probe results. Its value is the configmapName of the
+	// NetworkTopology CR the results are aggregated into.
+	ProbeResultsLabel = "networktopology.diktyo.x-k8s.io/probe-results"
+
+	// ProbeOriginAnnotation : annotation of the probe results ConfigMaps naming the node the probes were sent from
+	ProbeOriginAnnotation = "networktopology.diktyo.x-k8s.io/probe-origin"
+
+	// ProbeTimeAnnotation : annotation of the probe results ConfigMaps holding the time of the probes (RFC3339)
+	ProbeTimeAnnotation = "networktopology.diktyo.x-k8s.io/probe-time"
+)
+
+// Prober : measures the network latency from the local node to another node
+type Prober interface {
+	Probe(ctx context.Context, target *v1.Node) (time.Duration, error)
+}
+
+// FakeProber : Prober returning predefined latencies per target node, e.g., for tests and demos
+type FakeProber struct {
+	// Latencies to each target node by name
+	Latencies map[string]time.Duration
+}
+
+var _ Prober = &FakeProber{}
+
+// Probe : return the predefined latency to the target node
+func (p *FakeProber) Probe(_ context.Context, target *v1.Node) (time.Duration, error) {
+	latency, ok := p.Latencies[target.Name]
+	if !ok {
+		return 0, fmt.Errorf("node %v is unreachable", target.Name)
+	}
+	return latency, nil
+}
+
+// Reporter : probes the other nodes of the cluster from the local node and publishes the results in a ConfigMap,
+// one key per reachable target node with the latency as a duration (e.g., "1.5ms")
+type Reporter struct {
+	Client client.Client
+	Prober Prober
+
+	// Name of the local node
+	NodeName string
+
+	// Namespace of the NetworkTopology CR
+	Namespace string
+
+	// configmapName of the NetworkTopology CR, used as the ConfigMap name prefix and ProbeResultsLabel value
+	ResultsName string
+}
+
+// ConfigMapName : return the name of the ConfigMap holding the probe results of the local node
+func (r *Reporter) ConfigMapName() string {
+	return fmt.Sprintf("%v-%v", r.ResultsName, r.NodeName)
+}
+
+// Run : report the probe results every period until the context is done
+func (r *Reporter) Run(ctx context.Context, period time.Duration) {
+	logger := klog.FromContext(ctx).WithValues("node", r.NodeName)
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := r.Report(ctx); err != nil {
+			logger.Error(err, "Failed to report probe results")
+		}
+	}, period)
+}
+
+// Report : probe the other nodes of the cluster and publish the results.
+// Unreachable nodes are left out of the results.
+func (r *Reporter) Report(ctx context.Context) error {
+	logger := klog.FromContext(ctx).WithValues("node", r.NodeName)
+
+	nodeList := &v1.NodeList{}
+	if err := r.Client.List(ctx, nodeList); err != nil {
+		return err
+	}
+
+	data := make(map[string]string)
+	for i := range nodeList.Items {
+		target := &nodeList.Items[i]
+		if target.Name == r.NodeName {
+			continue
+		}
+		latency, err := r.Prober.Probe(ctx, target)
+		if err != nil {
+			logger.V(4).Info("Failed to probe node", "target", target.Name, "err", err)
+			continue
+		}
+		data[target.Name] = latency.String()
+	}
+
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.ConfigMapName(),
+			Namespace: r.Namespace,
+			Labels:    map[string]string{ProbeResultsLabel: r.ResultsName},
+			Annotations: map[string]string{
+				ProbeOriginAnnotation: r.NodeName,
+				ProbeTimeAnnotation:   time.Now().UTC().Format(time.RFC3339),
+			},
+		},
+		Data: data,
+	}
+	old := &v1.ConfigMap{}
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(cm), old); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		return r.Client.Create(ctx, cm)
+	}
+	cm.ResourceVersion = old.ResourceVersion
+	return r.Client.Update(ctx, cm)
+}
+
+// ParseResults : return the origin node, probe time and latencies to each target node of a probe results ConfigMap
+func ParseResults(cm *v1.ConfigMap) (string, time.Time, map[string]time.Duration, error) {
+	origin := cm.Annotations[ProbeOriginAnnotation]
+	if origin == "" {
+		return "", time.Time{}, nil, fmt.Errorf("missing %v annotation", ProbeOriginAnnotation)
+	}
+	probeTime, err := time.Parse(time.RFC3339, cm.Annotations[ProbeTimeAnnotation])
+	if err != nil {
+		return "", time.Time{}, nil, fmt.Errorf("invalid %v annotation: %w", ProbeTimeAnnotation, err)
+	}
+
+	latencies := make(map[string]time.Duration, len(cm.Data))
+	for target, value := range cm.Data {
+		latency, err := time.ParseDuration(value)
+		if err != nil {
+			return "", time.Time{}, nil, fmt.Errorf("invalid latency to node %v: %w", target, err)
+		}
+		latencies[target] = latency
+	}
+	return origin, probeTime, latencies, nil
+}
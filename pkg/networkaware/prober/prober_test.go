@@ -0,0 +1,111 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prober
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReport(t *testing.T) {
+	ctx := context.TODO()
+	nodes := []client.Object{
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "n1"}},
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "n2"}},
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "n3"}},
+	}
+	kClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(nodes...).Build()
+	reporter := &Reporter{
+		Client:      kClient,
+		Prober:      &FakeProber{Latencies: map[string]time.Duration{"n2": 1500 * time.Microsecond}},
+		NodeName:    "n1",
+		Namespace:   "default",
+		ResultsName: "netperf-metrics",
+	}
+
+	// The results are created, then updated.
+	for _, want := range []map[string]time.Duration{
+		{"n2": 1500 * time.Microsecond},
+		{"n2": 2 * time.Millisecond, "n3": 10 * time.Millisecond},
+	} {
+		reporter.Prober = &FakeProber{Latencies: want}
+		before := time.Now().Truncate(time.Second)
+		if err := reporter.Report(ctx); err != nil {
+			t.Fatalf("report: %v", err)
+		}
+
+		cm := &v1.ConfigMap{}
+		if err := kClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "netperf-metrics-n1"}, cm); err != nil {
+			t.Fatal(err)
+		}
+		if got := cm.Labels[ProbeResultsLabel]; got != "netperf-metrics" {
+			t.Errorf("want label %v, got %v", "netperf-metrics", got)
+		}
+		origin, probeTime, latencies, err := ParseResults(cm)
+		if err != nil {
+			t.Fatalf("parse results: %v", err)
+		}
+		if origin != "n1" {
+			t.Errorf("want origin n1, got %v", origin)
+		}
+		if probeTime.Before(before) {
+			t.Errorf("want probe time after %v, got %v", before, probeTime)
+		}
+		if !reflect.DeepEqual(want, latencies) {
+			t.Errorf("want latencies %v, got %v", want, latencies)
+		}
+	}
+}
+
+func TestParseResultsInvalid(t *testing.T) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		data        map[string]string
+	}{
+		{
+			name:        "missing origin",
+			annotations: map[string]string{ProbeTimeAnnotation: now},
+		},
+		{
+			name:        "invalid probe time",
+			annotations: map[string]string{ProbeOriginAnnotation: "n1", ProbeTimeAnnotation: "yesterday"},
+		},
+		{
+			name:        "invalid latency",
+			annotations: map[string]string{ProbeOriginAnnotation: "n1", ProbeTimeAnnotation: now},
+			data:        map[string]string{"n2": "fast"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}, Data: tt.data}
+			if _, _, _, err := ParseResults(cm); err == nil {
+				t.Errorf("want error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topologicalsort
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+
+	agv1alpha "github.com/diktyo-io/appgroup-api/pkg/apis/appgroup/v1alpha1"
+)
+
+// workloadOrder : order of the workloads of an AppGroup computed from its dependencies
+type workloadOrder struct {
+	// UID and generation of the AppGroup the order was computed for
+	uid        types.UID
+	generation int64
+
+	// rank of each workload by selector, from 0
+	ranks map[string]int32
+
+	// rank following the ranks of the workloads, given to unknown workloads
+	next int32
+}
+
+// newWorkloadOrder : order of the given workloads of an AppGroup
+func newWorkloadOrder(uid types.UID, generation int64, workloads agv1alpha.AppGroupWorkloadList, reverse bool) *workloadOrder {
+	ranks := computeWorkloadOrder(workloads, reverse)
+	return &workloadOrder{uid: uid, generation: generation, ranks: ranks, next: int32(len(ranks))}
+}
+
+// update : update the order for a new generation of the AppGroup. The workloads still in the AppGroup keep their
+// rank, so that their pending pods keep their order in the queue, and the new workloads are ranked after them in
+// their order among the given workloads. Ranks of removed workloads are not reused.
+func (o *workloadOrder) update(generation int64, workloads agv1alpha.AppGroupWorkloadList, reverse bool) {
+	ranks := computeWorkloadOrder(workloads, reverse)
+	added := make([]string, 0, len(ranks))
+	for selector := range ranks {
+		if kept, ok := o.ranks[selector]; ok {
+			ranks[selector] = kept
+		} else {
+			added = append(added, selector)
+		}
+	}
+	sort.Slice(added, func(i, j int) bool {
+		return ranks[added[i]] < ranks[added[j]]
+	})
+	for _, selector := range added {
+		ranks[selector] = o.next
+		o.next++
+	}
+	o.generation, o.ranks = generation, ranks
+}
+
+// computeWorkloadOrder : rank the workloads of an AppGroup so that workloads come before their dependencies, or
+// after them if reverse is set. Dependency cycles are condensed into strongly connected components, found with
+// Tarjan's algorithm, whose workloads get consecutive ranks ordered by selector. Components are ordered with Kahn's
+// algorithm, picking the ready component of the lowest selector first, so that the ranks only depend on the workloads
+// and dependencies of the AppGroup, whatever their order in the spec.
+func computeWorkloadOrder(workloads agv1alpha.AppGroupWorkloadList, reverse bool) map[string]int32 {
+	// Workloads sorted by selector
+	selectors := make([]string, 0, len(workloads))
+	index := make(map[string]int, len(workloads))
+	for _, w := range workloads {
+		if _, ok := index[w.Workload.Selector]; !ok {
+			index[w.Workload.Selector] = 0
+			selectors = append(selectors, w.Workload.Selector)
+		}
+	}
+	sort.Strings(selectors)
+	for i, s := range selectors {
+		index[s] = i
+	}
+
+	// Edges from the workloads to their dependencies, reversed if dependencies come first
+	edges := make([][]int, len(selectors))
+	for _, w := range workloads {
+		from := index[w.Workload.Selector]
+		for _, d := range w.Dependencies {
+			to, ok := index[d.Workload.Selector]
+			if !ok {
+				continue
+			}
+			if reverse {
+				edges[to] = append(edges[to], from)
+			} else {
+				edges[from] = append(edges[from], to)
+			}
+		}
+	}
+
+	components := findStronglyConnectedComponents(edges)
+
+	// Condensation of the graph: edges and in-degrees between components
+	componentEdges := make([]map[int]bool, len(components.members))
+	inDegrees := make([]int, len(components.members))
+	for from := range edges {
+		for _, to := range edges[from] {
+			c1, c2 := components.ids[from], components.ids[to]
+			if c1 == c2 {
+				continue
+			}
+			if componentEdges[c1] == nil {
+				componentEdges[c1] = make(map[int]bool)
+			}
+			if !componentEdges[c1][c2] {
+				componentEdges[c1][c2] = true
+				inDegrees[c2]++
+			}
+		}
+	}
+
+	// Kahn's algorithm on the condensation. The members of a component are sorted by selector, so the ready
+	// component of the lowest selector is the one of the lowest first member.
+	ready := make([]int, 0, len(components.members))
+	for c := range components.members {
+		if inDegrees[c] == 0 {
+			ready = append(ready, c)
+		}
+	}
+	ranks := make(map[string]int32, len(selectors))
+	var rank int32
+	for len(ready) > 0 {
+		sort.Slice(ready, func(i, j int) bool {
+			return components.members[ready[i]][0] < components.members[ready[j]][0]
+		})
+		c := ready[0]
+		ready = ready[1:]
+		for _, v := range components.members[c] {
+			ranks[selectors[v]] = rank
+			rank++
+		}
+		for next := range componentEdges[c] {
+			inDegrees[next]--
+			if inDegrees[next] == 0 {
+				ready = append(ready, next)
+			}
+		}
+	}
+	return ranks
+}
+
+// stronglyConnectedComponents : strongly connected components of a graph
+type stronglyConnectedComponents struct {
+	// component of each vertex
+	ids []int
+
+	// sorted vertices of each component
+	members [][]int
+}
+
+// findStronglyConnectedComponents : find the strongly connected components of a graph with Tarjan's algorithm
+func findStronglyConnectedComponents(edges [][]int) stronglyConnectedComponents {
+	n := len(edges)
+	result := stronglyConnectedComponents{ids: make([]int, n)}
+	indexes := make([]int, n)
+	lowLinks := make([]int, n)
+	onStack := make([]bool, n)
+	for v := range indexes {
+		indexes[v] = -1
+	}
+	stack := make([]int, 0, n)
+	next := 0
+
+	var strongConnect func(v int)
+	strongConnect = func(v int) {
+		indexes[v], lowLinks[v] = next, next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range edges[v] {
+			if indexes[w] < 0 {
+				strongConnect(w)
+				lowLinks[v] = min(lowLinks[v], lowLinks[w])
+			} else if onStack[w] {
+				lowLinks[v] = min(lowLinks[v], indexes[w])
+			}
+		}
+
+		// v is the root of a component: pop its members
+		if lowLinks[v] == indexes[v] {
+			var members []int
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				result.ids[w] = len(result.members)
+				members = append(members, w)
+				if w == v {
+					break
+				}
+			}
+			sort.Ints(members)
+			result.members = append(result.members, members)
+		}
+	}
+
+	for v := range edges {
+		if indexes[v] < 0 {
+			strongConnect(v)
+		}
+	}
+	return result
+}
+
+// deleteOrder : drop the workload order of a deleted AppGroup
+func (ts *TopologicalSort) deleteOrder(obj interface{}) {
+	var appGroup *agv1alpha.AppGroup
+	switch t := obj.(type) {
+	case *agv1alpha.AppGroup:
+		appGroup = t
+	case cache.DeletedFinalStateUnknown:
+		var ok bool
+		if appGroup, ok = t.Obj.(*agv1alpha.AppGroup); !ok {
+			return
+		}
+	default:
+		return
+	}
+
+	ts.ordersLock.Lock()
+	defer ts.ordersLock.Unlock()
+	key := types.NamespacedName{Namespace: appGroup.Namespace, Name: appGroup.Name}
+	if order, ok := ts.orders[key]; ok && order.uid == appGroup.UID {
+		delete(ts.orders, key)
+	}
+}
+
+// appGroupEventHandler : drop the workload orders of the deleted AppGroups
+func (ts *TopologicalSort) appGroupEventHandler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		DeleteFunc: ts.deleteOrder,
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topologicalsort
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+
+	agv1alpha1 "github.com/diktyo-io/appgroup-api/pkg/apis/appgroup/v1alpha1"
+)
+
+// makeWorkloads : create workloads depending on the given selectors, by selector
+func makeWorkloads(dependencies ...[]string) agv1alpha1.AppGroupWorkloadList {
+	workloads := agv1alpha1.AppGroupWorkloadList{}
+	for _, d := range dependencies {
+		w := agv1alpha1.AppGroupWorkload{Workload: agv1alpha1.AppGroupWorkloadInfo{Selector: d[0]}}
+		for _, selector := range d[1:] {
+			w.Dependencies = append(w.Dependencies, agv1alpha1.DependenciesInfo{Workload: agv1alpha1.AppGroupWorkloadInfo{Selector: selector}})
+		}
+		workloads = append(workloads, w)
+	}
+	return workloads
+}
+
+func TestComputeWorkloadOrder(t *testing.T) {
+	tests := []struct {
+		name        string
+		workloads   agv1alpha1.AppGroupWorkloadList
+		want        map[string]int32
+		wantReverse map[string]int32
+	}{
+		{
+			name:        "chain",
+			workloads:   makeWorkloads([]string{"p1", "p2"}, []string{"p2", "p3"}, []string{"p3"}),
+			want:        map[string]int32{"p1": 0, "p2": 1, "p3": 2},
+			wantReverse: map[string]int32{"p3": 0, "p2": 1, "p1": 2},
+		},
+		{
+			name:        "independent workloads are ordered by selector, whatever the spec order",
+			workloads:   makeWorkloads([]string{"p3"}, []string{"p2", "p1"}, []string{"p1"}),
+			want:        map[string]int32{"p2": 0, "p1": 1, "p3": 2},
+			wantReverse: map[string]int32{"p1": 0, "p2": 1, "p3": 2},
+		},
+		{
+			name: "cycles are ordered together",
+			workloads: makeWorkloads(
+				[]string{"a", "b"},
+				[]string{"b", "c"},
+				[]string{"c", "b", "d"},
+				[]string{"d"},
+			),
+			want:        map[string]int32{"a": 0, "b": 1, "c": 2, "d": 3},
+			wantReverse: map[string]int32{"d": 0, "b": 1, "c": 2, "a": 3},
+		},
+		{
+			name: "mutual dependencies of all workloads",
+			workloads: makeWorkloads(
+				[]string{"c", "a"},
+				[]string{"b", "c"},
+				[]string{"a", "b", "a"},
+			),
+			want:        map[string]int32{"a": 0, "b": 1, "c": 2},
+			wantReverse: map[string]int32{"a": 0, "b": 1, "c": 2},
+		},
+		{
+			name:        "dependencies out of the AppGroup are ignored",
+			workloads:   makeWorkloads([]string{"p1", "unknown"}, []string{"p2", "p1"}),
+			want:        map[string]int32{"p2": 0, "p1": 1},
+			wantReverse: map[string]int32{"p1": 0, "p2": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := computeWorkloadOrder(tt.workloads, false); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected order %v, got %v", tt.want, got)
+			}
+			if got := computeWorkloadOrder(tt.workloads, true); !reflect.DeepEqual(got, tt.wantReverse) {
+				t.Errorf("expected reverse order %v, got %v", tt.wantReverse, got)
+			}
+		})
+	}
+}
+
+func TestGetWorkloadOrderUpdates(t *testing.T) {
+	ts := &TopologicalSort{orderingAlgorithm: "KahnSort"}
+	appGroup := &agv1alpha1.AppGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "basic", Namespace: "default", UID: "uid", Generation: 1},
+		Spec:       agv1alpha1.AppGroupSpec{Workloads: makeWorkloads([]string{"p1", "p2"}, []string{"p2"})},
+	}
+	if got := ts.getWorkloadOrder(appGroup, "p2"); got != 1 {
+		t.Errorf("expected order 1, got %v", got)
+	}
+	order := ts.orders[types.NamespacedName{Namespace: "default", Name: "basic"}]
+
+	// The order is kept while the AppGroup is not updated
+	ts.getWorkloadOrder(appGroup, "p1")
+	if ts.orders[types.NamespacedName{Namespace: "default", Name: "basic"}] != order {
+		t.Errorf("expected the order to be kept")
+	}
+
+	// Updates of the AppGroup keep the order of the remaining workloads and rank the new ones after them
+	appGroup.Spec.Workloads = makeWorkloads([]string{"p1"}, []string{"p2", "p1"}, []string{"p4", "p3"}, []string{"p3"})
+	appGroup.Generation++
+	for selector, want := range map[string]int32{"p1": 0, "p2": 1, "p4": 2, "p3": 3, "unknown": 4} {
+		if got := ts.getWorkloadOrder(appGroup, selector); got != want {
+			t.Errorf("expected order %v for %v, got %v", want, selector, got)
+		}
+	}
+
+	// Ranks of removed workloads are not reused
+	appGroup.Spec.Workloads = makeWorkloads([]string{"p1"}, []string{"p3"}, []string{"p5"})
+	appGroup.Generation++
+	for selector, want := range map[string]int32{"p1": 0, "p3": 3, "p5": 4, "unknown": 5} {
+		if got := ts.getWorkloadOrder(appGroup, selector); got != want {
+			t.Errorf("expected order %v for %v, got %v", want, selector, got)
+		}
+	}
+
+	// The order of a recreated AppGroup is kept when the previous one is deleted
+	deleted := appGroup.DeepCopy()
+	deleted.UID = "old-uid"
+	ts.deleteOrder(cache.DeletedFinalStateUnknown{Key: "default/basic", Obj: deleted})
+	if len(ts.orders) != 1 {
+		t.Errorf("expected the order to be kept")
+	}
+
+	// Deleting the AppGroup drops its order
+	ts.deleteOrder(appGroup)
+	if len(ts.orders) != 0 {
+		t.Errorf("expected the order to be dropped, got %v", ts.orders)
+	}
+}
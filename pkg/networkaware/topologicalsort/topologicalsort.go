@@ -19,8 +19,10 @@ package topologicalsort
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/klog/v2"
@@ -53,6 +55,13 @@ type TopologicalSort struct {
 	logger     klog.Logger
 	handle     framework.Handle
 	namespaces []string
+
+	// algorithm ordering the workloads of an AppGroup
+	orderingAlgorithm string
+
+	// workload orders computed from the dependencies of the AppGroups
+	ordersLock sync.Mutex
+	orders     map[types.NamespacedName]*workloadOrder
 }
 
 var _ framework.QueueSortPlugin = &TopologicalSort{}
@@ -82,23 +91,42 @@ func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (fram
 		return nil, err
 	}
 
-	c, _, err := util.NewClientWithCachedReader(ctx, handle.KubeConfig(), scheme)
+	switch args.OrderingAlgorithm {
+	case "", pluginconfig.OrderingAlgorithmAppGroup, pluginconfig.OrderingAlgorithmKahnSort, pluginconfig.OrderingAlgorithmReverseKahn:
+	default:
+		return nil, fmt.Errorf("orderingAlgorithm must be either %v, %v or %v, got %q", pluginconfig.OrderingAlgorithmAppGroup,
+			pluginconfig.OrderingAlgorithmKahnSort, pluginconfig.OrderingAlgorithmReverseKahn, args.OrderingAlgorithm)
+	}
+
+	c, ccache, err := util.NewClientWithCachedReader(ctx, handle.KubeConfig(), scheme)
 	if err != nil {
 		return nil, err
 	}
 
 	pl := &TopologicalSort{
-		Client:     c,
-		logger:     logger,
-		handle:     handle,
-		namespaces: args.Namespaces,
+		Client:            c,
+		logger:            logger,
+		handle:            handle,
+		namespaces:        args.Namespaces,
+		orderingAlgorithm: args.OrderingAlgorithm,
+		orders:            make(map[types.NamespacedName]*workloadOrder),
+	}
+
+	// Forget the workload orders of deleted AppGroups
+	appGroupInformer, err := ccache.GetInformer(ctx, &agv1alpha.AppGroup{})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := appGroupInformer.AddEventHandler(pl.appGroupEventHandler()); err != nil {
+		return nil, err
 	}
 	return pl, nil
 }
 
 // Less is the function used by the activeQ heap algorithm to sort pods.
 // 1) Sort Pods based on their AppGroup and corresponding service topology graph.
-// 2) Otherwise, follow the strategy of the in-tree QueueSort Plugin (PrioritySort Plugin)
+// 2) Sort Pods of workloads with the same order by workload selector.
+// 3) Otherwise, follow the strategy of the in-tree QueueSort Plugin (PrioritySort Plugin)
 func (ts *TopologicalSort) Less(pInfo1, pInfo2 *framework.QueuedPodInfo) bool {
 	p1AppGroup := networkawareutil.GetPodAppGroupLabel(pInfo1.Pod)
 	p2AppGroup := networkawareutil.GetPodAppGroupLabel(pInfo2.Pod)
@@ -116,19 +144,69 @@ func (ts *TopologicalSort) Less(pInfo1, pInfo2 *framework.QueuedPodInfo) bool {
 	logger.V(6).Info("Pods belong to the same AppGroup CR", "p1 name", pInfo1.Pod.Name, "p2 name", pInfo2.Pod.Name, "appGroup", p1AppGroup)
 	agName := p1AppGroup
 	appGroup := ts.findAppGroupTopologicalSort(ctx, agName)
+	if appGroup == nil {
+		logger.V(4).Info("AppGroup CR not found", "appGroup", agName)
+		s := &queuesort.PrioritySort{}
+		return s.Less(pInfo1, pInfo2)
+	}
 
-	// Get labels from both pods
-	labelsP1 := pInfo1.Pod.GetLabels()
-	labelsP2 := pInfo2.Pod.GetLabels()
+	// Get workload selectors from both pods
+	selectorP1 := networkawareutil.GetPodAppGroupSelector(pInfo1.Pod)
+	selectorP2 := networkawareutil.GetPodAppGroupSelector(pInfo2.Pod)
 
-	// Binary search to find both order index since topology list is ordered by Workload Name
-	orderP1 := networkawareutil.FindPodOrder(appGroup.Status.TopologyOrder, labelsP1[agv1alpha.AppGroupSelectorLabel])
-	orderP2 := networkawareutil.FindPodOrder(appGroup.Status.TopologyOrder, labelsP2[agv1alpha.AppGroupSelectorLabel])
+	orderP1 := ts.getWorkloadOrder(appGroup, selectorP1)
+	orderP2 := ts.getWorkloadOrder(appGroup, selectorP2)
 
 	logger.V(6).Info("Pod order values", "p1 order", orderP1, "p2 order", orderP2)
 
 	// Lower is better
-	return orderP1 <= orderP2
+	if orderP1 != orderP2 {
+		return orderP1 < orderP2
+	}
+	// Workloads of the same order, e.g. unknown to the AppGroup, are sorted deterministically
+	if selectorP1 != selectorP2 {
+		return selectorP1 < selectorP2
+	}
+	s := &queuesort.PrioritySort{}
+	return s.Less(pInfo1, pInfo2)
+}
+
+// getWorkloadOrder : return the order of the given workload in the AppGroup, lower first. Workloads unknown to
+// the AppGroup come last.
+// With the AppGroup algorithm, the order precomputed in the AppGroup status is used, workloads missing from it
+// coming after the others in the KahnSort order. Otherwise, the order is computed from the dependencies of the
+// AppGroup. Updates of the AppGroup keep the order of its remaining workloads and rank the new ones after them.
+func (ts *TopologicalSort) getWorkloadOrder(appGroup *agv1alpha.AppGroup, selector string) int32 {
+	var base int32
+	if ts.orderingAlgorithm == "" || ts.orderingAlgorithm == pluginconfig.OrderingAlgorithmAppGroup {
+		// Binary search to find the order index since topology list is ordered by Workload Name
+		if order := networkawareutil.FindPodOrder(appGroup.Status.TopologyOrder, selector); order >= 0 {
+			return order
+		}
+		for _, t := range appGroup.Status.TopologyOrder {
+			base = max(base, t.Index+1)
+		}
+	}
+
+	ts.ordersLock.Lock()
+	defer ts.ordersLock.Unlock()
+	if ts.orders == nil {
+		ts.orders = make(map[types.NamespacedName]*workloadOrder)
+	}
+	key := types.NamespacedName{Namespace: appGroup.Namespace, Name: appGroup.Name}
+	reverse := ts.orderingAlgorithm == pluginconfig.OrderingAlgorithmReverseKahn
+	order, ok := ts.orders[key]
+	if !ok || order.uid != appGroup.UID {
+		order = newWorkloadOrder(appGroup.UID, appGroup.Generation, appGroup.Spec.Workloads, reverse)
+		ts.orders[key] = order
+	} else if order.generation != appGroup.Generation {
+		order.update(appGroup.Generation, appGroup.Spec.Workloads, reverse)
+	}
+
+	if rank, ok := order.ranks[selector]; ok {
+		return base + rank
+	}
+	return base + order.next
 }
 
 func (ts *TopologicalSort) findAppGroupTopologicalSort(ctx context.Context, agName string) *agv1alpha.AppGroup {
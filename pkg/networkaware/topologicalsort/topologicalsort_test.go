@@ -275,6 +275,111 @@ func TestTopologicalSortLess(t *testing.T) {
 	}
 }
 
+func TestTopologicalSortLessOrderingAlgorithm(t *testing.T) {
+	// AppGroup with a dependency cycle between p2 and p3, and no precomputed order
+	cyclicAppGroup := &agv1alpha1.AppGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "cyclic", Namespace: "default"},
+		Spec: agv1alpha1.AppGroupSpec{
+			NumMembers: 4,
+			Workloads:  makeWorkloads([]string{"p1", "p2"}, []string{"p2", "p3"}, []string{"p3", "p2", "p4"}, []string{"p4"}),
+		},
+	}
+
+	tests := []struct {
+		name              string
+		appGroup          *agv1alpha1.AppGroup
+		orderingAlgorithm string
+		p1Selector        string
+		p2Selector        string
+		want              bool
+	}{
+		{
+			name:              "KahnSort, workloads before their dependencies",
+			appGroup:          cyclicAppGroup,
+			orderingAlgorithm: "KahnSort",
+			p1Selector:        "p1",
+			p2Selector:        "p4",
+			want:              true,
+		},
+		{
+			name:              "KahnSort, workloads of a cycle sorted by selector",
+			appGroup:          cyclicAppGroup,
+			orderingAlgorithm: "KahnSort",
+			p1Selector:        "p3",
+			p2Selector:        "p2",
+			want:              false,
+		},
+		{
+			name:              "ReverseKahn, dependencies before their workloads",
+			appGroup:          cyclicAppGroup,
+			orderingAlgorithm: "ReverseKahn",
+			p1Selector:        "p1",
+			p2Selector:        "p4",
+			want:              false,
+		},
+		{
+			name:              "KahnSort, unknown workloads last",
+			appGroup:          cyclicAppGroup,
+			orderingAlgorithm: "KahnSort",
+			p1Selector:        "p0",
+			p2Selector:        "p4",
+			want:              false,
+		},
+		{
+			name:              "KahnSort, unknown workloads sorted by selector",
+			appGroup:          cyclicAppGroup,
+			orderingAlgorithm: "KahnSort",
+			p1Selector:        "p5",
+			p2Selector:        "p6",
+			want:              true,
+		},
+		{
+			name:              "AppGroup, workloads missing from the AppGroup status after the others",
+			appGroup:          GetAppGroupCRBasic(),
+			orderingAlgorithm: "AppGroup",
+			p1Selector:        "p5",
+			p2Selector:        "p3",
+			want:              false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := clientgoscheme.Scheme
+			utilruntime.Must(agv1alpha1.AddToScheme(s))
+			client := fake.NewClientBuilder().
+				WithScheme(s).
+				WithStatusSubresource(&agv1alpha1.AppGroup{}).
+				Build()
+
+			appGroup := tt.appGroup.DeepCopy()
+			sort.Sort(util.ByWorkloadSelector(appGroup.Status.TopologyOrder))
+			if err := client.Create(context.TODO(), appGroup); err != nil {
+				t.Errorf("failed to create AppGroup CR: %v", err)
+			}
+
+			ts := &TopologicalSort{
+				Client:            client,
+				namespaces:        []string{metav1.NamespaceDefault},
+				orderingAlgorithm: tt.orderingAlgorithm,
+			}
+
+			pInfo1 := &framework.QueuedPodInfo{
+				PodInfo: testutil.MustNewPodInfo(t, makePod(tt.p1Selector, tt.p1Selector+"-deployment", 0, appGroup.Name, nil, nil)),
+			}
+			pInfo2 := &framework.QueuedPodInfo{
+				PodInfo: testutil.MustNewPodInfo(t, makePod(tt.p2Selector, tt.p2Selector+"-deployment", 0, appGroup.Name, nil, nil)),
+			}
+			if got := ts.Less(pInfo1, pInfo2); got != tt.want {
+				t.Errorf("Less() = %v, want %v", got, tt.want)
+			}
+			// The order is strict
+			if got := ts.Less(pInfo2, pInfo1); got == tt.want {
+				t.Errorf("reversed Less() = %v, want %v", got, !tt.want)
+			}
+		})
+	}
+}
+
 func BenchmarkTopologicalSortPlugin(b *testing.B) {
 	ctx := context.TODO()
 	agName := "onlineboutique"
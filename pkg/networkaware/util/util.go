@@ -17,14 +17,19 @@ limitations under the License.
 package util
 
 import (
+	"strings"
+
 	v1 "k8s.io/api/core/v1"
 
 	agv1alpha1 "github.com/diktyo-io/appgroup-api/pkg/apis/appgroup/v1alpha1"
 	ntv1alpha1 "github.com/diktyo-io/networktopology-api/pkg/apis/networktopology/v1alpha1"
 )
 
-// CostKey : key for map concerning network costs (origin / destinations)
+// CostKey : key for map concerning network costs (origin / destinations) at a topology level.
+// Origin and destination are the names of the domains, or their paths to tell apart domains of the same name
+// under different parents (see GetDomainPath).
 type CostKey struct {
+	TopologyKey string
 	Origin      string
 	Destination string
 }
@@ -46,22 +51,76 @@ type ScheduledInfo struct {
 
 type ScheduledList []ScheduledInfo
 
-// GetNodeRegion : return the region of the node
-func GetNodeRegion(node *v1.Node) string {
-	labels := node.Labels
-	if labels == nil {
-		return ""
+// GetNodeDomains : return the topology domains of the node, i.e., the values of its labels for the given topology keys
+func GetNodeDomains(node *v1.Node, topologyKeys []string) []string {
+	domains := make([]string, len(topologyKeys))
+	for i, key := range topologyKeys {
+		domains[i] = node.Labels[key]
+	}
+	return domains
+}
+
+// IsDomainUnknown : return true if the node has no topology domain defined
+func IsDomainUnknown(domains []string) bool {
+	for _, d := range domains {
+		if d != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// GetDifferingLevel : return the broadest topology level at which the domains of two nodes differ,
+// or -1 if both nodes belong to the same domains
+func GetDifferingLevel(origin []string, destination []string) int {
+	for i := range origin {
+		if origin[i] != destination[i] {
+			return i
+		}
+	}
+	return -1
+}
+
+// GetDomainPath : return the path of the domain of a node at the given topology level, i.e., its domains from the
+// broadest level down to the given one separated by slashes (e.g., us-west-1/Z1)
+func GetDomainPath(domains []string, level int) string {
+	return strings.Join(domains[:level+1], "/")
+}
+
+// GetDomainsKey : return the key of the costs between the domains of two nodes at the given topology level,
+// identifying the domains by their paths
+func GetDomainsKey(origin []string, destination []string, level int, topologyKeys []string) CostKey {
+	return CostKey{
+		TopologyKey: topologyKeys[level],
+		Origin:      GetDomainPath(origin, level),
+		Destination: GetDomainPath(destination, level),
 	}
-	return labels[v1.LabelTopologyRegion]
 }
 
-// GetNodeZone : return the zone of the node
-func GetNodeZone(node *v1.Node) string {
-	labels := node.Labels
-	if labels == nil {
-		return ""
+// FindCost : return the value of the given key in the map, or else the value defined between the names of its
+// domains, for any parents
+func FindCost(key CostKey, costMap map[CostKey]int64) (int64, bool) {
+	if cost, ok := costMap[key]; ok {
+		return cost, true
+	}
+	key.Origin = key.Origin[strings.LastIndex(key.Origin, "/")+1:]
+	key.Destination = key.Destination[strings.LastIndex(key.Destination, "/")+1:]
+	cost, ok := costMap[key]
+	return cost, ok
+}
+
+// FindDomainsCost : return the network cost between the domains of two nodes at the given topology level.
+// If no cost is defined at that level, the cost lookup falls through the narrower levels at which the domains differ.
+func FindDomainsCost(origin []string, destination []string, level int, topologyKeys []string, costMap map[CostKey]int64) (int64, bool) {
+	for i := level; i < len(origin); i++ {
+		if origin[i] == destination[i] {
+			continue
+		}
+		if cost, ok := FindCost(GetDomainsKey(origin, destination, i, topologyKeys), costMap); ok {
+			return cost, true
+		}
 	}
-	return labels[v1.LabelTopologyZone]
+	return 0, false
 }
 
 // GetPodAppGroupLabel : get AppGroup from pod annotations
@@ -17,6 +17,7 @@ limitations under the License.
 package trimaran
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -35,21 +36,30 @@ const (
 
 // Collector : get data from load watcher, encapsulating the load watcher and its operations
 //
-// Trimaran plugins have different, potentially conflicting, objectives. Thus, it is recommended not
-// to enable them concurrently. As such, they are currently designed to each have its own Collector.
-// If a need arises in the future to enable multiple Trimaran plugins, a restructuring to have a single
-// Collector, serving the multiple plugins, may be beneficial for performance reasons.
+// Trimaran plugins configured with the same TrimaranSpec share a single Collector, see GetCollector.
 type Collector struct {
 	// load watcher client
 	client loadwatcherapi.Client
 	// data collected by load watcher
 	metrics watcher.WatcherMetrics
+	// whether the metrics were stale when last checked
+	stale bool
 	// for safe access to metrics
 	mu sync.RWMutex
+
+	// maximum age of the metrics before they are reported stale, the smallest one of the plugins using the
+	// collector, 0 to never report them stale
+	maxStaleness time.Duration
+	// load watcher source, to label the Trimaran metrics
+	source string
+	// interval between metrics updates
+	interval time.Duration
+	// histories of the metrics by step, for forecasting
+	histories map[time.Duration]*History
 }
 
-// NewCollector : create an instance of a data collector
-func NewCollector(logger klog.Logger, trimaranSpec *pluginConfig.TrimaranSpec) (*Collector, error) {
+// NewCollector : create an instance of a data collector, updating metrics periodically until the context is done
+func NewCollector(ctx context.Context, logger klog.Logger, trimaranSpec *pluginConfig.TrimaranSpec) (*Collector, error) {
 	if err := checkSpecs(trimaranSpec); err != nil {
 		return nil, err
 	}
@@ -77,25 +87,54 @@ func NewCollector(logger klog.Logger, trimaranSpec *pluginConfig.TrimaranSpec) (
 	}
 
 	collector := &Collector{
-		client: client,
+		client:       client,
+		maxStaleness: GetMaxStaleness(trimaranSpec),
+		source:       trimaranSpec.WatcherAddress,
+		interval:     getMetricsUpdateInterval(trimaranSpec),
+		histories:    make(map[time.Duration]*History),
+	}
+	if collector.source == "" {
+		collector.source = string(trimaranSpec.MetricProvider.Type)
 	}
+	RegisterMetrics()
 
 	// populate metrics before returning
 	err = collector.updateMetrics(logger)
 	if err != nil {
 		logger.Error(err, "Unable to populate metrics initially")
 	}
+	collector.checkStaleness(logger)
 	// start periodic updates
-	go func() {
-		metricsUpdaterTicker := time.NewTicker(time.Second * metricsUpdateIntervalSeconds)
-		for range metricsUpdaterTicker.C {
-			err = collector.updateMetrics(logger)
-			if err != nil {
+	go collector.run(ctx, logger, collector.interval)
+	return collector, nil
+}
+
+// run : update metrics at the given interval until the context is done
+func (collector *Collector) run(ctx context.Context, logger klog.Logger, interval time.Duration) {
+	metricsUpdaterTicker := time.NewTicker(interval)
+	defer metricsUpdaterTicker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.V(4).Info("Stopping metrics updates")
+			return
+		case <-metricsUpdaterTicker.C:
+			if err := collector.updateMetrics(logger); err != nil {
 				logger.Error(err, "Unable to update metrics")
+			} else {
+				collector.recordHistory()
 			}
+			collector.checkStaleness(logger)
 		}
-	}()
-	return collector, nil
+	}
+}
+
+// getMetricsUpdateInterval : get the interval between metrics updates, defaulting if not set
+func getMetricsUpdateInterval(trimaranSpec *pluginConfig.TrimaranSpec) time.Duration {
+	if trimaranSpec.MetricsUpdateIntervalSeconds <= 0 {
+		return time.Second * metricsUpdateIntervalSeconds
+	}
+	return time.Second * time.Duration(trimaranSpec.MetricsUpdateIntervalSeconds)
 }
 
 // getAllMetrics : get all metrics from watcher
@@ -106,20 +145,106 @@ func (collector *Collector) getAllMetrics() *watcher.WatcherMetrics {
 	return &metrics
 }
 
-// GetNodeMetrics : get metrics for a node from watcher
-func (collector *Collector) GetNodeMetrics(logger klog.Logger, nodeName string) ([]watcher.Metric, *watcher.WatcherMetrics) {
+// GetNodeMetrics : get metrics for a node from watcher, along with the end time of the metrics window
+func (collector *Collector) GetNodeMetrics(logger klog.Logger, nodeName string) ([]watcher.Metric, *watcher.WatcherMetrics, time.Time) {
 	allMetrics := collector.getAllMetrics()
 	// This happens if metrics were never populated since scheduler started
 	if allMetrics.Data.NodeMetricsMap == nil {
 		logger.Error(nil, "Metrics not available from watcher")
-		return nil, nil
+		return nil, nil, time.Time{}
 	}
+	metricsTime := getMetricsTime(allMetrics)
 	// Check if node is new (no metrics yet) or metrics are unavailable due to 404 or 500
 	if _, ok := allMetrics.Data.NodeMetricsMap[nodeName]; !ok {
 		logger.Error(nil, "Unable to find metrics for node", "nodeName", nodeName)
-		return nil, allMetrics
+		return nil, allMetrics, metricsTime
+	}
+	return allMetrics.Data.NodeMetricsMap[nodeName].Metrics, allMetrics, metricsTime
+}
+
+// IsStale : check whether metrics of the given time are older than the given maximum staleness, each plugin
+// sharing the collector having its own. Metrics never populated are stale, unless staleness is not checked.
+func (collector *Collector) IsStale(metricsTime time.Time, maxStaleness time.Duration) bool {
+	return maxStaleness > 0 && time.Since(metricsTime) > maxStaleness
+}
+
+// GetMaxStaleness : get the maximum age of the metrics before they are stale, 0 to never consider them stale
+func GetMaxStaleness(trimaranSpec *pluginConfig.TrimaranSpec) time.Duration {
+	return time.Second * time.Duration(trimaranSpec.MetricsMaxStalenessSeconds)
+}
+
+// setMaxStaleness : set the maximum age of the metrics before they are reported stale
+func (collector *Collector) setMaxStaleness(maxStaleness time.Duration) {
+	collector.mu.Lock()
+	collector.maxStaleness = maxStaleness
+	collector.mu.Unlock()
+}
+
+// getMetricsTime : get the end time of the metrics window, or the time the metrics were fetched if unset
+func getMetricsTime(metrics *watcher.WatcherMetrics) time.Time {
+	switch {
+	case metrics.Window.End > 0:
+		return time.Unix(metrics.Window.End, 0)
+	case metrics.Timestamp > 0:
+		return time.Unix(metrics.Timestamp, 0)
+	}
+	return time.Time{}
+}
+
+// GetHistory : get the history of the metrics sampled at the given step, keeping at least the given number of steps.
+// Histories are shared by step, and record the metrics on each update.
+func (collector *Collector) GetHistory(step time.Duration, capacity int) *History {
+	collector.mu.Lock()
+	history, ok := collector.histories[step]
+	if !ok {
+		history = NewHistory(step, capacity)
+		collector.histories[step] = history
+	}
+	collector.mu.Unlock()
+	history.grow(capacity)
+	if !ok {
+		history.Record(collector.getAllMetrics(), collector.getHistoryTime())
+	}
+	return history
+}
+
+// recordHistory : record the current metrics in all histories
+func (collector *Collector) recordHistory() {
+	metrics, metricsTime := collector.getAllMetrics(), collector.getHistoryTime()
+	collector.mu.RLock()
+	defer collector.mu.RUnlock()
+	for _, history := range collector.histories {
+		history.Record(metrics, metricsTime)
+	}
+}
+
+// getHistoryTime : get the time to record the current metrics at in histories, now if the metrics have no time
+func (collector *Collector) getHistoryTime() time.Time {
+	if metricsTime := getMetricsTime(collector.getAllMetrics()); !metricsTime.IsZero() {
+		return metricsTime
+	}
+	return time.Now()
+}
+
+// checkStaleness : report the age of the metrics, and whether they went stale since last checked
+func (collector *Collector) checkStaleness(logger klog.Logger) {
+	metricsTime := getMetricsTime(collector.getAllMetrics())
+	if !metricsTime.IsZero() {
+		metricsAge.WithLabelValues(collector.source).Set(time.Since(metricsTime).Seconds())
+	}
+	collector.mu.Lock()
+	maxStaleness := collector.maxStaleness
+	stale := collector.IsStale(metricsTime, maxStaleness)
+	wasStale := collector.stale
+	collector.stale = stale
+	collector.mu.Unlock()
+
+	if stale && !wasStale {
+		staleMetricsTotal.WithLabelValues(collector.source).Inc()
+		logger.Error(nil, "Metrics from load watcher are stale", "metricsTime", metricsTime, "maxStaleness", maxStaleness)
+	} else if !stale && wasStale {
+		logger.Info("Metrics from load watcher are up to date again", "metricsTime", metricsTime)
 	}
-	return allMetrics.Data.NodeMetricsMap[nodeName].Metrics, allMetrics
 }
 
 // checkSpecs : check trimaran specs
@@ -133,6 +258,11 @@ func checkSpecs(trimaranSpec *pluginConfig.TrimaranSpec) error {
 			return fmt.Errorf("invalid MetricProvider.Type, got %v", trimaranSpec.MetricProvider.Type)
 		}
 	}
+	switch trimaranSpec.StaleMetricsPolicy {
+	case "", pluginConfig.StaleMetricsPolicyNeutral, pluginConfig.StaleMetricsPolicyRequests, pluginConfig.StaleMetricsPolicyUnknown:
+	default:
+		return fmt.Errorf("invalid StaleMetricsPolicy, got %v", trimaranSpec.StaleMetricsPolicy)
+	}
 	return nil
 }
 
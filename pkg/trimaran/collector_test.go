@@ -22,9 +22,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/paypal/load-watcher/pkg/watcher"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/component-base/metrics/testutil"
 	"k8s.io/klog/v2"
 
 	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
@@ -77,7 +79,7 @@ var (
 
 func TestNewCollector(t *testing.T) {
 	logger := klog.FromContext(context.TODO())
-	col, err := NewCollector(logger, &args)
+	col, err := NewCollector(context.TODO(), logger, &args)
 	assert.NotNil(t, col)
 	assert.Nil(t, err)
 }
@@ -98,7 +100,7 @@ func TestNewCollectorSpecs(t *testing.T) {
 		MetricProvider: metricProvider,
 	}
 	logger := klog.FromContext(context.TODO())
-	col, err := NewCollector(logger, &trimaranSpec)
+	col, err := NewCollector(context.TODO(), logger, &trimaranSpec)
 	assert.Nil(t, col)
 	expectedErr := "invalid MetricProvider.Type, got " + string(metricProvider.Type)
 	assert.EqualError(t, err, expectedErr)
@@ -116,7 +118,7 @@ func TestGetAllMetrics(t *testing.T) {
 		WatcherAddress: server.URL,
 	}
 	logger := klog.FromContext(context.TODO())
-	collector, err := NewCollector(logger, &trimaranSpec)
+	collector, err := NewCollector(context.TODO(), logger, &trimaranSpec)
 	assert.NotNil(t, collector)
 	assert.Nil(t, err)
 
@@ -138,7 +140,7 @@ func TestUpdateMetrics(t *testing.T) {
 		WatcherAddress: server.URL,
 	}
 	logger := klog.FromContext(context.TODO())
-	collector, err := NewCollector(logger, &trimaranSpec)
+	collector, err := NewCollector(context.TODO(), logger, &trimaranSpec)
 	assert.NotNil(t, collector)
 	assert.Nil(t, err)
 
@@ -158,11 +160,11 @@ func TestGetNodeMetrics(t *testing.T) {
 		WatcherAddress: server.URL,
 	}
 	logger := klog.FromContext(context.TODO())
-	collector, err := NewCollector(logger, &trimaranSpec)
+	collector, err := NewCollector(context.TODO(), logger, &trimaranSpec)
 	assert.NotNil(t, collector)
 	assert.Nil(t, err)
 	nodeName := "node-1"
-	metrics, allMetrics := collector.GetNodeMetrics(logger, nodeName)
+	metrics, allMetrics, _ := collector.GetNodeMetrics(logger, nodeName)
 	expectedMetrics := watcherResponse.Data.NodeMetricsMap[nodeName].Metrics
 	assert.EqualValues(t, expectedMetrics, metrics)
 	expectedAllMetrics := &watcherResponse
@@ -181,11 +183,11 @@ func TestGetNodeMetricsNilForNode(t *testing.T) {
 		WatcherAddress: server.URL,
 	}
 	logger := klog.FromContext(context.TODO())
-	collector, err := NewCollector(logger, &trimaranSpec)
+	collector, err := NewCollector(context.TODO(), logger, &trimaranSpec)
 	assert.NotNil(t, collector)
 	assert.Nil(t, err)
 	nodeName := "node-1"
-	metrics, allMetrics := collector.GetNodeMetrics(logger, nodeName)
+	metrics, allMetrics, _ := collector.GetNodeMetrics(logger, nodeName)
 	expectedMetrics := noWatcherResponseForNode.Data.NodeMetricsMap[nodeName].Metrics
 	assert.EqualValues(t, expectedMetrics, metrics)
 	assert.NotNil(t, allMetrics)
@@ -211,7 +213,69 @@ func TestNewCollectorLoadWatcher(t *testing.T) {
 		MetricProvider: metricProvider,
 	}
 	logger := klog.FromContext(context.TODO())
-	col, err := NewCollector(logger, &trimaranSpec)
+	col, err := NewCollector(context.TODO(), logger, &trimaranSpec)
 	assert.NotNil(t, col)
 	assert.Nil(t, err)
 }
+
+func TestCollectorStaleness(t *testing.T) {
+	staleResponse := watcherResponse
+	staleResponse.Window = watcher.Window{Duration: "15m", Start: time.Now().Add(-25 * time.Minute).Unix(), End: time.Now().Add(-10 * time.Minute).Unix()}
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		bytes, err := json.Marshal(staleResponse)
+		assert.Nil(t, err)
+		resp.Write(bytes)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := klog.FromContext(ctx)
+
+	tests := []struct {
+		name         string
+		maxStaleness int64
+		wantStale    bool
+	}{
+		{
+			name:         "staleness not checked",
+			maxStaleness: 0,
+			wantStale:    false,
+		},
+		{
+			name:         "metrics older than the maximum staleness",
+			maxStaleness: 60,
+			wantStale:    true,
+		},
+		{
+			name:         "metrics more recent than the maximum staleness",
+			maxStaleness: 3600,
+			wantStale:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trimaranSpec := pluginConfig.TrimaranSpec{
+				WatcherAddress:             server.URL,
+				MetricsMaxStalenessSeconds: tt.maxStaleness,
+			}
+			before, err := testutil.GetCounterMetricValue(staleMetricsTotal.WithLabelValues(server.URL))
+			assert.Nil(t, err)
+
+			collector, err := NewCollector(ctx, logger, &trimaranSpec)
+			assert.Nil(t, err)
+			metrics, _, metricsTime := collector.GetNodeMetrics(logger, "node-1")
+			assert.NotNil(t, metrics)
+			assert.Equal(t, staleResponse.Window.End, metricsTime.Unix())
+			assert.Equal(t, tt.wantStale, collector.IsStale(metricsTime, GetMaxStaleness(&trimaranSpec)))
+
+			after, err := testutil.GetCounterMetricValue(staleMetricsTotal.WithLabelValues(server.URL))
+			assert.Nil(t, err)
+			if tt.wantStale {
+				assert.Equal(t, before+1, after)
+			} else {
+				assert.Equal(t, before, after)
+			}
+		})
+	}
+}
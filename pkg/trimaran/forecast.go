@@ -0,0 +1,258 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trimaran
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/paypal/load-watcher/pkg/watcher"
+	"k8s.io/klog/v2"
+
+	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
+)
+
+const (
+	// ewmaAlpha : smoothing factor of the EWMA predictor
+	ewmaAlpha = 0.3
+	// ewmaHistorySteps : number of steps of history kept for the EWMA predictor
+	ewmaHistorySteps = 60
+	// holtWintersAlpha, holtWintersBeta, holtWintersGamma : smoothing factors of the level, trend and
+	// seasonality of the HoltWinters predictor
+	holtWintersAlpha = 0.3
+	holtWintersBeta  = 0.05
+	holtWintersGamma = 0.1
+	// holtWintersStepsPerSeason : maximum number of steps of history per season for the HoltWinters predictor
+	holtWintersStepsPerSeason = 288
+	// holtWintersHistorySeasons : number of seasons of history kept for the HoltWinters predictor
+	holtWintersHistorySeasons = 3
+)
+
+// Predictor : predict the next values of a series sampled at a fixed step
+type Predictor interface {
+	// Predict : predict the given number of values following the series, nil if the series is too short
+	Predict(values []float64, steps int) []float64
+}
+
+// EWMA : predictor following the exponentially weighted moving average of the series
+type EWMA struct {
+	// smoothing factor, in (0, 1]
+	Alpha float64
+}
+
+var _ Predictor = &EWMA{}
+
+// Predict : predict the moving average of the series for all the steps
+func (p *EWMA) Predict(values []float64, steps int) []float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	level := values[0]
+	for _, value := range values[1:] {
+		level += p.Alpha * (value - level)
+	}
+	predicted := make([]float64, steps)
+	for i := range predicted {
+		predicted[i] = level
+	}
+	return predicted
+}
+
+// HoltWinters : predictor following the level, trend and seasonality of the series, with additive
+// Holt-Winters triple exponential smoothing
+type HoltWinters struct {
+	// smoothing factors of the level, trend and seasonality, in (0, 1]
+	Alpha, Beta, Gamma float64
+	// number of steps in a season
+	Season int
+}
+
+var _ Predictor = &HoltWinters{}
+
+// Predict : predict the series from its last level, trend and seasonality, nil if the series spans less than two seasons
+func (p *HoltWinters) Predict(values []float64, steps int) []float64 {
+	n, season := len(values), p.Season
+	if season < 2 || n < 2*season {
+		return nil
+	}
+	// initialise from the first two seasons
+	firstMean, secondMean := mean(values[:season]), mean(values[season:2*season])
+	level := firstMean
+	trend := (secondMean - firstMean) / float64(season)
+	seasonal := make([]float64, season)
+	for i := range seasonal {
+		seasonal[i] = values[i] - firstMean
+	}
+	for t := season; t < n; t++ {
+		lastLevel, lastSeasonal := level, seasonal[t%season]
+		level = p.Alpha*(values[t]-lastSeasonal) + (1-p.Alpha)*(level+trend)
+		trend = p.Beta*(level-lastLevel) + (1-p.Beta)*trend
+		seasonal[t%season] = p.Gamma*(values[t]-level) + (1-p.Gamma)*lastSeasonal
+	}
+	predicted := make([]float64, steps)
+	for h := range predicted {
+		predicted[h] = level + float64(h+1)*trend + seasonal[(n+h)%season]
+	}
+	return predicted
+}
+
+// mean : get the mean of non-empty values
+func mean(values []float64) float64 {
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+// Forecaster : forecast the utilisation of nodes from their history, for plugins to score nodes on their peak
+// utilisation within the horizon rather than on their current one. A nil Forecaster does not forecast.
+type Forecaster struct {
+	// history of the nodes, shared with other plugins using the same collector and step
+	history *History
+	// predictors by order of preference, the next one being used when the history is too short
+	predictors []Predictor
+	// number of steps of history within the horizon
+	steps int
+
+	// peak of the forecasted utilisation within the horizon of each node, by metric type, computed when the
+	// history is recorded
+	peaks map[string]map[string]float64
+	// for safe access to the peaks
+	mu sync.RWMutex
+}
+
+// newForecaster : create a forecaster of the given history, refreshed on each record
+func newForecaster(history *History, predictors []Predictor, steps int) *Forecaster {
+	forecaster := &Forecaster{
+		history:    history,
+		predictors: predictors,
+		steps:      steps,
+	}
+	history.addForecaster(forecaster)
+	return forecaster
+}
+
+// NewForecaster : create a forecaster of the given spec from the history of the collector, nil if not forecasting
+func NewForecaster(collector *Collector, forecastSpec *pluginConfig.ForecastSpec) (*Forecaster, error) {
+	if forecastSpec.HorizonSeconds < 0 || forecastSpec.SeasonSeconds < 0 {
+		return nil, fmt.Errorf("invalid Forecast, got horizon %vs and season %vs",
+			forecastSpec.HorizonSeconds, forecastSpec.SeasonSeconds)
+	}
+	var (
+		step       = collector.interval
+		capacity   = ewmaHistorySteps
+		predictors []Predictor
+	)
+	switch forecastSpec.Algorithm {
+	case "", pluginConfig.ForecastAlgorithmNone:
+		return nil, nil
+	case pluginConfig.ForecastAlgorithmEWMA:
+		predictors = []Predictor{&EWMA{Alpha: ewmaAlpha}}
+	case pluginConfig.ForecastAlgorithmHoltWinters:
+		seasonDuration := time.Second * time.Duration(forecastSpec.SeasonSeconds)
+		step = max(step, seasonDuration/holtWintersStepsPerSeason)
+		season := int(seasonDuration / step)
+		if season < 2 {
+			return nil, fmt.Errorf("invalid Forecast.SeasonSeconds, got %v for metrics updated every %v",
+				forecastSpec.SeasonSeconds, collector.interval)
+		}
+		capacity = holtWintersHistorySeasons * season
+		// until the history spans two seasons, fall back to the moving average
+		predictors = []Predictor{
+			&HoltWinters{Alpha: holtWintersAlpha, Beta: holtWintersBeta, Gamma: holtWintersGamma, Season: season},
+			&EWMA{Alpha: ewmaAlpha},
+		}
+	default:
+		return nil, fmt.Errorf("invalid Forecast.Algorithm, got %v", forecastSpec.Algorithm)
+	}
+	horizon := time.Second * time.Duration(forecastSpec.HorizonSeconds)
+	return newForecaster(collector.GetHistory(step, capacity), predictors, max(int((horizon+step-1)/step), 1)), nil
+}
+
+// refresh : forecast the peak utilisation of all the nodes of the history
+func (f *Forecaster) refresh() {
+	peaks := make(map[string]map[string]float64)
+	for _, nodeName := range f.history.NodeNames() {
+		for _, metricType := range historyTypes {
+			predicted := f.predict(f.history.Series(nodeName, metricType))
+			if predicted == nil {
+				continue
+			}
+			peak := predicted[0]
+			for _, value := range predicted[1:] {
+				peak = max(peak, value)
+			}
+			if peaks[nodeName] == nil {
+				peaks[nodeName] = make(map[string]float64)
+			}
+			peaks[nodeName][metricType] = peak
+		}
+	}
+	f.mu.Lock()
+	f.peaks = peaks
+	f.mu.Unlock()
+}
+
+// Forecast : get the metrics of a node with its CPU and memory utilisation replaced by the peak of its current
+// and forecasted utilisation within the horizon, as forecasted when the history was last recorded. Metrics are
+// unchanged when the history of the node is too short.
+func (f *Forecaster) Forecast(logger klog.Logger, nodeName string, metrics []watcher.Metric) []watcher.Metric {
+	if f == nil || metrics == nil {
+		return metrics
+	}
+	f.mu.RLock()
+	forecastedPeaks := f.peaks[nodeName]
+	f.mu.RUnlock()
+	peaks := make(map[string]float64)
+	for _, metricType := range historyTypes {
+		current, _, found := GetResourceData(metrics, metricType)
+		if !found {
+			continue
+		}
+		forecastedPeak, ok := forecastedPeaks[metricType]
+		if !ok {
+			continue
+		}
+		peaks[metricType] = max(min(max(current, forecastedPeak), 100), 0)
+		logger.V(6).Info("Forecasted utilization for node", "nodeName", nodeName, "type", metricType,
+			"current", current, "peak", peaks[metricType], "steps", f.steps)
+	}
+	if len(peaks) == 0 {
+		return metrics
+	}
+	forecasted := make([]watcher.Metric, len(metrics))
+	copy(forecasted, metrics)
+	for i, metric := range forecasted {
+		peak, ok := peaks[metric.Type]
+		if ok && (metric.Operator == watcher.Average || metric.Operator == watcher.Latest || metric.Operator == "") {
+			forecasted[i].Value = peak
+		}
+	}
+	return forecasted
+}
+
+// predict : predict the values within the horizon with the first predictor having enough history
+func (f *Forecaster) predict(values []float64) []float64 {
+	for _, predictor := range f.predictors {
+		if predicted := predictor.Predict(values, f.steps); predicted != nil {
+			return predicted
+		}
+	}
+	return nil
+}
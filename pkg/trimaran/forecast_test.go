@@ -0,0 +1,163 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trimaran
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/paypal/load-watcher/pkg/watcher"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/klog/v2"
+
+	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
+)
+
+func TestEWMAPredict(t *testing.T) {
+	predictor := &EWMA{Alpha: 0.5}
+	assert.Nil(t, predictor.Predict(nil, 3))
+	assert.Equal(t, []float64{10, 10}, predictor.Predict([]float64{10}, 2))
+	assert.Equal(t, []float64{22.5, 22.5, 22.5}, predictor.Predict([]float64{10, 20, 30}, 3))
+}
+
+func TestHoltWintersPredict(t *testing.T) {
+	predictor := &HoltWinters{Alpha: 0.3, Beta: 0.05, Gamma: 0.1, Season: 4}
+	daily := []float64{10, 20, 80, 20}
+
+	// less than two seasons of history
+	assert.Nil(t, predictor.Predict(append(daily, daily[:3]...), 2))
+
+	// the seasonality is followed, including the peak not seen in the last values
+	var values []float64
+	for i := 0; i < 3; i++ {
+		values = append(values, daily...)
+	}
+	assert.InDeltaSlice(t, []float64{10, 20, 80, 20, 10}, predictor.Predict(values, 5), 1e-9)
+
+	// the trend is followed
+	var trending []float64
+	for i := 0; i < 40; i++ {
+		trending = append(trending, daily[i%4]+float64(i))
+	}
+	predicted := predictor.Predict(trending, 4)
+	for h, value := range predicted {
+		assert.InDelta(t, daily[h%4]+float64(40+h), value, 2)
+	}
+}
+
+func TestNewForecaster(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		bytes, err := json.Marshal(watcherResponse)
+		assert.Nil(t, err)
+		resp.Write(bytes)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	collector, err := NewCollector(ctx, klog.FromContext(ctx), &pluginConfig.TrimaranSpec{WatcherAddress: server.URL})
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name         string
+		forecastSpec pluginConfig.ForecastSpec
+		wantNil      bool
+		wantErr      bool
+		wantStep     time.Duration
+		wantSteps    int
+	}{
+		{
+			name:         "not forecasting",
+			forecastSpec: pluginConfig.ForecastSpec{Algorithm: pluginConfig.ForecastAlgorithmNone},
+			wantNil:      true,
+		},
+		{
+			name:         "EWMA at the metrics update interval",
+			forecastSpec: pluginConfig.ForecastSpec{Algorithm: pluginConfig.ForecastAlgorithmEWMA, HorizonSeconds: 100},
+			wantStep:     30 * time.Second,
+			wantSteps:    4,
+		},
+		{
+			name: "HoltWinters at a fraction of the season",
+			forecastSpec: pluginConfig.ForecastSpec{Algorithm: pluginConfig.ForecastAlgorithmHoltWinters,
+				HorizonSeconds: 900, SeasonSeconds: 86400},
+			wantStep:  5 * time.Minute,
+			wantSteps: 3,
+		},
+		{
+			name: "season shorter than two metrics updates",
+			forecastSpec: pluginConfig.ForecastSpec{Algorithm: pluginConfig.ForecastAlgorithmHoltWinters,
+				HorizonSeconds: 900, SeasonSeconds: 30},
+			wantErr: true,
+		},
+		{
+			name:         "invalid algorithm",
+			forecastSpec: pluginConfig.ForecastSpec{Algorithm: "ARIMA"},
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forecaster, err := NewForecaster(collector, &tt.forecastSpec)
+			if tt.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			if tt.wantNil {
+				assert.Nil(t, forecaster)
+				return
+			}
+			assert.Equal(t, tt.wantStep, forecaster.history.Step())
+			assert.Equal(t, tt.wantSteps, forecaster.steps)
+		})
+	}
+}
+
+func TestForecast(t *testing.T) {
+	logger := klog.FromContext(context.TODO())
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	forecaster := newForecaster(NewHistory(time.Minute, 10), []Predictor{&EWMA{Alpha: 0.5}}, 1)
+	metrics := watcherResponse.Data.NodeMetricsMap["node-1"].Metrics
+
+	// not forecasting
+	var noForecaster *Forecaster
+	assert.Equal(t, metrics, noForecaster.Forecast(logger, "node-1", metrics))
+	// no history
+	assert.Equal(t, metrics, forecaster.Forecast(logger, "node-1", metrics))
+
+	forecaster.history.Record(makeWatcherMetrics(map[string][2]float64{"node-1": {100, 10}}), start)
+	forecaster.history.Record(makeWatcherMetrics(map[string][2]float64{"node-1": {100, 30}}), start.Add(time.Minute))
+	forecasted := forecaster.Forecast(logger, "node-1", metrics)
+	// the CPU utilisation is forecasted above the current one, the memory one below
+	assert.Equal(t, []watcher.Metric{
+		{Type: watcher.CPU, Operator: watcher.Average, Value: 100},
+		{Type: watcher.CPU, Operator: watcher.Std, Value: 16},
+		{Type: watcher.Memory, Operator: watcher.Average, Value: 25},
+		{Type: watcher.Memory, Operator: watcher.Std, Value: 6.25},
+	}, forecasted)
+	// the metrics of the collector are left unchanged
+	assert.Equal(t, float64(80), metrics[0].Value)
+
+	// forecasts are refreshed when the history is recorded, forgetting nodes missing from it
+	forecaster.history.Record(makeWatcherMetrics(map[string][2]float64{"node-2": {50, 50}}), start.Add(2*time.Minute))
+	assert.Equal(t, metrics, forecaster.Forecast(logger, "node-1", metrics))
+}
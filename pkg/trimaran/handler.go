@@ -57,13 +57,20 @@ type podInfo struct {
 	Pod       *v1.Pod
 }
 
-// New returns a new instance of PodAssignEventHandler, after starting a background go routine for cache cleanup
-func New() *PodAssignEventHandler {
+// New returns a new instance of PodAssignEventHandler, after starting a background go routine for cache cleanup,
+// running until the context is done
+func New(ctx context.Context) *PodAssignEventHandler {
 	p := PodAssignEventHandler{ScheduledPodsCache: make(map[string][]podInfo)}
 	go func() {
 		cacheCleanerTicker := time.NewTicker(time.Minute * cacheCleanupIntervalMinutes)
-		for range cacheCleanerTicker.C {
-			p.cleanupCache()
+		defer cacheCleanerTicker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-cacheCleanerTicker.C:
+				p.cleanupCache()
+			}
 		}
 	}()
 	return &p
@@ -71,7 +78,14 @@ func New() *PodAssignEventHandler {
 
 // AddToHandle : add event handler to framework handle
 func (p *PodAssignEventHandler) AddToHandle(handle framework.Handle) {
-	handle.SharedInformerFactory().Core().V1().Pods().Informer().AddEventHandler(
+	if _, err := p.addToInformer(handle.SharedInformerFactory().Core().V1().Pods().Informer()); err != nil {
+		utilruntime.HandleError(fmt.Errorf("unable to add event handler: %w", err))
+	}
+}
+
+// addToInformer : add event handler to a pod informer, returning its registration
+func (p *PodAssignEventHandler) addToInformer(informer clientcache.SharedIndexInformer) (clientcache.ResourceEventHandlerRegistration, error) {
+	return informer.AddEventHandler(
 		clientcache.FilteringResourceEventHandler{
 			FilterFunc: func(obj interface{}) bool {
 				switch t := obj.(type) {
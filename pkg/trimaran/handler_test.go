@@ -1,6 +1,7 @@
 package trimaran
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -58,7 +59,7 @@ func TestHandlerCacheCleanup(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			p := New()
+			p := New(context.TODO())
 			p.ScheduledPodsCache[testNode] = append(p.ScheduledPodsCache[testNode], tt.podInfoList...)
 			if tt.podToUpdate != "" {
 				pod := st.MakePod().Name(tt.podToUpdate).Obj()
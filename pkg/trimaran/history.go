@@ -0,0 +1,181 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trimaran
+
+import (
+	"sync"
+	"time"
+
+	"github.com/paypal/load-watcher/pkg/watcher"
+)
+
+// historyTypes : types of the metrics kept in the history of nodes
+var historyTypes = []string{watcher.CPU, watcher.Memory}
+
+// History : bounded history of the utilisation of nodes, in percent, sampled at a fixed step.
+// Metrics recorded within the same step are averaged, and steps without metrics repeat the last value.
+type History struct {
+	// duration of a step of the history
+	step time.Duration
+	// maximum number of steps kept per node and metric type
+	capacity int
+	// time of the last recorded metrics
+	recorded time.Time
+	// history of each node, by metric type
+	nodes map[string]map[string]*series
+	// forecasters of the history, refreshed on each record
+	forecasters []*Forecaster
+	// for safe access to the history
+	mu sync.RWMutex
+}
+
+// series : utilisation of a node for a metric type, one value per step
+type series struct {
+	// start of the step of the last value
+	last time.Time
+	// number of metrics averaged in the last value
+	count int
+	// utilisation by step, oldest first
+	values []float64
+}
+
+// NewHistory : create an empty history of the given step and capacity
+func NewHistory(step time.Duration, capacity int) *History {
+	return &History{
+		step:     step,
+		capacity: max(capacity, 1),
+		nodes:    make(map[string]map[string]*series),
+	}
+}
+
+// Step : get the duration of a step of the history
+func (h *History) Step() time.Duration {
+	return h.step
+}
+
+// grow : increase the capacity of the history, if smaller than the given capacity
+func (h *History) grow(capacity int) {
+	h.mu.Lock()
+	h.capacity = max(h.capacity, capacity)
+	h.mu.Unlock()
+}
+
+// addForecaster : add a forecaster to refresh on each record
+func (h *History) addForecaster(forecaster *Forecaster) {
+	h.mu.Lock()
+	h.forecasters = append(h.forecasters, forecaster)
+	h.mu.Unlock()
+	forecaster.refresh()
+}
+
+// Record : record metrics of all nodes at the given time, forgetting nodes missing from them, and refresh the
+// forecasts of the history. Metrics not more recent than the last recorded ones are ignored.
+func (h *History) Record(metrics *watcher.WatcherMetrics, t time.Time) {
+	if h.record(metrics, t) {
+		h.mu.RLock()
+		forecasters := h.forecasters
+		h.mu.RUnlock()
+		for _, forecaster := range forecasters {
+			forecaster.refresh()
+		}
+	}
+}
+
+// record : record metrics of all nodes at the given time, false if they are ignored
+func (h *History) record(metrics *watcher.WatcherMetrics, t time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if metrics.Data.NodeMetricsMap == nil || !t.After(h.recorded) {
+		return false
+	}
+	h.recorded = t
+	for nodeName := range h.nodes {
+		if _, ok := metrics.Data.NodeMetricsMap[nodeName]; !ok {
+			delete(h.nodes, nodeName)
+		}
+	}
+	stepStart := t.Truncate(h.step)
+	for nodeName, nodeMetrics := range metrics.Data.NodeMetricsMap {
+		for _, metricType := range historyTypes {
+			value, _, found := GetResourceData(nodeMetrics.Metrics, metricType)
+			if !found {
+				continue
+			}
+			if h.nodes[nodeName] == nil {
+				h.nodes[nodeName] = make(map[string]*series)
+			}
+			s := h.nodes[nodeName][metricType]
+			if s == nil {
+				s = &series{}
+				h.nodes[nodeName][metricType] = s
+			}
+			s.add(stepStart, value, h.step, h.capacity)
+		}
+	}
+	return true
+}
+
+// add : add a value in the step starting at the given time, keeping at most capacity values
+func (s *series) add(stepStart time.Time, value float64, step time.Duration, capacity int) {
+	switch {
+	case len(s.values) == 0:
+		s.values = append(s.values, value)
+		s.count = 1
+	case stepStart.Equal(s.last):
+		s.count++
+		s.values[len(s.values)-1] += (value - s.values[len(s.values)-1]) / float64(s.count)
+	case stepStart.After(s.last):
+		// fill the steps without metrics with the last value, at most the whole capacity
+		gaps := min(int(stepStart.Sub(s.last)/step)-1, capacity)
+		lastValue := s.values[len(s.values)-1]
+		for i := 0; i < gaps; i++ {
+			s.values = append(s.values, lastValue)
+		}
+		s.values = append(s.values, value)
+		s.count = 1
+	default:
+		return
+	}
+	s.last = stepStart
+	if len(s.values) > capacity {
+		s.values = append(s.values[:0], s.values[len(s.values)-capacity:]...)
+	}
+}
+
+// Series : get the history of a node for a metric type, oldest first
+func (h *History) Series(nodeName string, metricType string) []float64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	s := h.nodes[nodeName][metricType]
+	if s == nil {
+		return nil
+	}
+	values := make([]float64, len(s.values))
+	copy(values, s.values)
+	return values
+}
+
+// NodeNames : get the names of the nodes in the history
+func (h *History) NodeNames() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	nodeNames := make([]string, 0, len(h.nodes))
+	for nodeName := range h.nodes {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	return nodeNames
+}
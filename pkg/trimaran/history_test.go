@@ -0,0 +1,74 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trimaran
+
+import (
+	"testing"
+	"time"
+
+	"github.com/paypal/load-watcher/pkg/watcher"
+	"github.com/stretchr/testify/assert"
+)
+
+// makeWatcherMetrics : get watcher metrics with the average CPU and memory utilisation of the given nodes
+func makeWatcherMetrics(utilisations map[string][2]float64) *watcher.WatcherMetrics {
+	nodeMetricsMap := make(map[string]watcher.NodeMetrics)
+	for nodeName, utilisation := range utilisations {
+		nodeMetricsMap[nodeName] = watcher.NodeMetrics{
+			Metrics: []watcher.Metric{
+				{Type: watcher.CPU, Operator: watcher.Average, Value: utilisation[0]},
+				{Type: watcher.Memory, Operator: watcher.Average, Value: utilisation[1]},
+			},
+		}
+	}
+	return &watcher.WatcherMetrics{Data: watcher.Data{NodeMetricsMap: nodeMetricsMap}}
+}
+
+func TestHistoryRecord(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := NewHistory(time.Minute, 4)
+
+	history.Record(makeWatcherMetrics(map[string][2]float64{"node-1": {10, 50}, "node-2": {70, 20}}), start)
+	assert.Equal(t, []float64{10}, history.Series("node-1", watcher.CPU))
+	assert.Equal(t, []float64{20}, history.Series("node-2", watcher.Memory))
+
+	// metrics within the same step are averaged
+	history.Record(makeWatcherMetrics(map[string][2]float64{"node-1": {20, 60}, "node-2": {70, 20}}), start.Add(20*time.Second))
+	assert.Equal(t, []float64{15}, history.Series("node-1", watcher.CPU))
+	assert.Equal(t, []float64{55}, history.Series("node-1", watcher.Memory))
+
+	// metrics not more recent than the last recorded ones are ignored
+	history.Record(makeWatcherMetrics(map[string][2]float64{"node-1": {90, 90}, "node-2": {70, 20}}), start.Add(20*time.Second))
+	assert.Equal(t, []float64{15}, history.Series("node-1", watcher.CPU))
+
+	// steps without metrics repeat the last value, and nodes missing from the metrics are forgotten
+	history.Record(makeWatcherMetrics(map[string][2]float64{"node-1": {40, 60}}), start.Add(3*time.Minute))
+	assert.Equal(t, []float64{15, 15, 15, 40}, history.Series("node-1", watcher.CPU))
+	assert.Nil(t, history.Series("node-2", watcher.CPU))
+
+	// the history is bounded by its capacity
+	history.Record(makeWatcherMetrics(map[string][2]float64{"node-1": {50, 60}}), start.Add(4*time.Minute))
+	assert.Equal(t, []float64{15, 15, 40, 50}, history.Series("node-1", watcher.CPU))
+	history.Record(makeWatcherMetrics(map[string][2]float64{"node-1": {60, 60}}), start.Add(time.Hour))
+	assert.Equal(t, []float64{50, 50, 50, 60}, history.Series("node-1", watcher.CPU))
+
+	// the capacity only grows
+	history.grow(6)
+	history.grow(2)
+	history.Record(makeWatcherMetrics(map[string][2]float64{"node-1": {70, 60}}), start.Add(time.Hour+2*time.Minute))
+	assert.Equal(t, []float64{50, 50, 50, 60, 60, 70}, history.Series("node-1", watcher.CPU))
+}
@@ -46,6 +46,7 @@ type LoadVariationRiskBalancing struct {
 	handle       framework.Handle
 	eventHandler *trimaran.PodAssignEventHandler
 	collector    *trimaran.Collector
+	forecaster   *trimaran.Forecaster
 	args         *pluginConfig.LoadVariationRiskBalancingArgs
 }
 
@@ -60,20 +61,28 @@ func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (fram
 	if !ok {
 		return nil, fmt.Errorf("want args to be of type LoadVariationRiskBalancingArgs, got %T", obj)
 	}
-	collector, err := trimaran.NewCollector(logger, &args.TrimaranSpec)
+	collector, err := trimaran.GetCollector(ctx, logger, &args.TrimaranSpec)
 	if err != nil {
 		return nil, err
 	}
-	logger.V(4).Info("Using LoadVariationRiskBalancingArgs", "margin", args.SafeVarianceMargin, "sensitivity", args.SafeVarianceSensitivity)
+	forecaster, err := trimaran.NewForecaster(collector, &args.Forecast)
+	if err != nil {
+		return nil, err
+	}
+	logger.V(4).Info("Using LoadVariationRiskBalancingArgs", "margin", args.SafeVarianceMargin, "sensitivity", args.SafeVarianceSensitivity,
+		"forecast", args.Forecast.Algorithm)
 
-	podAssignEventHandler := trimaran.New()
-	podAssignEventHandler.AddToHandle(handle)
+	podAssignEventHandler, err := trimaran.GetPodAssignEventHandler(ctx, handle)
+	if err != nil {
+		return nil, err
+	}
 
 	pl := &LoadVariationRiskBalancing{
 		logger:       logger,
 		handle:       handle,
 		eventHandler: podAssignEventHandler,
 		collector:    collector,
+		forecaster:   forecaster,
 		args:         args,
 	}
 	return pl, nil
@@ -89,7 +98,15 @@ func (pl *LoadVariationRiskBalancing) Score(ctx context.Context, cycleState *fra
 		return score, framework.NewStatus(framework.Error, fmt.Sprintf("getting node %q from Snapshot: %v", nodeName, err))
 	}
 	// get node metrics
-	metrics, _ := pl.collector.GetNodeMetrics(logger, nodeName)
+	metrics, _, metricsTime := pl.collector.GetNodeMetrics(logger, nodeName)
+	if pl.collector.IsStale(metricsTime, trimaran.GetMaxStaleness(&pl.args.TrimaranSpec)) {
+		var neutral bool
+		if metrics, neutral = trimaran.ApplyStaleMetricsPolicy(logger, pl.args.StaleMetricsPolicy, nodeInfo); neutral {
+			return trimaran.NeutralScore, nil
+		}
+	} else {
+		metrics = pl.forecaster.Forecast(logger, nodeName, metrics)
+	}
 	if metrics == nil {
 		logger.Info("Failed to get metrics for node; using minimum score", "nodeName", nodeName)
 		return score, nil
@@ -67,7 +67,7 @@ func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (fram
 	if !ok {
 		return nil, fmt.Errorf("want args to be of type LowRiskOverCommitmentArgs, got %T", obj)
 	}
-	collector, err := trimaran.NewCollector(logger, &args.TrimaranSpec)
+	collector, err := trimaran.GetCollector(ctx, logger, &args.TrimaranSpec)
 	if err != nil {
 		return nil, err
 	}
@@ -131,7 +131,13 @@ func (pl *LowRiskOverCommitment) Score(ctx context.Context, cycleState *framewor
 		return score, framework.NewStatus(framework.Error, fmt.Sprintf("getting node %q from Snapshot: %v", nodeName, err))
 	}
 	// get node metrics
-	metrics, _ := pl.collector.GetNodeMetrics(logger, nodeName)
+	metrics, _, metricsTime := pl.collector.GetNodeMetrics(logger, nodeName)
+	if pl.collector.IsStale(metricsTime, trimaran.GetMaxStaleness(&pl.args.TrimaranSpec)) {
+		var neutral bool
+		if metrics, neutral = trimaran.ApplyStaleMetricsPolicy(logger, pl.args.StaleMetricsPolicy, nodeInfo); neutral {
+			return trimaran.NeutralScore, nil
+		}
+	}
 	if metrics == nil {
 		logger.Info("Failed to get metrics for node; using minimum score", "nodeName", nodeName)
 		return score, nil
@@ -0,0 +1,55 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trimaran
+
+import (
+	"sync"
+
+	basemetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const metricsSubsystem = "trimaran"
+
+var (
+	// metricsAge : age of the metrics served by the collectors, by load watcher source
+	metricsAge = basemetrics.NewGaugeVec(
+		&basemetrics.GaugeOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "metrics_age_seconds",
+			Help:           "Age in seconds of the metrics window last fetched from load watcher, by source.",
+			StabilityLevel: basemetrics.ALPHA,
+		}, []string{"source"})
+
+	// staleMetricsTotal : number of times the metrics of the collectors went stale, by load watcher source
+	staleMetricsTotal = basemetrics.NewCounterVec(
+		&basemetrics.CounterOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "stale_metrics_total",
+			Help:           "Number of times the metrics fetched from load watcher went stale, by source.",
+			StabilityLevel: basemetrics.ALPHA,
+		}, []string{"source"})
+
+	registerMetrics sync.Once
+)
+
+// RegisterMetrics : register the Trimaran metrics in the legacy registry
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(metricsAge, staleMetricsTotal)
+	})
+}
@@ -81,7 +81,7 @@ func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (fram
 	if !ok {
 		return nil, fmt.Errorf("want args to be of type PeaksArgs, got %T", obj)
 	}
-	collector, err := trimaran.NewCollector(logger, &config.TrimaranSpec{WatcherAddress: args.WatcherAddress})
+	collector, err := trimaran.GetCollector(ctx, logger, &config.TrimaranSpec{WatcherAddress: args.WatcherAddress})
 	if err != nil {
 		return nil, err
 	}
@@ -109,7 +109,7 @@ func (pl *Peaks) Score(ctx context.Context, cycleState *framework.CycleState, po
 		return score, framework.NewStatus(framework.Error, fmt.Sprintf("getting node %q from Snapshot: %v", nodeName, err))
 	}
 
-	metrics, _ := pl.collector.GetNodeMetrics(logger, nodeName)
+	metrics, _, _ := pl.collector.GetNodeMetrics(logger, nodeName)
 	if metrics == nil {
 		logger.Error(nil, "Failed to get metrics for node; using minimum score", "nodeName", nodeName)
 		return score, nil
@@ -0,0 +1,189 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trimaran
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientcache "k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
+)
+
+// defaultRegistry : registry shared by the Trimaran plugins of the scheduler process
+var defaultRegistry = newRegistry()
+
+// registry : reference-counted collectors and event handlers, shared by the Trimaran plugins.
+// Each plugin holds a reference until the context it was created with is done. Upon the last reference
+// being released, the collector stops polling load watcher and the event handler is removed from its informer.
+type registry struct {
+	sync.Mutex
+	// collectors by TrimaranSpec
+	collectors map[pluginConfig.TrimaranSpec]*collectorEntry
+	// event handlers by pod informer
+	handlers map[clientcache.SharedIndexInformer]*handlerEntry
+}
+
+// collectorEntry : a shared collector and its references
+type collectorEntry struct {
+	collector *Collector
+	refs      int
+	cancel    context.CancelFunc
+	// references by maximum staleness of the metrics, the collector reporting the smallest one
+	maxStalenesses map[time.Duration]int
+}
+
+// handlerEntry : a shared event handler, its registration in the informer and its references
+type handlerEntry struct {
+	handler      *PodAssignEventHandler
+	registration clientcache.ResourceEventHandlerRegistration
+	refs         int
+	cancel       context.CancelFunc
+}
+
+// newRegistry : create an empty registry
+func newRegistry() *registry {
+	return &registry{
+		collectors: make(map[pluginConfig.TrimaranSpec]*collectorEntry),
+		handlers:   make(map[clientcache.SharedIndexInformer]*handlerEntry),
+	}
+}
+
+// GetCollector : get the collector shared by the plugins with the same TrimaranSpec, creating it if needed.
+// The reference is released when the context is done.
+func GetCollector(ctx context.Context, logger klog.Logger, trimaranSpec *pluginConfig.TrimaranSpec) (*Collector, error) {
+	return defaultRegistry.getCollector(ctx, logger, trimaranSpec)
+}
+
+// GetPodAssignEventHandler : get the event handler shared by the plugins with the same framework handle informers,
+// creating and adding it to the pod informer if needed. The reference is released when the context is done.
+func GetPodAssignEventHandler(ctx context.Context, handle framework.Handle) (*PodAssignEventHandler, error) {
+	return defaultRegistry.getPodAssignEventHandler(ctx, handle.SharedInformerFactory().Core().V1().Pods().Informer())
+}
+
+func (r *registry) getCollector(ctx context.Context, logger klog.Logger, trimaranSpec *pluginConfig.TrimaranSpec) (*Collector, error) {
+	if err := checkSpecs(trimaranSpec); err != nil {
+		return nil, err
+	}
+	// Specs only differing by an unset interval, or by the maximum staleness and stale metrics policy applied by
+	// the plugins, share the collector
+	key := *trimaranSpec
+	key.MetricsUpdateIntervalSeconds = int64(getMetricsUpdateInterval(trimaranSpec).Seconds())
+	key.MetricsMaxStalenessSeconds = 0
+	key.StaleMetricsPolicy = ""
+	maxStaleness := GetMaxStaleness(trimaranSpec)
+
+	r.Lock()
+	defer r.Unlock()
+	entry, ok := r.collectors[key]
+	if !ok {
+		// The collector lives as long as it is referenced, not as long as the plugin creating it
+		collectorCtx, cancel := context.WithCancel(context.Background())
+		collector, err := NewCollector(collectorCtx, logger, &key)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		entry = &collectorEntry{collector: collector, cancel: cancel, maxStalenesses: make(map[time.Duration]int)}
+		r.collectors[key] = entry
+	}
+	entry.refs++
+	entry.maxStalenesses[maxStaleness]++
+	entry.updateMaxStaleness()
+	logger.V(4).Info("Using shared collector", "references", entry.refs)
+
+	go func() {
+		<-ctx.Done()
+		r.releaseCollector(logger, key, entry, maxStaleness)
+	}()
+	return entry.collector, nil
+}
+
+func (r *registry) releaseCollector(logger klog.Logger, key pluginConfig.TrimaranSpec, entry *collectorEntry, maxStaleness time.Duration) {
+	r.Lock()
+	defer r.Unlock()
+	entry.refs--
+	if entry.maxStalenesses[maxStaleness]--; entry.maxStalenesses[maxStaleness] == 0 {
+		delete(entry.maxStalenesses, maxStaleness)
+	}
+	if entry.refs > 0 {
+		entry.updateMaxStaleness()
+		return
+	}
+	logger.V(4).Info("Releasing shared collector")
+	entry.cancel()
+	if r.collectors[key] == entry {
+		delete(r.collectors, key)
+	}
+}
+
+// updateMaxStaleness : report stale metrics with the smallest maximum staleness of the references checking it
+func (entry *collectorEntry) updateMaxStaleness() {
+	var smallest time.Duration
+	for maxStaleness := range entry.maxStalenesses {
+		if maxStaleness > 0 && (smallest == 0 || maxStaleness < smallest) {
+			smallest = maxStaleness
+		}
+	}
+	entry.collector.setMaxStaleness(smallest)
+}
+
+func (r *registry) getPodAssignEventHandler(ctx context.Context, informer clientcache.SharedIndexInformer) (*PodAssignEventHandler, error) {
+	r.Lock()
+	defer r.Unlock()
+	entry, ok := r.handlers[informer]
+	if !ok {
+		handlerCtx, cancel := context.WithCancel(context.Background())
+		handler := New(handlerCtx)
+		registration, err := handler.addToInformer(informer)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("unable to add event handler: %w", err)
+		}
+		entry = &handlerEntry{handler: handler, registration: registration, cancel: cancel}
+		r.handlers[informer] = entry
+	}
+	entry.refs++
+
+	go func() {
+		<-ctx.Done()
+		r.releasePodAssignEventHandler(informer, entry)
+	}()
+	return entry.handler, nil
+}
+
+func (r *registry) releasePodAssignEventHandler(informer clientcache.SharedIndexInformer, entry *handlerEntry) {
+	r.Lock()
+	defer r.Unlock()
+	entry.refs--
+	if entry.refs > 0 {
+		return
+	}
+	entry.cancel()
+	if err := informer.RemoveEventHandler(entry.registration); err != nil {
+		utilruntime.HandleError(fmt.Errorf("unable to remove event handler: %w", err))
+	}
+	if r.handlers[informer] == entry {
+		delete(r.handlers, informer)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trimaran
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/klog/v2"
+
+	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
+)
+
+func TestRegistryCollectors(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		requests.Add(1)
+		bytes, err := json.Marshal(watcherResponse)
+		assert.Nil(t, err)
+		resp.Write(bytes)
+	}))
+	defer server.Close()
+
+	logger := klog.FromContext(context.TODO())
+	r := newRegistry()
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	ctx3, cancel3 := context.WithCancel(context.Background())
+	defer cancel3()
+
+	// Plugins with the same spec share the collector, an unset interval being the default one
+	collector1, err := r.getCollector(ctx1, logger, &pluginConfig.TrimaranSpec{WatcherAddress: server.URL})
+	assert.Nil(t, err)
+	collector2, err := r.getCollector(ctx2, logger, &pluginConfig.TrimaranSpec{
+		WatcherAddress: server.URL, MetricsUpdateIntervalSeconds: metricsUpdateIntervalSeconds})
+	assert.Nil(t, err)
+	assert.Same(t, collector1, collector2)
+	assert.Equal(t, int32(1), requests.Load())
+
+	// Plugins only differing by the maximum staleness share the collector, reporting the smallest one
+	ctx4, cancel4 := context.WithCancel(context.Background())
+	collector4, err := r.getCollector(ctx4, logger, &pluginConfig.TrimaranSpec{
+		WatcherAddress: server.URL, MetricsMaxStalenessSeconds: 120, StaleMetricsPolicy: pluginConfig.StaleMetricsPolicyUnknown})
+	assert.Nil(t, err)
+	assert.Same(t, collector1, collector4)
+	assert.Equal(t, int32(1), requests.Load())
+	assert.Equal(t, 120*time.Second, collector1.maxStaleness)
+	cancel4()
+	assert.Eventually(t, func() bool {
+		collector1.mu.RLock()
+		defer collector1.mu.RUnlock()
+		return collector1.maxStaleness == 0
+	}, time.Second, 10*time.Millisecond)
+
+	// Plugins with different specs have different collectors
+	collector3, err := r.getCollector(ctx3, logger, &pluginConfig.TrimaranSpec{
+		WatcherAddress: server.URL, MetricsUpdateIntervalSeconds: 10})
+	assert.Nil(t, err)
+	assert.NotSame(t, collector1, collector3)
+	assert.Equal(t, int32(2), requests.Load())
+
+	// The collector is released with its last reference
+	cancel1()
+	assert.Never(t, func() bool {
+		r.Lock()
+		defer r.Unlock()
+		return len(r.collectors) != 2
+	}, 100*time.Millisecond, 10*time.Millisecond)
+	cancel2()
+	assert.Eventually(t, func() bool {
+		r.Lock()
+		defer r.Unlock()
+		return len(r.collectors) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// Invalid specs are not registered
+	_, err = r.getCollector(context.Background(), logger, &pluginConfig.TrimaranSpec{})
+	assert.NotNil(t, err)
+	_, err = r.getCollector(context.Background(), logger, &pluginConfig.TrimaranSpec{
+		WatcherAddress: server.URL, StaleMetricsPolicy: "Invalid"})
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, len(r.collectors))
+}
+
+func TestRegistryPodAssignEventHandlers(t *testing.T) {
+	informer := informers.NewSharedInformerFactory(clientsetfake.NewSimpleClientset(), 0).Core().V1().Pods().Informer()
+	r := newRegistry()
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithCancel(context.Background())
+
+	handler1, err := r.getPodAssignEventHandler(ctx1, informer)
+	assert.Nil(t, err)
+	handler2, err := r.getPodAssignEventHandler(ctx2, informer)
+	assert.Nil(t, err)
+	assert.Same(t, handler1, handler2)
+
+	// The handler is released with its last reference
+	cancel1()
+	cancel2()
+	assert.Eventually(t, func() bool {
+		r.Lock()
+		defer r.Unlock()
+		return len(r.handlers) == 0
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trimaran
+
+import (
+	"github.com/paypal/load-watcher/pkg/watcher"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
+)
+
+// NeutralScore : score of the nodes scored neutrally, as their metrics are stale
+const NeutralScore = (framework.MinNodeScore + framework.MaxNodeScore) / 2
+
+// ApplyStaleMetricsPolicy : get the metrics to score a node with, as its metrics are stale, according to the given
+// policy. Nil metrics mean that the node is scored as a node without metrics. Returns true if the node is to be scored
+// neutrally instead.
+func ApplyStaleMetricsPolicy(logger klog.Logger, policy string, nodeInfo *framework.NodeInfo) ([]watcher.Metric, bool) {
+	switch policy {
+	case pluginConfig.StaleMetricsPolicyRequests:
+		logger.V(6).Info("Stale metrics for node; using requests of its pods", "nodeName", nodeInfo.Node().Name)
+		return GetRequestsMetrics(nodeInfo), false
+	case pluginConfig.StaleMetricsPolicyUnknown:
+		logger.V(6).Info("Stale metrics for node; ignoring them", "nodeName", nodeInfo.Node().Name)
+		return nil, false
+	default:
+		logger.V(6).Info("Stale metrics for node; using neutral score", "nodeName", nodeInfo.Node().Name)
+		return nil, true
+	}
+}
+
+// GetRequestsMetrics : get metrics of a node from the requests of its pods, in percent of the allocatable resources
+func GetRequestsMetrics(nodeInfo *framework.NodeInfo) []watcher.Metric {
+	var metrics []watcher.Metric
+	if nodeInfo.Allocatable.MilliCPU > 0 {
+		metrics = append(metrics, watcher.Metric{
+			Type:     watcher.CPU,
+			Operator: watcher.Average,
+			Value:    100 * float64(nodeInfo.Requested.MilliCPU) / float64(nodeInfo.Allocatable.MilliCPU),
+		})
+	}
+	if nodeInfo.Allocatable.Memory > 0 {
+		metrics = append(metrics, watcher.Metric{
+			Type:     watcher.Memory,
+			Operator: watcher.Average,
+			Value:    100 * float64(nodeInfo.Requested.Memory) / float64(nodeInfo.Allocatable.Memory),
+		})
+	}
+	return metrics
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trimaran
+
+import (
+	"context"
+	"testing"
+
+	"github.com/paypal/load-watcher/pkg/watcher"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+
+	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
+)
+
+func TestApplyStaleMetricsPolicy(t *testing.T) {
+	node := st.MakeNode().Name("node-1").Capacity(map[v1.ResourceName]string{
+		v1.ResourceCPU:    "4",
+		v1.ResourceMemory: "8Gi",
+	}).Obj()
+	pod := st.MakePod().Name("p").Req(map[v1.ResourceName]string{
+		v1.ResourceCPU:    "1",
+		v1.ResourceMemory: "4Gi",
+	}).Obj()
+	nodeInfo := framework.NewNodeInfo(pod)
+	nodeInfo.SetNode(node)
+
+	tests := []struct {
+		policy      string
+		wantMetrics []watcher.Metric
+		wantNeutral bool
+	}{
+		{
+			policy:      pluginConfig.StaleMetricsPolicyNeutral,
+			wantNeutral: true,
+		},
+		{
+			policy: pluginConfig.StaleMetricsPolicyRequests,
+			wantMetrics: []watcher.Metric{
+				{Type: watcher.CPU, Operator: watcher.Average, Value: 25},
+				{Type: watcher.Memory, Operator: watcher.Average, Value: 50},
+			},
+		},
+		{
+			policy: pluginConfig.StaleMetricsPolicyUnknown,
+		},
+		{
+			policy:      "",
+			wantNeutral: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			logger := klog.FromContext(context.TODO())
+			metrics, neutral := ApplyStaleMetricsPolicy(logger, tt.policy, nodeInfo)
+			assert.Equal(t, tt.wantMetrics, metrics)
+			assert.Equal(t, tt.wantNeutral, neutral)
+		})
+	}
+}
@@ -57,6 +57,7 @@ type TargetLoadPacking struct {
 	handle       framework.Handle
 	eventHandler *trimaran.PodAssignEventHandler
 	collector    *trimaran.Collector
+	forecaster   *trimaran.Forecaster
 	args         *pluginConfig.TargetLoadPackingArgs
 }
 
@@ -70,7 +71,7 @@ func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (fram
 	if !ok {
 		return nil, fmt.Errorf("want args to be of type TargetLoadPackingArgs, got %T", obj)
 	}
-	collector, err := trimaran.NewCollector(logger, &args.TrimaranSpec)
+	collector, err := trimaran.GetCollector(ctx, logger, &args.TrimaranSpec)
 	if err != nil {
 		return nil, err
 	}
@@ -82,19 +83,28 @@ func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (fram
 		return nil, errors.New("unable to parse DefaultRequestsMultiplier: " + err.Error())
 	}
 
+	forecaster, err := trimaran.NewForecaster(collector, &args.Forecast)
+	if err != nil {
+		return nil, err
+	}
+
 	logger.V(4).Info("Using TargetLoadPackingArgs",
 		"requestsMilliCores", requestsMilliCores,
 		"requestsMultiplier", requestsMultiplier,
-		"targetUtilization", hostTargetUtilizationPercent)
+		"targetUtilization", hostTargetUtilizationPercent,
+		"forecast", args.Forecast.Algorithm)
 
-	podAssignEventHandler := trimaran.New()
-	podAssignEventHandler.AddToHandle(handle)
+	podAssignEventHandler, err := trimaran.GetPodAssignEventHandler(ctx, handle)
+	if err != nil {
+		return nil, err
+	}
 
 	pl := &TargetLoadPacking{
 		logger:       logger,
 		handle:       handle,
 		eventHandler: podAssignEventHandler,
 		collector:    collector,
+		forecaster:   forecaster,
 		args:         args,
 	}
 	return pl, nil
@@ -113,7 +123,16 @@ func (pl *TargetLoadPacking) Score(ctx context.Context, cycleState *framework.Cy
 	}
 
 	// get node metrics
-	metrics, allMetrics := pl.collector.GetNodeMetrics(logger, nodeName)
+	metrics, allMetrics, metricsTime := pl.collector.GetNodeMetrics(logger, nodeName)
+	stale := pl.collector.IsStale(metricsTime, trimaran.GetMaxStaleness(&pl.args.TrimaranSpec))
+	if stale {
+		var neutral bool
+		if metrics, neutral = trimaran.ApplyStaleMetricsPolicy(logger, pl.args.StaleMetricsPolicy, nodeInfo); neutral {
+			return trimaran.NeutralScore, nil
+		}
+	} else {
+		metrics = pl.forecaster.Forecast(logger, nodeName, metrics)
+	}
 	if metrics == nil {
 		klog.InfoS("Failed to get metrics for node; using minimum score", "nodeName", nodeName)
 		// Avoid the node by scoring minimum
@@ -152,22 +171,25 @@ func (pl *TargetLoadPacking) Score(ctx context.Context, cycleState *framework.Cy
 	logger.V(6).Info("Calculating CPU utilization and capacity", "nodeName", nodeName, "cpuUtilMillis", nodeCPUUtilMillis, "cpuCapMillis", nodeCPUCapMillis)
 
 	var missingCPUUtilMillis int64 = 0
-	pl.eventHandler.RLock()
-	for _, info := range pl.eventHandler.ScheduledPodsCache[nodeName] {
-		// If the time stamp of the scheduled pod is outside fetched metrics window, or it is within metrics reporting interval seconds, we predict util.
-		// Note that the second condition doesn't guarantee metrics for that pod are not reported yet as the 0 <= t <= 2*metricsAgentReportingIntervalSeconds
-		// t = metricsAgentReportingIntervalSeconds is taken as average case and it doesn't hurt us much if we are
-		// counting metrics twice in case actual t is less than metricsAgentReportingIntervalSeconds
-		if info.Timestamp.Unix() > allMetrics.Window.End || info.Timestamp.Unix() <= allMetrics.Window.End &&
-			(allMetrics.Window.End-info.Timestamp.Unix()) < metricsAgentReportingIntervalSeconds {
-			for _, container := range info.Pod.Spec.Containers {
-				missingCPUUtilMillis += PredictUtilisation(&container)
+	// Metrics derived from requests, as metrics are stale, already account for the pods scheduled on the node
+	if !stale {
+		pl.eventHandler.RLock()
+		for _, info := range pl.eventHandler.ScheduledPodsCache[nodeName] {
+			// If the time stamp of the scheduled pod is outside fetched metrics window, or it is within metrics reporting interval seconds, we predict util.
+			// Note that the second condition doesn't guarantee metrics for that pod are not reported yet as the 0 <= t <= 2*metricsAgentReportingIntervalSeconds
+			// t = metricsAgentReportingIntervalSeconds is taken as average case and it doesn't hurt us much if we are
+			// counting metrics twice in case actual t is less than metricsAgentReportingIntervalSeconds
+			if info.Timestamp.Unix() > allMetrics.Window.End || info.Timestamp.Unix() <= allMetrics.Window.End &&
+				(allMetrics.Window.End-info.Timestamp.Unix()) < metricsAgentReportingIntervalSeconds {
+				for _, container := range info.Pod.Spec.Containers {
+					missingCPUUtilMillis += PredictUtilisation(&container)
+				}
+				missingCPUUtilMillis += info.Pod.Spec.Overhead.Cpu().MilliValue()
+				logger.V(6).Info("Missing utilization for pod", "podName", info.Pod.Name, "missingCPUUtilMillis", missingCPUUtilMillis)
 			}
-			missingCPUUtilMillis += info.Pod.Spec.Overhead.Cpu().MilliValue()
-			logger.V(6).Info("Missing utilization for pod", "podName", info.Pod.Name, "missingCPUUtilMillis", missingCPUUtilMillis)
 		}
+		pl.eventHandler.RUnlock()
 	}
-	pl.eventHandler.RUnlock()
 	logger.V(6).Info("Missing utilization for node", "nodeName", nodeName, "missingCPUUtilMillis", missingCPUUtilMillis)
 
 	var predictedCPUUsage float64
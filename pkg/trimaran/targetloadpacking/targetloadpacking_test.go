@@ -25,6 +25,7 @@ import (
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/paypal/load-watcher/pkg/watcher"
 	"github.com/stretchr/testify/assert"
@@ -47,6 +48,7 @@ import (
 
 	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
 	cfgv1 "sigs.k8s.io/scheduler-plugins/apis/config/v1"
+	"sigs.k8s.io/scheduler-plugins/pkg/trimaran"
 )
 
 var _ framework.SharedLister = &testSharedLister{}
@@ -277,6 +279,201 @@ func TestTargetLoadPackingScoring(t *testing.T) {
 	}
 }
 
+func TestTargetLoadPackingStaleMetrics(t *testing.T) {
+	// Metrics of a hot node, last updated 10 minutes ago
+	staleResponse := watcher.WatcherMetrics{
+		Window: watcher.Window{End: time.Now().Add(-10 * time.Minute).Unix()},
+		Data: watcher.Data{
+			NodeMetricsMap: map[string]watcher.NodeMetrics{
+				"node-1": {
+					Metrics: []watcher.Metric{
+						{
+							Type:     watcher.CPU,
+							Value:    90,
+							Operator: watcher.Latest,
+						},
+					},
+				},
+			},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		bytes, err := json.Marshal(staleResponse)
+		assert.Nil(t, err)
+		resp.Write(bytes)
+	}))
+	defer server.Close()
+
+	node := st.MakeNode().Name("node-1").Capacity(map[v1.ResourceName]string{
+		v1.ResourceCPU:    "1000m",
+		v1.ResourceMemory: "1Gi",
+	}).Obj()
+	existingPod := st.MakePod().Name("existing").Node("node-1").Req(map[v1.ResourceName]string{v1.ResourceCPU: "200m"}).Obj()
+
+	tests := []struct {
+		test         string
+		maxStaleness int64
+		policy       string
+		expected     int64
+	}{
+		{
+			test:     "staleness not checked uses the metrics",
+			policy:   pluginConfig.StaleMetricsPolicyNeutral,
+			expected: 7,
+		},
+		{
+			test:         "neutral policy",
+			maxStaleness: 60,
+			policy:       pluginConfig.StaleMetricsPolicyNeutral,
+			expected:     50,
+		},
+		{
+			test:         "requests policy packs on the requested CPU",
+			maxStaleness: 60,
+			policy:       pluginConfig.StaleMetricsPolicyRequests,
+			expected:     70,
+		},
+		{
+			test:         "unknown policy returns min score",
+			maxStaleness: 60,
+			policy:       pluginConfig.StaleMetricsPolicyUnknown,
+			expected:     framework.MinNodeScore,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.test, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			cs := testClientSet.NewSimpleClientset()
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+			snapshot := newTestSharedLister([]*v1.Pod{existingPod}, []*v1.Node{node})
+			registeredPlugins := []tf.RegisterPluginFunc{
+				tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+				tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+				tf.RegisterScorePlugin(Name, New, 1),
+			}
+			targetLoadPackingArgs := pluginConfig.TargetLoadPackingArgs{
+				TrimaranSpec: pluginConfig.TrimaranSpec{
+					WatcherAddress:             server.URL,
+					MetricsMaxStalenessSeconds: tt.maxStaleness,
+					StaleMetricsPolicy:         tt.policy,
+				},
+				TargetUtilization:         cfgv1.DefaultTargetUtilizationPercent,
+				DefaultRequestsMultiplier: cfgv1.DefaultRequestsMultiplier,
+			}
+			fh, err := testutil.NewFramework(ctx, registeredPlugins, []config.PluginConfig{{Name: Name, Args: &targetLoadPackingArgs}},
+				"default-scheduler", runtime.WithClientSet(cs),
+				runtime.WithInformerFactory(informerFactory), runtime.WithSnapshotSharedLister(snapshot))
+			assert.Nil(t, err)
+			p, err := New(ctx, &targetLoadPackingArgs, fh)
+			assert.Nil(t, err)
+			score, status := p.(framework.ScorePlugin).Score(context.Background(), framework.NewCycleState(), st.MakePod().Name("p").Obj(), node.Name)
+			assert.True(t, status.IsSuccess())
+			assert.Equal(t, tt.expected, score)
+		})
+	}
+}
+
+func TestTargetLoadPackingForecast(t *testing.T) {
+	metrics.Register()
+	// Metrics of a node, whose CPU utilisation rose since they were fetched
+	start := time.Now().Truncate(30 * time.Second).Add(-10 * time.Minute)
+	response := watcher.WatcherMetrics{
+		Window: watcher.Window{End: start.Unix()},
+		Data: watcher.Data{
+			NodeMetricsMap: map[string]watcher.NodeMetrics{
+				"node-1": {
+					Metrics: []watcher.Metric{
+						{
+							Type:     watcher.CPU,
+							Value:    30,
+							Operator: watcher.Average,
+						},
+					},
+				},
+			},
+		},
+	}
+	risen := response
+	risen.Data = watcher.Data{
+		NodeMetricsMap: map[string]watcher.NodeMetrics{
+			"node-1": {Metrics: []watcher.Metric{{Type: watcher.CPU, Value: 90, Operator: watcher.Average}}},
+		},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		bytes, err := json.Marshal(response)
+		assert.Nil(t, err)
+		resp.Write(bytes)
+	}))
+	defer server.Close()
+
+	node := st.MakeNode().Name("node-1").Capacity(map[v1.ResourceName]string{
+		v1.ResourceCPU:    "1000m",
+		v1.ResourceMemory: "1Gi",
+	}).Obj()
+
+	tests := []struct {
+		test      string
+		algorithm string
+		expected  int64
+	}{
+		{
+			test:      "current utilisation packs the node",
+			algorithm: pluginConfig.ForecastAlgorithmNone,
+			expected:  85,
+		},
+		{
+			test:      "forecasted utilisation above the target penalises the node",
+			algorithm: pluginConfig.ForecastAlgorithmEWMA,
+			expected:  35,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.test, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			cs := testClientSet.NewSimpleClientset()
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+			snapshot := newTestSharedLister(nil, []*v1.Node{node})
+			registeredPlugins := []tf.RegisterPluginFunc{
+				tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+				tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+				tf.RegisterScorePlugin(Name, New, 1),
+			}
+			targetLoadPackingArgs := pluginConfig.TargetLoadPackingArgs{
+				TrimaranSpec: pluginConfig.TrimaranSpec{
+					WatcherAddress: server.URL,
+				},
+				TargetUtilization:         cfgv1.DefaultTargetUtilizationPercent,
+				DefaultRequestsMultiplier: cfgv1.DefaultRequestsMultiplier,
+				Forecast: pluginConfig.ForecastSpec{
+					Algorithm:      tt.algorithm,
+					HorizonSeconds: cfgv1.DefaultForecastHorizonSeconds,
+				},
+			}
+			fh, err := testutil.NewFramework(ctx, registeredPlugins, []config.PluginConfig{{Name: Name, Args: &targetLoadPackingArgs}},
+				"default-scheduler", runtime.WithClientSet(cs),
+				runtime.WithInformerFactory(informerFactory), runtime.WithSnapshotSharedLister(snapshot))
+			assert.Nil(t, err)
+			p, err := New(ctx, &targetLoadPackingArgs, fh)
+			assert.Nil(t, err)
+
+			// record the risen utilisation in the history shared with the plugin
+			collector, err := trimaran.GetCollector(ctx, klog.FromContext(ctx), &targetLoadPackingArgs.TrimaranSpec)
+			assert.Nil(t, err)
+			collector.GetHistory(30*time.Second, 60).Record(&risen, start.Add(30*time.Second))
+
+			score, status := p.(framework.ScorePlugin).Score(context.Background(), framework.NewCycleState(), st.MakePod().Name("p").Obj(), node.Name)
+			assert.True(t, status.IsSuccess())
+			assert.Equal(t, tt.expected, score)
+		})
+	}
+}
+
 func BenchmarkTargetLoadPackingPlugin(b *testing.B) {
 	tests := []struct {
 		name     string
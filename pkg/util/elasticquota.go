@@ -0,0 +1,161 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+)
+
+// GetElasticQuotaParent returns the namespaced name of the parent of the given ElasticQuota, and whether it has one.
+func GetElasticQuotaParent(eq *v1alpha1.ElasticQuota) (types.NamespacedName, bool) {
+	if eq.Spec.Parent == nil {
+		return types.NamespacedName{}, false
+	}
+	return types.NamespacedName{Namespace: eq.Spec.Parent.Namespace, Name: eq.Spec.Parent.Name}, true
+}
+
+// GetElasticQuotaWeight returns the weight of the given ElasticQuota when borrowing, which defaults to 1.
+func GetElasticQuotaWeight(eq *v1alpha1.ElasticQuota) int64 {
+	if eq.Spec.Weight == nil || *eq.Spec.Weight < 1 {
+		return 1
+	}
+	return int64(*eq.Spec.Weight)
+}
+
+// GetElasticQuotaAncestors returns the ancestors of the given ElasticQuota among eqs, from its parent up to its root.
+// A parent missing from eqs ends the hierarchy. It returns an error if the parents form a cycle.
+func GetElasticQuotaAncestors(eq *v1alpha1.ElasticQuota, eqs []v1alpha1.ElasticQuota) ([]*v1alpha1.ElasticQuota, error) {
+	byName := make(map[types.NamespacedName]*v1alpha1.ElasticQuota, len(eqs))
+	for i := range eqs {
+		byName[types.NamespacedName{Namespace: eqs[i].Namespace, Name: eqs[i].Name}] = &eqs[i]
+	}
+
+	self := types.NamespacedName{Namespace: eq.Namespace, Name: eq.Name}
+	visited := map[types.NamespacedName]bool{self: true}
+	var ancestors []*v1alpha1.ElasticQuota
+	for current := eq; ; {
+		parentName, ok := GetElasticQuotaParent(current)
+		if !ok {
+			return ancestors, nil
+		}
+		if visited[parentName] {
+			return nil, fmt.Errorf("the parent %v of ElasticQuota %v forms a cycle", parentName, self)
+		}
+		parent, ok := byName[parentName]
+		if !ok {
+			return ancestors, nil
+		}
+		visited[parentName] = true
+		ancestors = append(ancestors, parent)
+		current = parent
+	}
+}
+
+// IsElasticQuotaScoped checks whether the given ElasticQuota selects its pods with a namespace or pod selector,
+// rather than applying to all the pods of its namespace.
+func IsElasticQuotaScoped(eq *v1alpha1.ElasticQuota) bool {
+	return eq.Spec.NamespaceSelector != nil || eq.Spec.PodSelector != nil
+}
+
+// ElasticQuotaScope is the set of pods an ElasticQuota applies to.
+type ElasticQuotaScope struct {
+	types.NamespacedName
+	// NamespaceSelector selects the namespaces of the pods, nil for the namespace of the ElasticQuota only.
+	NamespaceSelector labels.Selector
+	// PodSelector selects the pods among the ones of the selected namespaces, nil for all of them.
+	PodSelector       labels.Selector
+	CreationTimestamp metav1.Time
+}
+
+// NewElasticQuotaScope returns the scope of the given ElasticQuota, or an error if one of its selectors is invalid.
+func NewElasticQuotaScope(eq *v1alpha1.ElasticQuota) (*ElasticQuotaScope, error) {
+	scope := &ElasticQuotaScope{
+		NamespacedName:    types.NamespacedName{Namespace: eq.Namespace, Name: eq.Name},
+		CreationTimestamp: eq.CreationTimestamp,
+	}
+	var err error
+	if eq.Spec.NamespaceSelector != nil {
+		if scope.NamespaceSelector, err = metav1.LabelSelectorAsSelector(eq.Spec.NamespaceSelector); err != nil {
+			return nil, fmt.Errorf("invalid namespace selector of ElasticQuota %v: %w", scope.NamespacedName, err)
+		}
+	}
+	if eq.Spec.PodSelector != nil {
+		if scope.PodSelector, err = metav1.LabelSelectorAsSelector(eq.Spec.PodSelector); err != nil {
+			return nil, fmt.Errorf("invalid pod selector of ElasticQuota %v: %w", scope.NamespacedName, err)
+		}
+	}
+	return scope, nil
+}
+
+// Selects checks whether the given pod, in a namespace with the given labels, is in the scope.
+func (s *ElasticQuotaScope) Selects(pod *v1.Pod, namespaceLabels labels.Set) bool {
+	if s.NamespaceSelector == nil {
+		if pod.Namespace != s.Namespace {
+			return false
+		}
+	} else if !s.NamespaceSelector.Matches(namespaceLabels) {
+		return false
+	}
+	return s.PodSelector == nil || s.PodSelector.Matches(labels.Set(pod.Labels))
+}
+
+// Precedes checks whether the scope takes precedence over the given one for the pods they both select:
+// a scope with a pod selector precedes one without, then a scope limited to its own namespace precedes one with
+// a namespace selector, then the oldest scope precedes, then the first one by namespace and name.
+func (s *ElasticQuotaScope) Precedes(other *ElasticQuotaScope) bool {
+	if (s.PodSelector != nil) != (other.PodSelector != nil) {
+		return s.PodSelector != nil
+	}
+	if (s.NamespaceSelector == nil) != (other.NamespaceSelector == nil) {
+		return s.NamespaceSelector == nil
+	}
+	if !s.CreationTimestamp.Equal(&other.CreationTimestamp) {
+		return s.CreationTimestamp.Before(&other.CreationTimestamp)
+	}
+	if s.Namespace != other.Namespace {
+		return s.Namespace < other.Namespace
+	}
+	return s.Name < other.Name
+}
+
+// GetElasticQuotasForPod returns the ElasticQuotas among eqs that select the given pod, in a namespace with the
+// given labels, by precedence. The pod is subject to the first one. ElasticQuotas with invalid selectors select
+// no pods.
+func GetElasticQuotasForPod(pod *v1.Pod, namespaceLabels labels.Set, eqs []v1alpha1.ElasticQuota) []*v1alpha1.ElasticQuota {
+	var selecting []*v1alpha1.ElasticQuota
+	scopes := make(map[*v1alpha1.ElasticQuota]*ElasticQuotaScope)
+	for i := range eqs {
+		scope, err := NewElasticQuotaScope(&eqs[i])
+		if err != nil || !scope.Selects(pod, namespaceLabels) {
+			continue
+		}
+		selecting = append(selecting, &eqs[i])
+		scopes[&eqs[i]] = scope
+	}
+	sort.Slice(selecting, func(i, j int) bool {
+		return scopes[selecting[i]].Precedes(scopes[selecting[j]])
+	})
+	return selecting
+}
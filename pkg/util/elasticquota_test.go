@@ -0,0 +1,195 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+)
+
+func makeEQ(namespace, name string, parent *v1alpha1.ElasticQuotaReference) v1alpha1.ElasticQuota {
+	return v1alpha1.ElasticQuota{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       v1alpha1.ElasticQuotaSpec{Parent: parent},
+	}
+}
+
+func TestGetElasticQuotaAncestors(t *testing.T) {
+	tests := []struct {
+		name          string
+		eqs           []v1alpha1.ElasticQuota
+		wantAncestors []string
+		wantErr       bool
+	}{
+		{
+			name: "root elastic quota",
+			eqs: []v1alpha1.ElasticQuota{
+				makeEQ("ns", "eq", nil),
+			},
+		},
+		{
+			name: "elastic quota with ancestors",
+			eqs: []v1alpha1.ElasticQuota{
+				makeEQ("ns", "eq", &v1alpha1.ElasticQuotaReference{Namespace: "team", Name: "eq"}),
+				makeEQ("team", "eq", &v1alpha1.ElasticQuotaReference{Namespace: "dept", Name: "eq"}),
+				makeEQ("dept", "eq", nil),
+			},
+			wantAncestors: []string{"team/eq", "dept/eq"},
+		},
+		{
+			name: "missing parent ends the hierarchy",
+			eqs: []v1alpha1.ElasticQuota{
+				makeEQ("ns", "eq", &v1alpha1.ElasticQuotaReference{Namespace: "team", Name: "eq"}),
+				makeEQ("team", "eq", &v1alpha1.ElasticQuotaReference{Namespace: "dept", Name: "missing"}),
+				makeEQ("dept", "eq", nil),
+			},
+			wantAncestors: []string{"team/eq"},
+		},
+		{
+			name: "elastic quota being its own parent",
+			eqs: []v1alpha1.ElasticQuota{
+				makeEQ("ns", "eq", &v1alpha1.ElasticQuotaReference{Namespace: "ns", Name: "eq"}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "parents forming a cycle",
+			eqs: []v1alpha1.ElasticQuota{
+				makeEQ("ns", "eq", &v1alpha1.ElasticQuotaReference{Namespace: "team", Name: "eq"}),
+				makeEQ("team", "eq", &v1alpha1.ElasticQuotaReference{Namespace: "dept", Name: "eq"}),
+				makeEQ("dept", "eq", &v1alpha1.ElasticQuotaReference{Namespace: "team", Name: "eq"}),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ancestors, err := GetElasticQuotaAncestors(&tt.eqs[0], tt.eqs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("want error %v, got %v", tt.wantErr, err)
+			}
+			var got []string
+			for _, ancestor := range ancestors {
+				got = append(got, ancestor.Namespace+"/"+ancestor.Name)
+			}
+			if len(got) != len(tt.wantAncestors) {
+				t.Fatalf("want ancestors %v, got %v", tt.wantAncestors, got)
+			}
+			for i := range got {
+				if got[i] != tt.wantAncestors[i] {
+					t.Errorf("want ancestors %v, got %v", tt.wantAncestors, got)
+				}
+			}
+		})
+	}
+}
+
+func makeScopedEQ(namespace, name string, created int64, namespaceSelector, podSelector *metav1.LabelSelector) v1alpha1.ElasticQuota {
+	eq := makeEQ(namespace, name, nil)
+	eq.CreationTimestamp = metav1.NewTime(time.Unix(created, 0))
+	eq.Spec.NamespaceSelector = namespaceSelector
+	eq.Spec.PodSelector = podSelector
+	return eq
+}
+
+func TestGetElasticQuotasForPod(t *testing.T) {
+	teamA := &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+	research := &metav1.LabelSelector{MatchLabels: map[string]string{"org": "research"}}
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "p", Labels: map[string]string{"team": "a"}},
+	}
+	namespaceLabels := labels.Set{"org": "research"}
+
+	tests := []struct {
+		name    string
+		eqs     []v1alpha1.ElasticQuota
+		wantEQs []string
+	}{
+		{
+			name: "elastic quota of another namespace",
+			eqs: []v1alpha1.ElasticQuota{
+				makeScopedEQ("other", "eq", 0, nil, nil),
+			},
+		},
+		{
+			name: "pod selector not matching",
+			eqs: []v1alpha1.ElasticQuota{
+				makeScopedEQ("ns", "eq", 0, nil, &metav1.LabelSelector{MatchLabels: map[string]string{"team": "b"}}),
+			},
+		},
+		{
+			name: "invalid selector selects no pods",
+			eqs: []v1alpha1.ElasticQuota{
+				makeScopedEQ("ns", "eq", 0, nil, &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: "Invalid"}}}),
+			},
+		},
+		{
+			name: "pod selector takes precedence",
+			eqs: []v1alpha1.ElasticQuota{
+				makeScopedEQ("ns", "namespace", 0, nil, nil),
+				makeScopedEQ("other", "team-a", 1, research, teamA),
+			},
+			wantEQs: []string{"other/team-a", "ns/namespace"},
+		},
+		{
+			name: "own namespace takes precedence over namespace selector",
+			eqs: []v1alpha1.ElasticQuota{
+				makeScopedEQ("other", "research", 0, research, nil),
+				makeScopedEQ("ns", "namespace", 1, nil, nil),
+			},
+			wantEQs: []string{"ns/namespace", "other/research"},
+		},
+		{
+			name: "oldest elastic quota takes precedence",
+			eqs: []v1alpha1.ElasticQuota{
+				makeScopedEQ("ns", "newer", 1, nil, teamA),
+				makeScopedEQ("ns", "older", 0, nil, teamA),
+			},
+			wantEQs: []string{"ns/older", "ns/newer"},
+		},
+		{
+			name: "first elastic quota by name takes precedence",
+			eqs: []v1alpha1.ElasticQuota{
+				makeScopedEQ("ns", "b", 0, nil, teamA),
+				makeScopedEQ("ns", "a", 0, nil, teamA),
+			},
+			wantEQs: []string{"ns/a", "ns/b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, eq := range GetElasticQuotasForPod(pod, namespaceLabels, tt.eqs) {
+				got = append(got, eq.Namespace+"/"+eq.Name)
+			}
+			if len(got) != len(tt.wantEQs) {
+				t.Fatalf("want elastic quotas %v, got %v", tt.wantEQs, got)
+			}
+			for i := range got {
+				if got[i] != tt.wantEQs[i] {
+					t.Errorf("want elastic quotas %v, got %v", tt.wantEQs, got)
+				}
+			}
+		})
+	}
+}
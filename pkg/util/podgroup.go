@@ -17,14 +17,21 @@ limitations under the License.
 package util
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	metav1ac "k8s.io/client-go/applyconfigurations/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	schedv1alpha1ac "sigs.k8s.io/scheduler-plugins/pkg/generated/applyconfiguration/scheduling/v1alpha1"
 )
 
 // DefaultWaitTime is 60s if ScheduleTimeoutSeconds is not specified.
@@ -52,6 +59,33 @@ func GetPodGroupLabel(pod *v1.Pod) string {
 	return pod.Labels[v1alpha1.PodGroupLabel]
 }
 
+// GetPodGroupRole get the role of a pod within its pod group from pod labels
+func GetPodGroupRole(pod *v1.Pod) string {
+	return pod.Labels[v1alpha1.PodGroupRoleLabel]
+}
+
+// CheckPodGroupRoles checks whether every role of the given pg is played by at least
+// its minMember pods, given the number of pods playing each role.
+// It returns an error detailing the first unsatisfied role if not; otherwise returns nil.
+func CheckPodGroupRoles(pg *v1alpha1.PodGroup, podsByRole map[string]int32) error {
+	for _, role := range pg.Spec.Roles {
+		if podsByRole[role.Name] < role.MinMember {
+			return fmt.Errorf("role %v of podGroup %v has %v pods, minMember of role: %v",
+				role.Name, pg.Name, podsByRole[role.Name], role.MinMember)
+		}
+	}
+	return nil
+}
+
+// GetPodGroupMaxMember returns the maxMember of the given pg, which is never less than its minMember,
+// and whether the pg is elastic.
+func GetPodGroupMaxMember(pg *v1alpha1.PodGroup) (int32, bool) {
+	if pg.Spec.MaxMember == nil {
+		return 0, false
+	}
+	return max(*pg.Spec.MaxMember, pg.Spec.MinMember), true
+}
+
 // GetPodGroupFullName get namespaced group name from pod labels
 func GetPodGroupFullName(pod *v1.Pod) string {
 	pgName := GetPodGroupLabel(pod)
@@ -74,3 +108,46 @@ func GetWaitTimeDuration(pg *v1alpha1.PodGroup, scheduleTimeout *time.Duration)
 	}
 	return DefaultWaitTime
 }
+
+// ChangedConditions returns the given conditions which differ from the current ones, with their
+// lastTransitionTime set as meta.SetStatusCondition does.
+func ChangedConditions(current []metav1.Condition, conditions ...metav1.Condition) []metav1.Condition {
+	updated := make([]metav1.Condition, 0, len(current)+len(conditions))
+	for i := range current {
+		updated = append(updated, *current[i].DeepCopy())
+	}
+	var changed []metav1.Condition
+	for _, condition := range conditions {
+		if meta.SetStatusCondition(&updated, condition) {
+			changed = append(changed, *meta.FindStatusCondition(updated, condition.Type))
+		}
+	}
+	return changed
+}
+
+// ApplyPodGroupConditions sets the given conditions in the status of the given pg with server-side apply.
+// Each condition type is applied by its own field manager, named after the given one and the type, so that
+// conditions are never removed, and the conditions set meanwhile by the scheduler or the controller are kept
+// instead of being replaced along with the whole list, as with a merge patch.
+func ApplyPodGroupConditions(ctx context.Context, c client.Client, pg *v1alpha1.PodGroup, fieldManager string, conditions ...metav1.Condition) error {
+	for _, condition := range conditions {
+		applyConfig := schedv1alpha1ac.PodGroup(pg.Name, pg.Namespace).WithStatus(schedv1alpha1ac.PodGroupStatus().WithConditions(
+			metav1ac.Condition().
+				WithType(condition.Type).
+				WithStatus(condition.Status).
+				WithObservedGeneration(condition.ObservedGeneration).
+				WithLastTransitionTime(condition.LastTransitionTime).
+				WithReason(condition.Reason).
+				WithMessage(condition.Message),
+		))
+		patch, err := json.Marshal(applyConfig)
+		if err != nil {
+			return err
+		}
+		if err := c.Status().Patch(ctx, pg.DeepCopy(), client.RawPatch(types.ApplyPatchType, patch),
+			client.FieldOwner(fieldManager+"-"+condition.Type), client.ForceOwnership); err != nil {
+			return err
+		}
+	}
+	return nil
+}
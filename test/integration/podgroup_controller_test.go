@@ -125,7 +125,7 @@ func TestPodGroupController(t *testing.T) {
 	if _, err := cs.CoreV1().Nodes().Create(testCtx.Ctx, node, metav1.CreateOptions{}); err != nil {
 		t.Fatalf("Failed to create Node %q: %v", nodeName, err)
 	}
-	ignoreOpts := cmpopts.IgnoreFields(v1alpha1.PodGroupStatus{}, "ScheduleStartTime")
+	ignoreOpts := cmpopts.IgnoreFields(v1alpha1.PodGroupStatus{}, "ScheduleStartTime", "Conditions", "CompletionTime")
 	// TODO: Update the number of scheduled pods when changing the Reconcile logic.
 	// PostBind is not running in this test, so the number of Scheduled pods in PodGroup is 0.
 	for _, tt := range []struct {
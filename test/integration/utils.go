@@ -122,6 +122,29 @@ func (p *podWrapper) InitContainerRequest(request v1.ResourceList) *podWrapper {
 	return p
 }
 
+func (p *podWrapper) Labels(labels map[string]string) *podWrapper {
+	p.Pod.Labels = labels
+	return p
+}
+
+func (p *podWrapper) Annotation(key, value string) *podWrapper {
+	if p.Pod.Annotations == nil {
+		p.Pod.Annotations = map[string]string{}
+	}
+	p.Pod.Annotations[key] = value
+	return p
+}
+
+func (p *podWrapper) Unschedulable(message string) *podWrapper {
+	p.Pod.Status.Conditions = append(p.Pod.Status.Conditions, v1.PodCondition{
+		Type:    v1.PodScheduled,
+		Status:  v1.ConditionFalse,
+		Reason:  v1.PodReasonUnschedulable,
+		Message: message,
+	})
+	return p
+}
+
 func (p *podWrapper) Node(name string) *podWrapper {
 	p.Pod.Spec.NodeName = name
 	return p
@@ -153,11 +176,41 @@ func (e *eqWrapper) Max(max v1.ResourceList) *eqWrapper {
 	return e
 }
 
+func (e *eqWrapper) Parent(namespace, name string) *eqWrapper {
+	e.ElasticQuota.Spec.Parent = &v1alpha1.ElasticQuotaReference{Namespace: namespace, Name: name}
+	return e
+}
+
+func (e *eqWrapper) Weight(weight int32) *eqWrapper {
+	e.ElasticQuota.Spec.Weight = &weight
+	return e
+}
+
+func (e *eqWrapper) NamespaceSelector(matchLabels map[string]string) *eqWrapper {
+	e.ElasticQuota.Spec.NamespaceSelector = &metav1.LabelSelector{MatchLabels: matchLabels}
+	return e
+}
+
+func (e *eqWrapper) PodSelector(matchLabels map[string]string) *eqWrapper {
+	e.ElasticQuota.Spec.PodSelector = &metav1.LabelSelector{MatchLabels: matchLabels}
+	return e
+}
+
 func (e *eqWrapper) Used(used v1.ResourceList) *eqWrapper {
 	e.ElasticQuota.Status.Used = used
 	return e
 }
 
+func (e *eqWrapper) Condition(conditionType string, status metav1.ConditionStatus) *eqWrapper {
+	e.ElasticQuota.Status.Conditions = append(e.ElasticQuota.Status.Conditions, metav1.Condition{Type: conditionType, Status: status})
+	return e
+}
+
+func (e *eqWrapper) PendingPods(pendingPods int32) *eqWrapper {
+	e.ElasticQuota.Status.PendingPods = pendingPods
+	return e
+}
+
 func (e *eqWrapper) Obj() *v1alpha1.ElasticQuota {
 	return e.ElasticQuota
 }
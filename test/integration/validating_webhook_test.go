@@ -0,0 +1,135 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package integration
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	schedv1alpha1 "sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/controllers"
+)
+
+func TestValidatingWebhooks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// envtest serves the webhook configurations of config/webhook from a local address, with a generated certificate.
+	webhookOptions := &envtest.WebhookInstallOptions{
+		Paths: []string{filepath.Join("..", "..", "config", "webhook")},
+	}
+	if err := webhookOptions.Install(globalKubeConfig); err != nil {
+		t.Fatalf("Failed to install the webhooks: %v", err)
+	}
+	defer webhookOptions.Cleanup()
+	cs := kubernetes.NewForConfigOrDie(globalKubeConfig)
+	defer func() {
+		for _, configuration := range webhookOptions.MutatingWebhooks {
+			cs.AdmissionregistrationV1().MutatingWebhookConfigurations().Delete(ctx, configuration.Name, metav1.DeleteOptions{})
+		}
+		for _, configuration := range webhookOptions.ValidatingWebhooks {
+			cs.AdmissionregistrationV1().ValidatingWebhookConfigurations().Delete(ctx, configuration.Name, metav1.DeleteOptions{})
+		}
+	}()
+
+	s := scheme.Scheme
+	runtime.Must(schedv1alpha1.AddToScheme(s))
+	mgr, err := ctrl.NewManager(globalKubeConfig, manager.Options{
+		Scheme: s,
+		Metrics: metricsserver.Options{
+			BindAddress: "0", // disable metrics to avoid conflicts between packages.
+		},
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Host:    webhookOptions.LocalServingHost,
+			Port:    webhookOptions.LocalServingPort,
+			CertDir: webhookOptions.LocalServingCertDir,
+		}),
+	})
+	if err != nil {
+		t.Fatal("unable to create manager", err)
+	}
+	(&controllers.ElasticQuotaValidator{}).SetupWithManager(mgr)
+	(&controllers.PodGroupValidator{}).SetupWithManager(mgr)
+	go func() {
+		if err := mgr.Start(ctx); err != nil {
+			panic(err)
+		}
+	}()
+
+	c, err := client.New(globalKubeConfig, client.Options{Scheme: s})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Create(ctx, &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "webhook-ns"}}); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Delete(ctx, &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "webhook-ns"}})
+
+	// The requests fail until the webhook server is ready.
+	valid := MakeEQ("webhook-ns", "valid").
+		Min(MakeResourceList().CPU(2).Obj()).
+		Max(MakeResourceList().CPU(4).Obj()).Obj()
+	if err := wait.PollUntilContextTimeout(ctx, 100*time.Millisecond, 10*time.Second, false, func(ctx context.Context) (bool, error) {
+		return c.Create(ctx, valid.DeepCopy()) == nil, nil
+	}); err != nil {
+		t.Fatalf("Timed out waiting for the webhook server: %v", err)
+	}
+
+	for _, tt := range []struct {
+		name       string
+		obj        client.Object
+		wantDenied string
+	}{
+		{
+			name: "elastic quota with min more than max",
+			obj: MakeEQ("webhook-ns", "min-more-than-max").
+				Min(MakeResourceList().CPU(4).Obj()).
+				Max(MakeResourceList().CPU(2).Obj()).Obj(),
+			wantDenied: "spec.min[cpu]: Invalid value: \"4\": must be less than or equal to spec.max[cpu] (2)",
+		},
+		{
+			name: "pod group without minMember",
+			obj: &schedv1alpha1.PodGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "no-min-member", Namespace: "webhook-ns"},
+			},
+			wantDenied: "spec.minMember: Invalid value: 0: must be greater than 0",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := c.Create(ctx, tt.obj)
+			if err == nil || !strings.Contains(err.Error(), tt.wantDenied) {
+				t.Errorf("want the creation to be denied with %q, got %v", tt.wantDenied, err)
+			}
+		})
+	}
+}
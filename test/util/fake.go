@@ -18,6 +18,7 @@ package util
 
 import (
 	"context"
+	"encoding/json"
 	"sync"
 
 	v1 "k8s.io/api/core/v1"
@@ -30,6 +31,7 @@ import (
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
 	"sigs.k8s.io/scheduler-plugins/pkg/util"
 
@@ -287,7 +289,12 @@ func NewFakeClient(objs ...runtime.Object) (client.WithWatch, error) {
 	if err := topologyv1alpha2.AddToScheme(scheme); err != nil {
 		return nil, err
 	}
-	return fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build(), nil
+	return fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.PodGroup{}).
+		WithRuntimeObjects(objs...).
+		WithInterceptorFuncs(PodGroupApplyInterceptor()).
+		Build(), nil
 }
 
 // NewClientOrDie returns a generic controller-runtime client or panic upon any error.
@@ -304,3 +311,45 @@ func NewClientOrDie(ctx context.Context, cfg *rest.Config) client.Client {
 	}
 	return c
 }
+
+// PodGroupApplyInterceptor returns the interceptors of a fake client emulating the server-side apply of
+// PodGroup conditions, which the fake client doesn't support. Each applied condition replaces the condition
+// of the same type, as the API server does for conditions applied with all their fields.
+func PodGroupApplyInterceptor() interceptor.Funcs {
+	return interceptor.Funcs{
+		SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object,
+			patch client.Patch, opts ...client.SubResourcePatchOption) error {
+			if patch.Type() != types.ApplyPatchType {
+				return c.SubResource(subResourceName).Patch(ctx, obj, patch, opts...)
+			}
+			data, err := patch.Data(obj)
+			if err != nil {
+				return err
+			}
+			applied := &v1alpha1.PodGroup{}
+			if err := json.Unmarshal(data, applied); err != nil {
+				return err
+			}
+			pg := &v1alpha1.PodGroup{}
+			if err := c.Get(ctx, client.ObjectKeyFromObject(applied), pg); err != nil {
+				return err
+			}
+			for _, condition := range applied.Status.Conditions {
+				replaced := false
+				for i := range pg.Status.Conditions {
+					if pg.Status.Conditions[i].Type == condition.Type {
+						pg.Status.Conditions[i] = condition
+						replaced = true
+					}
+				}
+				if !replaced {
+					pg.Status.Conditions = append(pg.Status.Conditions, condition)
+				}
+			}
+			if err := c.Status().Update(ctx, pg); err != nil {
+				return err
+			}
+			return c.Get(ctx, client.ObjectKeyFromObject(applied), obj)
+		},
+	}
+}
@@ -50,6 +50,21 @@ func (p *PodGroupWrapper) MinMember(i int32) *PodGroupWrapper {
 	return p
 }
 
+func (p *PodGroupWrapper) MaxMember(i int32) *PodGroupWrapper {
+	p.Spec.MaxMember = &i
+	return p
+}
+
+func (p *PodGroupWrapper) PreemptibleSurplus() *PodGroupWrapper {
+	p.Spec.PreemptibleSurplus = true
+	return p
+}
+
+func (p *PodGroupWrapper) PriorityClassName(s string) *PodGroupWrapper {
+	p.Spec.PriorityClassName = s
+	return p
+}
+
 func (p *PodGroupWrapper) Time(t time.Time) *PodGroupWrapper {
 	p.CreationTimestamp.Time = t
 	return p
@@ -68,3 +83,13 @@ func (p *PodGroupWrapper) Phase(phase v1alpha1.PodGroupPhase) *PodGroupWrapper {
 	p.Status.Phase = phase
 	return p
 }
+
+func (p *PodGroupWrapper) TopologyPreference(preference v1alpha1.TopologyPreference) *PodGroupWrapper {
+	p.Spec.TopologyPreference = preference
+	return p
+}
+
+func (p *PodGroupWrapper) Role(name string, minMember int32) *PodGroupWrapper {
+	p.Spec.Roles = append(p.Spec.Roles, v1alpha1.PodGroupRole{Name: name, MinMember: minMember})
+	return p
+}